@@ -41,6 +41,9 @@ func main() {
 	r.Route("/accounts", func(r chi.Router) {
 		r.Handle("/*", paymentProxy)
 	})
+	r.Route("/payments", func(r chi.Router) {
+		r.Handle("/*", paymentProxy)
+	})
 
 	r.Route("/swagger/payment", func(r chi.Router) {
 		r.Handle("/*", paymentProxy)
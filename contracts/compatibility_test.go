@@ -0,0 +1,195 @@
+package contracts
+
+import (
+	"bytes"
+	pb "contracts/pb/contracts/v1"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// Файлы testdata фиксируют сообщения, которые уже передаются между сервисами.
+// Изменение формата, ломающее их разбор, требует новой версии сообщения.
+var update = flag.Bool("update", false, "rewrite golden files in testdata")
+
+var goldenRequest = PaymentRequest{Id: 42, UserId: 7, AccountId: 3, Amount: 150.25, Date: time.Date(2025, 10, 30, 12, 0, 0, 0, time.UTC)}
+
+var goldenResult = PaymentResult{TransactionId: 42, Status: PaymentDeclined, ErrorCode: ErrorInsufficientFunds, Message: "insufficient funds"}
+
+// golden возвращает содержимое файла name из testdata,
+// а с флагом -update предварительно записывает в него data.
+func golden(t *testing.T, name string, data []byte) []byte {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		err := os.WriteFile(path, data, 0o644)
+		if err != nil {
+			t.Fatalf("write golden file: %v", err)
+		}
+	}
+	return readGolden(t, name)
+}
+
+func readGolden(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("read golden file: %v", err)
+	}
+	return data
+}
+
+func TestGolden_V1Messages(t *testing.T) {
+	files := map[Encoding]string{EncodingJSON: "json", EncodingProtobuf: "binpb"}
+	for encoding, ext := range files {
+		for _, payload := range []Payload{&goldenRequest, &goldenResult} {
+			data, err := Marshal(encoding, "corr-42", payload)
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+			name := string(payload.MessageType()) + "_v1." + ext
+			if want := golden(t, name, data); !bytes.Equal(data, want) {
+				t.Errorf("%s: encoding changed:\n got %q\nwant %q", name, data, want)
+			}
+		}
+	}
+}
+
+func TestGolden_DecodesStoredMessages(t *testing.T) {
+	files := map[Encoding]string{EncodingJSON: "json", EncodingProtobuf: "binpb"}
+	for encoding, ext := range files {
+		var request PaymentRequest
+		_, err := Unmarshal(encoding, readGolden(t, "payment.request_v1."+ext), &request)
+		if err != nil {
+			t.Fatalf("%s: unmarshal request: %v", encoding, err)
+		}
+		if !request.Date.Equal(goldenRequest.Date) {
+			t.Errorf("%s: expected date %v, got %v", encoding, goldenRequest.Date, request.Date)
+		}
+		request.Date = goldenRequest.Date
+		if request != goldenRequest {
+			t.Errorf("%s: expected request %+v, got %+v", encoding, goldenRequest, request)
+		}
+
+		var result PaymentResult
+		_, err = Unmarshal(encoding, readGolden(t, "payment.result_v1."+ext), &result)
+		if err != nil {
+			t.Fatalf("%s: unmarshal result: %v", encoding, err)
+		}
+		if result != goldenResult {
+			t.Errorf("%s: expected result %+v, got %+v", encoding, goldenResult, result)
+		}
+	}
+}
+
+func TestLegacy_RequestMatchesV1Payload(t *testing.T) {
+	request, err := UnmarshalLegacyPaymentRequest(readGolden(t, "payment.request_legacy.json"))
+	if err != nil {
+		t.Fatalf("unmarshal legacy request: %v", err)
+	}
+	if !request.Date.Equal(goldenRequest.Date) {
+		t.Errorf("expected date %v, got %v", goldenRequest.Date, request.Date)
+	}
+	request.Date = goldenRequest.Date
+	if request != goldenRequest {
+		t.Errorf("expected request %+v, got %+v", goldenRequest, request)
+	}
+}
+
+func TestLegacy_ResultRoundTrip(t *testing.T) {
+	results := []PaymentResult{
+		{Status: PaymentApproved},
+		{Status: PaymentDeclined, ErrorCode: ErrorLimitExceeded, Message: "daily limit exceeded"},
+		{Status: PaymentPendingReview, ErrorCode: ErrorPendingReview, Message: "transaction is pending manual review"},
+		{Status: PaymentFailed, Message: "database is down"},
+	}
+	for _, want := range results {
+		body, code := want.Legacy()
+		got := ParseLegacyPaymentResult(body, code)
+		if got != want {
+			t.Errorf("expected %+v, got %+v (body %q, code %q)", want, got, body, code)
+		}
+	}
+	body, code := goldenResult.Legacy()
+	if body != "Error processing transaction: insufficient funds" || code != "INSUFFICIENT_FUNDS" {
+		t.Errorf("unexpected legacy reply %q, %q", body, code)
+	}
+}
+
+func TestCompatibility_IgnoresUnknownFields(t *testing.T) {
+	data := []byte(`{"type":"payment.result","version":1,"correlation_id":"c","trace_id":"t",` +
+		`"payload":{"transaction_id":1,"status":"APPROVED","currency":"RUB"}}`)
+	var result PaymentResult
+	_, err := Unmarshal(EncodingJSON, data, &result)
+	if err != nil {
+		t.Fatalf("json: unexpected error: %v", err)
+	}
+	if result.Status != PaymentApproved {
+		t.Errorf("json: expected APPROVED, got %s", result.Status)
+	}
+
+	payload, err := proto.Marshal(&pb.PaymentResult{TransactionId: 1, Status: pb.PaymentStatus_PAYMENT_STATUS_APPROVED})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	payload = protowire.AppendTag(payload, 15, protowire.BytesType)
+	payload = protowire.AppendString(payload, "RUB")
+	data, err = proto.Marshal(&pb.Envelope{Type: string(TypePaymentResult), Version: 1, Payload: payload})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	result = PaymentResult{}
+	_, err = Unmarshal(EncodingProtobuf, data, &result)
+	if err != nil {
+		t.Fatalf("protobuf: unexpected error: %v", err)
+	}
+	if result.Status != PaymentApproved || result.TransactionId != 1 {
+		t.Errorf("protobuf: unexpected result %+v", result)
+	}
+}
+
+func TestCompatibility_UnknownStatusIsFailure(t *testing.T) {
+	payload, err := proto.Marshal(&pb.PaymentResult{TransactionId: 1, Status: pb.PaymentStatus(99), ErrorCode: "NEW_CODE"})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	data, err := proto.Marshal(&pb.Envelope{Type: string(TypePaymentResult), Version: 1, Payload: payload})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var result PaymentResult
+	_, err = Unmarshal(EncodingProtobuf, data, &result)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Status != PaymentFailed || result.ErrorCode != "NEW_CODE" {
+		t.Errorf("unexpected result %+v", result)
+	}
+}
+
+func TestCompatibility_RejectsNewerVersion(t *testing.T) {
+	data := []byte(`{"type":"payment.request","version":2,"correlation_id":"c","payload":{"id":1}}`)
+	var request PaymentRequest
+	envelope, err := Unmarshal(EncodingJSON, data, &request)
+	if !errors.Is(err, ErrUnsupportedVersion) {
+		t.Fatalf("json: expected ErrUnsupportedVersion, got %v", err)
+	}
+	if envelope.CorrelationId != "c" {
+		t.Errorf("expected correlation id to be reported, got %q", envelope.CorrelationId)
+	}
+
+	data, err = proto.Marshal(&pb.Envelope{Type: string(TypePaymentRequest), Version: 2})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	_, err = Unmarshal(EncodingProtobuf, data, &request)
+	if !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("protobuf: expected ErrUnsupportedVersion, got %v", err)
+	}
+}
@@ -0,0 +1,174 @@
+// Package contracts описывает сообщения, которыми сервисы обмениваются через Kafka.
+//
+// Каждое сообщение передаётся в конверте (Envelope) с типом, версией,
+// идентификатором корреляции и содержимым. Конверт кодируется в JSON или Protobuf;
+// кодировка передаётся в заголовке ContentTypeHeader.
+package contracts
+
+import (
+	pb "contracts/pb/contracts/v1"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// ContentTypeHeader — заголовок Kafka-сообщения с кодировкой конверта.
+// Сообщение без заголовка передано в прежнем формате без конверта (LegacyVersion).
+const ContentTypeHeader = "content-type"
+
+// LegacyVersion — версия сообщений, которые передавались без конверта.
+const LegacyVersion = 0
+
+// Encoding — кодировка конверта и его содержимого.
+type Encoding string
+
+const (
+	EncodingJSON     Encoding = "application/json"       // Конверт и содержимое в JSON
+	EncodingProtobuf Encoding = "application/x-protobuf" // Конверт и содержимое в Protobuf
+)
+
+// ParseEncoding возвращает кодировку по значению заголовка ContentTypeHeader.
+func ParseEncoding(value string) (Encoding, error) {
+	switch encoding := Encoding(value); encoding {
+	case EncodingJSON, EncodingProtobuf:
+		return encoding, nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnsupportedEncoding, value)
+}
+
+// MessageType — тип содержимого конверта.
+type MessageType string
+
+const (
+	TypePaymentRequest MessageType = "payment.request" // Запрос на оплату (PaymentRequest)
+	TypePaymentResult  MessageType = "payment.result"  // Ответ на запрос на оплату (PaymentResult)
+)
+
+var (
+	// ErrUnsupportedEncoding означает, что кодировка сообщения неизвестна.
+	ErrUnsupportedEncoding = errors.New("unsupported encoding")
+	// ErrUnexpectedType означает, что конверт содержит сообщение другого типа.
+	ErrUnexpectedType = errors.New("unexpected message type")
+	// ErrUnsupportedVersion означает, что версия сообщения новее, чем известна получателю.
+	ErrUnsupportedVersion = errors.New("unsupported message version")
+	// ErrMalformedMessage означает, что сообщение не удалось разобрать.
+	ErrMalformedMessage = errors.New("malformed message")
+)
+
+// Envelope — заголовок конверта сообщения.
+type Envelope struct {
+	Type          MessageType // Тип содержимого
+	Version       int         // Версия содержимого; LegacyVersion — сообщение без конверта
+	CorrelationId string      // Идентификатор корреляции запроса и ответа
+}
+
+// Payload — содержимое конверта.
+// Поля, которых нет у получателя, игнорируются, поэтому новые поля добавляются
+// без смены версии; версия повышается только при несовместимом изменении.
+type Payload interface {
+	// MessageType возвращает тип содержимого.
+	MessageType() MessageType
+	// Version возвращает версию, в которой содержимое кодируется.
+	Version() int
+
+	toProto() proto.Message
+	newProto() proto.Message
+	fromProto(message proto.Message)
+}
+
+// jsonEnvelope — представление конверта в JSON.
+type jsonEnvelope struct {
+	Type          MessageType     `json:"type"`
+	Version       int             `json:"version"`
+	CorrelationId string          `json:"correlation_id"`
+	Payload       json.RawMessage `json:"payload"`
+}
+
+// Marshal кодирует payload в конверт с идентификатором корреляции correlationId.
+func Marshal(encoding Encoding, correlationId string, payload Payload) ([]byte, error) {
+	switch encoding {
+	case EncodingJSON:
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("encoding %s payload: %w", payload.MessageType(), err)
+		}
+		return json.Marshal(jsonEnvelope{
+			Type:          payload.MessageType(),
+			Version:       payload.Version(),
+			CorrelationId: correlationId,
+			Payload:       data,
+		})
+	case EncodingProtobuf:
+		data, err := proto.Marshal(payload.toProto())
+		if err != nil {
+			return nil, fmt.Errorf("encoding %s payload: %w", payload.MessageType(), err)
+		}
+		return proto.Marshal(&pb.Envelope{
+			Type:          string(payload.MessageType()),
+			Version:       int32(payload.Version()),
+			CorrelationId: correlationId,
+			Payload:       data,
+		})
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnsupportedEncoding, encoding)
+}
+
+// Unmarshal разбирает конверт data в кодировке encoding и записывает содержимое в payload.
+//
+// Возвращает ошибку, если конверт содержит сообщение другого типа (ErrUnexpectedType),
+// версия сообщения новее версии payload (ErrUnsupportedVersion)
+// или сообщение не удалось разобрать (ErrMalformedMessage).
+func Unmarshal(encoding Encoding, data []byte, payload Payload) (Envelope, error) {
+	var envelope Envelope
+	switch encoding {
+	case EncodingJSON:
+		var message jsonEnvelope
+		err := json.Unmarshal(data, &message)
+		if err != nil {
+			return Envelope{}, fmt.Errorf("%w: %w", ErrMalformedMessage, err)
+		}
+		envelope = Envelope{Type: message.Type, Version: message.Version, CorrelationId: message.CorrelationId}
+		err = checkEnvelope(envelope, payload)
+		if err != nil {
+			return envelope, err
+		}
+		err = json.Unmarshal(message.Payload, payload)
+		if err != nil {
+			return envelope, fmt.Errorf("%w: %w", ErrMalformedMessage, err)
+		}
+	case EncodingProtobuf:
+		var message pb.Envelope
+		err := proto.Unmarshal(data, &message)
+		if err != nil {
+			return Envelope{}, fmt.Errorf("%w: %w", ErrMalformedMessage, err)
+		}
+		envelope = Envelope{Type: MessageType(message.Type), Version: int(message.Version), CorrelationId: message.CorrelationId}
+		err = checkEnvelope(envelope, payload)
+		if err != nil {
+			return envelope, err
+		}
+		content := payload.newProto()
+		err = proto.Unmarshal(message.Payload, content)
+		if err != nil {
+			return envelope, fmt.Errorf("%w: %w", ErrMalformedMessage, err)
+		}
+		payload.fromProto(content)
+	default:
+		return Envelope{}, fmt.Errorf("%w: %q", ErrUnsupportedEncoding, encoding)
+	}
+	return envelope, nil
+}
+
+// checkEnvelope проверяет, что конверт содержит сообщение типа payload
+// в версии, которую получатель умеет разбирать.
+func checkEnvelope(envelope Envelope, payload Payload) error {
+	if envelope.Type != payload.MessageType() {
+		return fmt.Errorf("%w: got %q, want %q", ErrUnexpectedType, envelope.Type, payload.MessageType())
+	}
+	if envelope.Version < 1 || envelope.Version > payload.Version() {
+		return fmt.Errorf("%w: %s version %d", ErrUnsupportedVersion, envelope.Type, envelope.Version)
+	}
+	return nil
+}
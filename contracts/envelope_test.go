@@ -0,0 +1,93 @@
+package contracts
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+var encodings = []Encoding{EncodingJSON, EncodingProtobuf}
+
+func TestMarshal_RoundTrip(t *testing.T) {
+	request := PaymentRequest{Id: 7, UserId: 3, AccountId: 12, Amount: 99.5, Date: time.Date(2025, 10, 30, 12, 0, 0, 0, time.UTC)}
+	result := PaymentResult{TransactionId: 7, Status: PaymentDeclined, ErrorCode: ErrorLimitExceeded, Message: "limit exceeded"}
+
+	for _, encoding := range encodings {
+		t.Run(string(encoding), func(t *testing.T) {
+			data, err := Marshal(encoding, "corr-1", &request)
+			if err != nil {
+				t.Fatalf("marshal request: %v", err)
+			}
+			var gotRequest PaymentRequest
+			envelope, err := Unmarshal(encoding, data, &gotRequest)
+			if err != nil {
+				t.Fatalf("unmarshal request: %v", err)
+			}
+			wantEnvelope := Envelope{Type: TypePaymentRequest, Version: PaymentRequestVersion, CorrelationId: "corr-1"}
+			if envelope != wantEnvelope {
+				t.Errorf("expected envelope %+v, got %+v", wantEnvelope, envelope)
+			}
+			if !gotRequest.Date.Equal(request.Date) {
+				t.Errorf("expected date %v, got %v", request.Date, gotRequest.Date)
+			}
+			gotRequest.Date = request.Date
+			if gotRequest != request {
+				t.Errorf("expected request %+v, got %+v", request, gotRequest)
+			}
+
+			data, err = Marshal(encoding, "corr-1", &result)
+			if err != nil {
+				t.Fatalf("marshal result: %v", err)
+			}
+			var gotResult PaymentResult
+			_, err = Unmarshal(encoding, data, &gotResult)
+			if err != nil {
+				t.Fatalf("unmarshal result: %v", err)
+			}
+			if gotResult != result {
+				t.Errorf("expected result %+v, got %+v", result, gotResult)
+			}
+		})
+	}
+}
+
+func TestUnmarshal_RejectsOtherType(t *testing.T) {
+	for _, encoding := range encodings {
+		data, err := Marshal(encoding, "corr-1", &PaymentResult{Status: PaymentApproved})
+		if err != nil {
+			t.Fatalf("marshal: %v", err)
+		}
+		var request PaymentRequest
+		_, err = Unmarshal(encoding, data, &request)
+		if !errors.Is(err, ErrUnexpectedType) {
+			t.Errorf("%s: expected ErrUnexpectedType, got %v", encoding, err)
+		}
+	}
+}
+
+func TestUnmarshal_RejectsMalformedMessage(t *testing.T) {
+	for _, encoding := range encodings {
+		var request PaymentRequest
+		_, err := Unmarshal(encoding, []byte("{not a message"), &request)
+		if !errors.Is(err, ErrMalformedMessage) {
+			t.Errorf("%s: expected ErrMalformedMessage, got %v", encoding, err)
+		}
+	}
+}
+
+func TestParseEncoding(t *testing.T) {
+	for _, encoding := range encodings {
+		got, err := ParseEncoding(string(encoding))
+		if err != nil || got != encoding {
+			t.Errorf("expected %s, got %s (%v)", encoding, got, err)
+		}
+	}
+	_, err := ParseEncoding("text/plain")
+	if !errors.Is(err, ErrUnsupportedEncoding) {
+		t.Errorf("expected ErrUnsupportedEncoding, got %v", err)
+	}
+	_, err = Marshal("text/plain", "", &PaymentRequest{})
+	if !errors.Is(err, ErrUnsupportedEncoding) {
+		t.Errorf("expected ErrUnsupportedEncoding from Marshal, got %v", err)
+	}
+}
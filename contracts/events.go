@@ -0,0 +1,22 @@
+package contracts
+
+// EventTypeHeader — заголовок сообщения топика событий payment-service с типом события.
+const EventTypeHeader = "event-type"
+
+// EventPaymentReviewed — тип события payment-service о решении администратора
+// по платежу, задержанному до ручной проверки.
+const EventPaymentReviewed = "PaymentReviewed"
+
+// Итоговые статусы транзакции в событии EventPaymentReviewed.
+const (
+	TransactionCompleted = "completed" // Платёж проведён
+	TransactionDeclined  = "declined"  // Платёж отклонён, код причины в FailureCode
+)
+
+// PaymentReviewed — поля события EventPaymentReviewed, которые читают другие сервисы.
+// Событие публикуется в топик событий счетов в JSON без конверта.
+type PaymentReviewed struct {
+	TransactionId     int       `json:"transaction_id"`         // Идентификатор задержанной транзакции
+	TransactionStatus string    `json:"transaction_status"`     // Итоговый статус транзакции
+	FailureCode       ErrorCode `json:"failure_code,omitempty"` // Код причины отказа для отклонённого платежа
+}
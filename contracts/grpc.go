@@ -0,0 +1,31 @@
+package contracts
+
+import pb "contracts/pb/contracts/v1"
+
+// Методы gRPC-сервиса PaymentService (pb.PaymentServiceServer) принимают и возвращают
+// те же сообщения, что передаются в конвертах, поэтому преобразования совпадают.
+
+// PaymentRequestToProto возвращает запрос на оплату в представлении Protobuf.
+func PaymentRequestToProto(request PaymentRequest) *pb.PaymentRequest {
+	return request.toProto().(*pb.PaymentRequest)
+}
+
+// PaymentRequestFromProto возвращает запрос на оплату из представления Protobuf.
+func PaymentRequestFromProto(message *pb.PaymentRequest) PaymentRequest {
+	var request PaymentRequest
+	request.fromProto(message)
+	return request
+}
+
+// PaymentResultToProto возвращает результат оплаты в представлении Protobuf.
+func PaymentResultToProto(result PaymentResult) *pb.PaymentResult {
+	return result.toProto().(*pb.PaymentResult)
+}
+
+// PaymentResultFromProto возвращает результат оплаты из представления Protobuf.
+// Неизвестный получателю статус считается сбоем обработки.
+func PaymentResultFromProto(message *pb.PaymentResult) PaymentResult {
+	var result PaymentResult
+	result.fromProto(message)
+	return result
+}
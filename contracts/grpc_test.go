@@ -0,0 +1,19 @@
+package contracts
+
+import "testing"
+
+func TestProto_RoundTrip(t *testing.T) {
+	request := PaymentRequestFromProto(PaymentRequestToProto(goldenRequest))
+	if !request.Date.Equal(goldenRequest.Date) {
+		t.Errorf("expected date %v, got %v", goldenRequest.Date, request.Date)
+	}
+	request.Date = goldenRequest.Date
+	if request != goldenRequest {
+		t.Errorf("expected request %+v, got %+v", goldenRequest, request)
+	}
+
+	result := PaymentResultFromProto(PaymentResultToProto(goldenResult))
+	if result != goldenResult {
+		t.Errorf("expected result %+v, got %+v", goldenResult, result)
+	}
+}
@@ -0,0 +1,58 @@
+package contracts
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Сообщения LegacyVersion передавались без конверта: запрос — JSON транзакции,
+// ответ — строка "OK" или текст ошибки, а код причины отказа — в заголовке "reason-code".
+// Функции ниже позволяют обмениваться сообщениями с сервисами, которые ещё не перешли на конверт.
+
+const (
+	legacyApproved    = "OK"
+	legacyErrorPrefix = "Error processing transaction: "
+)
+
+// UnmarshalLegacyPaymentRequest разбирает запрос на оплату, переданный без конверта.
+func UnmarshalLegacyPaymentRequest(data []byte) (PaymentRequest, error) {
+	var request PaymentRequest
+	err := json.Unmarshal(data, &request)
+	if err != nil {
+		return PaymentRequest{}, fmt.Errorf("%w: %w", ErrMalformedMessage, err)
+	}
+	return request, nil
+}
+
+// ParseLegacyPaymentResult возвращает результат по ответу без конверта:
+// телу body и коду причины reasonCode из заголовка ответа.
+func ParseLegacyPaymentResult(body string, reasonCode string) PaymentResult {
+	if body == legacyApproved {
+		return PaymentResult{Status: PaymentApproved}
+	}
+	result := PaymentResult{
+		Status:    PaymentFailed,
+		ErrorCode: ErrorCode(reasonCode),
+		Message:   strings.TrimPrefix(body, legacyErrorPrefix),
+	}
+	switch {
+	case result.ErrorCode == ErrorPendingReview:
+		result.Status = PaymentPendingReview
+	case result.ErrorCode != "":
+		result.Status = PaymentDeclined
+	}
+	return result
+}
+
+// Legacy возвращает тело ответа и код причины для заголовка в формате без конверта.
+// Для сбоя обработки код причины не передаётся.
+func (r *PaymentResult) Legacy() (body string, reasonCode string) {
+	switch r.Status {
+	case PaymentApproved:
+		return legacyApproved, ""
+	case PaymentDeclined, PaymentPendingReview:
+		return legacyErrorPrefix + r.Message, string(r.ErrorCode)
+	}
+	return legacyErrorPrefix + r.Message, ""
+}
@@ -0,0 +1,131 @@
+package contracts
+
+import (
+	pb "contracts/pb/contracts/v1"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	PaymentRequestVersion = 1 // Текущая версия PaymentRequest
+	PaymentResultVersion  = 1 // Текущая версия PaymentResult
+)
+
+// PaymentRequest — запрос order-service на списание или пополнение счёта.
+// Поля JSON совпадают с прежним форматом запроса без конверта.
+type PaymentRequest struct {
+	Id        int       `json:"id"`         // Идентификатор транзакции
+	UserId    int       `json:"user_id"`    // ID пользователя, к которому относится транзакция
+	AccountId int       `json:"account_id"` // ID кошелька; 0 — основной кошелёк пользователя
+	IsDeposit bool      `json:"is_deposit"` // true — пополнение, false — списание
+	Amount    float64   `json:"amount"`     // Сумма транзакции
+	Date      time.Time `json:"date"`       // Дата и время проведения транзакции
+}
+
+func (r *PaymentRequest) MessageType() MessageType { return TypePaymentRequest }
+
+func (r *PaymentRequest) Version() int { return PaymentRequestVersion }
+
+func (r *PaymentRequest) toProto() proto.Message {
+	return &pb.PaymentRequest{
+		Id:        int64(r.Id),
+		UserId:    int64(r.UserId),
+		AccountId: int64(r.AccountId),
+		IsDeposit: r.IsDeposit,
+		Amount:    r.Amount,
+		Date:      timestamppb.New(r.Date),
+	}
+}
+
+func (r *PaymentRequest) newProto() proto.Message { return &pb.PaymentRequest{} }
+
+func (r *PaymentRequest) fromProto(message proto.Message) {
+	m := message.(*pb.PaymentRequest)
+	*r = PaymentRequest{
+		Id:        int(m.Id),
+		UserId:    int(m.UserId),
+		AccountId: int(m.AccountId),
+		IsDeposit: m.IsDeposit,
+		Amount:    m.Amount,
+	}
+	if m.Date != nil {
+		r.Date = m.Date.AsTime()
+	}
+}
+
+// PaymentStatus — итог обработки запроса на оплату.
+type PaymentStatus string
+
+const (
+	PaymentApproved      PaymentStatus = "APPROVED"       // Транзакция проведена
+	PaymentDeclined      PaymentStatus = "DECLINED"       // Отказ по бизнес-правилам, код в ErrorCode
+	PaymentPendingReview PaymentStatus = "PENDING_REVIEW" // Транзакция задержана до ручной проверки
+	PaymentFailed        PaymentStatus = "FAILED"         // Запрос не удалось обработать
+)
+
+// ErrorCode — машиночитаемый код причины отказа или сбоя.
+type ErrorCode string
+
+const (
+	ErrorInvalidAmount       ErrorCode = "INVALID_AMOUNT"        // Некорректная сумма платежа
+	ErrorInsufficientFunds   ErrorCode = "INSUFFICIENT_FUNDS"    // На счёте недостаточно средств
+	ErrorLimitExceeded       ErrorCode = "LIMIT_EXCEEDED"        // Превышено ограничение на списания
+	ErrorOperationNotAllowed ErrorCode = "OPERATION_NOT_ALLOWED" // Статус счёта запрещает операцию
+	ErrorAccountNotFound     ErrorCode = "ACCOUNT_NOT_FOUND"     // Кошелёк не найден
+	ErrorForeignAccount      ErrorCode = "FOREIGN_ACCOUNT"       // Кошелёк принадлежит другому пользователю
+	ErrorRejectedByReview    ErrorCode = "REJECTED_BY_REVIEW"    // Платёж отклонён при ручной проверке
+	ErrorPendingReview       ErrorCode = "PENDING_REVIEW"        // Платёж задержан до ручной проверки
+	ErrorInvalidMessage      ErrorCode = "INVALID_MESSAGE"       // Запрос не удалось разобрать
+	ErrorInvalidSignature    ErrorCode = "INVALID_SIGNATURE"     // Подпись запроса отсутствует, устарела или неверна
+	ErrorInternal            ErrorCode = "INTERNAL"              // Сбой при обработке запроса
+)
+
+// PaymentResult — ответ payment-service на запрос на оплату.
+type PaymentResult struct {
+	TransactionId int           `json:"transaction_id"`       // Идентификатор транзакции из запроса
+	Status        PaymentStatus `json:"status"`               // Итог обработки
+	ErrorCode     ErrorCode     `json:"error_code,omitempty"` // Код причины для DECLINED, PENDING_REVIEW и FAILED
+	Message       string        `json:"message,omitempty"`    // Описание причины для человека
+}
+
+func (r *PaymentResult) MessageType() MessageType { return TypePaymentResult }
+
+func (r *PaymentResult) Version() int { return PaymentResultVersion }
+
+// paymentStatuses сопоставляет статусы результата значениям перечисления Protobuf.
+var paymentStatuses = map[PaymentStatus]pb.PaymentStatus{
+	PaymentApproved:      pb.PaymentStatus_PAYMENT_STATUS_APPROVED,
+	PaymentDeclined:      pb.PaymentStatus_PAYMENT_STATUS_DECLINED,
+	PaymentPendingReview: pb.PaymentStatus_PAYMENT_STATUS_PENDING_REVIEW,
+	PaymentFailed:        pb.PaymentStatus_PAYMENT_STATUS_FAILED,
+}
+
+func (r *PaymentResult) toProto() proto.Message {
+	return &pb.PaymentResult{
+		TransactionId: int64(r.TransactionId),
+		Status:        paymentStatuses[r.Status],
+		ErrorCode:     string(r.ErrorCode),
+		Message:       r.Message,
+	}
+}
+
+func (r *PaymentResult) newProto() proto.Message { return &pb.PaymentResult{} }
+
+// fromProto переносит результат из Protobuf; неизвестный получателю статус
+// считается сбоем обработки.
+func (r *PaymentResult) fromProto(message proto.Message) {
+	m := message.(*pb.PaymentResult)
+	*r = PaymentResult{
+		TransactionId: int(m.TransactionId),
+		Status:        PaymentFailed,
+		ErrorCode:     ErrorCode(m.ErrorCode),
+		Message:       m.Message,
+	}
+	for status, value := range paymentStatuses {
+		if value == m.Status {
+			r.Status = status
+		}
+	}
+}
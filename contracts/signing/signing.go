@@ -0,0 +1,278 @@
+// Package signing подписывает сообщения между сервисами и проверяет подписи,
+// чтобы получатель принимал команды только от отправителей, знающих ключ.
+//
+// Подпись покрывает ключ, тело и все заголовки сообщения, кроме самой подписи,
+// в том числе идентификатор ключа KeyIdHeader и время подписи SignedAtHeader.
+// Алгоритм определяется ключом получателя, а не заголовком сообщения.
+package signing
+
+import (
+	"contracts/transport"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	SignatureHeader = "signature"        // Подпись сообщения в base64
+	KeyIdHeader     = "signature-key-id" // Идентификатор ключа подписи
+	SignedAtHeader  = "signed-at"        // Время подписи в миллисекундах Unix
+)
+
+// Algorithm — алгоритм подписи.
+type Algorithm string
+
+const (
+	HMACSHA256 Algorithm = "hmac-sha256" // Общий секрет отправителя и получателя
+	Ed25519    Algorithm = "ed25519"     // Закрытый ключ у отправителя, открытый — у получателя
+)
+
+// minHMACKeySize — минимальная длина секрета HMAC в байтах.
+const minHMACKeySize = 32
+
+// canonicalPrefix отделяет подписи этого формата от подписей других форматов.
+const canonicalPrefix = "payment-command-signature-v1"
+
+var (
+	// ErrUnsigned означает, что сообщение не подписано.
+	ErrUnsigned = errors.New("message is not signed")
+	// ErrUnknownKey означает, что сообщение подписано ключом, которого нет у получателя.
+	ErrUnknownKey = errors.New("unknown signing key")
+	// ErrInvalidSignature означает, что подпись не совпала с содержимым сообщения.
+	ErrInvalidSignature = errors.New("invalid signature")
+	// ErrStale означает, что сообщение записано в транспорт слишком долго после подписи
+	// или время подписи в будущем. Подпись такого сообщения действительна.
+	ErrStale = errors.New("stale signature")
+)
+
+// Key — ключ подписи.
+type Key struct {
+	Id        string    // Идентификатор ключа; передаётся в заголовке KeyIdHeader
+	Algorithm Algorithm // Алгоритм подписи
+	// Material — секрет HMAC; для Ed25519 у отправителя — закрытый ключ или его 32-байтовое
+	// начальное значение, у получателя — открытый ключ.
+	Material []byte
+}
+
+// ParseKey разбирает ключ в формате "id:algorithm:base64".
+func ParseKey(value string) (Key, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 3 || parts[0] == "" {
+		return Key{}, errors.New("signing key must be in format id:algorithm:base64")
+	}
+	material, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Key{}, fmt.Errorf("signing key %s is not valid base64: %w", parts[0], err)
+	}
+	key := Key{Id: parts[0], Algorithm: Algorithm(parts[1]), Material: material}
+	switch key.Algorithm {
+	case HMACSHA256:
+		if len(material) < minHMACKeySize {
+			return Key{}, fmt.Errorf("signing key %s must be at least %d bytes", key.Id, minHMACKeySize)
+		}
+	case Ed25519:
+		if len(material) != ed25519.PublicKeySize && len(material) != ed25519.PrivateKeySize {
+			return Key{}, fmt.Errorf("signing key %s must be %d or %d bytes", key.Id, ed25519.PublicKeySize, ed25519.PrivateKeySize)
+		}
+	default:
+		return Key{}, fmt.Errorf("signing key %s: unsupported algorithm %q", key.Id, key.Algorithm)
+	}
+	return key, nil
+}
+
+// ParseKeys разбирает список ключей, разделённых ';'.
+// Идентификаторы ключей в списке не должны повторяться.
+func ParseKeys(value string) ([]Key, error) {
+	keys := make([]Key, 0)
+	ids := make(map[string]bool)
+	for _, part := range strings.Split(value, ";") {
+		key, err := ParseKey(part)
+		if err != nil {
+			return nil, err
+		}
+		if ids[key.Id] {
+			return nil, fmt.Errorf("signing key %s is listed twice", key.Id)
+		}
+		ids[key.Id] = true
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// Signer подписывает сообщения одним ключом.
+type Signer struct {
+	key        Key
+	privateKey ed25519.PrivateKey
+	now        func() time.Time
+}
+
+// NewSigner создаёт Signer с ключом key.
+func NewSigner(key Key) *Signer {
+	signer := &Signer{key: key, now: time.Now}
+	if key.Algorithm == Ed25519 {
+		signer.privateKey = ed25519.PrivateKey(key.Material)
+		if len(key.Material) == ed25519.SeedSize {
+			signer.privateKey = ed25519.NewKeyFromSeed(key.Material)
+		}
+	}
+	return signer
+}
+
+// Sign устанавливает заголовки KeyIdHeader и SignedAtHeader и подписывает сообщение.
+// Сообщение нельзя изменять после подписи.
+func (s *Signer) Sign(message *transport.Message) {
+	message.SetHeader(KeyIdHeader, s.key.Id)
+	message.SetHeader(SignedAtHeader, strconv.FormatInt(s.now().UnixMilli(), 10))
+	data := canonical(message)
+	var signature []byte
+	switch s.key.Algorithm {
+	case HMACSHA256:
+		mac := hmac.New(sha256.New, s.key.Material)
+		mac.Write(data)
+		signature = mac.Sum(nil)
+	case Ed25519:
+		signature = ed25519.Sign(s.privateKey, data)
+	}
+	message.SetHeader(SignatureHeader, base64.StdEncoding.EncodeToString(signature))
+}
+
+// Verifier проверяет подписи сообщений набором ключей.
+// Несколько ключей позволяют менять ключ отправителя без остановки получателя.
+type Verifier struct {
+	keys   map[string]Key
+	maxAge time.Duration
+	now    func() time.Time
+}
+
+// NewVerifier создаёт Verifier, который принимает сообщения, подписанные любым из ключей keys
+// не раньше чем maxAge назад. Закрытые ключи Ed25519 заменяются открытыми.
+func NewVerifier(keys []Key, maxAge time.Duration) *Verifier {
+	verifier := &Verifier{keys: make(map[string]Key, len(keys)), maxAge: maxAge, now: time.Now}
+	for _, key := range keys {
+		if key.Algorithm == Ed25519 && len(key.Material) == ed25519.PrivateKeySize {
+			key.Material = ed25519.PrivateKey(key.Material).Public().(ed25519.PublicKey)
+		}
+		verifier.keys[key.Id] = key
+	}
+	return verifier
+}
+
+// Verify проверяет подпись сообщения.
+//
+// Возвращает ErrUnsigned, если подписи нет, ErrUnknownKey, если ключ неизвестен,
+// ErrInvalidSignature, если подпись не совпадает с сообщением, и ErrStale, если время подписи
+// отличается от времени записи сообщения в транспорт (transport.Message.Time) больше чем на maxAge.
+// Если время записи неизвестно, время подписи сравнивается с текущим.
+//
+// Поэтому сообщение, которое получатель обрабатывает с опозданием, не устаревает,
+// а переотправленное с прежней подписью — устаревает: его нужно подписать заново.
+// Время записи должен ставить транспорт, а не отправитель (для Kafka — метка времени брокера
+// LogAppendTime), иначе отправитель может выдать старое сообщение за новое. Если такого
+// времени нет, получатель указывает время получения сообщения.
+func (v *Verifier) Verify(message *transport.Message) error {
+	encoded := message.Header(SignatureHeader)
+	if encoded == "" {
+		return ErrUnsigned
+	}
+	keyId := message.Header(KeyIdHeader)
+	key, ok := v.keys[keyId]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownKey, keyId)
+	}
+	signature, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+	}
+	var valid bool
+	switch key.Algorithm {
+	case HMACSHA256:
+		mac := hmac.New(sha256.New, key.Material)
+		mac.Write(canonical(message))
+		valid = hmac.Equal(signature, mac.Sum(nil))
+	case Ed25519:
+		valid = ed25519.Verify(ed25519.PublicKey(key.Material), canonical(message), signature)
+	}
+	if !valid {
+		return ErrInvalidSignature
+	}
+
+	// Время подписи проверяется после подписи: заголовок SignedAtHeader подписан.
+	signedAt, err := strconv.ParseInt(message.Header(SignedAtHeader), 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: malformed %s header", ErrInvalidSignature, SignedAtHeader)
+	}
+	writtenAt := message.Time
+	if writtenAt.IsZero() {
+		writtenAt = v.now()
+	}
+	age := writtenAt.Sub(time.UnixMilli(signedAt))
+	if age > v.maxAge || age < -v.maxAge {
+		return fmt.Errorf("%w: signed %s before it was sent", ErrStale, age.Round(time.Millisecond))
+	}
+	return nil
+}
+
+// Resigner подписывает заново переотправляемые сообщения, подпись которых устарела.
+//
+// Переотправка не должна превращать неподписанные или поддельные сообщения в подписанные,
+// поэтому заново подписываются только сообщения с действительной подписью. Ключ оператора,
+// которым подписывает Resigner, должен быть среди ключей проверки получателя.
+type Resigner struct {
+	signer   *Signer
+	verifier *Verifier
+}
+
+// NewResigner создаёт Resigner, который проверяет подписи verifier и подписывает заново signer.
+func NewResigner(signer *Signer, verifier *Verifier) *Resigner {
+	return &Resigner{signer: signer, verifier: verifier}
+}
+
+// Resign проверяет подпись сообщения так, как её проверит получатель после переотправки,
+// то есть относительно текущего времени. Сообщение с устаревшей подписью (ErrStale)
+// подписывается заново, а с действительной — не изменяется.
+// Для остальных сообщений возвращается ошибка проверки; такие сообщения переотправлять нельзя.
+func (r *Resigner) Resign(message *transport.Message) error {
+	replay := *message
+	replay.Time = time.Time{}
+	err := r.verifier.Verify(&replay)
+	if errors.Is(err, ErrStale) {
+		r.signer.Sign(message)
+		return nil
+	}
+	return err
+}
+
+// canonical возвращает подписываемое представление сообщения: ключ, тело и заголовки,
+// кроме SignatureHeader, в порядке имён. Каждое поле предваряется длиной,
+// поэтому разные сообщения не дают одинакового представления.
+func canonical(message *transport.Message) []byte {
+	names := make([]string, 0, len(message.Headers))
+	for name := range message.Headers {
+		if name != SignatureHeader {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	data := appendField(nil, []byte(canonicalPrefix))
+	data = appendField(data, message.Key)
+	data = appendField(data, message.Value)
+	for _, name := range names {
+		data = appendField(data, []byte(name))
+		data = appendField(data, []byte(message.Headers[name]))
+	}
+	return data
+}
+
+func appendField(data []byte, field []byte) []byte {
+	data = binary.BigEndian.AppendUint32(data, uint32(len(field)))
+	return append(data, field...)
+}
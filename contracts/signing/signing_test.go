@@ -0,0 +1,220 @@
+package signing
+
+import (
+	"bytes"
+	"contracts/transport"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+)
+
+var hmacKey = Key{Id: "k1", Algorithm: HMACSHA256, Material: bytes.Repeat([]byte("s"), 32)}
+
+func newMessage() *transport.Message {
+	return &transport.Message{
+		Key:   []byte("10"),
+		Value: []byte(`{"type":"payment.request","payload":{"amount":50}}`),
+		Headers: map[string]string{
+			"content-type":          "application/json",
+			"correlation-id":        "c-1",
+			transport.ReplyToHeader: "response.order-1",
+		},
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	cases := []struct {
+		name   string
+		signer Key
+		keys   []Key
+	}{
+		{"hmac", hmacKey, []Key{hmacKey}},
+		{"ed25519", Key{Id: "e1", Algorithm: Ed25519, Material: private}, []Key{{Id: "e1", Algorithm: Ed25519, Material: public}}},
+		{"ed25519 seed", Key{Id: "e1", Algorithm: Ed25519, Material: private.Seed()}, []Key{{Id: "e1", Algorithm: Ed25519, Material: private}}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			message := newMessage()
+			NewSigner(c.signer).Sign(message)
+			if message.Header(KeyIdHeader) != c.signer.Id || message.Header(SignatureHeader) == "" {
+				t.Fatalf("expected signature headers, got %+v", message.Headers)
+			}
+			verifier := NewVerifier(c.keys, time.Minute)
+			if err := verifier.Verify(message); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			message.Value = []byte(`{"type":"payment.request","payload":{"amount":5000}}`)
+			if err := verifier.Verify(message); !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("expected ErrInvalidSignature for changed payload, got %v", err)
+			}
+		})
+	}
+}
+
+func TestVerify_RejectsChangedHeaders(t *testing.T) {
+	message := newMessage()
+	NewSigner(hmacKey).Sign(message)
+	verifier := NewVerifier([]Key{hmacKey}, time.Minute)
+
+	message.SetHeader(transport.ReplyToHeader, "response.attacker")
+	if err := verifier.Verify(message); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature for changed header, got %v", err)
+	}
+	message.SetHeader(transport.ReplyToHeader, "response.order-1")
+	message.SetHeader("extra", "value")
+	if err := verifier.Verify(message); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature for added header, got %v", err)
+	}
+}
+
+func TestVerify_RejectsUnsignedUnknownAndStale(t *testing.T) {
+	verifier := NewVerifier([]Key{hmacKey}, time.Minute)
+	if err := verifier.Verify(newMessage()); !errors.Is(err, ErrUnsigned) {
+		t.Errorf("expected ErrUnsigned, got %v", err)
+	}
+
+	other := Key{Id: "k2", Algorithm: HMACSHA256, Material: bytes.Repeat([]byte("o"), 32)}
+	message := newMessage()
+	NewSigner(other).Sign(message)
+	if err := verifier.Verify(message); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("expected ErrUnknownKey, got %v", err)
+	}
+
+	signer := NewSigner(hmacKey)
+	signer.now = func() time.Time { return time.Now().Add(-2 * time.Minute) }
+	message = newMessage()
+	signer.Sign(message)
+	if err := verifier.Verify(message); !errors.Is(err, ErrStale) {
+		t.Errorf("expected ErrStale for old message, got %v", err)
+	}
+	signer.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	message = newMessage()
+	signer.Sign(message)
+	if err := verifier.Verify(message); !errors.Is(err, ErrStale) {
+		t.Errorf("expected ErrStale for message from the future, got %v", err)
+	}
+}
+
+func TestVerify_AgeFromWriteTime(t *testing.T) {
+	verifier := NewVerifier([]Key{hmacKey}, time.Minute)
+	signedAt := time.Now()
+	signer := NewSigner(hmacKey)
+	signer.now = func() time.Time { return signedAt }
+
+	// Получатель отстал от топика: сообщение записано сразу после подписи, а проверяется позже maxAge.
+	lagging := newMessage()
+	signer.Sign(lagging)
+	lagging.Time = signedAt
+	verifier.now = func() time.Time { return signedAt.Add(10 * time.Minute) }
+	if err := verifier.Verify(lagging); err != nil {
+		t.Errorf("expected lagging message to be accepted, got %v", err)
+	}
+
+	// Переотправка записывает сообщение заново, позже maxAge после подписи.
+	replayed := newMessage()
+	signer.Sign(replayed)
+	replayed.Time = signedAt.Add(10 * time.Minute)
+	if err := verifier.Verify(replayed); !errors.Is(err, ErrStale) {
+		t.Errorf("expected ErrStale for replayed message, got %v", err)
+	}
+	NewSigner(hmacKey).Sign(replayed)
+	replayed.Time = time.Now()
+	verifier.now = time.Now
+	if err := verifier.Verify(replayed); err != nil {
+		t.Errorf("expected re-signed message to be accepted, got %v", err)
+	}
+}
+
+func TestResigner(t *testing.T) {
+	operator := Key{Id: "operator", Algorithm: HMACSHA256, Material: bytes.Repeat([]byte("p"), 32)}
+	verifier := NewVerifier([]Key{hmacKey, operator}, time.Minute)
+	resigner := NewResigner(NewSigner(operator), verifier)
+	old := NewSigner(hmacKey)
+	old.now = func() time.Time { return time.Now().Add(-10 * time.Minute) }
+
+	stale := newMessage()
+	old.Sign(stale)
+	stale.Time = time.Now().Add(-10 * time.Minute)
+	if err := resigner.Resign(stale); err != nil {
+		t.Fatalf("expected stale message to be re-signed, got %v", err)
+	}
+	stale.Time = time.Time{}
+	if err := verifier.Verify(stale); err != nil || stale.Header(KeyIdHeader) != "operator" {
+		t.Errorf("expected message signed by the operator key, got %v, key %q", err, stale.Header(KeyIdHeader))
+	}
+
+	fresh := newMessage()
+	NewSigner(hmacKey).Sign(fresh)
+	signature := fresh.Header(SignatureHeader)
+	if err := resigner.Resign(fresh); err != nil || fresh.Header(SignatureHeader) != signature {
+		t.Errorf("expected fresh message to be left as is, got %v", err)
+	}
+
+	forged := newMessage()
+	old.Sign(forged)
+	forged.Value = []byte(`{"amount":1000}`)
+	unknown := newMessage()
+	NewSigner(Key{Id: "k2", Algorithm: HMACSHA256, Material: bytes.Repeat([]byte("o"), 32)}).Sign(unknown)
+	for name, c := range map[string]struct {
+		message *transport.Message
+		want    error
+	}{
+		"unsigned":    {newMessage(), ErrUnsigned},
+		"forged":      {forged, ErrInvalidSignature},
+		"unknown key": {unknown, ErrUnknownKey},
+	} {
+		signature := c.message.Header(SignatureHeader)
+		if err := resigner.Resign(c.message); !errors.Is(err, c.want) {
+			t.Errorf("%s: expected %v, got %v", name, c.want, err)
+		}
+		if c.message.Header(SignatureHeader) != signature {
+			t.Errorf("%s: message must not be re-signed", name)
+		}
+	}
+}
+
+func TestVerify_KeyRotation(t *testing.T) {
+	next := Key{Id: "k2", Algorithm: HMACSHA256, Material: bytes.Repeat([]byte("n"), 32)}
+	verifier := NewVerifier([]Key{hmacKey, next}, time.Minute)
+	for _, key := range []Key{hmacKey, next} {
+		message := newMessage()
+		NewSigner(key).Sign(message)
+		if err := verifier.Verify(message); err != nil {
+			t.Errorf("%s: unexpected error: %v", key.Id, err)
+		}
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	secret := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte("s"), 32))
+	public, _, _ := ed25519.GenerateKey(nil)
+	keys, err := ParseKeys("old:hmac-sha256:" + secret + ";new:ed25519:" + base64.StdEncoding.EncodeToString(public))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(keys) != 2 || keys[0].Id != "old" || keys[1].Algorithm != Ed25519 || !bytes.Equal(keys[1].Material, public) {
+		t.Errorf("unexpected keys %+v", keys)
+	}
+
+	invalid := []string{
+		"",
+		"old:hmac-sha256",
+		"old:hmac-sha256:not-base64!",
+		"old:hmac-sha256:" + base64.StdEncoding.EncodeToString([]byte("short")),
+		"old:ed25519:" + secret[:8],
+		"old:rsa:" + secret,
+		"old:hmac-sha256:" + secret + ";old:hmac-sha256:" + secret,
+	}
+	for _, value := range invalid {
+		if _, err := ParseKeys(value); err == nil {
+			t.Errorf("expected error for %q", value)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrTopicServed возвращается при попытке подписать второй обработчик на тот же топик.
+var ErrTopicServed = errors.New("topic already has a handler")
+
+// InMemory — брокер сообщений в памяти процесса.
+// Позволяет запустить сервисы в одном процессе (например, в одном тестовом бинарнике)
+// и обмениваться запросами и ответами без Kafka.
+//
+// Запрос передаётся обработчику топика синхронно. Если обработчика ещё нет,
+// запрос ждёт его появления — так же, как сообщение ждёт потребителя в топике Kafka.
+// Опубликованные события хранятся в памяти: они доступны через Messages
+// и передаются подписчикам топика через Subscribe.
+type InMemory struct {
+	mu         sync.Mutex
+	handlers   map[string]*subscription
+	registered chan struct{}        // Закрывается и заменяется при подписке нового обработчика
+	published  map[string][]Message // Опубликованные сообщения по топикам
+	updated    chan struct{}        // Закрывается и заменяется при публикации сообщений
+}
+
+// subscription — обработчик топика и счётчик запросов, которые он обрабатывает.
+type subscription struct {
+	handler  Handler
+	inFlight sync.WaitGroup
+}
+
+// NewInMemory создаёт пустой брокер в памяти.
+func NewInMemory() *InMemory {
+	return &InMemory{
+		handlers:   make(map[string]*subscription),
+		registered: make(chan struct{}),
+		published:  make(map[string][]Message),
+		updated:    make(chan struct{}),
+	}
+}
+
+// Serve передаёт запросы топика topic обработчику handler, пока не будет отменён ctx.
+// После отмены новые запросы ждут следующего обработчика, а Serve дожидается
+// обработки уже полученных запросов и возвращается.
+func (b *InMemory) Serve(ctx context.Context, topic string, handler Handler) error {
+	b.mu.Lock()
+	if _, ok := b.handlers[topic]; ok {
+		b.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrTopicServed, topic)
+	}
+	sub := &subscription{handler: handler}
+	b.handlers[topic] = sub
+	close(b.registered)
+	b.registered = make(chan struct{})
+	b.mu.Unlock()
+
+	<-ctx.Done()
+
+	b.mu.Lock()
+	delete(b.handlers, topic)
+	b.mu.Unlock()
+	sub.inFlight.Wait()
+	return nil
+}
+
+// Request передаёт запрос message обработчику топика topic и возвращает его ответ.
+// Ждёт появления обработчика, пока не будет отменён ctx.
+func (b *InMemory) Request(ctx context.Context, topic string, message *Message) (*Message, error) {
+	for {
+		b.mu.Lock()
+		sub, ok := b.handlers[topic]
+		if ok {
+			sub.inFlight.Add(1)
+			b.mu.Unlock()
+			defer sub.inFlight.Done()
+			return sub.handler(ctx, message)
+		}
+		registered := b.registered
+		b.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("waiting for handler of topic %s: %w", topic, ctx.Err())
+		case <-registered:
+		}
+	}
+}
+
+// Publish сохраняет сообщения messages в топике topic в переданном порядке.
+func (b *InMemory) Publish(topic string, messages ...Message) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.published[topic] = append(b.published[topic], messages...)
+	close(b.updated)
+	b.updated = make(chan struct{})
+}
+
+// Subscribe передаёт обработчику handler сообщения топика topic в порядке публикации,
+// начиная с первого, пока не будет отменён ctx, — как потребитель новой группы в Kafka.
+// Каждый подписчик получает все сообщения топика.
+func (b *InMemory) Subscribe(ctx context.Context, topic string, handler func(ctx context.Context, message Message)) {
+	delivered := 0
+	for {
+		b.mu.Lock()
+		pending := append([]Message(nil), b.published[topic][delivered:]...)
+		updated := b.updated
+		b.mu.Unlock()
+
+		for _, message := range pending {
+			handler(ctx, message)
+		}
+		delivered += len(pending)
+		if len(pending) > 0 {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-updated:
+		}
+	}
+}
+
+// Messages возвращает копию сообщений, опубликованных в топике topic.
+func (b *InMemory) Messages(topic string) []Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]Message(nil), b.published[topic]...)
+}
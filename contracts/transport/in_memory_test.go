@@ -0,0 +1,134 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func echo(ctx context.Context, message *Message) (*Message, error) {
+	reply := &Message{Key: message.Key, Value: append([]byte("re: "), message.Value...)}
+	reply.SetHeader("correlation-id", message.Header("correlation-id"))
+	return reply, nil
+}
+
+func TestInMemory_RequestWaitsForHandler(t *testing.T) {
+	broker := NewInMemory()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	replies := make(chan *Message, 1)
+	go func() {
+		request := &Message{Key: []byte("1"), Value: []byte("ping")}
+		request.SetHeader("correlation-id", "c-1")
+		reply, err := broker.Request(ctx, "requests", request)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		replies <- reply
+	}()
+
+	served := make(chan error, 1)
+	go func() { served <- broker.Serve(ctx, "requests", echo) }()
+
+	select {
+	case reply := <-replies:
+		if string(reply.Value) != "re: ping" || reply.Header("correlation-id") != "c-1" {
+			t.Errorf("unexpected reply %+v", reply)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("request was not delivered to the handler")
+	}
+
+	cancel()
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Serve did not return after cancel")
+	}
+}
+
+func TestInMemory_RequestCancelled(t *testing.T) {
+	broker := NewInMemory()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := broker.Request(ctx, "requests", &Message{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestInMemory_SingleHandlerPerTopic(t *testing.T) {
+	broker := NewInMemory()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		_ = broker.Serve(ctx, "requests", echo)
+		close(done)
+	}()
+	_, err := broker.Request(ctx, "requests", &Message{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = broker.Serve(ctx, "requests", echo)
+	if !errors.Is(err, ErrTopicServed) {
+		t.Errorf("expected ErrTopicServed, got %v", err)
+	}
+	cancel()
+	<-done
+}
+
+func TestInMemory_Publish(t *testing.T) {
+	broker := NewInMemory()
+	broker.Publish("events", Message{Value: []byte("1")}, Message{Value: []byte("2")})
+	broker.Publish("events", Message{Value: []byte("3")})
+	messages := broker.Messages("events")
+	if len(messages) != 3 || string(messages[0].Value) != "1" || string(messages[2].Value) != "3" {
+		t.Errorf("unexpected messages %+v", messages)
+	}
+	messages[0].Value = []byte("changed")
+	if string(broker.Messages("events")[0].Value) != "1" {
+		t.Error("Messages must return a copy")
+	}
+}
+
+func TestInMemory_Subscribe(t *testing.T) {
+	broker := NewInMemory()
+	broker.Publish("events", Message{Value: []byte("1")})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	received := make(chan string, 3)
+	done := make(chan struct{})
+	go func() {
+		broker.Subscribe(ctx, "events", func(ctx context.Context, message Message) {
+			received <- string(message.Value)
+		})
+		close(done)
+	}()
+	broker.Publish("events", Message{Value: []byte("2")}, Message{Value: []byte("3")})
+	broker.Publish("other", Message{Value: []byte("4")})
+
+	for _, expected := range []string{"1", "2", "3"} {
+		select {
+		case value := <-received:
+			if value != expected {
+				t.Errorf("expected message %s, got %s", expected, value)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("message %s was not delivered", expected)
+		}
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Subscribe did not return after cancel")
+	}
+	if len(received) != 0 {
+		t.Errorf("unexpected message from another topic: %s", <-received)
+	}
+}
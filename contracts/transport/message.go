@@ -0,0 +1,39 @@
+// Package transport описывает сообщения между сервисами без привязки к брокеру
+// и содержит брокер в памяти процесса для запуска сервисов без Kafka.
+package transport
+
+import (
+	"context"
+	"time"
+)
+
+// ReplyToHeader — заголовок запроса с адресом, по которому отправитель ожидает ответ;
+// для Kafka — топик ответов экземпляра отправителя.
+// Запрос без заголовка получает ответ по адресу ответов по умолчанию.
+const ReplyToHeader = "reply-to"
+
+// Message — сообщение транспорта.
+type Message struct {
+	Id      string            // Идентификатор доставки в транспорте; для Kafka — топик/партиция/смещение
+	Key     []byte            // Ключ сообщения; определяет порядок обработки сообщений
+	Value   []byte            // Тело сообщения
+	Headers map[string]string // Заголовки сообщения
+	Time    time.Time         // Время записи сообщения в транспорт, которому доверяет получатель; нулевое, если неизвестно
+}
+
+// Header возвращает значение заголовка key или пустую строку, если заголовка нет.
+func (m *Message) Header(key string) string {
+	return m.Headers[key]
+}
+
+// SetHeader устанавливает значение заголовка key.
+func (m *Message) SetHeader(key string, value string) {
+	if m.Headers == nil {
+		m.Headers = make(map[string]string)
+	}
+	m.Headers[key] = value
+}
+
+// Handler обрабатывает сообщение и возвращает ответ на него.
+// Вместе с ошибкой обработчик может вернуть ответ с её описанием.
+type Handler func(ctx context.Context, message *Message) (*Message, error)
@@ -0,0 +1,352 @@
+// Package e2e запускает order-service и payment-service в одном процессе,
+// связывая их брокером сообщений в памяти вместо Kafka или по gRPC.
+//
+// Тестам нужны две базы данных с применёнными миграциями сервисов:
+// E2E_PAYMENT_DATABASE_URL и E2E_ORDER_DATABASE_URL. Без них тесты пропускаются.
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"contracts"
+	"contracts/signing"
+	"contracts/transport"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	orderapp "order-service/app"
+	orderconfig "order-service/config"
+	"os"
+	paymentapp "payment-service/app"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// services — запущенные в тесте сервисы.
+type services struct {
+	payment    *httptest.Server
+	order      *httptest.Server
+	paymentApp *paymentapp.App
+	signer     *signing.Signer // Подписывает запросы ключом, который проверяет payment-service
+}
+
+func connect(t *testing.T, ctx context.Context, url string) *pgxpool.Pool {
+	t.Helper()
+	db, err := pgxpool.New(ctx, url)
+	if err != nil {
+		t.Fatalf("failed to connect to db: %v", err)
+	}
+	err = db.Ping(ctx)
+	if err != nil {
+		db.Close()
+		t.Fatalf("failed to ping db: %v", err)
+	}
+	t.Cleanup(db.Close)
+	return db
+}
+
+// startServices запускает оба сервиса с транспортом memory поверх общего брокера.
+// paymentClient выбирает клиент payment-service в order-service: bus или grpc.
+func startServices(t *testing.T, paymentClient string) services {
+	t.Helper()
+	paymentDb, orderDb := envOrSkip(t, "E2E_PAYMENT_DATABASE_URL"), envOrSkip(t, "E2E_ORDER_DATABASE_URL")
+	ctx, cancel := context.WithCancel(context.Background())
+	broker := transport.NewInMemory()
+
+	t.Setenv("HTTP_PORT", "0")
+	t.Setenv("MESSAGE_BUS", "memory")
+	t.Setenv("KAFKA_REQUEST_TOPIC", "request")
+	t.Setenv("KAFKA_RESPONSE_TOPIC", "response")
+	t.Setenv("PAYMENT_PROVIDER_SECRET", "e2e-secret")
+	t.Setenv("KAFKA_REPLY_TIMEOUT", "5s")
+	// Запросы на оплату подписываются order-service и проверяются payment-service.
+	signingKey := "e2e:hmac-sha256:" + base64.StdEncoding.EncodeToString([]byte("e2e-signing-secret-of-32-bytes!!"))
+	t.Setenv("REQUEST_SIGNING_KEY", signingKey)
+	t.Setenv("REQUEST_VERIFICATION_KEYS", signingKey)
+	key, err := signing.ParseKey(signingKey)
+	if err != nil {
+		t.Fatalf("signing key: %v", err)
+	}
+
+	t.Setenv("DATABASE_URL", paymentDb)
+	paymentCfg, err := paymentapp.LoadConfig()
+	if err != nil {
+		t.Fatalf("payment config: %v", err)
+	}
+	payment, err := paymentapp.New(ctx, paymentCfg, connect(t, ctx, paymentDb), broker)
+	if err != nil {
+		t.Fatalf("payment service: %v", err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("grpc listener: %v", err)
+	}
+	go payment.GrpcServer.Serve(listener)
+	t.Setenv("PAYMENT_CLIENT", paymentClient)
+	t.Setenv("PAYMENT_GRPC_ADDR", listener.Addr().String())
+
+	t.Setenv("DATABASE_URL", orderDb)
+	orderCfg, err := orderconfig.LoadConfig()
+	if err != nil {
+		t.Fatalf("order config: %v", err)
+	}
+	order, err := orderapp.New(ctx, orderCfg, connect(t, ctx, orderDb), broker)
+	if err != nil {
+		t.Fatalf("order service: %v", err)
+	}
+
+	paymentDone := make(chan struct{})
+	go func() {
+		payment.Start(ctx)
+		close(paymentDone)
+	}()
+	go order.Start(ctx)
+	s := services{
+		payment:    httptest.NewServer(payment.Handler),
+		order:      httptest.NewServer(order.Handler),
+		paymentApp: payment,
+		signer:     signing.NewSigner(key),
+	}
+	t.Cleanup(func() {
+		s.order.Close()
+		s.payment.Close()
+		payment.GrpcServer.Stop()
+		cancel()
+		<-paymentDone
+		payment.Close()
+		order.Close()
+	})
+	return s
+}
+
+func envOrSkip(t *testing.T, key string) string {
+	t.Helper()
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		t.Skipf("%s is not set", key)
+	}
+	return value
+}
+
+// call выполняет HTTP-запрос и декодирует JSON-ответ в out, если он передан.
+func call(t *testing.T, method, url string, body any, out any) *http.Response {
+	t.Helper()
+	var payload bytes.Buffer
+	if body != nil {
+		err := json.NewEncoder(&payload).Encode(body)
+		if err != nil {
+			t.Fatalf("encode request: %v", err)
+		}
+	}
+	req, err := http.NewRequest(method, url, &payload)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, url, err)
+	}
+	defer resp.Body.Close()
+	if out != nil && resp.StatusCode < 300 {
+		err = json.NewDecoder(resp.Body).Decode(out)
+		if err != nil {
+			t.Fatalf("decode %s %s: %v", method, url, err)
+		}
+	}
+	return resp
+}
+
+type account struct {
+	Id      int     `json:"id"`
+	Balance float64 `json:"balance"`
+}
+
+type order struct {
+	Id                   int  `json:"id"`
+	IsPayed              bool `json:"is_payed"`
+	PendingTransactionId *int `json:"pending_transaction_id"`
+}
+
+func TestPaymentFlow(t *testing.T) {
+	testPaymentFlow(t, startServices(t, "bus"))
+}
+
+func TestPaymentFlow_Grpc(t *testing.T) {
+	testPaymentFlow(t, startServices(t, "grpc"))
+}
+
+// testPaymentFlow оплачивает заказ в пределах кредитного лимита
+// и проверяет отказ в оплате заказа сверх лимита.
+func testPaymentFlow(t *testing.T, s services) {
+	t.Helper()
+	userId := int(time.Now().UnixNano() % 1_000_000_000)
+
+	var wallet account
+	resp := call(t, http.MethodPost, s.payment.URL+"/accounts",
+		map[string]any{"user_id": userId, "name": "main", "currency": "RUB", "is_default": true}, &wallet)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected wallet to be created, got %d", resp.StatusCode)
+	}
+	resp = call(t, http.MethodPut, fmt.Sprintf("%s/admin/accounts/%d/credit-limit", s.payment.URL, wallet.Id),
+		map[string]any{"credit_limit": 500, "grace_period_days": 30}, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected credit limit to be set, got %d", resp.StatusCode)
+	}
+
+	var paid order
+	call(t, http.MethodPost, s.order.URL+"/orders", map[string]any{"user_id": userId, "item_id": 1, "price": 300}, &paid)
+	resp = call(t, http.MethodPatch, fmt.Sprintf("%s/orders/%d", s.order.URL, paid.Id), nil, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected order to be paid, got %d", resp.StatusCode)
+	}
+	call(t, http.MethodGet, fmt.Sprintf("%s/orders/%d", s.order.URL, paid.Id), nil, &paid)
+	if !paid.IsPayed {
+		t.Errorf("expected order %d to be marked as paid", paid.Id)
+	}
+	call(t, http.MethodGet, fmt.Sprintf("%s/accounts/%d", s.payment.URL, wallet.Id), nil, &wallet)
+	if wallet.Balance != -300 {
+		t.Errorf("expected balance -300, got %.2f", wallet.Balance)
+	}
+
+	var declined order
+	call(t, http.MethodPost, s.order.URL+"/orders", map[string]any{"user_id": userId, "item_id": 2, "price": 400}, &declined)
+	resp = call(t, http.MethodPatch, fmt.Sprintf("%s/orders/%d", s.order.URL, declined.Id), nil, nil)
+	if resp.StatusCode != http.StatusPaymentRequired || resp.Header.Get("X-Decline-Reason") != "INSUFFICIENT_FUNDS" {
+		t.Errorf("expected 402 INSUFFICIENT_FUNDS, got %d %q", resp.StatusCode, resp.Header.Get("X-Decline-Reason"))
+	}
+	call(t, http.MethodGet, fmt.Sprintf("%s/accounts/%d", s.payment.URL, wallet.Id), nil, &wallet)
+	if wallet.Balance != -300 {
+		t.Errorf("expected declined payment to keep balance -300, got %.2f", wallet.Balance)
+	}
+}
+
+// TestPaymentReview проверяет, что решение администратора по платежу, задержанному
+// до ручной проверки, доходит до order-service событием и завершает оплату заказа.
+func TestPaymentReview(t *testing.T) {
+	t.Setenv("RISK_LARGE_AMOUNT", "1000")
+	s := startServices(t, "bus")
+	ctx := context.Background()
+	userId := int(time.Now().UnixNano() % 1_000_000_000)
+
+	var wallet account
+	resp := call(t, http.MethodPost, s.payment.URL+"/accounts",
+		map[string]any{"user_id": userId, "name": "main", "currency": "RUB", "is_default": true}, &wallet)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected wallet to be created, got %d", resp.StatusCode)
+	}
+	resp = call(t, http.MethodPut, fmt.Sprintf("%s/admin/accounts/%d/credit-limit", s.payment.URL, wallet.Id),
+		map[string]any{"credit_limit": 5000, "grace_period_days": 30}, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected credit limit to be set, got %d", resp.StatusCode)
+	}
+
+	// hold оформляет заказ на сумму price и возвращает его после задержки оплаты до проверки.
+	hold := func(price float64) order {
+		t.Helper()
+		var held order
+		call(t, http.MethodPost, s.order.URL+"/orders", map[string]any{"user_id": userId, "item_id": 1, "price": price}, &held)
+		resp := call(t, http.MethodPatch, fmt.Sprintf("%s/orders/%d", s.order.URL, held.Id), nil, nil)
+		if resp.StatusCode != http.StatusAccepted {
+			t.Fatalf("expected payment to be held for review, got %d", resp.StatusCode)
+		}
+		call(t, http.MethodGet, fmt.Sprintf("%s/orders/%d", s.order.URL, held.Id), nil, &held)
+		if held.PendingTransactionId == nil {
+			t.Fatalf("expected order %d to await the review", held.Id)
+		}
+		return held
+	}
+	// await ждёт, пока событие с решением не изменит заказ.
+	await := func(id int) order {
+		t.Helper()
+		var current order
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			_, err := s.paymentApp.EventRelay.PublishPending(ctx, 100)
+			if err != nil {
+				t.Fatalf("publish events: %v", err)
+			}
+			call(t, http.MethodGet, fmt.Sprintf("%s/orders/%d", s.order.URL, id), nil, &current)
+			if current.PendingTransactionId == nil {
+				return current
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+		t.Fatalf("order %d did not receive the review decision", id)
+		return current
+	}
+
+	approved := hold(2000)
+	resp = call(t, http.MethodPost, fmt.Sprintf("%s/admin/reviews/%d/approve", s.payment.URL, *approved.PendingTransactionId),
+		map[string]any{"decided_by": "admin"}, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected review to be approved, got %d", resp.StatusCode)
+	}
+	if approved = await(approved.Id); !approved.IsPayed {
+		t.Errorf("expected approved order %d to be paid", approved.Id)
+	}
+
+	rejected := hold(1500)
+	resp = call(t, http.MethodPost, fmt.Sprintf("%s/admin/reviews/%d/reject", s.payment.URL, *rejected.PendingTransactionId),
+		map[string]any{"decided_by": "admin"}, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected review to be rejected, got %d", resp.StatusCode)
+	}
+	if rejected = await(rejected.Id); rejected.IsPayed {
+		t.Errorf("expected rejected order %d to stay unpaid", rejected.Id)
+	}
+	call(t, http.MethodGet, fmt.Sprintf("%s/accounts/%d", s.payment.URL, wallet.Id), nil, &wallet)
+	if wallet.Balance != -2000 {
+		t.Errorf("expected only the approved payment to be charged, got balance %.2f", wallet.Balance)
+	}
+}
+
+// TestPaymentDryRun проверяет, что пробная обработка запроса возвращает ответ
+// payment-service, но не меняет баланс и не мешает затем обработать запрос по-настоящему.
+func TestPaymentDryRun(t *testing.T) {
+	s := startServices(t, "bus")
+	ctx := context.Background()
+	userId := int(time.Now().UnixNano() % 1_000_000_000)
+
+	var wallet account
+	resp := call(t, http.MethodPost, s.payment.URL+"/accounts",
+		map[string]any{"user_id": userId, "name": "main", "currency": "RUB", "is_default": true}, &wallet)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected wallet to be created, got %d", resp.StatusCode)
+	}
+
+	request := contracts.PaymentRequest{Id: userId, UserId: userId, IsDeposit: true, Amount: 100, Date: time.Now()}
+	correlationId := fmt.Sprintf("dry-run-%d", userId)
+	value, err := contracts.Marshal(contracts.EncodingJSON, correlationId, &request)
+	if err != nil {
+		t.Fatalf("marshal request: %v", err)
+	}
+	message := &transport.Message{Key: []byte(fmt.Sprint(userId)), Value: value}
+	message.SetHeader(contracts.ContentTypeHeader, string(contracts.EncodingJSON))
+	message.SetHeader("correlation-id", correlationId)
+	s.signer.Sign(message)
+
+	for i := 0; i < 2; i++ {
+		reply, err := s.paymentApp.DryRun(ctx, message)
+		if err != nil {
+			t.Fatalf("dry run %d: %v", i, err)
+		}
+		var result contracts.PaymentResult
+		_, err = contracts.Unmarshal(contracts.EncodingJSON, reply.Value, &result)
+		if err != nil {
+			t.Fatalf("unmarshal reply: %v", err)
+		}
+		if result.Status != contracts.PaymentApproved || result.TransactionId != request.Id {
+			t.Errorf("expected approved transaction %d, got %+v", request.Id, result)
+		}
+	}
+	call(t, http.MethodGet, fmt.Sprintf("%s/accounts/%d", s.payment.URL, wallet.Id), nil, &wallet)
+	if wallet.Balance != 0 {
+		t.Errorf("expected dry run to keep balance 0, got %.2f", wallet.Balance)
+	}
+}
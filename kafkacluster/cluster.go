@@ -0,0 +1,130 @@
+// Package kafkacluster содержит общие для сервисов настройки подключения к Kafka:
+// адреса брокеров, TLS и SASL, проверку и создание топиков, проверку готовности
+// и преобразование сообщений Kafka в сообщения транспорта (contracts/transport) и обратно.
+package kafkacluster
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+	"os"
+	"time"
+)
+
+// Механизмы аутентификации SASL.
+const (
+	SASLPlain       = "PLAIN"
+	SASLScramSHA256 = "SCRAM-SHA-256"
+	SASLScramSHA512 = "SCRAM-SHA-512"
+)
+
+// Security — параметры защищённого подключения к Kafka.
+// Загружается из переменных окружения функцией LoadSecurity.
+type Security struct {
+	TLS           bool   // Подключаться к брокерам по TLS
+	CAFile        string // PEM-файл сертификатов CA; пустое значение — системные сертификаты
+	CertFile      string // PEM-файл клиентского сертификата
+	KeyFile       string // PEM-файл ключа клиентского сертификата
+	SASLMechanism string // SASLPlain, SASLScramSHA256 или SASLScramSHA512; пустое значение — без SASL
+	SASLUsername  string // Имя пользователя SASL
+	SASLPassword  string // Пароль SASL
+}
+
+// Cluster — адреса брокеров Kafka и параметры подключения к ним.
+// Используется всеми читателями, писателями и административными запросами сервисов.
+type Cluster struct {
+	Brokers []string       // Адреса брокеров
+	TLS     *tls.Config    // Настройки TLS; nil — подключение без шифрования
+	SASL    sasl.Mechanism // Механизм аутентификации; nil — без аутентификации
+}
+
+// NewCluster создаёт Cluster для брокеров brokers: загружает сертификаты
+// и готовит механизм SASL по параметрам security.
+// Возвращает ошибку, если файлы сертификатов не удалось прочитать или механизм SASL неизвестен.
+func NewCluster(brokers []string, security Security) (Cluster, error) {
+	cluster := Cluster{Brokers: brokers}
+	if security.TLS {
+		config, err := security.tlsConfig()
+		if err != nil {
+			return Cluster{}, err
+		}
+		cluster.TLS = config
+	}
+	if security.SASLMechanism != "" {
+		mechanism, err := security.saslMechanism()
+		if err != nil {
+			return Cluster{}, err
+		}
+		cluster.SASL = mechanism
+	}
+	return cluster, nil
+}
+
+func (s Security) tlsConfig() (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if s.CAFile != "" {
+		pem, err := os.ReadFile(s.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading kafka CA file: %w", err)
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("kafka CA file %s contains no PEM certificates", s.CAFile)
+		}
+	}
+	if s.CertFile != "" || s.KeyFile != "" {
+		certificate, err := tls.LoadX509KeyPair(s.CertFile, s.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error loading kafka client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{certificate}
+	}
+	return config, nil
+}
+
+func (s Security) saslMechanism() (sasl.Mechanism, error) {
+	var mechanism sasl.Mechanism
+	var err error
+	switch s.SASLMechanism {
+	case SASLPlain:
+		mechanism = plain.Mechanism{Username: s.SASLUsername, Password: s.SASLPassword}
+	case SASLScramSHA256:
+		mechanism, err = scram.Mechanism(scram.SHA256, s.SASLUsername, s.SASLPassword)
+	case SASLScramSHA512:
+		mechanism, err = scram.Mechanism(scram.SHA512, s.SASLUsername, s.SASLPassword)
+	default:
+		return nil, fmt.Errorf("unsupported kafka SASL mechanism %q", s.SASLMechanism)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error initializing kafka SASL: %w", err)
+	}
+	return mechanism, nil
+}
+
+// Dialer возвращает Dialer для читателей и прямых подключений к брокерам.
+func (c Cluster) Dialer() *kafka.Dialer {
+	return &kafka.Dialer{
+		Timeout:       10 * time.Second,
+		DualStack:     true,
+		TLS:           c.TLS,
+		SASLMechanism: c.SASL,
+	}
+}
+
+// Transport возвращает транспорт для писателей и административных запросов.
+func (c Cluster) Transport() kafka.RoundTripper {
+	if c.TLS == nil && c.SASL == nil {
+		return kafka.DefaultTransport
+	}
+	return &kafka.Transport{TLS: c.TLS, SASL: c.SASL}
+}
+
+// Writer возвращает писателя в топик topic кластера; topic может быть пустым,
+// если топик задаётся в каждом сообщении.
+func (c Cluster) Writer(topic string) *kafka.Writer {
+	return &kafka.Writer{Addr: kafka.TCP(c.Brokers...), Topic: topic, Transport: c.Transport()}
+}
@@ -0,0 +1,107 @@
+package kafkacluster
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeCertificate записывает в dir самоподписанный сертификат и его ключ в формате PEM.
+func writeCertificate(t *testing.T, dir string) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "kafka-test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	err = os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600)
+	if err != nil {
+		t.Fatalf("write certificate: %v", err)
+	}
+	err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600)
+	if err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+	return certFile, keyFile
+}
+
+func TestNewCluster_Plaintext(t *testing.T) {
+	cluster, err := NewCluster([]string{"kafka:9092"}, Security{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cluster.TLS != nil || cluster.SASL != nil {
+		t.Errorf("expected plaintext connection, got TLS %v, SASL %v", cluster.TLS, cluster.SASL)
+	}
+}
+
+func TestNewCluster_TLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeCertificate(t, dir)
+
+	cluster, err := NewCluster([]string{"kafka:9093"}, Security{TLS: true, CAFile: certFile, CertFile: certFile, KeyFile: keyFile})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cluster.TLS == nil || cluster.TLS.RootCAs == nil || len(cluster.TLS.Certificates) != 1 {
+		t.Errorf("expected CA and client certificate to be loaded, got %+v", cluster.TLS)
+	}
+	if cluster.Dialer().TLS != cluster.TLS {
+		t.Error("expected dialer to use the cluster TLS config")
+	}
+
+	_, err = NewCluster(nil, Security{TLS: true, CAFile: filepath.Join(dir, "missing.pem")})
+	if err == nil {
+		t.Error("expected error for missing CA file")
+	}
+	_, err = NewCluster(nil, Security{TLS: true, CAFile: keyFile})
+	if err == nil {
+		t.Error("expected error for CA file without certificates")
+	}
+	_, err = NewCluster(nil, Security{TLS: true, CertFile: certFile})
+	if err == nil {
+		t.Error("expected error for client certificate without key")
+	}
+}
+
+func TestNewCluster_SASL(t *testing.T) {
+	for _, mechanism := range []string{SASLPlain, SASLScramSHA256, SASLScramSHA512} {
+		cluster, err := NewCluster(nil, Security{SASLMechanism: mechanism, SASLUsername: "user", SASLPassword: "secret"})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", mechanism, err)
+		}
+		if cluster.SASL == nil || cluster.SASL.Name() != mechanism {
+			t.Errorf("expected %s mechanism, got %v", mechanism, cluster.SASL)
+		}
+	}
+
+	_, err := NewCluster(nil, Security{SASLMechanism: "GSSAPI"})
+	if err == nil {
+		t.Error("expected error for unsupported mechanism")
+	}
+}
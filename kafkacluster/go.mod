@@ -0,0 +1,17 @@
+module kafkacluster
+
+go 1.25.1
+
+require github.com/segmentio/kafka-go v0.4.49
+
+require (
+	contracts v0.0.0-00010101000000-000000000000
+	github.com/klauspost/compress v1.15.9 // indirect
+	github.com/pierrec/lz4/v4 v4.1.15 // indirect
+	github.com/xdg-go/pbkdf2 v1.0.0 // indirect
+	github.com/xdg-go/scram v1.1.2 // indirect
+	github.com/xdg-go/stringprep v1.0.4 // indirect
+	golang.org/x/text v0.27.0 // indirect
+)
+
+replace contracts => ../contracts
@@ -0,0 +1,38 @@
+package kafkacluster
+
+import (
+	"contracts/transport"
+	"fmt"
+	"github.com/segmentio/kafka-go"
+	"sort"
+)
+
+// FromKafka преобразует сообщение Kafka в сообщение транспорта.
+// Идентификатор доставки — топик, партиция и смещение сообщения, время записи — его метка времени.
+func FromKafka(m *kafka.Message) *transport.Message {
+	message := &transport.Message{
+		Id:    fmt.Sprintf("%s/%d/%d", m.Topic, m.Partition, m.Offset),
+		Key:   m.Key,
+		Value: m.Value,
+		Time:  m.Time,
+	}
+	for _, header := range m.Headers {
+		message.SetHeader(header.Key, string(header.Value))
+	}
+	return message
+}
+
+// ToKafka преобразует сообщение транспорта в сообщение Kafka.
+// Заголовки упорядочиваются по имени.
+func ToKafka(message *transport.Message) *kafka.Message {
+	m := &kafka.Message{Key: message.Key, Value: message.Value}
+	keys := make([]string, 0, len(message.Headers))
+	for key := range message.Headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		m.Headers = append(m.Headers, kafka.Header{Key: key, Value: []byte(message.Headers[key])})
+	}
+	return m
+}
@@ -0,0 +1,30 @@
+package kafkacluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+func TestMessageConversion(t *testing.T) {
+	m := &kafka.Message{Topic: "request", Partition: 2, Offset: 15, Key: []byte("10"), Value: []byte("{}"),
+		Time:    time.Date(2025, 10, 31, 12, 0, 0, 0, time.UTC),
+		Headers: []kafka.Header{{Key: "correlation-id", Value: []byte("abc")}, {Key: "content-type", Value: []byte("application/json")}}}
+
+	message := FromKafka(m)
+	if message.Id != "request/2/15" || string(message.Key) != "10" || string(message.Value) != "{}" || !message.Time.Equal(m.Time) {
+		t.Errorf("unexpected message: %+v", message)
+	}
+	if message.Header("correlation-id") != "abc" || message.Header("content-type") != "application/json" {
+		t.Errorf("unexpected headers: %+v", message.Headers)
+	}
+
+	back := ToKafka(message)
+	if string(back.Key) != "10" || string(back.Value) != "{}" || len(back.Headers) != 2 {
+		t.Fatalf("unexpected kafka message: %+v", back)
+	}
+	if back.Headers[0].Key != "content-type" || back.Headers[1].Key != "correlation-id" {
+		t.Errorf("expected headers sorted by name, got %+v", back.Headers)
+	}
+}
@@ -0,0 +1,73 @@
+package kafkacluster
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+)
+
+// readinessTimeout — срок проверки топиков при запросе готовности.
+const readinessTimeout = 3 * time.Second
+
+// TopicChecker возвращает состояние топиков Kafka, которые нужны сервису.
+type TopicChecker interface {
+	Check(ctx context.Context) (map[string]error, error)
+}
+
+// Readiness — состояние готовности сервиса.
+type Readiness struct {
+	Ready  bool              `json:"ready"`            // Сервис готов обрабатывать запросы
+	Error  string            `json:"error,omitempty"`  // Причина, по которой топики не удалось проверить
+	Topics map[string]string `json:"topics,omitempty"` // Состояние топиков: ok или описание ошибки
+}
+
+type ReadinessHandler struct {
+	topics TopicChecker
+	ctx    context.Context
+}
+
+// NewReadinessHandler создаёт обработчик готовности; topics равен nil,
+// если сервис не использует Kafka.
+func NewReadinessHandler(ctx context.Context, topics TopicChecker) *ReadinessHandler {
+	return &ReadinessHandler{topics: topics, ctx: ctx}
+}
+
+// Ready godoc
+// @Summary      Готовность сервиса
+// @Description  Проверяет доступность брокера Kafka и топиков сервиса. Без Kafka сервис всегда готов
+// @Tags         health
+// @Produce      json
+// @Success      200  {object}  kafkacluster.Readiness
+// @Failure      503  {object}  kafkacluster.Readiness
+// @Router       /ready [get]
+func (h *ReadinessHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	readiness := Readiness{Ready: true}
+	if h.topics != nil {
+		ctx, cancel := context.WithTimeout(h.ctx, readinessTimeout)
+		defer cancel()
+		status, err := h.topics.Check(ctx)
+		if err != nil {
+			readiness.Ready = false
+			readiness.Error = err.Error()
+		}
+		readiness.Topics = make(map[string]string, len(status))
+		for topic, err := range status {
+			readiness.Topics[topic] = "ok"
+			if err != nil {
+				readiness.Ready = false
+				readiness.Topics[topic] = err.Error()
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !readiness.Ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	err := json.NewEncoder(w).Encode(readiness)
+	if err != nil {
+		log.Printf("Failed to encode readiness to JSON: %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package kafkacluster
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// LoadSecurity загружает параметры TLS и SASL из переменных окружения KAFKA_TLS, KAFKA_TLS_CA_FILE,
+// KAFKA_TLS_CERT_FILE, KAFKA_TLS_KEY_FILE, KAFKA_SASL_MECHANISM, KAFKA_SASL_USERNAME и KAFKA_SASL_PASSWORD
+// и проверяет, что они согласованы, а файлы сертификатов существуют.
+// Возвращает параметры и описания всех найденных ошибок, чтобы сервис сообщил их
+// вместе с остальными ошибками конфигурации.
+func LoadSecurity() (Security, []string) {
+	security := Security{
+		CAFile:        os.Getenv("KAFKA_TLS_CA_FILE"),
+		CertFile:      os.Getenv("KAFKA_TLS_CERT_FILE"),
+		KeyFile:       os.Getenv("KAFKA_TLS_KEY_FILE"),
+		SASLMechanism: strings.ToUpper(os.Getenv("KAFKA_SASL_MECHANISM")),
+		SASLUsername:  os.Getenv("KAFKA_SASL_USERNAME"),
+		SASLPassword:  os.Getenv("KAFKA_SASL_PASSWORD"),
+	}
+	errs := make([]string, 0)
+
+	if value := os.Getenv("KAFKA_TLS"); value != "" {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			errs = append(errs, "KAFKA_TLS must be a boolean")
+		}
+		security.TLS = enabled
+	}
+	files := []struct{ key, path string }{
+		{"KAFKA_TLS_CA_FILE", security.CAFile},
+		{"KAFKA_TLS_CERT_FILE", security.CertFile},
+		{"KAFKA_TLS_KEY_FILE", security.KeyFile},
+	}
+	for _, file := range files {
+		if file.path == "" {
+			continue
+		}
+		if !security.TLS {
+			errs = append(errs, file.key+" requires KAFKA_TLS=true")
+			continue
+		}
+		_, err := os.Stat(file.path)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", file.key, err))
+		}
+	}
+	if (security.CertFile == "") != (security.KeyFile == "") {
+		errs = append(errs, "KAFKA_TLS_CERT_FILE and KAFKA_TLS_KEY_FILE must be set together")
+	}
+
+	switch security.SASLMechanism {
+	case "":
+		if security.SASLUsername != "" || security.SASLPassword != "" {
+			errs = append(errs, "KAFKA_SASL_USERNAME and KAFKA_SASL_PASSWORD require KAFKA_SASL_MECHANISM")
+		}
+	case SASLPlain, SASLScramSHA256, SASLScramSHA512:
+		if security.SASLUsername == "" || security.SASLPassword == "" {
+			errs = append(errs, "KAFKA_SASL_USERNAME and KAFKA_SASL_PASSWORD are required for KAFKA_SASL_MECHANISM")
+		}
+	default:
+		errs = append(errs, "KAFKA_SASL_MECHANISM must be PLAIN, SCRAM-SHA-256 or SCRAM-SHA-512")
+	}
+	return security, errs
+}
@@ -0,0 +1,78 @@
+package kafkacluster
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setSecurityEnv задаёт переменные окружения параметров безопасности; пустое значение снимает переменную.
+func setSecurityEnv(t *testing.T, values map[string]string) {
+	t.Helper()
+	for _, key := range []string{"KAFKA_TLS", "KAFKA_TLS_CA_FILE", "KAFKA_TLS_CERT_FILE", "KAFKA_TLS_KEY_FILE",
+		"KAFKA_SASL_MECHANISM", "KAFKA_SASL_USERNAME", "KAFKA_SASL_PASSWORD"} {
+		t.Setenv(key, values[key])
+		if values[key] == "" {
+			_ = os.Unsetenv(key)
+		}
+	}
+}
+
+// assertErrors проверяет, что среди ошибок errs есть все ожидаемые фрагменты messages.
+func assertErrors(t *testing.T, errs []string, messages ...string) {
+	t.Helper()
+	joined := strings.Join(errs, "\n")
+	for _, message := range messages {
+		if !strings.Contains(joined, message) {
+			t.Errorf("Error should mention %q, got: %v", message, errs)
+		}
+	}
+}
+
+func TestLoadSecurity(t *testing.T) {
+	setSecurityEnv(t, nil)
+	security, errs := LoadSecurity()
+	if len(errs) != 0 || security != (Security{}) {
+		t.Errorf("Expected plaintext connection without errors, got %+v, %v", security, errs)
+	}
+
+	dir := t.TempDir()
+	certFile, keyFile := writeCertificate(t, dir)
+	setSecurityEnv(t, map[string]string{
+		"KAFKA_TLS": "true", "KAFKA_TLS_CA_FILE": certFile, "KAFKA_TLS_CERT_FILE": certFile, "KAFKA_TLS_KEY_FILE": keyFile,
+		"KAFKA_SASL_MECHANISM": "scram-sha-512", "KAFKA_SASL_USERNAME": "payment", "KAFKA_SASL_PASSWORD": "password",
+	})
+	security, errs = LoadSecurity()
+	if len(errs) != 0 {
+		t.Fatalf("Expected no errors, got %v", errs)
+	}
+	expected := Security{TLS: true, CAFile: certFile, CertFile: certFile, KeyFile: keyFile,
+		SASLMechanism: SASLScramSHA512, SASLUsername: "payment", SASLPassword: "password"}
+	if security != expected {
+		t.Errorf("Expected security %+v, got %+v", expected, security)
+	}
+}
+
+func TestLoadSecurity_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	certFile, _ := writeCertificate(t, dir)
+	missing := filepath.Join(dir, "missing.pem")
+
+	setSecurityEnv(t, map[string]string{
+		"KAFKA_TLS": "false", "KAFKA_TLS_CA_FILE": certFile, "KAFKA_TLS_CERT_FILE": certFile,
+		"KAFKA_SASL_USERNAME": "order", "KAFKA_SASL_PASSWORD": "password",
+	})
+	_, errs := LoadSecurity()
+	assertErrors(t, errs, "KAFKA_TLS_CA_FILE requires KAFKA_TLS=true", "must be set together", "require KAFKA_SASL_MECHANISM")
+
+	setSecurityEnv(t, map[string]string{
+		"KAFKA_TLS": "true", "KAFKA_TLS_CA_FILE": missing, "KAFKA_SASL_MECHANISM": "GSSAPI",
+	})
+	_, errs = LoadSecurity()
+	assertErrors(t, errs, "KAFKA_TLS_CA_FILE: ", "KAFKA_SASL_MECHANISM must be")
+
+	setSecurityEnv(t, map[string]string{"KAFKA_TLS": "yes please", "KAFKA_SASL_MECHANISM": "PLAIN", "KAFKA_SASL_USERNAME": "order"})
+	_, errs = LoadSecurity()
+	assertErrors(t, errs, "KAFKA_TLS must be a boolean", "KAFKA_SASL_PASSWORD are required")
+}
@@ -0,0 +1,274 @@
+package kafkacluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/segmentio/kafka-go"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultStartupTimeout — срок проверки топиков при запуске сервиса.
+const DefaultStartupTimeout = 10 * time.Second
+
+// Настройка топика, которая определяет, кто ставит метки времени сообщений,
+// и её значение, при котором метки ставит брокер при записи, а не отправитель.
+const (
+	timestampTypeConfig = "message.timestamp.type"
+	logAppendTime       = "LogAppendTime"
+)
+
+// ErrTopicNotFound означает, что топика нет в Kafka.
+var ErrTopicNotFound = errors.New("topic does not exist")
+
+// TopicSpec описывает топик, который нужен сервису.
+type TopicSpec struct {
+	Name              string // Имя топика
+	Partitions        int    // Число партиций при создании топика
+	ReplicationFactor int    // Фактор репликации при создании топика
+	// LogAppendTime требует, чтобы метки времени сообщений ставил брокер (message.timestamp.type=LogAppendTime).
+	// Метке времени, которую ставит отправитель, нельзя доверять: её может задать любой, кто пишет в топик.
+	LogAppendTime bool
+}
+
+// topicAdmin запрашивает метаданные кластера, создаёт топики и меняет их настройки; реализуется kafka.Client.
+type topicAdmin interface {
+	Metadata(ctx context.Context, req *kafka.MetadataRequest) (*kafka.MetadataResponse, error)
+	CreateTopics(ctx context.Context, req *kafka.CreateTopicsRequest) (*kafka.CreateTopicsResponse, error)
+	DescribeConfigs(ctx context.Context, req *kafka.DescribeConfigsRequest) (*kafka.DescribeConfigsResponse, error)
+	IncrementalAlterConfigs(ctx context.Context, req *kafka.IncrementalAlterConfigsRequest) (*kafka.IncrementalAlterConfigsResponse, error)
+}
+
+// Topics проверяет наличие топиков сервиса и создаёт недостающие.
+type Topics struct {
+	admin topicAdmin
+	specs []TopicSpec
+}
+
+// NewTopics создаёт новый экземпляр Topics для топиков specs в кластере cluster.
+func NewTopics(cluster Cluster, specs []TopicSpec) *Topics {
+	admin := &kafka.Client{Addr: kafka.TCP(cluster.Brokers...), Transport: cluster.Transport()}
+	return &Topics{admin: admin, specs: specs}
+}
+
+// Check возвращает состояние каждого топика: nil, если топик доступен,
+// ErrTopicNotFound, если его нет, или ошибку из метаданных топика.
+// Возвращает ошибку, если брокер недоступен.
+func (t *Topics) Check(ctx context.Context) (map[string]error, error) {
+	topics, err := t.describe(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return t.status(topics), nil
+}
+
+// status возвращает состояние каждого топика сервиса по метаданным topics.
+func (t *Topics) status(topics map[string]kafka.Topic) map[string]error {
+	status := make(map[string]error, len(t.specs))
+	for _, spec := range t.specs {
+		topic, ok := topics[spec.Name]
+		switch {
+		case !ok || errors.Is(topic.Error, kafka.UnknownTopicOrPartition):
+			status[spec.Name] = ErrTopicNotFound
+		default:
+			status[spec.Name] = topic.Error
+		}
+	}
+	return status
+}
+
+// describe возвращает метаданные топиков сервиса по имени.
+// Возвращает ошибку, если брокер недоступен.
+func (t *Topics) describe(ctx context.Context) (map[string]kafka.Topic, error) {
+	names := make([]string, 0, len(t.specs))
+	for _, spec := range t.specs {
+		names = append(names, spec.Name)
+	}
+	metadata, err := t.admin.Metadata(ctx, &kafka.MetadataRequest{Topics: names})
+	if err != nil {
+		return nil, fmt.Errorf("kafka broker is unreachable: %w", err)
+	}
+	topics := make(map[string]kafka.Topic, len(metadata.Topics))
+	for _, topic := range metadata.Topics {
+		topics[topic.Name] = topic
+	}
+	return topics, nil
+}
+
+// Ensure проверяет, что все топики доступны. Если create, недостающие топики создаются
+// с заданными числом партиций и фактором репликации; топик, созданный одновременно
+// другим сервисом, не считается ошибкой.
+//
+// Существующие топики не изменяются: если их число партиций или фактор репликации
+// отличаются от заданных, в журнал записывается предупреждение.
+// Исключение — TopicSpec.LogAppendTime: топики создаются с метками времени брокера,
+// а если create, то и в существующих топиках включаются метки времени брокера.
+// Не удалось это сделать — в журнал записывается предупреждение; проверить результат
+// можно методом LogAppendTime.
+// Возвращает ошибку, если брокер недоступен или топики так и не появились.
+func (t *Topics) Ensure(ctx context.Context, create bool) error {
+	topics, err := t.describe(ctx)
+	if err != nil {
+		return err
+	}
+	status := t.status(topics)
+	missing := make([]kafka.TopicConfig, 0)
+	existing := make([]string, 0)
+	failures := make([]string, 0)
+	for _, spec := range t.specs {
+		err := status[spec.Name]
+		switch {
+		case err == nil:
+			if mismatch := layoutMismatch(spec, topics[spec.Name]); mismatch != "" {
+				log.Printf("Kafka topic %s is not changed: %s", spec.Name, mismatch)
+			}
+			if spec.LogAppendTime {
+				existing = append(existing, spec.Name)
+			}
+		case errors.Is(err, ErrTopicNotFound):
+			topic := kafka.TopicConfig{
+				Topic:             spec.Name,
+				NumPartitions:     spec.Partitions,
+				ReplicationFactor: spec.ReplicationFactor,
+			}
+			if spec.LogAppendTime {
+				topic.ConfigEntries = []kafka.ConfigEntry{{ConfigName: timestampTypeConfig, ConfigValue: logAppendTime}}
+			}
+			missing = append(missing, topic)
+		case err != nil:
+			failures = append(failures, fmt.Sprintf("%s: %s", spec.Name, err))
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("kafka topics are unavailable: %s", strings.Join(failures, "; "))
+	}
+	if len(missing) > 0 {
+		err := t.create(ctx, create, missing)
+		if err != nil {
+			return err
+		}
+	}
+	t.ensureLogAppendTime(ctx, create, existing)
+	return nil
+}
+
+// create создаёт недостающие топики missing, если create;
+// иначе возвращает ErrTopicNotFound со списком недостающих топиков.
+func (t *Topics) create(ctx context.Context, create bool, missing []kafka.TopicConfig) error {
+	names := make([]string, 0, len(missing))
+	for _, topic := range missing {
+		names = append(names, topic.Topic)
+	}
+	if !create {
+		return fmt.Errorf("%w: %s", ErrTopicNotFound, strings.Join(names, ", "))
+	}
+
+	response, err := t.admin.CreateTopics(ctx, &kafka.CreateTopicsRequest{Topics: missing})
+	if err != nil {
+		return fmt.Errorf("error creating topics %s: %w", strings.Join(names, ", "), err)
+	}
+	failures := make([]string, 0)
+	for name, err := range response.Errors {
+		if err != nil && !errors.Is(err, kafka.TopicAlreadyExists) {
+			failures = append(failures, fmt.Sprintf("%s: %s", name, err))
+		}
+	}
+	if len(failures) > 0 {
+		sort.Strings(failures)
+		return fmt.Errorf("error creating topics: %s", strings.Join(failures, "; "))
+	}
+	log.Printf("Created Kafka topics: %s", strings.Join(names, ", "))
+	return nil
+}
+
+// ensureLogAppendTime проверяет, что метки времени сообщений в существующих топиках names
+// ставит брокер, и, если create, включает метки времени брокера там, где их ставит отправитель.
+// Ошибки не прерывают запуск сервиса, а записываются в журнал.
+func (t *Topics) ensureLogAppendTime(ctx context.Context, create bool, names []string) {
+	for _, name := range names {
+		enabled, err := t.LogAppendTime(ctx, name)
+		switch {
+		case err != nil:
+			log.Printf("Failed to check timestamps of Kafka topic %s: %v", name, err)
+		case enabled:
+		case !create:
+			log.Printf("Kafka topic %s uses producer timestamps; set %s=%s", name, timestampTypeConfig, logAppendTime)
+		default:
+			err = t.setLogAppendTime(ctx, name)
+			if err != nil {
+				log.Printf("Failed to set %s=%s for Kafka topic %s: %v", timestampTypeConfig, logAppendTime, name, err)
+				continue
+			}
+			log.Printf("Set %s=%s for Kafka topic %s", timestampTypeConfig, logAppendTime, name)
+		}
+	}
+}
+
+// LogAppendTime сообщает, ставит ли метки времени сообщений топика name брокер
+// (message.timestamp.type=LogAppendTime). Возвращает ошибку, если настройки топика не удалось получить.
+func (t *Topics) LogAppendTime(ctx context.Context, name string) (bool, error) {
+	response, err := t.admin.DescribeConfigs(ctx, &kafka.DescribeConfigsRequest{
+		Resources: []kafka.DescribeConfigRequestResource{{
+			ResourceType: kafka.ResourceTypeTopic,
+			ResourceName: name,
+			ConfigNames:  []string{timestampTypeConfig},
+		}},
+	})
+	if err != nil {
+		return false, fmt.Errorf("error describing topic %s: %w", name, err)
+	}
+	for _, resource := range response.Resources {
+		if resource.Error != nil {
+			return false, fmt.Errorf("error describing topic %s: %w", name, resource.Error)
+		}
+		for _, entry := range resource.ConfigEntries {
+			if entry.ConfigName == timestampTypeConfig {
+				return entry.ConfigValue == logAppendTime, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// setLogAppendTime включает метки времени брокера в топике name.
+func (t *Topics) setLogAppendTime(ctx context.Context, name string) error {
+	response, err := t.admin.IncrementalAlterConfigs(ctx, &kafka.IncrementalAlterConfigsRequest{
+		Resources: []kafka.IncrementalAlterConfigsRequestResource{{
+			ResourceType: kafka.ResourceTypeTopic,
+			ResourceName: name,
+			Configs: []kafka.IncrementalAlterConfigsRequestConfig{{
+				Name:            timestampTypeConfig,
+				Value:           logAppendTime,
+				ConfigOperation: kafka.ConfigOperationSet,
+			}},
+		}},
+	})
+	if err != nil {
+		return err
+	}
+	for _, resource := range response.Resources {
+		if resource.Error != nil {
+			return resource.Error
+		}
+	}
+	return nil
+}
+
+// layoutMismatch описывает, чем число партиций и фактор репликации топика topic
+// отличаются от заданных в spec, или возвращает пустую строку, если не отличаются.
+func layoutMismatch(spec TopicSpec, topic kafka.Topic) string {
+	mismatches := make([]string, 0, 2)
+	if len(topic.Partitions) != spec.Partitions {
+		mismatches = append(mismatches, fmt.Sprintf("%d partitions instead of %d", len(topic.Partitions), spec.Partitions))
+	}
+	for _, partition := range topic.Partitions {
+		if len(partition.Replicas) != spec.ReplicationFactor {
+			mismatches = append(mismatches, fmt.Sprintf("replication factor %d instead of %d", len(partition.Replicas), spec.ReplicationFactor))
+			break
+		}
+	}
+	return strings.Join(mismatches, ", ")
+}
@@ -0,0 +1,219 @@
+package kafkacluster
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+)
+
+type fakeAdmin struct {
+	topics     map[string]error
+	err        error
+	created    []kafka.TopicConfig
+	timestamps map[string]string // message.timestamp.type существующих топиков
+	altered    []string
+}
+
+func (a *fakeAdmin) Metadata(ctx context.Context, req *kafka.MetadataRequest) (*kafka.MetadataResponse, error) {
+	if a.err != nil {
+		return nil, a.err
+	}
+	response := &kafka.MetadataResponse{}
+	for _, name := range req.Topics {
+		err, ok := a.topics[name]
+		if !ok {
+			err = kafka.UnknownTopicOrPartition
+		}
+		response.Topics = append(response.Topics, kafka.Topic{Name: name, Error: err})
+	}
+	return response, nil
+}
+
+func (a *fakeAdmin) CreateTopics(ctx context.Context, req *kafka.CreateTopicsRequest) (*kafka.CreateTopicsResponse, error) {
+	response := &kafka.CreateTopicsResponse{Errors: make(map[string]error)}
+	for _, topic := range req.Topics {
+		a.created = append(a.created, topic)
+		response.Errors[topic.Topic] = nil
+	}
+	return response, nil
+}
+
+func (a *fakeAdmin) DescribeConfigs(ctx context.Context, req *kafka.DescribeConfigsRequest) (*kafka.DescribeConfigsResponse, error) {
+	response := &kafka.DescribeConfigsResponse{}
+	for _, resource := range req.Resources {
+		value, ok := a.timestamps[resource.ResourceName]
+		if !ok {
+			value = "CreateTime"
+		}
+		response.Resources = append(response.Resources, kafka.DescribeConfigResponseResource{
+			ResourceName:  resource.ResourceName,
+			ConfigEntries: []kafka.DescribeConfigResponseConfigEntry{{ConfigName: timestampTypeConfig, ConfigValue: value}},
+		})
+	}
+	return response, nil
+}
+
+func (a *fakeAdmin) IncrementalAlterConfigs(ctx context.Context, req *kafka.IncrementalAlterConfigsRequest) (*kafka.IncrementalAlterConfigsResponse, error) {
+	response := &kafka.IncrementalAlterConfigsResponse{}
+	for _, resource := range req.Resources {
+		a.altered = append(a.altered, resource.ResourceName)
+		if a.timestamps == nil {
+			a.timestamps = make(map[string]string)
+		}
+		a.timestamps[resource.ResourceName] = resource.Configs[0].Value
+		response.Resources = append(response.Resources, kafka.IncrementalAlterConfigsResponseResource{ResourceName: resource.ResourceName})
+	}
+	return response, nil
+}
+
+func newTestTopics(admin *fakeAdmin) *Topics {
+	return &Topics{admin: admin, specs: []TopicSpec{
+		{Name: "request", Partitions: 3, ReplicationFactor: 1},
+		{Name: "response", Partitions: 3, ReplicationFactor: 1},
+	}}
+}
+
+func TestTopics_Check(t *testing.T) {
+	admin := &fakeAdmin{topics: map[string]error{"request": nil}}
+	status, err := newTestTopics(admin).Check(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status["request"] != nil || !errors.Is(status["response"], ErrTopicNotFound) {
+		t.Errorf("unexpected topic status: %v", status)
+	}
+
+	admin.err = errors.New("dial tcp: connection refused")
+	_, err = newTestTopics(admin).Check(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "unreachable") {
+		t.Errorf("expected unreachable broker error, got %v", err)
+	}
+}
+
+func TestTopics_Ensure(t *testing.T) {
+	admin := &fakeAdmin{topics: map[string]error{"request": nil}}
+	err := newTestTopics(admin).Ensure(context.Background(), false)
+	if !errors.Is(err, ErrTopicNotFound) || !strings.Contains(err.Error(), "response") {
+		t.Errorf("expected missing response topic, got %v", err)
+	}
+	if len(admin.created) != 0 {
+		t.Errorf("expected no topics to be created, got %+v", admin.created)
+	}
+
+	err = newTestTopics(admin).Ensure(context.Background(), true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(admin.created) != 1 || admin.created[0].Topic != "response" || admin.created[0].NumPartitions != 3 {
+		t.Errorf("expected response topic to be created, got %+v", admin.created)
+	}
+
+	admin = &fakeAdmin{topics: map[string]error{"request": nil, "response": kafka.LeaderNotAvailable}}
+	err = newTestTopics(admin).Ensure(context.Background(), true)
+	if err == nil || len(admin.created) != 0 {
+		t.Errorf("expected unavailable topic error without creating topics, got %v", err)
+	}
+}
+
+type mockTopicChecker struct {
+	status map[string]error
+	err    error
+}
+
+func (m *mockTopicChecker) Check(ctx context.Context) (map[string]error, error) {
+	return m.status, m.err
+}
+
+func TestTopics_EnsureLogAppendTime(t *testing.T) {
+	ctx := context.Background()
+	newTopics := func(admin *fakeAdmin) *Topics {
+		return &Topics{admin: admin, specs: []TopicSpec{
+			{Name: "request", Partitions: 1, ReplicationFactor: 1, LogAppendTime: true},
+			{Name: "response", Partitions: 1, ReplicationFactor: 1},
+		}}
+	}
+
+	admin := &fakeAdmin{topics: map[string]error{}}
+	err := newTopics(admin).Ensure(ctx, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(admin.created) != 2 || len(admin.created[0].ConfigEntries) != 1 || admin.created[0].ConfigEntries[0].ConfigValue != logAppendTime ||
+		len(admin.created[1].ConfigEntries) != 0 {
+		t.Errorf("expected only the request topic to be created with broker timestamps, got %+v", admin.created)
+	}
+
+	admin = &fakeAdmin{topics: map[string]error{"request": nil, "response": nil}}
+	topics := newTopics(admin)
+	err = topics.Ensure(ctx, false)
+	if err != nil || len(admin.altered) != 0 {
+		t.Errorf("expected existing topic to be left as is without create, got %v, altered %v", err, admin.altered)
+	}
+	enabled, err := topics.LogAppendTime(ctx, "request")
+	if err != nil || enabled {
+		t.Errorf("expected producer timestamps, got %v, %v", enabled, err)
+	}
+
+	err = topics.Ensure(ctx, true)
+	if err != nil || len(admin.altered) != 1 || admin.altered[0] != "request" {
+		t.Errorf("expected broker timestamps to be enabled for the request topic, got %v, altered %v", err, admin.altered)
+	}
+	enabled, err = topics.LogAppendTime(ctx, "request")
+	if err != nil || !enabled {
+		t.Errorf("expected broker timestamps, got %v, %v", enabled, err)
+	}
+}
+
+func TestLayoutMismatch(t *testing.T) {
+	spec := TopicSpec{Name: "request", Partitions: 2, ReplicationFactor: 2}
+	replicas := []kafka.Broker{{ID: 1}, {ID: 2}}
+	topic := kafka.Topic{Name: "request", Partitions: []kafka.Partition{{ID: 0, Replicas: replicas}, {ID: 1, Replicas: replicas}}}
+	if mismatch := layoutMismatch(spec, topic); mismatch != "" {
+		t.Errorf("expected no mismatch, got %q", mismatch)
+	}
+
+	topic.Partitions = []kafka.Partition{{ID: 0, Replicas: replicas[:1]}}
+	mismatch := layoutMismatch(spec, topic)
+	if !strings.Contains(mismatch, "1 partitions instead of 2") || !strings.Contains(mismatch, "replication factor 1 instead of 2") {
+		t.Errorf("expected partition and replication mismatch, got %q", mismatch)
+	}
+}
+
+func TestReady(t *testing.T) {
+	ready := func(topics TopicChecker) (int, Readiness) {
+		req := httptest.NewRequest(http.MethodGet, "/ready", nil)
+		w := httptest.NewRecorder()
+		NewReadinessHandler(context.Background(), topics).Ready(w, req)
+		var readiness Readiness
+		if err := json.NewDecoder(w.Body).Decode(&readiness); err != nil {
+			t.Fatalf("decode error: %v", err)
+		}
+		return w.Code, readiness
+	}
+
+	code, readiness := ready(nil)
+	if code != http.StatusOK || !readiness.Ready {
+		t.Errorf("expected ready without Kafka, got %d %+v", code, readiness)
+	}
+
+	code, readiness = ready(&mockTopicChecker{status: map[string]error{"request": nil, "response": nil}})
+	if code != http.StatusOK || readiness.Topics["request"] != "ok" {
+		t.Errorf("expected ready with healthy topics, got %d %+v", code, readiness)
+	}
+
+	code, readiness = ready(&mockTopicChecker{status: map[string]error{"request": nil, "response": errors.New("topic does not exist")}})
+	if code != http.StatusServiceUnavailable || readiness.Ready || readiness.Topics["response"] != "topic does not exist" {
+		t.Errorf("expected not ready with missing topic, got %d %+v", code, readiness)
+	}
+
+	code, readiness = ready(&mockTopicChecker{err: errors.New("kafka broker is unreachable")})
+	if code != http.StatusServiceUnavailable || readiness.Error == "" {
+		t.Errorf("expected not ready with unreachable broker, got %d %+v", code, readiness)
+	}
+}
@@ -0,0 +1,166 @@
+// Package app собирает order-service из компонентов: репозиториев, сервисов,
+// HTTP API и клиента payment-service. Используется командой cmd и сквозными тестами,
+// которые запускают сервисы в одном процессе с брокером в памяти.
+package app
+
+import (
+	"context"
+	"contracts/signing"
+	"contracts/transport"
+	"errors"
+	"expvar"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/swaggo/http-swagger"
+	"io"
+	"kafkacluster"
+	"log"
+	"net/http"
+	"order-service/config"
+	"order-service/internal/adapters/eventhandler"
+	"order-service/internal/adapters/httphandler"
+	"order-service/internal/application/payment"
+	"order-service/internal/application/service"
+	"order-service/internal/infrastructure/grpcclient"
+	"order-service/internal/infrastructure/kafka"
+	"order-service/internal/infrastructure/memory"
+	"order-service/internal/infrastructure/postgres"
+	"sync"
+)
+
+// App — собранный order-service.
+type App struct {
+	Handler http.Handler                // HTTP API сервиса
+	start   []func(ctx context.Context) // Чтение ответов шины сообщений и событий payment-service
+	closers []io.Closer                 // Ресурсы, освобождаемые в Close
+}
+
+// New собирает order-service поверх пула соединений db.
+// Клиент payment-service выбирается по cfg.PaymentClient: gRPC или шина сообщений.
+// Транспорт шины выбирается по cfg.MessageBus; для транспорта memory
+// запросы и события передаются через брокер broker.
+// Решения по платежам, задержанным до ручной проверки, order-service получает
+// событиями payment-service при любом клиенте.
+func New(ctx context.Context, cfg *config.Config, db *pgxpool.Pool, broker *transport.InMemory) (*App, error) {
+	orderDb, err := postgres.NewPgOrderDb(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to account database: %w", err)
+	}
+	orderService := service.NewOrderService(orderDb)
+
+	var signer *signing.Signer
+	if cfg.SigningKey != nil {
+		signer = signing.NewSigner(*cfg.SigningKey)
+	}
+	if cfg.MessageBus == "memory" && broker == nil {
+		return nil, errors.New("in-memory message bus requires a broker")
+	}
+	a := &App{}
+	var cluster kafkacluster.Cluster
+	var topicChecker kafkacluster.TopicChecker
+	if cfg.MessageBus == "kafka" {
+		cluster, err = kafkaCluster(cfg)
+		if err != nil {
+			return nil, err
+		}
+		specs := []kafkacluster.TopicSpec{
+			{Name: cfg.KafkaEventsTopic, Partitions: cfg.KafkaPartitions, ReplicationFactor: cfg.KafkaReplication},
+		}
+		if cfg.PaymentClient == "bus" {
+			specs = append(specs,
+				// payment-service проверяет возраст подписи запроса по метке времени брокера.
+				kafkacluster.TopicSpec{Name: cfg.KafkaRequestTopic, Partitions: cfg.KafkaPartitions, ReplicationFactor: cfg.KafkaReplication, LogAppendTime: true},
+				kafkacluster.TopicSpec{Name: cfg.KafkaReplyTopic, Partitions: cfg.KafkaPartitions, ReplicationFactor: cfg.KafkaReplication},
+			)
+		}
+		topics := kafkacluster.NewTopics(cluster, specs)
+		startupCtx, cancel := context.WithTimeout(ctx, kafkacluster.DefaultStartupTimeout)
+		err = topics.Ensure(startupCtx, cfg.KafkaCreateTopics)
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("kafka topics are not ready: %w", err)
+		}
+		topicChecker = topics
+	}
+
+	var paymentClient payment.Client
+	switch {
+	case cfg.PaymentClient == "grpc":
+		conn, err := grpcclient.Dial(cfg.PaymentGrpcAddr)
+		if err != nil {
+			return nil, err
+		}
+		paymentClient = grpcclient.NewPaymentClient(conn, cfg.PaymentGrpcTimeout)
+		a.closers = append(a.closers, conn)
+	case cfg.MessageBus == "memory":
+		messageBus := memory.NewMessageBus(broker, cfg.KafkaRequestTopic, cfg.KafkaReplyTimeout, cfg.KafkaEncoding, signer)
+		paymentClient = payment.NewBusClient(messageBus)
+	default:
+		// Каждый экземпляр читает ответы из своего топика в своей группе потребителей.
+		consumer := kafka.NewConsumer(cluster, cfg.KafkaReplyTopic, cfg.KafkaGroupID+"."+cfg.KafkaInstanceID)
+		producer := kafka.NewProducer(cluster, cfg.KafkaRequestTopic)
+		kafkaBus := kafka.NewMessageBus(consumer, producer, cfg.KafkaReplyTopic, cfg.KafkaReplyTimeout, cfg.KafkaEncoding, signer)
+		paymentClient = payment.NewBusClient(kafkaBus)
+		a.start = append(a.start, kafkaBus.StartReading)
+		a.closers = append(a.closers, consumer, producer)
+	}
+
+	eventsHandler := eventhandler.NewPaymentEventsHandler(orderService)
+	if cfg.MessageBus == "memory" {
+		a.start = append(a.start, func(ctx context.Context) {
+			memory.ListenEvents(ctx, broker, cfg.KafkaEventsTopic, eventsHandler.Handle)
+		})
+	} else {
+		// Экземпляры читают события в общей группе потребителей: каждое решение обрабатывается один раз.
+		consumer := kafka.NewConsumer(cluster, cfg.KafkaEventsTopic, cfg.KafkaGroupID)
+		a.start = append(a.start, kafka.NewEventListener(consumer, eventsHandler.Handle).Start)
+		a.closers = append(a.closers, consumer)
+	}
+
+	httpHandler := httphandler.NewOrderHandler(ctx, orderService, paymentClient)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /orders/{id}", httpHandler.GetOrder)
+	mux.HandleFunc("POST /orders", httpHandler.CreateOrder)
+	mux.HandleFunc("GET /users/{id}/orders", httpHandler.GetUserOrders)
+	mux.HandleFunc("PATCH /orders/{id}", httpHandler.PayOrder)
+	readinessHandler := kafkacluster.NewReadinessHandler(ctx, topicChecker)
+	mux.HandleFunc("GET /ready", readinessHandler.Ready)
+	mux.Handle("/swagger/order/", httpSwagger.WrapHandler)
+	mux.Handle("GET /debug/vars", expvar.Handler())
+	a.Handler = mux
+	return a, nil
+}
+
+// kafkaCluster возвращает параметры подключения к Kafka: адреса брокеров, TLS и SASL.
+// Возвращает ошибку, если сертификаты не удалось загрузить.
+func kafkaCluster(cfg *config.Config) (kafkacluster.Cluster, error) {
+	cluster, err := kafkacluster.NewCluster(cfg.KafkaBrokers, cfg.KafkaSecurity)
+	if err != nil {
+		return kafkacluster.Cluster{}, fmt.Errorf("invalid kafka security settings: %w", err)
+	}
+	return cluster, nil
+}
+
+// Start читает ответы на запросы из шины сообщений и события payment-service,
+// пока не будет отменён контекст.
+func (a *App) Start(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, start := range a.start {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+// Close закрывает соединения шины сообщений или gRPC-клиента.
+func (a *App) Close() {
+	for _, closer := range a.closers {
+		err := closer.Close()
+		if err != nil {
+			log.Printf("failed to close resource: %v", err)
+		}
+	}
+}
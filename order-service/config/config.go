@@ -1,18 +1,38 @@
 package config
 
 import (
+	"contracts"
+	"contracts/signing"
 	"fmt"
+	"kafkacluster"
 	"os"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type Config struct {
 	HttpPort           string
 	DatabaseURL        string
+	MessageBus         string
 	KafkaBrokers       []string
 	KafkaRequestTopic  string
 	KafkaResponseTopic string
 	KafkaGroupID       string
+	KafkaInstanceID    string
+	KafkaReplyTopic    string
+	KafkaEventsTopic   string
+	KafkaReplyTimeout  time.Duration
+	KafkaEncoding      contracts.Encoding
+	KafkaCreateTopics  bool
+	KafkaPartitions    int
+	KafkaReplication   int
+	KafkaSecurity      kafkacluster.Security
+	SigningKey         *signing.Key
+	PaymentClient      string
+	PaymentGrpcAddr    string
+	PaymentGrpcTimeout time.Duration
 }
 
 func mustGetEnv(key string) (string, error) {
@@ -23,6 +43,67 @@ func mustGetEnv(key string) (string, error) {
 	return value, nil
 }
 
+func getDurationEnv(key string) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return 0, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative duration", key)
+	}
+	return duration, nil
+}
+
+func getBoolEnv(key string) (bool, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return false, nil
+	}
+	flag, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%s must be a boolean", key)
+	}
+	return flag, nil
+}
+
+// getPositiveIntEnv получает необязательное положительное целое число из переменной окружения
+// или fallback, если переменная не задана.
+func getPositiveIntEnv(key string, fallback int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil || number <= 0 {
+		return 0, fmt.Errorf("%s must be a positive integer", key)
+	}
+	return number, nil
+}
+
+// instanceIdPattern — допустимые символы имени топика Kafka.
+var instanceIdPattern = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// getInstanceIdEnv возвращает идентификатор экземпляра сервиса из переменной окружения
+// или пустую строку, если она не задана. Идентификатор входит в имя топика ответов экземпляра.
+func getInstanceIdEnv(key string) (string, error) {
+	value := os.Getenv(key)
+	if value != "" && !instanceIdPattern.MatchString(value) {
+		return "", fmt.Errorf("%s must contain only letters, digits, '.', '_' and '-'", key)
+	}
+	return value, nil
+}
+
+func getEncodingEnv(key string) (contracts.Encoding, error) {
+	switch os.Getenv(key) {
+	case "", "json":
+		return contracts.EncodingJSON, nil
+	case "protobuf":
+		return contracts.EncodingProtobuf, nil
+	}
+	return "", fmt.Errorf("%s must be json or protobuf", key)
+}
+
 func LoadConfig() (*Config, error) {
 	errs := make([]string, 0)
 
@@ -36,11 +117,37 @@ func LoadConfig() (*Config, error) {
 		errs = append(errs, err.Error())
 	}
 
-	brokers, err := mustGetEnv("KAFKA_URL")
+	messageBus := os.Getenv("MESSAGE_BUS")
+	if messageBus == "" {
+		messageBus = "kafka"
+	}
+	if messageBus != "kafka" && messageBus != "memory" {
+		errs = append(errs, "MESSAGE_BUS must be kafka or memory")
+	}
+
+	paymentClient := os.Getenv("PAYMENT_CLIENT")
+	if paymentClient == "" {
+		paymentClient = "bus"
+	}
+	if paymentClient != "bus" && paymentClient != "grpc" {
+		errs = append(errs, "PAYMENT_CLIENT must be bus or grpc")
+	}
+
+	paymentGrpcAddr := os.Getenv("PAYMENT_GRPC_ADDR")
+	if paymentGrpcAddr == "" && paymentClient == "grpc" {
+		errs = append(errs, "PAYMENT_GRPC_ADDR is required")
+	}
+
+	paymentGrpcTimeout, err := getDurationEnv("PAYMENT_GRPC_TIMEOUT")
 	if err != nil {
 		errs = append(errs, err.Error())
 	}
 
+	brokers := os.Getenv("KAFKA_URL")
+	if brokers == "" && messageBus == "kafka" {
+		errs = append(errs, "KAFKA_URL is required")
+	}
+
 	consumerTopic, err := mustGetEnv("KAFKA_REQUEST_TOPIC")
 	if err != nil {
 		errs = append(errs, err.Error())
@@ -51,20 +158,88 @@ func LoadConfig() (*Config, error) {
 		errs = append(errs, err.Error())
 	}
 
-	groupID, err := mustGetEnv("KAFKA_GROUP_ID")
+	// Топик событий payment-service: из него приходят решения по платежам,
+	// задержанным до ручной проверки.
+	eventsTopic := os.Getenv("KAFKA_EVENTS_TOPIC")
+	if eventsTopic == "" {
+		eventsTopic = "account-events"
+	}
+
+	groupID := os.Getenv("KAFKA_GROUP_ID")
+	if groupID == "" && messageBus == "kafka" {
+		errs = append(errs, "KAFKA_GROUP_ID is required")
+	}
+
+	// Идентификатор должен сохраняться при перезапуске: иначе каждый запуск
+	// создаёт новый топик ответов, а прежний остаётся в кластере.
+	instanceId, err := getInstanceIdEnv("KAFKA_INSTANCE_ID")
+	if err != nil {
+		errs = append(errs, err.Error())
+	} else if instanceId == "" && messageBus == "kafka" {
+		errs = append(errs, "KAFKA_INSTANCE_ID is required")
+	}
+
+	replyTimeout, err := getDurationEnv("KAFKA_REPLY_TIMEOUT")
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	encoding, err := getEncodingEnv("KAFKA_MESSAGE_ENCODING")
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	createTopics, err := getBoolEnv("KAFKA_CREATE_TOPICS")
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	partitions, err := getPositiveIntEnv("KAFKA_TOPIC_PARTITIONS", 1)
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	replication, err := getPositiveIntEnv("KAFKA_TOPIC_REPLICATION", 1)
 	if err != nil {
 		errs = append(errs, err.Error())
 	}
 
+	kafkaSecurity, securityErrs := kafkacluster.LoadSecurity()
+	errs = append(errs, securityErrs...)
+
+	// Без ключа запросы на оплату не подписываются.
+	var signingKey *signing.Key
+	if value := os.Getenv("REQUEST_SIGNING_KEY"); value != "" {
+		key, err := signing.ParseKey(value)
+		if err != nil {
+			errs = append(errs, "REQUEST_SIGNING_KEY: "+err.Error())
+		}
+		signingKey = &key
+	}
+
 	if len(errs) > 0 {
 		return nil, fmt.Errorf("config validation failed:\n  %s", strings.Join(errs, "\n  "))
 	}
 	return &Config{
 		HttpPort:           httpPort,
 		DatabaseURL:        db,
+		MessageBus:         messageBus,
 		KafkaBrokers:       strings.Split(brokers, ";"),
 		KafkaRequestTopic:  consumerTopic,
 		KafkaResponseTopic: producerTopic,
 		KafkaGroupID:       groupID,
+		KafkaInstanceID:    instanceId,
+		KafkaReplyTopic:    producerTopic + "." + instanceId,
+		KafkaEventsTopic:   eventsTopic,
+		KafkaReplyTimeout:  replyTimeout,
+		KafkaEncoding:      encoding,
+		KafkaCreateTopics:  createTopics,
+		KafkaPartitions:    partitions,
+		KafkaReplication:   replication,
+		KafkaSecurity:      kafkaSecurity,
+		SigningKey:         signingKey,
+		PaymentClient:      paymentClient,
+		PaymentGrpcAddr:    paymentGrpcAddr,
+		PaymentGrpcTimeout: paymentGrpcTimeout,
 	}, nil
 }
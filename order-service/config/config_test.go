@@ -1,10 +1,16 @@
 package config
 
 import (
+	"contracts"
+	"contracts/signing"
+	"encoding/base64"
+	"kafkacluster"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestLoadConfig_Success(t *testing.T) {
@@ -14,6 +20,7 @@ func TestLoadConfig_Success(t *testing.T) {
 	_ = os.Setenv("KAFKA_REQUEST_TOPIC", "requests")
 	_ = os.Setenv("KAFKA_RESPONSE_TOPIC", "responses")
 	_ = os.Setenv("KAFKA_GROUP_ID", "app-group")
+	_ = os.Setenv("KAFKA_INSTANCE_ID", "order-1")
 
 	defer func() {
 		_ = os.Unsetenv("HTTP_PORT")
@@ -22,6 +29,7 @@ func TestLoadConfig_Success(t *testing.T) {
 		_ = os.Unsetenv("KAFKA_REQUEST_TOPIC")
 		_ = os.Unsetenv("KAFKA_RESPONSE_TOPIC")
 		_ = os.Unsetenv("KAFKA_GROUP_ID")
+		_ = os.Unsetenv("KAFKA_INSTANCE_ID")
 	}()
 
 	config, err := LoadConfig()
@@ -53,6 +61,14 @@ func TestLoadConfig_Success(t *testing.T) {
 	if config.KafkaGroupID != "app-group" {
 		t.Errorf("Expected group ID 'app-group', got %s", config.KafkaGroupID)
 	}
+
+	if config.KafkaReplyTopic != "responses.order-1" {
+		t.Errorf("Expected reply topic 'responses.order-1', got %s", config.KafkaReplyTopic)
+	}
+
+	if config.KafkaEventsTopic != "account-events" {
+		t.Errorf("Expected default events topic 'account-events', got %s", config.KafkaEventsTopic)
+	}
 }
 
 func TestLoadConfig_MissingRequired(t *testing.T) {
@@ -84,7 +100,7 @@ func TestLoadConfig_AllMissing(t *testing.T) {
 	}
 
 	errorMsg := err.Error()
-	requiredVars := []string{"HTTP_PORT", "DATABASE_URL", "KAFKA_URL", "KAFKA_REQUEST_TOPIC", "KAFKA_RESPONSE_TOPIC", "KAFKA_GROUP_ID"}
+	requiredVars := []string{"HTTP_PORT", "DATABASE_URL", "KAFKA_URL", "KAFKA_REQUEST_TOPIC", "KAFKA_RESPONSE_TOPIC", "KAFKA_GROUP_ID", "KAFKA_INSTANCE_ID"}
 
 	for _, varName := range requiredVars {
 		if !strings.Contains(errorMsg, varName) {
@@ -93,6 +109,28 @@ func TestLoadConfig_AllMissing(t *testing.T) {
 	}
 }
 
+func TestLoadConfig_InstanceIdRequiredForKafka(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("HTTP_PORT", "8080")
+	_ = os.Setenv("DATABASE_URL", "postgres://localhost:5432/db")
+	_ = os.Setenv("KAFKA_URL", "kafka:9092")
+	_ = os.Setenv("KAFKA_REQUEST_TOPIC", "requests")
+	_ = os.Setenv("KAFKA_RESPONSE_TOPIC", "responses")
+	_ = os.Setenv("KAFKA_GROUP_ID", "app-group")
+	defer os.Clearenv()
+
+	_, err := LoadConfig()
+	if err == nil || !strings.Contains(err.Error(), "KAFKA_INSTANCE_ID is required") {
+		t.Errorf("Expected error for missing KAFKA_INSTANCE_ID, got %v", err)
+	}
+
+	_ = os.Setenv("MESSAGE_BUS", "memory")
+	_, err = LoadConfig()
+	if err != nil {
+		t.Errorf("Expected in-memory bus to work without KAFKA_INSTANCE_ID, got %v", err)
+	}
+}
+
 func TestLoadConfig_KafkaBrokersParsing(t *testing.T) {
 	_ = os.Setenv("HTTP_PORT", "8080")
 	_ = os.Setenv("DATABASE_URL", "postgres://localhost:5432/db")
@@ -100,6 +138,7 @@ func TestLoadConfig_KafkaBrokersParsing(t *testing.T) {
 	_ = os.Setenv("KAFKA_REQUEST_TOPIC", "requests")
 	_ = os.Setenv("KAFKA_RESPONSE_TOPIC", "responses")
 	_ = os.Setenv("KAFKA_GROUP_ID", "group")
+	_ = os.Setenv("KAFKA_INSTANCE_ID", "order-1")
 
 	defer func() {
 		_ = os.Unsetenv("HTTP_PORT")
@@ -108,6 +147,7 @@ func TestLoadConfig_KafkaBrokersParsing(t *testing.T) {
 		_ = os.Unsetenv("KAFKA_REQUEST_TOPIC")
 		_ = os.Unsetenv("KAFKA_RESPONSE_TOPIC")
 		_ = os.Unsetenv("KAFKA_GROUP_ID")
+		_ = os.Unsetenv("KAFKA_INSTANCE_ID")
 	}()
 
 	config, err := LoadConfig()
@@ -140,3 +180,239 @@ func TestMustGetEnv(t *testing.T) {
 		t.Errorf("Unexpected error message: %v", err)
 	}
 }
+
+func TestGetDurationEnv(t *testing.T) {
+	_ = os.Setenv("TEST_DURATION", "15s")
+	defer os.Unsetenv("TEST_DURATION")
+
+	value, err := getDurationEnv("TEST_DURATION")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if value != 15*time.Second {
+		t.Errorf("Expected 15s, got %s", value)
+	}
+
+	value, err = getDurationEnv("NON_EXISTENT_VAR")
+	if err != nil || value != 0 {
+		t.Errorf("Expected 0 for unset var, got %s, %v", value, err)
+	}
+
+	_ = os.Setenv("TEST_DURATION", "soon")
+	_, err = getDurationEnv("TEST_DURATION")
+	if err == nil {
+		t.Error("Expected error for invalid duration")
+	}
+}
+
+func TestGetEncodingEnv(t *testing.T) {
+	defer os.Unsetenv("TEST_ENCODING")
+	cases := map[string]contracts.Encoding{
+		"":         contracts.EncodingJSON,
+		"json":     contracts.EncodingJSON,
+		"protobuf": contracts.EncodingProtobuf,
+	}
+	for value, want := range cases {
+		_ = os.Setenv("TEST_ENCODING", value)
+		got, err := getEncodingEnv("TEST_ENCODING")
+		if err != nil || got != want {
+			t.Errorf("Expected %s for %q, got %s, %v", want, value, got, err)
+		}
+	}
+
+	_ = os.Setenv("TEST_ENCODING", "xml")
+	_, err := getEncodingEnv("TEST_ENCODING")
+	if err == nil {
+		t.Error("Expected error for unsupported encoding")
+	}
+}
+
+func TestLoadConfig_MemoryMessageBus(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("HTTP_PORT", "8080")
+	_ = os.Setenv("DATABASE_URL", "postgres://localhost:5432/db")
+	_ = os.Setenv("MESSAGE_BUS", "memory")
+	_ = os.Setenv("KAFKA_REQUEST_TOPIC", "requests")
+	_ = os.Setenv("KAFKA_RESPONSE_TOPIC", "responses")
+	defer os.Clearenv()
+
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Expected no error without Kafka broker for memory bus, got %v", err)
+	}
+	if config.MessageBus != "memory" {
+		t.Errorf("Expected memory message bus, got %s", config.MessageBus)
+	}
+
+	_ = os.Setenv("MESSAGE_BUS", "rabbitmq")
+	_, err = LoadConfig()
+	if err == nil || !strings.Contains(err.Error(), "MESSAGE_BUS") {
+		t.Errorf("Expected error for unsupported message bus, got %v", err)
+	}
+}
+
+func TestLoadConfig_GrpcPaymentClient(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("HTTP_PORT", "8080")
+	_ = os.Setenv("DATABASE_URL", "postgres://localhost:5432/db")
+	_ = os.Setenv("MESSAGE_BUS", "memory")
+	_ = os.Setenv("KAFKA_REQUEST_TOPIC", "requests")
+	_ = os.Setenv("KAFKA_RESPONSE_TOPIC", "responses")
+	_ = os.Setenv("PAYMENT_CLIENT", "grpc")
+	defer os.Clearenv()
+
+	_, err := LoadConfig()
+	if err == nil || !strings.Contains(err.Error(), "PAYMENT_GRPC_ADDR") {
+		t.Errorf("Expected error for missing gRPC address, got %v", err)
+	}
+
+	_ = os.Setenv("PAYMENT_GRPC_ADDR", "payment-service:9090")
+	_ = os.Setenv("PAYMENT_GRPC_TIMEOUT", "2s")
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if config.PaymentClient != "grpc" || config.PaymentGrpcAddr != "payment-service:9090" || config.PaymentGrpcTimeout != 2*time.Second {
+		t.Errorf("Unexpected payment client config: %s %s %v", config.PaymentClient, config.PaymentGrpcAddr, config.PaymentGrpcTimeout)
+	}
+
+	_ = os.Setenv("PAYMENT_CLIENT", "http")
+	_, err = LoadConfig()
+	if err == nil || !strings.Contains(err.Error(), "PAYMENT_CLIENT") {
+		t.Errorf("Expected error for unsupported payment client, got %v", err)
+	}
+}
+
+func TestGetInstanceIdEnv(t *testing.T) {
+	defer os.Unsetenv("TEST_INSTANCE_ID")
+	_ = os.Setenv("TEST_INSTANCE_ID", "order-2")
+	value, err := getInstanceIdEnv("TEST_INSTANCE_ID")
+	if err != nil || value != "order-2" {
+		t.Errorf("Expected order-2, got %s, %v", value, err)
+	}
+
+	value, err = getInstanceIdEnv("NON_EXISTENT_VAR")
+	if err != nil || value != "" {
+		t.Errorf("Expected empty instance id for unset var, got %s, %v", value, err)
+	}
+
+	_ = os.Setenv("TEST_INSTANCE_ID", "order/2")
+	_, err = getInstanceIdEnv("TEST_INSTANCE_ID")
+	if err == nil {
+		t.Error("Expected error for invalid instance id")
+	}
+}
+
+func TestGetPositiveIntEnv(t *testing.T) {
+	_ = os.Setenv("TEST_PARTITIONS", "3")
+	defer os.Unsetenv("TEST_PARTITIONS")
+
+	value, err := getPositiveIntEnv("TEST_PARTITIONS", 1)
+	if err != nil || value != 3 {
+		t.Errorf("Expected 3, got %d, %v", value, err)
+	}
+
+	value, err = getPositiveIntEnv("NON_EXISTENT_VAR", 1)
+	if err != nil || value != 1 {
+		t.Errorf("Expected fallback 1 for unset var, got %d, %v", value, err)
+	}
+
+	_ = os.Setenv("TEST_PARTITIONS", "0")
+	_, err = getPositiveIntEnv("TEST_PARTITIONS", 1)
+	if err == nil {
+		t.Error("Expected error for non-positive value")
+	}
+}
+
+func TestGetBoolEnv(t *testing.T) {
+	_ = os.Setenv("TEST_CREATE_TOPICS", "true")
+	defer os.Unsetenv("TEST_CREATE_TOPICS")
+
+	value, err := getBoolEnv("TEST_CREATE_TOPICS")
+	if err != nil || !value {
+		t.Errorf("Expected true, got %v, %v", value, err)
+	}
+
+	value, err = getBoolEnv("NON_EXISTENT_VAR")
+	if err != nil || value {
+		t.Errorf("Expected false for unset var, got %v, %v", value, err)
+	}
+
+	_ = os.Setenv("TEST_CREATE_TOPICS", "sometimes")
+	_, err = getBoolEnv("TEST_CREATE_TOPICS")
+	if err == nil {
+		t.Error("Expected error for invalid boolean")
+	}
+}
+
+func TestLoadConfig_KafkaSecurity(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("HTTP_PORT", "8080")
+	_ = os.Setenv("DATABASE_URL", "postgres://localhost:5432/db")
+	_ = os.Setenv("KAFKA_URL", "kafka:9093")
+	_ = os.Setenv("KAFKA_REQUEST_TOPIC", "requests")
+	_ = os.Setenv("KAFKA_RESPONSE_TOPIC", "responses")
+	_ = os.Setenv("KAFKA_GROUP_ID", "app-group")
+	_ = os.Setenv("KAFKA_INSTANCE_ID", "order-1")
+	defer os.Clearenv()
+
+	// Разбор и проверка параметров подробно проверяются в contracts/kafkacluster.
+	_ = os.Setenv("KAFKA_SASL_MECHANISM", "plain")
+	_ = os.Setenv("KAFKA_SASL_USERNAME", "order")
+	_ = os.Setenv("KAFKA_SASL_PASSWORD", "password")
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := kafkacluster.Security{SASLMechanism: kafkacluster.SASLPlain, SASLUsername: "order", SASLPassword: "password"}
+	if config.KafkaSecurity != expected {
+		t.Errorf("Expected security %+v, got %+v", expected, config.KafkaSecurity)
+	}
+
+	_ = os.Setenv("KAFKA_TLS_CA_FILE", filepath.Join(t.TempDir(), "ca.pem"))
+	_ = os.Setenv("KAFKA_SASL_MECHANISM", "GSSAPI")
+	_, err = LoadConfig()
+	if err == nil {
+		t.Fatal("Expected error for inconsistent Kafka security settings, got nil")
+	}
+	for _, message := range []string{"KAFKA_TLS_CA_FILE requires KAFKA_TLS=true", "KAFKA_SASL_MECHANISM must be"} {
+		if !strings.Contains(err.Error(), message) {
+			t.Errorf("Error should mention %q, got: %v", message, err)
+		}
+	}
+}
+func TestLoadConfig_SigningKey(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("HTTP_PORT", "8080")
+	_ = os.Setenv("DATABASE_URL", "postgres://localhost:5432/db")
+	_ = os.Setenv("KAFKA_URL", "kafka:9092")
+	_ = os.Setenv("KAFKA_REQUEST_TOPIC", "requests")
+	_ = os.Setenv("KAFKA_RESPONSE_TOPIC", "responses")
+	_ = os.Setenv("KAFKA_GROUP_ID", "app-group")
+	_ = os.Setenv("KAFKA_INSTANCE_ID", "order-1")
+	defer os.Clearenv()
+
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if config.SigningKey != nil {
+		t.Errorf("Expected requests to be unsigned by default, got key %s", config.SigningKey.Id)
+	}
+
+	seed := base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+	_ = os.Setenv("REQUEST_SIGNING_KEY", "2025-11:ed25519:"+seed)
+	config, err = LoadConfig()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if config.SigningKey == nil || config.SigningKey.Id != "2025-11" || config.SigningKey.Algorithm != signing.Ed25519 {
+		t.Errorf("Unexpected signing key %+v", config.SigningKey)
+	}
+
+	_ = os.Setenv("REQUEST_SIGNING_KEY", "2025-11:hmac-sha256:c2hvcnQ=")
+	_, err = LoadConfig()
+	if err == nil || !strings.Contains(err.Error(), "REQUEST_SIGNING_KEY") {
+		t.Errorf("Expected error for short signing key, got %v", err)
+	}
+}
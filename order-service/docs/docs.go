@@ -63,6 +63,7 @@ const docTemplate = `{
                 }
             },
             "patch": {
+                "description": "Charges the order amount. When the payment is declined the reason code is returned\nin the X-Decline-Reason header: 402 for INSUFFICIENT_FUNDS, 403 for LIMIT_EXCEEDED.\nA suspicious payment is held for manual review and answered with 202 and PENDING_REVIEW;\nthe order is marked as paid, or released for another payment, once the review is decided.",
                 "parameters": [
                     {
                         "type": "integer",
@@ -70,12 +71,64 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "wallet to charge, the default wallet is used if omitted",
+                        "name": "account_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {}
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {}
+                    },
+                    "402": {
+                        "description": "Payment Required",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {}
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {}
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/ready": {
+            "get": {
+                "description": "Проверяет доступность брокера Kafka и топиков сервиса. Без Kafka сервис всегда готов",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Готовность сервиса",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/kafkacluster.Readiness"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/kafkacluster.Readiness"
+                        }
                     }
                 }
             }
@@ -114,6 +167,26 @@ const docTemplate = `{
                     "type": "integer"
                 }
             }
+        },
+        "kafkacluster.Readiness": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "Причина, по которой топики не удалось проверить",
+                    "type": "string"
+                },
+                "ready": {
+                    "description": "Сервис готов обрабатывать запросы",
+                    "type": "boolean"
+                },
+                "topics": {
+                    "description": "Состояние топиков: ok или описание ошибки",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
         }
     }
 }`
@@ -128,6 +201,8 @@ var SwaggerInfo = &swag.Spec{
 	Description:      "",
 	InfoInstanceName: "swagger",
 	SwaggerTemplate:  docTemplate,
+	LeftDelim:        "{{",
+	RightDelim:       "}}",
 }
 
 func init() {
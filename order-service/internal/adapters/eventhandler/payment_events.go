@@ -0,0 +1,36 @@
+package eventhandler
+
+import (
+	"context"
+	"contracts"
+	"contracts/transport"
+	"encoding/json"
+	"fmt"
+	"order-service/internal/application/service"
+)
+
+// PaymentEventsHandler завершает оплату заказов по событиям, которые payment-service
+// публикует в топик событий счетов.
+type PaymentEventsHandler struct {
+	orderService *service.OrderService
+}
+
+// NewPaymentEventsHandler создаёт новый экземпляр PaymentEventsHandler.
+func NewPaymentEventsHandler(orderService *service.OrderService) *PaymentEventsHandler {
+	return &PaymentEventsHandler{orderService: orderService}
+}
+
+// Handle обрабатывает событие message. Учитываются только решения по платежам,
+// задержанным до ручной проверки; события других типов пропускаются.
+// Возвращает ошибку, если событие не удалось разобрать или сохранить заказ.
+func (h *PaymentEventsHandler) Handle(ctx context.Context, message *transport.Message) error {
+	if message.Header(contracts.EventTypeHeader) != contracts.EventPaymentReviewed {
+		return nil
+	}
+	var decision contracts.PaymentReviewed
+	err := json.Unmarshal(message.Value, &decision)
+	if err != nil {
+		return fmt.Errorf("error decoding %s event: %w", contracts.EventPaymentReviewed, err)
+	}
+	return h.orderService.CompleteReviewedPayment(ctx, decision)
+}
@@ -0,0 +1,68 @@
+package eventhandler
+
+import (
+	"context"
+	"contracts"
+	"contracts/transport"
+	"order-service/internal/application/service"
+	"order-service/internal/domain"
+	"testing"
+)
+
+type mockOrderRepository struct {
+	data map[int]domain.Order
+}
+
+func (m *mockOrderRepository) GetById(ctx context.Context, id int) (*domain.Order, error) {
+	order := m.data[id]
+	return &order, nil
+}
+
+func (m *mockOrderRepository) Save(ctx context.Context, order *domain.Order) error {
+	m.data[order.Id] = *order
+	return nil
+}
+
+func (m *mockOrderRepository) GetUserOrders(ctx context.Context, userId int) ([]domain.Order, error) {
+	return nil, nil
+}
+
+func (m *mockOrderRepository) GetByPendingTransactionId(ctx context.Context, transactionId int) (*domain.Order, error) {
+	for _, order := range m.data {
+		if order.PendingTransactionId != nil && *order.PendingTransactionId == transactionId {
+			return &order, nil
+		}
+	}
+	return nil, nil
+}
+
+func TestPaymentEventsHandler_Handle(t *testing.T) {
+	ctx := context.Background()
+	order := domain.Order{Id: 1, UserId: 10, Amount: 100}
+	order.AwaitPayment(77)
+	orderDb := &mockOrderRepository{data: map[int]domain.Order{1: order}}
+	handler := NewPaymentEventsHandler(service.NewOrderService(orderDb))
+
+	event := func(eventType, value string) *transport.Message {
+		message := &transport.Message{Key: []byte("5"), Value: []byte(value)}
+		message.SetHeader(contracts.EventTypeHeader, eventType)
+		return message
+	}
+
+	err := handler.Handle(ctx, event("AccountDebited", `{"transaction_id":77,"amount":100}`))
+	if err != nil || orderDb.data[1].IsPayed {
+		t.Fatalf("expected other events to be skipped, got %v, order %+v", err, orderDb.data[1])
+	}
+	err = handler.Handle(ctx, event(contracts.EventPaymentReviewed, `{"transaction_id":`))
+	if err == nil {
+		t.Error("expected error for a malformed event")
+	}
+	err = handler.Handle(ctx, event(contracts.EventPaymentReviewed,
+		`{"type":"PaymentReviewed","account_id":5,"transaction_id":77,"transaction_status":"completed","status":"active"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !orderDb.data[1].IsPayed || orderDb.data[1].PendingTransactionId != nil {
+		t.Errorf("expected order to be paid, got %+v", orderDb.data[1])
+	}
+}
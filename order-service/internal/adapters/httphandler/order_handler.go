@@ -2,25 +2,28 @@ package httphandler
 
 import (
 	"context"
+	"contracts"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"order-service/internal/application/payment"
 	"order-service/internal/application/service"
-	"order-service/internal/infrastructure/kafka"
+	"order-service/internal/domain"
 	"strconv"
 )
 
 type OrderHandler struct {
-	orderService *service.OrderService
-	messageBus   *kafka.MessageBus
-	ctx          context.Context
+	orderService  *service.OrderService
+	paymentClient payment.Client
+	ctx           context.Context
 }
 
-func NewOrderHandler(ctx context.Context, orderService *service.OrderService, bus *kafka.MessageBus) *OrderHandler {
+func NewOrderHandler(ctx context.Context, orderService *service.OrderService, paymentClient payment.Client) *OrderHandler {
 	return &OrderHandler{
-		messageBus:   bus,
-		ctx:          ctx,
-		orderService: orderService,
+		paymentClient: paymentClient,
+		ctx:           ctx,
+		orderService:  orderService,
 	}
 }
 
@@ -73,8 +76,18 @@ func (h *OrderHandler) GetOrder(w http.ResponseWriter, r *http.Request) {
 }
 
 // PayOrder godoc
+// @Description Charges the order amount. When the payment is declined the reason code is returned
+// @Description in the X-Decline-Reason header: 402 for INSUFFICIENT_FUNDS, 403 for LIMIT_EXCEEDED.
+// @Description A suspicious payment is held for manual review and answered with 202 and PENDING_REVIEW;
+// @Description the order is marked as paid, or released for another payment, once the review is decided.
 // @Param id path int true "id"
+// @Param account_id query int false "wallet to charge, the default wallet is used if omitted"
 // @Success 200 {object} interface{}
+// @Success 202 {object} interface{}
+// @Failure 402 {object} interface{}
+// @Failure 403 {object} interface{}
+// @Failure 503 {object} interface{}
+// @Failure 504 {object} interface{}
 // @Router /orders/{id} [patch]
 func (h *OrderHandler) PayOrder(w http.ResponseWriter, r *http.Request) {
 	id, err := getIntPathValue(r, "id")
@@ -82,6 +95,14 @@ func (h *OrderHandler) PayOrder(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	accountId := 0
+	if r.URL.Query().Has("account_id") {
+		accountId, err = getIntQueryValue(r, "account_id")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
 	order, err := h.orderService.GetById(h.ctx, id)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusNotFound)
@@ -92,19 +113,20 @@ func (h *OrderHandler) PayOrder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	txn := h.orderService.CreateTransaction(h.ctx, order)
-	txnJson, err := json.Marshal(txn)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-	strUserId := []byte(strconv.Itoa(txn.UserId))
-	message, err := h.messageBus.SendMessage(h.ctx, strUserId, txnJson)
+	txn := h.orderService.CreateTransaction(h.ctx, order, accountId)
+	result, err := h.paymentClient.Charge(h.ctx, contracts.PaymentRequest{
+		Id:        txn.Id,
+		UserId:    txn.UserId,
+		AccountId: txn.AccountId,
+		IsDeposit: txn.IsDeposit,
+		Amount:    txn.Amount,
+		Date:      txn.Date,
+	})
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), paymentErrorStatus(err))
 		return
 	}
-	if string(message) == "OK" {
+	if result.Status == contracts.PaymentApproved {
 		err = h.orderService.PayOrder(h.ctx, id)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusBadRequest)
@@ -113,7 +135,26 @@ func (h *OrderHandler) PayOrder(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		return
 	}
-	http.Error(w, string(message), http.StatusBadRequest)
+	if result.Status == contracts.PaymentPendingReview {
+		order.AwaitPayment(txn.Id)
+	} else {
+		order.ResetPayment()
+	}
+	err = h.orderService.Save(h.ctx, order)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	reason := declineReason(result)
+	if reason != "" {
+		w.Header().Set(DeclineReasonHeader, string(reason))
+	}
+	if result.Status == contracts.PaymentPendingReview {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	body, _ := result.Legacy()
+	http.Error(w, body, declineStatus(reason))
 
 }
 
@@ -139,6 +180,48 @@ func (h *OrderHandler) GetUserOrders(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// DeclineReasonHeader — заголовок HTTP-ответа с кодом причины отказа в оплате.
+const DeclineReasonHeader = "X-Decline-Reason"
+
+// declineReason возвращает код причины отказа в оплате по результату payment-service.
+// Для проведённой оплаты и сбоя обработки код пустой.
+func declineReason(result contracts.PaymentResult) domain.PaymentDeclineReason {
+	switch result.Status {
+	case contracts.PaymentDeclined:
+		return domain.PaymentDeclineReason(result.ErrorCode)
+	case contracts.PaymentPendingReview:
+		return domain.DeclinePendingReview
+	}
+	return ""
+}
+
+// declineStatus возвращает HTTP-статус ответа на отклонённую оплату по коду причины.
+func declineStatus(reason domain.PaymentDeclineReason) int {
+	switch reason {
+	case domain.DeclineInsufficientFunds:
+		return http.StatusPaymentRequired
+	case domain.DeclineLimitExceeded, domain.DeclineOperationNotAllowed, domain.DeclineForeignAccount,
+		domain.DeclineRejectedByReview:
+		return http.StatusForbidden
+	case domain.DeclineAccountNotFound:
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
+
+// paymentErrorStatus возвращает HTTP-статус ответа на ошибку обращения к payment-service.
+func paymentErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, payment.ErrTimeout):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, payment.ErrUnavailable):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, payment.ErrInvalidRequest):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
 func getIntPathValue(r *http.Request, key string) (int, error) {
 	valueStr := r.PathValue(key)
 	if valueStr == "" {
@@ -5,8 +5,10 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"order-service/internal/application/payment"
 	"order-service/internal/application/service"
 	"order-service/internal/domain"
 	"strconv"
@@ -43,6 +45,15 @@ func (m *mockAccountRepository) GetUserOrders(ctx context.Context, userId int) (
 	return orders, nil
 }
 
+func (m *mockAccountRepository) GetByPendingTransactionId(ctx context.Context, transactionId int) (*domain.Order, error) {
+	for _, order := range m.data {
+		if order.PendingTransactionId != nil && *order.PendingTransactionId == transactionId {
+			return &order, nil
+		}
+	}
+	return nil, nil
+}
+
 func setupOrderTest(t *testing.T) (context.Context, *service.OrderService, *OrderHandler) {
 	t.Helper()
 	ctx := context.Background()
@@ -156,3 +167,38 @@ func TestGetIntPathValue(t *testing.T) {
 		t.Error("expected error for invalid int format")
 	}
 }
+
+func TestDeclineStatus(t *testing.T) {
+	tests := []struct {
+		reason domain.PaymentDeclineReason
+		want   int
+	}{
+		{domain.DeclineInsufficientFunds, http.StatusPaymentRequired},
+		{domain.DeclineLimitExceeded, http.StatusForbidden},
+		{domain.DeclineAccountNotFound, http.StatusNotFound},
+		{domain.DeclineRejectedByReview, http.StatusForbidden},
+		{"", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if got := declineStatus(tt.reason); got != tt.want {
+			t.Errorf("declineStatus(%q) = %d, want %d", tt.reason, got, tt.want)
+		}
+	}
+}
+
+func TestPaymentErrorStatus(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{fmt.Errorf("%w: deadline exceeded", payment.ErrTimeout), http.StatusGatewayTimeout},
+		{fmt.Errorf("%w: connection refused", payment.ErrUnavailable), http.StatusServiceUnavailable},
+		{payment.ErrInvalidRequest, http.StatusBadRequest},
+		{errors.New("broker is down"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		if got := paymentErrorStatus(tt.err); got != tt.want {
+			t.Errorf("paymentErrorStatus(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package messaging
+
+import (
+	"context"
+	"contracts"
+	"contracts/transport"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+)
+
+// CorrelationIdHeader — заголовок с идентификатором корреляции запроса.
+// payment-service возвращает его в ответе без изменений.
+const CorrelationIdHeader = "correlation-id"
+
+// ReasonCodeHeader — заголовок ответа payment-service в прежнем формате с кодом причины отказа в оплате.
+const ReasonCodeHeader = "reason-code"
+
+// MessageBus определяет интерфейс обмена запросами и ответами с другими сервисами.
+// Реализуется поверх Kafka и поверх брокера в памяти процесса.
+type MessageBus interface {
+	// SendMessage отправляет запрос с ключом key и содержимым payload в конверте
+	// и ожидает ответ с тем же идентификатором корреляции.
+	// Ключ определяет порядок обработки и может совпадать у одновременных запросов.
+	SendMessage(ctx context.Context, key []byte, payload contracts.Payload) (*transport.Message, error)
+}
+
+// EventHandler обрабатывает событие другого сервиса, прочитанное из топика событий.
+type EventHandler func(ctx context.Context, message *transport.Message) error
+
+// NewCorrelationId возвращает случайный идентификатор корреляции запроса.
+func NewCorrelationId() (string, error) {
+	id := make([]byte, 16)
+	_, err := rand.Read(id)
+	if err != nil {
+		return "", fmt.Errorf("error generating correlation id: %w", err)
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// NewRequest возвращает запрос с ключом key и содержимым payload в конверте кодировки encoding.
+// Идентификатор корреляции передаётся и в конверте, и в заголовке CorrelationIdHeader.
+func NewRequest(key []byte, payload contracts.Payload, encoding contracts.Encoding) (*transport.Message, error) {
+	correlationId, err := NewCorrelationId()
+	if err != nil {
+		return nil, err
+	}
+	value, err := contracts.Marshal(encoding, correlationId, payload)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding message: %w", err)
+	}
+	return &transport.Message{
+		Key:   key,
+		Value: value,
+		Headers: map[string]string{
+			CorrelationIdHeader:         correlationId,
+			contracts.ContentTypeHeader: string(encoding),
+		},
+	}, nil
+}
+
+// DecodePaymentResult возвращает результат оплаты из ответа payment-service.
+// Ответ без заголовка contracts.ContentTypeHeader разбирается в прежнем формате:
+// строка "OK" или текст ошибки и код причины в заголовке ReasonCodeHeader.
+func DecodePaymentResult(message *transport.Message) (contracts.PaymentResult, error) {
+	contentType := message.Header(contracts.ContentTypeHeader)
+	if contentType == "" {
+		return contracts.ParseLegacyPaymentResult(string(message.Value), message.Header(ReasonCodeHeader)), nil
+	}
+	encoding, err := contracts.ParseEncoding(contentType)
+	if err != nil {
+		return contracts.PaymentResult{}, err
+	}
+	var result contracts.PaymentResult
+	_, err = contracts.Unmarshal(encoding, message.Value, &result)
+	if err != nil {
+		return contracts.PaymentResult{}, err
+	}
+	return result, nil
+}
@@ -0,0 +1,62 @@
+package messaging
+
+import (
+	"contracts"
+	"contracts/transport"
+	"testing"
+)
+
+func TestNewRequest(t *testing.T) {
+	request, err := NewRequest([]byte("10"), &contracts.PaymentRequest{Id: 7, Amount: 50}, contracts.EncodingProtobuf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	correlationId := request.Header(CorrelationIdHeader)
+	if correlationId == "" || request.Header(contracts.ContentTypeHeader) != string(contracts.EncodingProtobuf) {
+		t.Errorf("unexpected headers: %+v", request.Headers)
+	}
+	var payload contracts.PaymentRequest
+	envelope, err := contracts.Unmarshal(contracts.EncodingProtobuf, request.Value, &payload)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if envelope.CorrelationId != correlationId || payload.Id != 7 {
+		t.Errorf("unexpected envelope %+v with payload %+v", envelope, payload)
+	}
+
+	other, _ := NewRequest([]byte("10"), &contracts.PaymentRequest{Id: 7}, contracts.EncodingJSON)
+	if other.Header(CorrelationIdHeader) == correlationId {
+		t.Errorf("expected unique correlation ids, got %s twice", correlationId)
+	}
+}
+
+func TestDecodePaymentResult(t *testing.T) {
+	legacy := &transport.Message{Value: []byte("Error processing transaction: limit exceeded"),
+		Headers: map[string]string{ReasonCodeHeader: "LIMIT_EXCEEDED"}}
+	result, err := DecodePaymentResult(legacy)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Status != contracts.PaymentDeclined || result.ErrorCode != contracts.ErrorLimitExceeded {
+		t.Errorf("expected legacy decline, got %+v", result)
+	}
+
+	for _, encoding := range []contracts.Encoding{contracts.EncodingJSON, contracts.EncodingProtobuf} {
+		want := contracts.PaymentResult{TransactionId: 5, Status: contracts.PaymentPendingReview, ErrorCode: contracts.ErrorPendingReview}
+		value, err := contracts.Marshal(encoding, "c", &want)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		msg := &transport.Message{Value: value, Headers: map[string]string{contracts.ContentTypeHeader: string(encoding)}}
+		result, err = DecodePaymentResult(msg)
+		if err != nil || result != want {
+			t.Errorf("%s: expected %+v, got %+v, %v", encoding, want, result, err)
+		}
+	}
+
+	unknown := &transport.Message{Value: []byte("OK"), Headers: map[string]string{contracts.ContentTypeHeader: "text/plain"}}
+	_, err = DecodePaymentResult(unknown)
+	if err == nil {
+		t.Error("expected error for unsupported encoding")
+	}
+}
@@ -0,0 +1,48 @@
+package payment
+
+import (
+	"context"
+	"contracts"
+	"errors"
+	"order-service/internal/application/messaging"
+	"strconv"
+)
+
+var (
+	// ErrTimeout означает, что payment-service не ответил за отведённое время.
+	// Оплата могла быть проведена: повторный запрос с тем же ID вернёт её результат.
+	ErrTimeout = errors.New("payment service did not respond in time")
+	// ErrUnavailable означает, что payment-service недоступен и запрос не был обработан.
+	ErrUnavailable = errors.New("payment service is unavailable")
+	// ErrInvalidRequest означает, что payment-service отклонил запрос как некорректный.
+	ErrInvalidRequest = errors.New("invalid payment request")
+)
+
+// Client определяет интерфейс проведения оплаты в payment-service.
+// Реализуется поверх шины сообщений (BusClient) и поверх gRPC.
+type Client interface {
+	// Charge отправляет запрос на оплату и возвращает её результат.
+	// Отказ по бизнес-правилам — штатный результат со статусом contracts.PaymentDeclined,
+	// а не ошибка.
+	Charge(ctx context.Context, request contracts.PaymentRequest) (contracts.PaymentResult, error)
+}
+
+// BusClient — Client, передающий запросы на оплату через шину сообщений.
+type BusClient struct {
+	bus messaging.MessageBus
+}
+
+// NewBusClient создаёт новый экземпляр BusClient поверх шины bus.
+func NewBusClient(bus messaging.MessageBus) *BusClient {
+	return &BusClient{bus: bus}
+}
+
+// Charge отправляет запрос на оплату с ключом — идентификатором пользователя,
+// чтобы платежи одного пользователя обрабатывались по порядку.
+func (c *BusClient) Charge(ctx context.Context, request contracts.PaymentRequest) (contracts.PaymentResult, error) {
+	message, err := c.bus.SendMessage(ctx, []byte(strconv.Itoa(request.UserId)), &request)
+	if err != nil {
+		return contracts.PaymentResult{}, err
+	}
+	return messaging.DecodePaymentResult(message)
+}
@@ -0,0 +1,46 @@
+package payment
+
+import (
+	"context"
+	"contracts"
+	"contracts/transport"
+	"errors"
+	"testing"
+)
+
+type mockMessageBus struct {
+	key     []byte
+	payload contracts.Payload
+	reply   *transport.Message
+	err     error
+}
+
+func (m *mockMessageBus) SendMessage(ctx context.Context, key []byte, payload contracts.Payload) (*transport.Message, error) {
+	m.key, m.payload = key, payload
+	return m.reply, m.err
+}
+
+func TestBusClient_Charge(t *testing.T) {
+	bus := &mockMessageBus{reply: &transport.Message{Value: []byte("OK")}}
+	client := NewBusClient(bus)
+
+	result, err := client.Charge(context.Background(), contracts.PaymentRequest{Id: 7, UserId: 15, Amount: 50})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Status != contracts.PaymentApproved {
+		t.Errorf("expected APPROVED, got %s", result.Status)
+	}
+	if string(bus.key) != "15" {
+		t.Errorf("expected message key to be the user id, got %q", bus.key)
+	}
+	if request, ok := bus.payload.(*contracts.PaymentRequest); !ok || request.Id != 7 {
+		t.Errorf("unexpected payload %+v", bus.payload)
+	}
+
+	bus.err = errors.New("broker is down")
+	_, err = client.Charge(context.Background(), contracts.PaymentRequest{Id: 8, UserId: 15})
+	if !errors.Is(err, bus.err) {
+		t.Errorf("expected bus error, got %v", err)
+	}
+}
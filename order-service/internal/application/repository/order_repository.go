@@ -19,4 +19,8 @@ type OrderRepository interface {
 	// GetUserOrders возвращает список всех заказов пользователя по его ID.
 	// В случае ошибки возвращает пустой срез и ошибку.
 	GetUserOrders(ctx context.Context, userId int) ([]domain.Order, error)
+
+	// GetByPendingTransactionId возвращает заказ, оплата которого ожидает решения
+	// по транзакции transactionId. Возвращает nil, nil если такого заказа нет.
+	GetByPendingTransactionId(ctx context.Context, transactionId int) (*domain.Order, error)
 }
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"contracts"
 	"fmt"
 	"math/rand"
 	"order-service/internal/application/repository"
@@ -81,9 +82,7 @@ func (os *OrderService) PayOrder(ctx context.Context, id int) error {
 	if order.IsPayed {
 		return fmt.Errorf("order is already payed")
 	}
-	order.IsPayed = true
-	paymentDate := time.Now()
-	order.PaymentDate = &paymentDate
+	order.Pay()
 	err = os.Save(ctx, order)
 	if err != nil {
 		return err
@@ -91,13 +90,40 @@ func (os *OrderService) PayOrder(ctx context.Context, id int) error {
 	return nil
 }
 
-// CreateTransaction создаёт транзакцию для оплаты заказа.
-// Генерирует случайный ID.
-func (os *OrderService) CreateTransaction(ctx context.Context, order *domain.Order) *domain.Transaction {
+// CompleteReviewedPayment завершает оплату заказа по решению payment-service о транзакции,
+// задержанной до ручной проверки: проведённая транзакция помечает заказ оплаченным,
+// отклонённая — освобождает заказ для новой попытки оплаты.
+// Решение по транзакции, которую не ожидает ни один заказ, пропускается:
+// заказ уже получил результат при повторной оплате или решение доставлено повторно.
+func (os *OrderService) CompleteReviewedPayment(ctx context.Context, decision contracts.PaymentReviewed) error {
+	order, err := os.orderRepository.GetByPendingTransactionId(ctx, decision.TransactionId)
+	if err != nil {
+		return fmt.Errorf("failed to get order by pending transaction %d: %w", decision.TransactionId, err)
+	}
+	if order == nil {
+		return nil
+	}
+	if decision.TransactionStatus == contracts.TransactionCompleted {
+		order.Pay()
+	} else {
+		order.ResetPayment()
+	}
+	return os.Save(ctx, order)
+}
+
+// CreateTransaction создаёт транзакцию для оплаты заказа с кошелька accountId.
+// Если accountId равен 0, оплата производится с основного кошелька пользователя.
+// Если оплата заказа ожидает ручной проверки, повторно использует ID задержанной транзакции,
+// иначе генерирует случайный ID.
+func (os *OrderService) CreateTransaction(ctx context.Context, order *domain.Order, accountId int) *domain.Transaction {
 	id := rand.Intn(2147483645)
+	if order.PendingTransactionId != nil {
+		id = *order.PendingTransactionId
+	}
 	return &domain.Transaction{
 		Id:        id,
 		UserId:    order.UserId,
+		AccountId: accountId,
 		IsDeposit: false,
 		Amount:    order.Amount,
 		Date:      time.Now(),
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"contracts"
 	"errors"
 	"order-service/internal/application/repository"
 	"order-service/internal/domain"
@@ -39,6 +40,15 @@ func (m *mockAccountRepository) GetUserOrders(ctx context.Context, userId int) (
 	return orders, nil
 }
 
+func (m *mockAccountRepository) GetByPendingTransactionId(ctx context.Context, transactionId int) (*domain.Order, error) {
+	for _, order := range m.data {
+		if order.PendingTransactionId != nil && *order.PendingTransactionId == transactionId {
+			return &order, nil
+		}
+	}
+	return nil, nil
+}
+
 func setupTestEnv(t *testing.T) (context.Context, repository.OrderRepository, *OrderService) {
 	t.Helper()
 	ctx := context.Background()
@@ -193,7 +203,7 @@ func TestCreateTransaction(t *testing.T) {
 		IsPayed: false,
 	}
 
-	tx := svc.CreateTransaction(ctx, order)
+	tx := svc.CreateTransaction(ctx, order, 7)
 
 	if tx.UserId != order.UserId {
 		t.Errorf("expected UserId %d, got %d", order.UserId, tx.UserId)
@@ -204,4 +214,61 @@ func TestCreateTransaction(t *testing.T) {
 	if tx.IsDeposit {
 		t.Errorf("expected IsDeposit = false for order transaction")
 	}
+	if tx.AccountId != 7 {
+		t.Errorf("expected AccountId 7, got %d", tx.AccountId)
+	}
+}
+
+func TestCreateTransaction_PendingReview(t *testing.T) {
+	ctx, _, svc := setupTestEnv(t)
+
+	order := &domain.Order{Id: 3, UserId: 42, Amount: 100}
+	order.AwaitPayment(77)
+
+	tx := svc.CreateTransaction(ctx, order, 0)
+	if tx.Id != 77 {
+		t.Errorf("expected pending transaction id 77 to be reused, got %d", tx.Id)
+	}
+
+	order.ResetPayment()
+	tx = svc.CreateTransaction(ctx, order, 0)
+	if tx.Id == 77 {
+		t.Errorf("expected new transaction id after reset")
+	}
+}
+
+func TestCompleteReviewedPayment(t *testing.T) {
+	ctx, db, svc := setupTestEnv(t)
+
+	approved := &domain.Order{Id: 1, UserId: 10, Amount: 100}
+	approved.AwaitPayment(77)
+	rejected := &domain.Order{Id: 2, UserId: 10, Amount: 200}
+	rejected.AwaitPayment(78)
+	_ = db.Save(ctx, approved)
+	_ = db.Save(ctx, rejected)
+
+	err := svc.CompleteReviewedPayment(ctx, contracts.PaymentReviewed{TransactionId: 77, TransactionStatus: contracts.TransactionCompleted})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	order, _ := db.GetById(ctx, 1)
+	if !order.IsPayed || order.PaymentDate == nil || order.PendingTransactionId != nil {
+		t.Errorf("expected order to be paid after approval, got %+v", order)
+	}
+
+	err = svc.CompleteReviewedPayment(ctx, contracts.PaymentReviewed{TransactionId: 78,
+		TransactionStatus: contracts.TransactionDeclined, FailureCode: contracts.ErrorRejectedByReview})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	order, _ = db.GetById(ctx, 2)
+	if order.IsPayed || order.PendingTransactionId != nil {
+		t.Errorf("expected order payment to be reset after rejection, got %+v", order)
+	}
+
+	// Повторно доставленное решение не находит ожидающего заказа и пропускается.
+	err = svc.CompleteReviewedPayment(ctx, contracts.PaymentReviewed{TransactionId: 77, TransactionStatus: contracts.TransactionCompleted})
+	if err != nil {
+		t.Errorf("unexpected error for a repeated decision: %v", err)
+	}
 }
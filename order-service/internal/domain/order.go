@@ -12,6 +12,9 @@ type Order struct {
 	IsPayed      bool       `json:"is_payed"`      // Статус оплаты: true, если заказ оплачен
 	CreationDate time.Time  `json:"creation_date"` // Дата создания заказа
 	PaymentDate  *time.Time `json:"payment_date"`  // Дата оплаты (nil, если заказ ещё не оплачен)
+	// ID транзакции оплаты, ожидающей ручной проверки в payment-service (nil, если такой нет).
+	// Повторная оплата заказа отправляет ту же транзакцию, чтобы получить решение по ней.
+	PendingTransactionId *int `json:"pending_transaction_id"`
 }
 
 // Pay помечает заказ как оплаченный и устанавливает текущую дату в PaymentDate.
@@ -19,4 +22,16 @@ func (o *Order) Pay() {
 	o.IsPayed = true
 	date := time.Now()
 	o.PaymentDate = &date
+	o.PendingTransactionId = nil
+}
+
+// AwaitPayment запоминает транзакцию оплаты, задержанную до ручной проверки.
+func (o *Order) AwaitPayment(transactionId int) {
+	o.PendingTransactionId = &transactionId
+}
+
+// ResetPayment забывает транзакцию оплаты после окончательного отказа,
+// чтобы следующая попытка оплаты создала новую транзакцию.
+func (o *Order) ResetPayment() {
+	o.PendingTransactionId = nil
 }
@@ -15,6 +15,7 @@ func TestOrder_Pay(t *testing.T) {
 		CreationDate: time.Now(),
 		PaymentDate:  nil,
 	}
+	order.AwaitPayment(5)
 	order.Pay()
 	if !order.IsPayed {
 		t.Errorf("Order is not payed")
@@ -22,5 +23,8 @@ func TestOrder_Pay(t *testing.T) {
 	if order.PaymentDate == nil {
 		t.Errorf("Payment date is nil")
 	}
+	if order.PendingTransactionId != nil {
+		t.Errorf("Pending transaction is not cleared")
+	}
 	return
 }
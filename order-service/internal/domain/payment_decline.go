@@ -0,0 +1,16 @@
+package domain
+
+// PaymentDeclineReason — код причины отказа в оплате, который payment-service
+// возвращает вместе с ответом на транзакцию.
+type PaymentDeclineReason string
+
+const (
+	DeclineInvalidAmount       PaymentDeclineReason = "INVALID_AMOUNT"        // Некорректная сумма платежа
+	DeclineInsufficientFunds   PaymentDeclineReason = "INSUFFICIENT_FUNDS"    // На счёте недостаточно средств
+	DeclineLimitExceeded       PaymentDeclineReason = "LIMIT_EXCEEDED"        // Превышено ограничение на списания
+	DeclineOperationNotAllowed PaymentDeclineReason = "OPERATION_NOT_ALLOWED" // Статус счёта запрещает списания
+	DeclineAccountNotFound     PaymentDeclineReason = "ACCOUNT_NOT_FOUND"     // Кошелёк не найден
+	DeclineForeignAccount      PaymentDeclineReason = "FOREIGN_ACCOUNT"       // Кошелёк принадлежит другому пользователю
+	DeclineRejectedByReview    PaymentDeclineReason = "REJECTED_BY_REVIEW"    // Платёж отклонён при ручной проверке
+	DeclinePendingReview       PaymentDeclineReason = "PENDING_REVIEW"        // Платёж задержан до ручной проверки
+)
@@ -6,6 +6,7 @@ import "time"
 type Transaction struct {
 	Id        int       `json:"id"`         // Уникальный идентификатор транзакции
 	UserId    int       `json:"user_id"`    // ID пользователя, к которому относится транзакция
+	AccountId int       `json:"account_id"` // ID кошелька для списания; 0 - основной кошелёк пользователя
 	IsDeposit bool      `json:"is_deposit"` // true - если это пополнение, false - если списание
 	Amount    float64   `json:"amount"`     // Сумма транзакции
 	Date      time.Time `json:"date"`       // Дата и время проведения транзакции
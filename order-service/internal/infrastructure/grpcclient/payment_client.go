@@ -0,0 +1,70 @@
+package grpcclient
+
+import (
+	"context"
+	"contracts"
+	pb "contracts/pb/contracts/v1"
+	"fmt"
+	"order-service/internal/application/payment"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultTimeout — срок ответа payment-service на запрос по умолчанию.
+const DefaultTimeout = 5 * time.Second
+
+// PaymentClient — payment.Client, вызывающий gRPC-сервис PaymentService payment-service.
+type PaymentClient struct {
+	client  pb.PaymentServiceClient
+	timeout time.Duration // Срок ответа на запрос
+}
+
+// Dial создаёт соединение с gRPC-сервером payment-service по адресу addr.
+// Соединение устанавливается при первом запросе.
+func Dial(addr string) (*grpc.ClientConn, error) {
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to payment service: %w", err)
+	}
+	return conn, nil
+}
+
+// NewPaymentClient создаёт новый экземпляр PaymentClient поверх соединения conn.
+// Если timeout не положителен, используется DefaultTimeout.
+func NewPaymentClient(conn grpc.ClientConnInterface, timeout time.Duration) *PaymentClient {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &PaymentClient{client: pb.NewPaymentServiceClient(conn), timeout: timeout}
+}
+
+// Charge вызывает PaymentService.Charge со сроком ответа timeout.
+// Коды ошибок gRPC преобразуются в ошибки payment.ErrTimeout, payment.ErrUnavailable
+// и payment.ErrInvalidRequest.
+func (c *PaymentClient) Charge(ctx context.Context, request contracts.PaymentRequest) (contracts.PaymentResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	result, err := c.client.Charge(ctx, contracts.PaymentRequestToProto(request))
+	if err != nil {
+		return contracts.PaymentResult{}, statusError(err)
+	}
+	return contracts.PaymentResultFromProto(result), nil
+}
+
+// statusError возвращает ошибку payment.Client по ошибке gRPC.
+func statusError(err error) error {
+	st := status.Convert(err)
+	switch st.Code() {
+	case codes.DeadlineExceeded:
+		return fmt.Errorf("%w: %s", payment.ErrTimeout, st.Message())
+	case codes.Unavailable:
+		return fmt.Errorf("%w: %s", payment.ErrUnavailable, st.Message())
+	case codes.InvalidArgument:
+		return fmt.Errorf("%w: %s", payment.ErrInvalidRequest, st.Message())
+	}
+	return fmt.Errorf("payment service error %s: %s", st.Code(), st.Message())
+}
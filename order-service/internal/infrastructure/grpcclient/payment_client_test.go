@@ -0,0 +1,101 @@
+package grpcclient
+
+import (
+	"context"
+	"contracts"
+	pb "contracts/pb/contracts/v1"
+	"errors"
+	"net"
+	"order-service/internal/application/payment"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type mockPaymentServer struct {
+	pb.UnimplementedPaymentServiceServer
+	delay  time.Duration
+	err    error
+	result *pb.PaymentResult
+}
+
+func (m *mockPaymentServer) Charge(ctx context.Context, request *pb.PaymentRequest) (*pb.PaymentResult, error) {
+	select {
+	case <-time.After(m.delay):
+	case <-ctx.Done():
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.result, nil
+}
+
+// newTestConn запускает server на соединении в памяти и возвращает подключение к нему.
+func newTestConn(t *testing.T, server pb.PaymentServiceServer) *grpc.ClientConn {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer()
+	pb.RegisterPaymentServiceServer(grpcServer, server)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("error connecting to server: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestPaymentClient_Charge(t *testing.T) {
+	server := &mockPaymentServer{result: &pb.PaymentResult{TransactionId: 7, Status: pb.PaymentStatus_PAYMENT_STATUS_DECLINED,
+		ErrorCode: "INSUFFICIENT_FUNDS", Message: "not enough balance for withdraw"}}
+	client := NewPaymentClient(newTestConn(t, server), time.Second)
+
+	result, err := client.Charge(context.Background(), contracts.PaymentRequest{Id: 7, UserId: 1, Amount: 10})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := contracts.PaymentResult{TransactionId: 7, Status: contracts.PaymentDeclined,
+		ErrorCode: contracts.ErrorInsufficientFunds, Message: "not enough balance for withdraw"}
+	if result != want {
+		t.Errorf("expected %+v, got %+v", want, result)
+	}
+}
+
+func TestPaymentClient_StatusErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		server *mockPaymentServer
+		want   error
+	}{
+		{"deadline", &mockPaymentServer{delay: time.Second}, payment.ErrTimeout},
+		{"unavailable", &mockPaymentServer{err: status.Error(codes.Unavailable, "shutting down")}, payment.ErrUnavailable},
+		{"invalid argument", &mockPaymentServer{err: status.Error(codes.InvalidArgument, "charge must be a withdrawal")}, payment.ErrInvalidRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewPaymentClient(newTestConn(t, tt.server), 50*time.Millisecond)
+			_, err := client.Charge(context.Background(), contracts.PaymentRequest{Id: 1})
+			if !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+
+	client := NewPaymentClient(newTestConn(t, &mockPaymentServer{err: status.Error(codes.Internal, "db is down")}), time.Second)
+	_, err := client.Charge(context.Background(), contracts.PaymentRequest{Id: 1})
+	if err == nil || errors.Is(err, payment.ErrUnavailable) || errors.Is(err, payment.ErrTimeout) {
+		t.Errorf("expected untyped error for internal failure, got %v", err)
+	}
+}
@@ -0,0 +1,50 @@
+package kafka
+
+import (
+	"context"
+	"contracts"
+	"errors"
+	"io"
+	"kafkacluster"
+	"log"
+	"order-service/internal/application/messaging"
+)
+
+// EventListener читает события другого сервиса из топика Kafka
+// и передаёт их обработчику.
+type EventListener struct {
+	consumer *Consumer              // Kafka consumer для чтения событий
+	handle   messaging.EventHandler // Обработчик событий
+}
+
+// NewEventListener создаёт новый EventListener, передающий события consumer обработчику handle.
+func NewEventListener(consumer *Consumer, handle messaging.EventHandler) *EventListener {
+	return &EventListener{consumer: consumer, handle: handle}
+}
+
+// Start читает события, пока не будет отменён контекст, и фиксирует смещение группы
+// после обработки каждого события. Событие, которое не удалось обработать,
+// записывается в лог и пропускается: заказ, ожидающий решения по оплате,
+// получит его при повторной оплате.
+func (l *EventListener) Start(ctx context.Context) {
+	for {
+		msg, err := l.consumer.FetchMessage(ctx)
+		if err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, context.Canceled) {
+				log.Printf("Event reader stopped gracefully")
+				return
+			}
+			log.Printf("Error reading event: %s\n", err)
+			continue
+		}
+
+		err = l.handle(ctx, kafkacluster.FromKafka(msg))
+		if err != nil {
+			log.Printf("Error handling %s event: %s\n", HeaderValue(msg, contracts.EventTypeHeader), err)
+		}
+		err = l.consumer.CommitMessage(ctx, msg)
+		if err != nil {
+			log.Printf("Error committing event: %s\n", err)
+		}
+	}
+}
@@ -2,72 +2,129 @@ package kafka
 
 import (
 	"context"
+	"contracts"
+	"contracts/signing"
+	"contracts/transport"
 	"errors"
 	"fmt"
 	"io"
+	"kafkacluster"
 	"log"
+	"order-service/internal/application/messaging"
 	"time"
 
 	"github.com/segmentio/kafka-go"
 )
 
+// DefaultReplyTimeout — время ожидания ответа на запрос по умолчанию.
+const DefaultReplyTimeout = 60 * time.Second
+
 // MessageBus — это высокоуровневая обёртка над Kafka Producer и Consumer,
 // обеспечивающая двустороннюю коммуникацию между сервисами.
 //
 // Основная идея — реализовать механизм корреляции сообщений,
 // чтобы можно было отправить запрос (через Producer) и получить
-// конкретный ответ (через Consumer) по тому же ключу (correlation key).
+// конкретный ответ (через Consumer) по тому же идентификатору корреляции.
+// Идентификатор генерируется на каждый запрос и передаётся в заголовке messaging.CorrelationIdHeader
+// и в конверте сообщения, а ключ сообщения используется только для распределения по партициям.
+//
+// Каждый экземпляр сервиса читает ответы из собственного топика и передаёт его
+// в заголовке запроса transport.ReplyToHeader, поэтому ответ получает именно тот экземпляр,
+// который ожидает его.
+// Реализует messaging.MessageBus.
 type MessageBus struct {
-	consumer       *Consumer              // Kafka consumer для чтения сообщений
-	producer       *Producer              // Kafka producer для отправки сообщений
-	correlationMap map[string]chan []byte // Карта ключей корреляции -> каналы для передачи ответов
+	consumer     *Consumer          // Kafka consumer для чтения ответов из топика replyTopic
+	producer     *Producer          // Kafka producer для отправки сообщений
+	replyTopic   string             // Топик ответов этого экземпляра
+	pending      *pendingRequests   // Реестр запросов, ожидающих ответа
+	replyTimeout time.Duration      // Время ожидания ответа на запрос
+	encoding     contracts.Encoding // Кодировка конверта запросов
+	signer       *signing.Signer    // Подпись запросов; nil — запросы не подписываются
 }
 
 // NewMessageBus создаёт новый экземпляр MessageBus с заданными Consumer и Producer.
-// correlationMap инициализируется пустой map для отслеживания ожидаемых ответов.
-func NewMessageBus(consumer *Consumer, producer *Producer) *MessageBus {
+// replyTopic — топик ответов этого экземпляра, из которого читает consumer.
+// Если replyTimeout не положителен, используется DefaultReplyTimeout;
+// если кодировка encoding не задана — contracts.EncodingJSON.
+// Если задан signer, каждый запрос подписывается вместе с его заголовками.
+func NewMessageBus(consumer *Consumer, producer *Producer, replyTopic string, replyTimeout time.Duration,
+	encoding contracts.Encoding, signer *signing.Signer) *MessageBus {
+	if replyTimeout <= 0 {
+		replyTimeout = DefaultReplyTimeout
+	}
+	if encoding == "" {
+		encoding = contracts.EncodingJSON
+	}
 	return &MessageBus{
-		consumer:       consumer,
-		producer:       producer,
-		correlationMap: make(map[string]chan []byte),
+		consumer:     consumer,
+		producer:     producer,
+		replyTopic:   replyTopic,
+		pending:      newPendingRequests(),
+		replyTimeout: replyTimeout,
+		encoding:     encoding,
+		signer:       signer,
 	}
 }
 
-// SendMessage отправляет сообщение в Kafka с заданным ключом и значением,
-// затем блокирующе ожидает ответа с тем же ключом через ReceiveMessage.
+// SendMessage отправляет в Kafka сообщение с заданным ключом и содержимым payload в конверте,
+// затем блокирующе ожидает ответа с тем же идентификатором корреляции через ReceiveMessage.
+// Ключ определяет партицию и может совпадать у одновременных запросов.
+// Запрос удаляется из реестра ожидающих при любом исходе.
 //
-// Возвращает тело ответа (msg.Value) либо ошибку, если:
-//   - не удалось отправить сообщение в Kafka,
-//   - не пришёл ответ в течение таймаута (60 секунд),
+// Возвращает ответ вместе с заголовками либо ошибку, если:
+//   - не удалось закодировать или отправить сообщение в Kafka,
+//   - не пришёл ответ в течение таймаута replyTimeout,
 //   - или контекст был отменён.
-func (mb *MessageBus) SendMessage(ctx context.Context, key []byte, value []byte) ([]byte, error) {
-	err := mb.producer.SendMessage(ctx, &kafka.Message{Key: key, Value: value})
+func (mb *MessageBus) SendMessage(ctx context.Context, key []byte, payload contracts.Payload) (*transport.Message, error) {
+	request, err := mb.newRequest(key, payload)
 	if err != nil {
-		return nil, fmt.Errorf("error sending message: %w", err)
+		return nil, err
 	}
+	correlationId := request.Header(messaging.CorrelationIdHeader)
+	mb.pending.add(correlationId)
+	answered := false
+	defer func() { mb.pending.remove(correlationId, answered) }()
 
-	strKey := string(key)
-	mb.correlationMap[strKey] = make(chan []byte)
+	err = mb.producer.SendMessage(ctx, kafkacluster.ToKafka(request))
+	if err != nil {
+		return nil, fmt.Errorf("error sending message: %w", err)
+	}
 
-	kafkaMsg, err := mb.ReceiveMessage(ctx, strKey)
+	kafkaMsg, err := mb.ReceiveMessage(ctx, correlationId)
 	if err != nil {
 		return nil, fmt.Errorf("error receiving message: %w", err)
 	}
-	return kafkaMsg.Value, nil
+	answered = true
+	return kafkacluster.FromKafka(kafkaMsg), nil
+}
+
+// newRequest возвращает запрос с ключом key и содержимым payload,
+// ответ на который должен прийти в топик ответов этого экземпляра.
+func (mb *MessageBus) newRequest(key []byte, payload contracts.Payload) (*transport.Message, error) {
+	request, err := messaging.NewRequest(key, payload, mb.encoding)
+	if err != nil {
+		return nil, err
+	}
+	if mb.replyTopic != "" {
+		request.SetHeader(transport.ReplyToHeader, mb.replyTopic)
+	}
+	// Подпись ставится последней: она покрывает все заголовки запроса.
+	if mb.signer != nil {
+		mb.signer.Sign(request)
+	}
+	return request, nil
 }
 
 // StartReading запускает бесконечный цикл чтения сообщений из Kafka.
-// Для каждого прочитанного сообщения проверяет наличие соответствующего
-// канала в correlationMap и пересылает значение сообщения туда.
-//
-// Если ключ не найден, выводит предупреждение в лог.
+// Каждое прочитанное сообщение передаётся ожидающему запросу через dispatch,
+// и только после этого фиксируется смещение группы.
 //
 // Цикл завершается только при закрытии контекста (ctx.Done()) или ошибке чтения.
 func (mb *MessageBus) StartReading(ctx context.Context) {
 	for {
-		msg, err := mb.consumer.ReadMessage(ctx)
+		msg, err := mb.consumer.FetchMessage(ctx)
 		if err != nil {
-			if err == io.EOF || errors.Is(err, context.Canceled) {
+			if errors.Is(err, io.EOF) || errors.Is(err, context.Canceled) {
 				log.Printf("Reader stopped gracefully")
 				return
 			}
@@ -75,40 +132,61 @@ func (mb *MessageBus) StartReading(ctx context.Context) {
 			continue
 		}
 
-		strKey := string(msg.Key)
-		ch, ok := mb.correlationMap[strKey]
-		if ok {
-			ch <- msg.Value
-		} else {
-			log.Printf("Key not found in correlation map: %s", strKey)
+		mb.dispatch(msg)
+		err = mb.consumer.CommitMessage(ctx, msg)
+		if err != nil {
+			log.Printf("Error committing message: %s\n", err)
 		}
 	}
 }
 
-// ReceiveMessage ожидает получение ответа по заданному ключу корреляции.
+// dispatch передаёт ответ запросу с идентификатором корреляции из заголовка сообщения.
+// Не блокируется: если заголовка нет, запрос уже не ожидает ответа или получил его,
+// выводит предупреждение в лог.
+func (mb *MessageBus) dispatch(msg *kafka.Message) {
+	correlationId := HeaderValue(msg, messaging.CorrelationIdHeader)
+	if !mb.pending.complete(correlationId, msg) {
+		log.Printf("No pending request for correlation id: %q", correlationId)
+	}
+}
+
+// ReceiveMessage ожидает получение ответа по заданному идентификатору корреляции.
 //
 // Поведение:
 //   - Если контекст завершён — возвращает ошибку контекста.
-//   - Если в течение 60 секунд не поступило сообщение — возвращает timeout-ошибку.
+//   - Если в течение replyTimeout не поступило сообщение — возвращает timeout-ошибку.
 //   - Если канал закрыт — возвращает ошибку io.EOF.
 //   - Иначе возвращает полученное сообщение.
 //
 // Используется внутри SendMessage для получения ответа на запрос.
 func (mb *MessageBus) ReceiveMessage(ctx context.Context, key string) (*kafka.Message, error) {
-	ch, ok := mb.correlationMap[key]
+	ch, ok := mb.pending.get(key)
 	if !ok {
-		return nil, fmt.Errorf("key not found in correlation map: %s", key)
+		return nil, fmt.Errorf("no pending request for correlation id: %s", key)
 	}
 
+	timer := time.NewTimer(mb.replyTimeout)
+	defer timer.Stop()
 	select {
 	case <-ctx.Done():
 		return nil, ctx.Err()
-	case <-time.After(60 * time.Second):
+	case <-timer.C:
 		return nil, fmt.Errorf("timeout waiting for message: %s", key)
 	case msg, ok := <-ch:
 		if !ok {
 			return nil, fmt.Errorf("message channel closed: %w", io.EOF)
 		}
-		return &kafka.Message{Value: msg}, nil
+		return msg, nil
+	}
+}
+
+// HeaderValue возвращает значение заголовка key сообщения
+// или пустую строку, если заголовка нет.
+func HeaderValue(message *kafka.Message, key string) string {
+	for _, header := range message.Headers {
+		if header.Key == key {
+			return string(header.Value)
+		}
 	}
+	return ""
 }
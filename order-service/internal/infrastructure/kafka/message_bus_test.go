@@ -0,0 +1,130 @@
+package kafka
+
+import (
+	"context"
+	"contracts"
+	"contracts/signing"
+	"contracts/transport"
+	"errors"
+	"kafkacluster"
+	"order-service/internal/application/messaging"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+func reply(correlationId, value string) *kafka.Message {
+	return &kafka.Message{
+		Key:     []byte("10"),
+		Value:   []byte(value),
+		Headers: []kafka.Header{{Key: messaging.CorrelationIdHeader, Value: []byte(correlationId)}},
+	}
+}
+
+func TestMessageBus_DispatchByCorrelationId(t *testing.T) {
+	ctx := context.Background()
+	mb := NewMessageBus(nil, nil, "", 0, "", nil)
+	first, _ := messaging.NewCorrelationId()
+	second, _ := messaging.NewCorrelationId()
+	if first == second {
+		t.Fatalf("expected unique correlation ids, got %s twice", first)
+	}
+	mb.pending.add(first)
+	mb.pending.add(second)
+
+	mb.dispatch(reply(second, "second"))
+	mb.dispatch(reply(first, "first"))
+	mb.dispatch(reply(first, "duplicate"))
+	mb.dispatch(reply("unknown", "lost"))
+
+	msg, err := mb.ReceiveMessage(ctx, first)
+	if err != nil || string(msg.Value) != "first" {
+		t.Errorf("expected reply 'first', got %v, %v", msg, err)
+	}
+	msg, err = mb.ReceiveMessage(ctx, second)
+	if err != nil || string(msg.Value) != "second" {
+		t.Errorf("expected reply 'second', got %v, %v", msg, err)
+	}
+
+	mb.pending.remove(first, true)
+	_, err = mb.ReceiveMessage(ctx, first)
+	if err == nil {
+		t.Errorf("expected error for removed correlation id")
+	}
+}
+
+func TestMessageBus_ReplyTimeout(t *testing.T) {
+	mb := NewMessageBus(nil, nil, "", 10*time.Millisecond, "", nil)
+	if mb.replyTimeout != 10*time.Millisecond {
+		t.Fatalf("expected reply timeout 10ms, got %s", mb.replyTimeout)
+	}
+	if NewMessageBus(nil, nil, "", 0, "", nil).replyTimeout != DefaultReplyTimeout {
+		t.Errorf("expected default reply timeout")
+	}
+
+	mb.pending.add("late")
+	_, err := mb.ReceiveMessage(context.Background(), "late")
+	if err == nil {
+		t.Fatal("expected timeout error")
+	}
+	abandoned := abandonedRequests.Value()
+	mb.pending.remove("late", false)
+	if mb.pending.len() != 0 || abandonedRequests.Value() != abandoned+1 {
+		t.Errorf("expected abandoned request to be removed, pending %d", mb.pending.len())
+	}
+
+	done := make(chan struct{})
+	go func() {
+		mb.dispatch(reply("late", "too late"))
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("dispatch blocked on abandoned request")
+	}
+}
+
+func TestMessageBus_SendMessageRequiresEncoding(t *testing.T) {
+	mb := NewMessageBus(nil, nil, "", 0, "text/plain", nil)
+	_, err := mb.SendMessage(context.Background(), []byte("10"), &contracts.PaymentRequest{Id: 1})
+	if !errors.Is(err, contracts.ErrUnsupportedEncoding) {
+		t.Errorf("expected ErrUnsupportedEncoding, got %v", err)
+	}
+	if mb.pending.len() != 0 {
+		t.Errorf("expected no pending requests, got %d", mb.pending.len())
+	}
+}
+
+func TestMessageBus_RequestsReplyToInstanceTopic(t *testing.T) {
+	mb := NewMessageBus(nil, nil, "response.order-1", 0, "", nil)
+	request, err := mb.newRequest([]byte("10"), &contracts.PaymentRequest{Id: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if replyTo := request.Header(transport.ReplyToHeader); replyTo != "response.order-1" {
+		t.Errorf("expected reply-to response.order-1, got %q", replyTo)
+	}
+	if request.Header(messaging.CorrelationIdHeader) == "" {
+		t.Errorf("expected correlation id header, got %+v", request.Headers)
+	}
+}
+
+func TestMessageBus_SignsRequests(t *testing.T) {
+	key := signing.Key{Id: "k1", Algorithm: signing.HMACSHA256, Material: []byte("0123456789abcdef0123456789abcdef")}
+	mb := NewMessageBus(nil, nil, "response.order-1", 0, "", signing.NewSigner(key))
+	request, err := mb.newRequest([]byte("10"), &contracts.PaymentRequest{Id: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if request.Header(signing.KeyIdHeader) != "k1" {
+		t.Errorf("expected request signed with k1, got %+v", request.Headers)
+	}
+	// Подпись должна пережить преобразование в сообщение Kafka и обратно.
+	received := kafkacluster.FromKafka(kafkacluster.ToKafka(request))
+	err = signing.NewVerifier([]signing.Key{key}, time.Minute).Verify(received)
+	if err != nil {
+		t.Errorf("expected valid signature covering reply-to, got %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package kafka
+
+import (
+	"expvar"
+	"sync"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Метрики ожидающих запросов, доступные через expvar (/debug/vars).
+var (
+	pendingRequestsGauge = expvar.NewInt("kafka_pending_requests")   // Запросы, ожидающие ответа
+	completedRequests    = expvar.NewInt("kafka_completed_requests") // Запросы, получившие ответ
+	abandonedRequests    = expvar.NewInt("kafka_abandoned_requests") // Запросы, не дождавшиеся ответа: таймаут, отмена или ошибка отправки
+	unmatchedReplies     = expvar.NewInt("kafka_unmatched_replies")  // Ответы без ожидающего запроса: опоздавшие, повторные или чужие
+)
+
+// pendingRequests — потокобезопасный реестр запросов, ожидающих ответа,
+// по идентификатору корреляции.
+//
+// Канал каждого запроса вмещает один ответ, поэтому доставка ответа не блокирует
+// чтение из Kafka, даже если запрос уже перестал ждать.
+type pendingRequests struct {
+	mu       sync.Mutex
+	requests map[string]chan *kafka.Message
+}
+
+// newPendingRequests создаёт пустой реестр ожидающих запросов.
+func newPendingRequests() *pendingRequests {
+	return &pendingRequests{requests: make(map[string]chan *kafka.Message)}
+}
+
+// add регистрирует запрос correlationId и возвращает канал для ответа.
+func (p *pendingRequests) add(correlationId string) <-chan *kafka.Message {
+	ch := make(chan *kafka.Message, 1)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.requests[correlationId] = ch
+	pendingRequestsGauge.Add(1)
+	return ch
+}
+
+// get возвращает канал ответа запроса correlationId, если запрос ещё ожидает.
+func (p *pendingRequests) get(correlationId string) (<-chan *kafka.Message, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ch, ok := p.requests[correlationId]
+	return ch, ok
+}
+
+// complete доставляет ответ запросу correlationId.
+// Возвращает false, если запрос не ожидает ответа или уже получил его.
+func (p *pendingRequests) complete(correlationId string, msg *kafka.Message) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ch, ok := p.requests[correlationId]
+	if !ok {
+		unmatchedReplies.Add(1)
+		return false
+	}
+	select {
+	case ch <- msg:
+		return true
+	default:
+		unmatchedReplies.Add(1)
+		return false
+	}
+}
+
+// remove удаляет запрос correlationId из реестра после ответа или отказа от ожидания.
+// answered определяет, учитывается запрос в метрике завершённых или брошенных.
+func (p *pendingRequests) remove(correlationId string, answered bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.requests[correlationId]; !ok {
+		return
+	}
+	delete(p.requests, correlationId)
+	pendingRequestsGauge.Add(-1)
+	if answered {
+		completedRequests.Add(1)
+	} else {
+		abandonedRequests.Add(1)
+	}
+}
+
+// len возвращает число ожидающих запросов.
+func (p *pendingRequests) len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.requests)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/segmentio/kafka-go"
+	"kafkacluster"
 )
 
 // Producer - обёртка над kafka.Writer, обеспечивающая отправку сообщений в топик.
@@ -11,12 +12,9 @@ type Producer struct {
 	writer *kafka.Writer
 }
 
-// NewProducer создаёт нового Producer с заданными брокерами и топиком.
-func NewProducer(brokers []string, topic string) *Producer {
-	return &Producer{writer: &kafka.Writer{
-		Addr:  kafka.TCP(brokers...),
-		Topic: topic,
-	}}
+// NewProducer создаёт нового Producer для кластера cluster с заданным топиком.
+func NewProducer(cluster kafkacluster.Cluster, topic string) *Producer {
+	return &Producer{writer: cluster.Writer(topic)}
 }
 
 // SendMessage отправляет сообщение в Kafka.
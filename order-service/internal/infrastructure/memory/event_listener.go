@@ -0,0 +1,20 @@
+package memory
+
+import (
+	"context"
+	"contracts"
+	"contracts/transport"
+	"log"
+	"order-service/internal/application/messaging"
+)
+
+// ListenEvents передаёт обработчику handle события топика topic брокера broker,
+// пока не будет отменён ctx. Событие, которое не удалось обработать, записывается в лог и пропускается.
+func ListenEvents(ctx context.Context, broker *transport.InMemory, topic string, handle messaging.EventHandler) {
+	broker.Subscribe(ctx, topic, func(ctx context.Context, message transport.Message) {
+		err := handle(ctx, &message)
+		if err != nil {
+			log.Printf("Error handling %s event: %s\n", message.Header(contracts.EventTypeHeader), err)
+		}
+	})
+}
@@ -0,0 +1,37 @@
+package memory
+
+import (
+	"context"
+	"contracts/transport"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestListenEvents(t *testing.T) {
+	broker := transport.NewInMemory()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	handled := make(chan string, 2)
+	go ListenEvents(ctx, broker, "events", func(ctx context.Context, message *transport.Message) error {
+		handled <- string(message.Value)
+		if string(message.Value) == "1" {
+			return errors.New("handler failed")
+		}
+		return nil
+	})
+	broker.Publish("events", transport.Message{Value: []byte("1")}, transport.Message{Value: []byte("2")})
+
+	// Событие, которое не удалось обработать, не останавливает чтение следующих.
+	for _, expected := range []string{"1", "2"} {
+		select {
+		case value := <-handled:
+			if value != expected {
+				t.Errorf("expected event %s, got %s", expected, value)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("event %s was not handled", expected)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"context"
+	"contracts"
+	"contracts/signing"
+	"contracts/transport"
+	"fmt"
+	"order-service/internal/application/messaging"
+	"time"
+)
+
+// DefaultReplyTimeout — время ожидания ответа на запрос по умолчанию.
+const DefaultReplyTimeout = 60 * time.Second
+
+// MessageBus отправляет запросы через брокер в памяти процесса и реализует messaging.MessageBus.
+// Используется для запуска сервисов в одном процессе без Kafka.
+type MessageBus struct {
+	broker       *transport.InMemory
+	topic        string             // Топик запросов
+	replyTimeout time.Duration      // Время ожидания ответа на запрос
+	encoding     contracts.Encoding // Кодировка конверта запросов
+	signer       *signing.Signer    // Подпись запросов; nil — запросы не подписываются
+}
+
+// NewMessageBus создаёт новый экземпляр MessageBus, отправляющий запросы в топик topic брокера broker.
+// Если replyTimeout не положителен, используется DefaultReplyTimeout;
+// если кодировка encoding не задана — contracts.EncodingJSON.
+// Если задан signer, каждый запрос подписывается.
+func NewMessageBus(broker *transport.InMemory, topic string, replyTimeout time.Duration, encoding contracts.Encoding,
+	signer *signing.Signer) *MessageBus {
+	if replyTimeout <= 0 {
+		replyTimeout = DefaultReplyTimeout
+	}
+	if encoding == "" {
+		encoding = contracts.EncodingJSON
+	}
+	return &MessageBus{broker: broker, topic: topic, replyTimeout: replyTimeout, encoding: encoding, signer: signer}
+}
+
+// SendMessage передаёт запрос с ключом key и содержимым payload обработчику топика
+// и возвращает его ответ. Возвращает ошибку, если ответ не получен за replyTimeout
+// или обработчик не смог ответить.
+func (mb *MessageBus) SendMessage(ctx context.Context, key []byte, payload contracts.Payload) (*transport.Message, error) {
+	request, err := messaging.NewRequest(key, payload, mb.encoding)
+	if err != nil {
+		return nil, err
+	}
+	if mb.signer != nil {
+		mb.signer.Sign(request)
+	}
+	ctx, cancel := context.WithTimeout(ctx, mb.replyTimeout)
+	defer cancel()
+	reply, err := mb.broker.Request(ctx, mb.topic, request)
+	if err != nil {
+		return nil, fmt.Errorf("error receiving message: %w", err)
+	}
+	return reply, nil
+}
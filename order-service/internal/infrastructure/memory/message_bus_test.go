@@ -0,0 +1,56 @@
+package memory
+
+import (
+	"context"
+	"contracts"
+	"contracts/transport"
+	"errors"
+	"order-service/internal/application/messaging"
+	"testing"
+	"time"
+)
+
+func TestMessageBus_SendMessage(t *testing.T) {
+	broker := transport.NewInMemory()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = broker.Serve(ctx, "request", func(ctx context.Context, message *transport.Message) (*transport.Message, error) {
+			var request contracts.PaymentRequest
+			envelope, err := contracts.Unmarshal(contracts.EncodingJSON, message.Value, &request)
+			if err != nil {
+				return nil, err
+			}
+			result := contracts.PaymentResult{TransactionId: request.Id, Status: contracts.PaymentApproved}
+			value, err := contracts.Marshal(contracts.EncodingJSON, envelope.CorrelationId, &result)
+			if err != nil {
+				return nil, err
+			}
+			return &transport.Message{Key: message.Key, Value: value, Headers: map[string]string{
+				messaging.CorrelationIdHeader: message.Header(messaging.CorrelationIdHeader),
+				contracts.ContentTypeHeader:   string(contracts.EncodingJSON),
+			}}, nil
+		})
+	}()
+
+	mb := NewMessageBus(broker, "request", time.Second, "", nil)
+	reply, err := mb.SendMessage(ctx, []byte("10"), &contracts.PaymentRequest{Id: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result, err := messaging.DecodePaymentResult(reply)
+	if err != nil || result.Status != contracts.PaymentApproved || result.TransactionId != 3 {
+		t.Errorf("expected approved transaction 3, got %+v, %v", result, err)
+	}
+}
+
+func TestMessageBus_ReplyTimeout(t *testing.T) {
+	mb := NewMessageBus(transport.NewInMemory(), "request", 10*time.Millisecond, "", nil)
+	_, err := mb.SendMessage(context.Background(), []byte("10"), &contracts.PaymentRequest{Id: 3})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected timeout without handler, got %v", err)
+	}
+	if NewMessageBus(nil, "request", 0, "", nil).replyTimeout != DefaultReplyTimeout {
+		t.Errorf("expected default reply timeout")
+	}
+}
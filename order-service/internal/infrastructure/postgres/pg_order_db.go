@@ -25,14 +25,14 @@ func NewPgOrderDb(pool PgxPool) (*PgOrderDb, error) {
 // При других ошибках возвращает ошибку выполнения SQL-запроса.
 func (p *PgOrderDb) GetById(ctx context.Context, id int) (*domain.Order, error) {
 	sql := `
-		SELECT id, user_id, item_id, amount, is_payed, creation_date, payment_date 
+		SELECT id, user_id, item_id, amount, is_payed, creation_date, payment_date, pending_transaction_id 
 		FROM orders 
 		WHERE id = $1`
 	row := p.db.QueryRow(ctx, sql, &id)
 
 	var order domain.Order
 	err := row.Scan(&order.Id, &order.UserId, &order.ItemId, &order.Amount,
-		&order.IsPayed, &order.CreationDate, &order.PaymentDate)
+		&order.IsPayed, &order.CreationDate, &order.PaymentDate, &order.PendingTransactionId)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, fmt.Errorf("order not found: %w", err)
@@ -43,30 +43,52 @@ func (p *PgOrderDb) GetById(ctx context.Context, id int) (*domain.Order, error)
 }
 
 // Save сохраняет заказ в базу данных.
-// Если заказ с таким ID уже существует — обновляет флаги оплаты и дату платежа.
+// Если заказ с таким ID уже существует — обновляет флаги оплаты, дату платежа и ожидающую проверки транзакцию.
 // При ошибках выполнения SQL-запроса возвращает подробную ошибку.
 func (p *PgOrderDb) Save(ctx context.Context, order *domain.Order) error {
 	sql := `
-		INSERT INTO orders(id, user_id, item_id, amount, is_payed, creation_date, payment_date)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO orders(id, user_id, item_id, amount, is_payed, creation_date, payment_date, pending_transaction_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		ON CONFLICT (id) DO UPDATE 
 		SET is_payed = EXCLUDED.is_payed,
-		    payment_date = EXCLUDED.payment_date;`
+		    payment_date = EXCLUDED.payment_date,
+		    pending_transaction_id = EXCLUDED.pending_transaction_id;`
 
 	_, err := p.db.Exec(ctx, sql,
 		&order.Id, &order.UserId, &order.ItemId,
-		&order.Amount, &order.IsPayed, &order.CreationDate, order.PaymentDate)
+		&order.Amount, &order.IsPayed, &order.CreationDate, order.PaymentDate, order.PendingTransactionId)
 	if err != nil {
 		return fmt.Errorf("error inserting order: %w", err)
 	}
 	return nil
 }
 
+// GetByPendingTransactionId возвращает заказ, оплата которого ожидает решения по транзакции transactionId.
+// Если такого заказа нет — возвращает nil, nil.
+func (p *PgOrderDb) GetByPendingTransactionId(ctx context.Context, transactionId int) (*domain.Order, error) {
+	sql := `
+		SELECT id, user_id, item_id, amount, is_payed, creation_date, payment_date, pending_transaction_id 
+		FROM orders 
+		WHERE pending_transaction_id = $1`
+	row := p.db.QueryRow(ctx, sql, &transactionId)
+
+	var order domain.Order
+	err := row.Scan(&order.Id, &order.UserId, &order.ItemId, &order.Amount,
+		&order.IsPayed, &order.CreationDate, &order.PaymentDate, &order.PendingTransactionId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting order: %w", err)
+	}
+	return &order, nil
+}
+
 // GetUserOrders возвращает все заказы, принадлежащие пользователю с указанным ID.
 // Если при запросе или чтении данных возникает ошибка — возвращает её.
 func (p *PgOrderDb) GetUserOrders(ctx context.Context, userId int) ([]domain.Order, error) {
 	sql := `
-		SELECT id, user_id, item_id, amount, is_payed, creation_date, payment_date 
+		SELECT id, user_id, item_id, amount, is_payed, creation_date, payment_date, pending_transaction_id 
 		FROM orders 
 		WHERE user_id = $1`
 
@@ -80,7 +102,7 @@ func (p *PgOrderDb) GetUserOrders(ctx context.Context, userId int) ([]domain.Ord
 	for rows.Next() {
 		var order domain.Order
 		err := rows.Scan(&order.Id, &order.UserId, &order.ItemId,
-			&order.Amount, &order.IsPayed, &order.CreationDate, &order.PaymentDate)
+			&order.Amount, &order.IsPayed, &order.CreationDate, &order.PaymentDate, &order.PendingTransactionId)
 		if err != nil {
 			continue
 		}
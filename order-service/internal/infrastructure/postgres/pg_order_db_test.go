@@ -37,9 +37,9 @@ func TestPgOrderDb_GetById_Success(t *testing.T) {
 	}
 
 	rows := pgxmock.NewRows([]string{
-		"id", "user_id", "item_id", "amount", "is_payed", "creation_date", "payment_date",
+		"id", "user_id", "item_id", "amount", "is_payed", "creation_date", "payment_date", "pending_transaction_id",
 	}).AddRow(order.Id, order.UserId, order.ItemId, order.Amount,
-		order.IsPayed, order.CreationDate, order.PaymentDate)
+		order.IsPayed, order.CreationDate, order.PaymentDate, order.PendingTransactionId)
 
 	mock.ExpectQuery("SELECT id, user_id, item_id").
 		WithArgs(&order.Id).
@@ -70,6 +70,37 @@ func TestPgOrderDb_GetById_NotFound(t *testing.T) {
 	require.Nil(t, res)
 }
 
+func TestPgOrderDb_GetByPendingTransactionId(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	transactionId := 77
+	rows := pgxmock.NewRows([]string{
+		"id", "user_id", "item_id", "amount", "is_payed", "creation_date", "payment_date", "pending_transaction_id",
+	}).AddRow(1, 2, 3, 100.0, false, time.Now(), (*time.Time)(nil), &transactionId)
+
+	mock.ExpectQuery("FROM orders WHERE pending_transaction_id = \\$1").
+		WithArgs(&transactionId).
+		WillReturnRows(rows)
+	missing := 78
+	mock.ExpectQuery("FROM orders WHERE pending_transaction_id = \\$1").
+		WithArgs(&missing).
+		WillReturnError(pgx.ErrNoRows)
+
+	db, _ := NewPgOrderDb(mock)
+	result, err := db.GetByPendingTransactionId(context.Background(), transactionId)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	require.Equal(t, 1, result.Id)
+	require.Equal(t, transactionId, *result.PendingTransactionId)
+
+	result, err = db.GetByPendingTransactionId(context.Background(), missing)
+	require.NoError(t, err)
+	require.Nil(t, result)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestPgOrderDb_Save_Success(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	require.NoError(t, err)
@@ -86,7 +117,7 @@ func TestPgOrderDb_Save_Success(t *testing.T) {
 
 	mock.ExpectExec("INSERT INTO orders").
 		WithArgs(&order.Id, &order.UserId, &order.ItemId,
-			&order.Amount, &order.IsPayed, &order.CreationDate, order.PaymentDate).
+			&order.Amount, &order.IsPayed, &order.CreationDate, order.PaymentDate, order.PendingTransactionId).
 		WillReturnResult(pgxmock.NewResult("INSERT", 1))
 
 	db, _ := NewPgOrderDb(mock)
@@ -102,7 +133,7 @@ func TestPgOrderDb_Save_Error(t *testing.T) {
 	order := domain.Order{Id: 1}
 	mock.ExpectExec("INSERT INTO orders").
 		WithArgs(&order.Id, &order.UserId, &order.ItemId,
-			&order.Amount, &order.IsPayed, &order.CreationDate, order.PaymentDate).
+			&order.Amount, &order.IsPayed, &order.CreationDate, order.PaymentDate, order.PendingTransactionId).
 		WillReturnError(errors.New("insert failed"))
 
 	db, _ := NewPgOrderDb(mock)
@@ -120,9 +151,9 @@ func TestPgOrderDb_GetUserOrders_Success(t *testing.T) {
 	order2 := domain.Order{Id: 2, UserId: userId, ItemId: 3, Amount: 50, IsPayed: false, CreationDate: time.Now(), PaymentDate: nil}
 
 	rows := pgxmock.NewRows([]string{
-		"id", "user_id", "item_id", "amount", "is_payed", "creation_date", "payment_date",
-	}).AddRow(order1.Id, order1.UserId, order1.ItemId, order1.Amount, order1.IsPayed, order1.CreationDate, order1.PaymentDate).
-		AddRow(order2.Id, order2.UserId, order2.ItemId, order2.Amount, order2.IsPayed, order2.CreationDate, order2.PaymentDate)
+		"id", "user_id", "item_id", "amount", "is_payed", "creation_date", "payment_date", "pending_transaction_id",
+	}).AddRow(order1.Id, order1.UserId, order1.ItemId, order1.Amount, order1.IsPayed, order1.CreationDate, order1.PaymentDate, order1.PendingTransactionId).
+		AddRow(order2.Id, order2.UserId, order2.ItemId, order2.Amount, order2.IsPayed, order2.CreationDate, order2.PaymentDate, order2.PendingTransactionId)
 
 	mock.ExpectQuery("SELECT id, user_id, item_id, amount, is_payed, creation_date, payment_date").
 		WithArgs(&userId).
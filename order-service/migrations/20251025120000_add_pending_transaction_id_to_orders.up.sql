@@ -0,0 +1 @@
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS pending_transaction_id INTEGER;
\ No newline at end of file
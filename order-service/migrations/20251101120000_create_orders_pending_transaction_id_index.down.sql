@@ -0,0 +1 @@
+DROP INDEX IF EXISTS orders_pending_transaction_id_idx;
\ No newline at end of file
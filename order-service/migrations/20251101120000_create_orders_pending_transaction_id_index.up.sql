@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS orders_pending_transaction_id_idx ON orders (pending_transaction_id) WHERE pending_transaction_id IS NOT NULL;
\ No newline at end of file
@@ -0,0 +1,281 @@
+// Package app собирает payment-service из компонентов: репозиториев, сервисов,
+// HTTP и gRPC API и шины сообщений. Используется командой cmd и сквозными тестами,
+// которые запускают сервисы в одном процессе с брокером в памяти.
+package app
+
+import (
+	"context"
+	pb "contracts/pb/contracts/v1"
+	"contracts/signing"
+	"contracts/transport"
+	"errors"
+	"expvar"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/swaggo/http-swagger"
+	"google.golang.org/grpc"
+	"io"
+	"kafkacluster"
+	"log"
+	"net/http"
+	"payment-service/internal/adapters/grpchandler"
+	"payment-service/internal/adapters/httphandler"
+	"payment-service/internal/adapters/kafkahandler"
+	"payment-service/internal/application/events"
+	"payment-service/internal/application/messaging"
+	"payment-service/internal/application/repository"
+	"payment-service/internal/application/service"
+	"payment-service/internal/config"
+	"payment-service/internal/infrastructure/fakeprovider"
+	"payment-service/internal/infrastructure/kafka"
+	"payment-service/internal/infrastructure/memory"
+	"payment-service/internal/infrastructure/postgres"
+)
+
+// Config — конфигурация payment-service.
+type Config = config.Config
+
+// LoadConfig загружает конфигурацию из переменных окружения.
+func LoadConfig() (*Config, error) {
+	return config.LoadConfig()
+}
+
+// App — собранный payment-service.
+type App struct {
+	Handler               http.Handler                   // HTTP API сервиса
+	GrpcServer            *grpc.Server                   // gRPC API сервиса (PaymentService)
+	EventRelay            *service.EventRelay            // Публикация доменных событий счетов
+	BalanceService        *service.BalanceService        // Балансы и их снимки
+	StatementService      *service.StatementService      // Выписки по счетам
+	ReconciliationService *service.ReconciliationService // Сверка балансов
+	messageBus            messaging.MessageBus
+	paymentHandler        transport.Handler
+	transactor            repository.Transactor
+	closers               []io.Closer // Ресурсы, освобождаемые в Close
+}
+
+// New собирает payment-service поверх пула соединений db.
+// Транспорт сообщений выбирается по cfg.MessageBus; для транспорта memory
+// запросы и события передаются через брокер broker.
+func New(ctx context.Context, cfg *Config, db *pgxpool.Pool, broker *transport.InMemory) (*App, error) {
+	accountRepo, err := postgres.NewAccountDb(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to account database: %w", err)
+	}
+	transactionRepo, err := postgres.NewTransactionDb(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to transaciton database: %w", err)
+	}
+	statusRepo, err := postgres.NewAccountStatusDb(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to account status database: %w", err)
+	}
+	limitsRepo, err := postgres.NewSpendingLimitsDb(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to spending limits database: %w", err)
+	}
+	reviewRepo, err := postgres.NewTransactionReviewDb(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to transaction review database: %w", err)
+	}
+	topUpRepo, err := postgres.NewTopUpDb(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to top-up database: %w", err)
+	}
+	snapshotRepo, err := postgres.NewBalanceSnapshotDb(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to balance snapshot database: %w", err)
+	}
+	eventRepo, err := postgres.NewAccountEventDb(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to account event database: %w", err)
+	}
+	inboxRepo, err := postgres.NewInboxDb(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to inbox database: %w", err)
+	}
+	transactor, err := postgres.NewTransactor(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize transactor: %w", err)
+	}
+	accountService := service.NewAccountService(accountRepo, transactionRepo, statusRepo, limitsRepo, eventRepo, transactor)
+	paymentService, err := service.NewPaymentService(accountRepo, transactionRepo, limitsRepo, reviewRepo, eventRepo, snapshotRepo, transactor, cfg.RiskRules)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize payment service: %w", err)
+	}
+	paymentProvider := fakeprovider.New(cfg.ProviderSecret, cfg.PublicURL+"/payments/callback", cfg.PublicURL)
+	topUpService, err := service.NewTopUpService(topUpRepo, accountRepo, transactionRepo, eventRepo, transactor, paymentProvider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize top-up service: %w", err)
+	}
+	inboxService, err := service.NewInboxService(inboxRepo, transactor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize inbox service: %w", err)
+	}
+
+	var verifier *signing.Verifier
+	if len(cfg.VerificationKeys) > 0 {
+		verifier = signing.NewVerifier(cfg.VerificationKeys, cfg.SignatureMaxAge)
+	} else {
+		log.Printf("WARNING: REQUEST_VERIFICATION_KEYS is not set, payment request signatures are not verified")
+	}
+	a := &App{
+		BalanceService:        service.NewBalanceService(accountRepo, transactionRepo, snapshotRepo),
+		ReconciliationService: service.NewReconciliationService(accountRepo, transactionRepo, transactor),
+		paymentHandler:        kafkahandler.NewPaymentHandler(paymentService, inboxService, verifier),
+		transactor:            transactor,
+	}
+	a.StatementService = service.NewStatementService(accountRepo, transactionRepo, a.BalanceService)
+	var eventPublisher events.Publisher
+	var topicChecker kafkacluster.TopicChecker
+	switch cfg.MessageBus {
+	case "memory":
+		if broker == nil {
+			return nil, errors.New("in-memory message bus requires a broker")
+		}
+		a.messageBus = memory.NewMessageBus(broker, cfg.KafkaConsumerTopic)
+		eventPublisher = memory.NewEventPublisher(broker, cfg.KafkaEventsTopic)
+	default:
+		cluster, err := KafkaCluster(cfg)
+		if err != nil {
+			return nil, err
+		}
+		topics := kafkacluster.NewTopics(cluster, kafkaTopicSpecs(cfg))
+		startupCtx, cancel := context.WithTimeout(ctx, kafkacluster.DefaultStartupTimeout)
+		err = topics.Ensure(startupCtx, cfg.KafkaCreateTopics)
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("kafka topics are not ready: %w", err)
+		}
+		// Возраст подписи запроса отсчитывается от метки времени брокера, только если
+		// топик запросов подтверждённо использует LogAppendTime.
+		brokerTimestamps, err := topics.LogAppendTime(startupCtx, cfg.KafkaConsumerTopic)
+		cancel()
+		switch {
+		case err != nil:
+			log.Printf("WARNING: signature age is measured from the receive time: %v", err)
+		case !brokerTimestamps:
+			log.Printf("WARNING: topic %s does not use LogAppendTime, signature age is measured from the receive time", cfg.KafkaConsumerTopic)
+		}
+		topicChecker = topics
+		retryPolicy := kafka.RetryPolicy{
+			MaxAttempts:    cfg.KafkaMaxAttempts,
+			InitialBackoff: cfg.KafkaRetryBackoff,
+			MaxBackoff:     cfg.KafkaMaxBackoff,
+			Retryable: func(err error) bool {
+				return !errors.Is(err, kafkahandler.ErrInvalidMessage)
+			},
+		}
+		a.messageBus = kafka.NewMessageBus(cluster, cfg.KafkaConsumerTopic, cfg.KafkaProducerTopic,
+			cfg.KafkaDeadLetters, cfg.KafkaGroupID, retryPolicy, cfg.KafkaWorkers, cfg.KafkaWorkerQueue, brokerTimestamps)
+		kafkaPublisher := kafka.NewEventPublisher(cluster, cfg.KafkaEventsTopic)
+		a.closers = append(a.closers, kafkaPublisher)
+		eventPublisher = kafkaPublisher
+	}
+	a.EventRelay, err = service.NewEventRelay(eventRepo, transactor, eventPublisher)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize event relay: %w", err)
+	}
+
+	httpHandler := httphandler.NewAccountHandler(ctx, accountService)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /accounts/{id}", httpHandler.GetAccount)
+	mux.HandleFunc("POST /accounts", httpHandler.CreateAccount)
+	mux.HandleFunc("PUT /accounts/{id}/default", httpHandler.SetDefaultAccount)
+	mux.HandleFunc("GET /users/{id}/account", httpHandler.GetUsersAccount)
+	mux.HandleFunc("PUT /admin/accounts/{id}/credit-limit", httpHandler.SetCreditLimit)
+	mux.HandleFunc("PUT /admin/accounts/{id}/status", httpHandler.ChangeAccountStatus)
+	mux.HandleFunc("GET /admin/accounts/{id}/status-history", httpHandler.GetStatusHistory)
+	mux.HandleFunc("GET /admin/accounts/{id}/limits", httpHandler.GetSpendingLimits)
+	mux.HandleFunc("PUT /admin/accounts/{id}/limits", httpHandler.SetSpendingLimits)
+	mux.HandleFunc("DELETE /admin/accounts/{id}/limits", httpHandler.RemoveSpendingLimits)
+	reviewHandler := httphandler.NewReviewHandler(ctx, paymentService)
+	mux.HandleFunc("GET /admin/reviews", reviewHandler.GetPendingReviews)
+	mux.HandleFunc("POST /admin/reviews/{id}/approve", reviewHandler.ApproveReview)
+	mux.HandleFunc("POST /admin/reviews/{id}/reject", reviewHandler.RejectReview)
+	transactionHandler := httphandler.NewTransactionHandler(ctx, paymentService)
+	mux.HandleFunc("GET /admin/transactions/{id}", transactionHandler.GetTransaction)
+	mux.HandleFunc("POST /admin/transactions/{id}/reversals", transactionHandler.ReverseTransaction)
+	topUpHandler := httphandler.NewTopUpHandler(ctx, topUpService)
+	mux.HandleFunc("PATCH /accounts/{id}", topUpHandler.CreateTopUp)
+	mux.HandleFunc("GET /accounts/{id}/top-ups", topUpHandler.GetAccountTopUps)
+	mux.HandleFunc("GET /admin/top-ups", topUpHandler.GetTopUps)
+	mux.HandleFunc("POST /payments/callback", topUpHandler.ProviderCallback)
+	if cfg.PaymentProvider == "fake" && cfg.FakeProviderPages {
+		// Страница подтверждения оплаты позволяет зачислить пополнение без провайдера — только для локального запуска.
+		log.Printf("WARNING: FAKE_PROVIDER_PAGES is enabled, top-ups can be confirmed without a payment provider")
+		mux.HandleFunc("POST /fake-provider/payments/{reference}", paymentProvider.ConfirmPayment)
+	}
+	balanceHandler := httphandler.NewBalanceHandler(ctx, a.BalanceService)
+	mux.HandleFunc("GET /accounts/{id}/balance", balanceHandler.GetBalance)
+	mux.HandleFunc("GET /admin/balances", balanceHandler.GetBalances)
+	statementHandler := httphandler.NewStatementHandler(ctx, a.StatementService)
+	mux.HandleFunc("GET /accounts/{id}/statements/{month}", statementHandler.GetStatement)
+	readinessHandler := kafkacluster.NewReadinessHandler(ctx, topicChecker)
+	mux.HandleFunc("GET /ready", readinessHandler.Ready)
+	mux.Handle("/swagger/payment/", httpSwagger.WrapHandler)
+	mux.Handle("GET /debug/vars", expvar.Handler())
+	a.Handler = mux
+
+	a.GrpcServer = grpc.NewServer()
+	pb.RegisterPaymentServiceServer(a.GrpcServer, grpchandler.NewPaymentServer(paymentService, accountService))
+	return a, nil
+}
+
+// KafkaCluster возвращает параметры подключения к Kafka: адреса брокеров, TLS и SASL.
+// Возвращает ошибку, если сертификаты не удалось загрузить.
+func KafkaCluster(cfg *Config) (kafkacluster.Cluster, error) {
+	cluster, err := kafkacluster.NewCluster(cfg.KafkaBrokers, cfg.KafkaSecurity)
+	if err != nil {
+		return kafkacluster.Cluster{}, fmt.Errorf("invalid kafka security settings: %w", err)
+	}
+	return cluster, nil
+}
+
+// kafkaTopicSpecs возвращает топики, которые payment-service читает и в которые пишет.
+func kafkaTopicSpecs(cfg *Config) []kafkacluster.TopicSpec {
+	names := []string{cfg.KafkaConsumerTopic, cfg.KafkaProducerTopic, cfg.KafkaDeadLetters, cfg.KafkaEventsTopic}
+	specs := make([]kafkacluster.TopicSpec, 0, len(names))
+	for _, name := range names {
+		specs = append(specs, kafkacluster.TopicSpec{Name: name, Partitions: cfg.KafkaPartitions, ReplicationFactor: cfg.KafkaReplication})
+	}
+	// Метке времени запроса, которую ставит отправитель, нельзя доверять при проверке возраста подписи.
+	specs[0].LogAppendTime = true
+	return specs
+}
+
+// Start обрабатывает запросы на оплату из шины сообщений, пока не будет отменён контекст,
+// затем дожидается обработки уже полученных запросов и возвращается.
+func (a *App) Start(ctx context.Context) {
+	a.messageBus.Start(ctx, a.paymentHandler)
+}
+
+// errDryRun откатывает транзакцию пробной обработки запроса в DryRun.
+var errDryRun = errors.New("dry run")
+
+// DryRun обрабатывает запрос на оплату message так же, как при получении из шины сообщений,
+// и возвращает ответ на него, но откатывает все изменения: баланс, журнал обработанных
+// сообщений и события счетов остаются прежними. Ошибка обработки возвращается вместе с ответом.
+func (a *App) DryRun(ctx context.Context, message *transport.Message) (*transport.Message, error) {
+	var reply *transport.Message
+	var handlerErr error
+	err := a.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		reply, handlerErr = a.paymentHandler(ctx, message)
+		return errDryRun
+	})
+	if !errors.Is(err, errDryRun) {
+		return nil, err
+	}
+	return reply, handlerErr
+}
+
+// Close освобождает ресурсы, не связанные с обработкой запросов: шина сообщений
+// закрывается сама по завершении Start.
+func (a *App) Close() {
+	for _, closer := range a.closers {
+		err := closer.Close()
+		if err != nil {
+			log.Printf("failed to close resource: %v", err)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"contracts/signing"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"payment-service/app"
+	"payment-service/internal/config"
+	"payment-service/internal/infrastructure/kafka"
+	"time"
+)
+
+// runDeadLetters выполняет подкоманду dead-letters.
+//
+// dead-letters list выводит недоставленные сообщения с причинами сбоя в формате JSON.
+// dead-letters replay переотправляет ещё не переотправленные сообщения в топик запросов,
+// чтобы payment-service обработал их заново. С флагом -resign сообщения с устаревшей подписью
+// подписываются заново ключом оператора REPLAY_SIGNING_KEY, а сообщения без действительной
+// подписи пропускаются (kafka.ReplayDeadLetters).
+func runDeadLetters(args []string) error {
+	if len(args) == 0 || (args[0] != "list" && args[0] != "replay") {
+		return fmt.Errorf("usage: dead-letters list|replay [-limit N] [-resign]")
+	}
+	flags := flag.NewFlagSet("dead-letters "+args[0], flag.ContinueOnError)
+	limit := flags.Int("limit", 100, "максимальное число сообщений")
+	idle := flags.Duration("idle", 5*time.Second, "replay: завершить, если новых сообщений нет дольше этого времени")
+	resign := flags.Bool("resign", false, "replay: подписать заново сообщения с устаревшей подписью ключом REPLAY_SIGNING_KEY")
+	err := flags.Parse(args[1:])
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return err
+	}
+	cluster, err := app.KafkaCluster(cfg)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	if args[0] == "replay" {
+		var resigner *signing.Resigner
+		if *resign {
+			replaySigning, err := config.LoadReplaySigning()
+			if err != nil {
+				return err
+			}
+			resigner = signing.NewResigner(signing.NewSigner(replaySigning.SigningKey),
+				signing.NewVerifier(replaySigning.VerificationKeys, replaySigning.SignatureMaxAge))
+		}
+		replayed, err := kafka.ReplayDeadLetters(ctx, cluster, cfg.KafkaDeadLetters, cfg.KafkaConsumerTopic,
+			cfg.KafkaGroupID+"-dead-letters", resigner, *limit, *idle)
+		log.Printf("dead letters: %d replayed to %s", replayed, cfg.KafkaConsumerTopic)
+		return err
+	}
+
+	deadLetters, err := kafka.ReadDeadLetters(ctx, cluster, cfg.KafkaDeadLetters, *limit)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(deadLetters)
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"log"
+	"payment-service/internal/application/service"
+	"time"
+)
+
+const (
+	eventPublishInterval = time.Second // Период опроса журнала исходящих событий
+	eventPublishBatch    = 100         // Максимальное число событий в одной публикации
+)
+
+// scheduleEventPublishing периодически публикует накопленные события счетов.
+// Пока журнал не опустошён, следующий пакет публикуется без ожидания.
+func scheduleEventPublishing(ctx context.Context, relay *service.EventRelay) {
+	ticker := time.NewTicker(eventPublishInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for {
+				published, err := relay.PublishPending(ctx, eventPublishBatch)
+				if err != nil {
+					log.Printf("account events publishing failed: %v", err)
+					break
+				}
+				if published < eventPublishBatch {
+					break
+				}
+			}
+		}
+	}
+}
@@ -2,25 +2,41 @@ package main
 
 import (
 	"context"
+	"contracts/transport"
+	"errors"
 	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/swaggo/http-swagger"
 	"log"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"payment-service/app"
 	_ "payment-service/docs"
-	"payment-service/internal/adapters/httphandler"
-	"payment-service/internal/adapters/kafkahandler"
-	"payment-service/internal/application/service"
-	"payment-service/internal/config"
-	"payment-service/internal/infrastructure/kafka"
-	"payment-service/internal/infrastructure/postgres"
+	"syscall"
 )
 
 func main() {
-	cfg, err := config.LoadConfig()
+	if len(os.Args) > 1 && os.Args[1] == "reconcile" {
+		err := runReconcile(os.Args[2:])
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "dead-letters" {
+		err := runDeadLetters(os.Args[2:])
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	cfg, err := app.LoadConfig()
 	if err != nil {
 		log.Fatal(err)
 	}
-	ctx := context.Background()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 	var db *pgxpool.Pool
 	db, err = pgxpool.New(ctx, cfg.DatabaseURL)
 	if err != nil {
@@ -32,34 +48,53 @@ func main() {
 		log.Fatalf("Unable to ping database: %v", err)
 	}
 	defer db.Close()
-	accountRepo, err := postgres.NewAccountDb(db)
+	application, err := app.New(ctx, cfg, db, transport.NewInMemory())
 	if err != nil {
-		log.Fatalf("failed to connect to account database: %v", err)
+		log.Fatal(err)
 	}
-	transactionRepo, err := postgres.NewTransactionDb(db)
-	if err != nil {
-		log.Fatalf("failed to connect to transaciton database: %v", err)
+	defer application.Close()
+	go scheduleEventPublishing(ctx, application.EventRelay)
+	if cfg.SnapshotInterval > 0 {
+		go scheduleBalanceSnapshots(ctx, application.BalanceService, cfg.SnapshotInterval)
 	}
-	accountService := service.NewAccountService(accountRepo)
-	paymentService, err := service.NewPaymentService(accountRepo, transactionRepo)
-	if err != nil {
-		log.Fatalf("failed to initialize payment service: %v", err)
+	if cfg.StatementDir != "" {
+		go scheduleStatements(ctx, application.StatementService, cfg.StatementDir)
 	}
-
-	httpHandler := httphandler.NewAccountHandler(ctx, accountService)
-	mux := http.NewServeMux()
-	mux.HandleFunc("GET /accounts/{id}", httpHandler.GetAccount)
-	mux.HandleFunc("PATCH /accounts/{id}", httpHandler.Deposit)
-	mux.HandleFunc("POST /accounts", httpHandler.CreateAccount)
-	mux.HandleFunc("GET /users/{id}/account", httpHandler.GetUsersAccount)
-	mux.Handle("/swagger/payment/", httpSwagger.WrapHandler)
-	messageBus := kafka.NewMessageBus(cfg.KafkaBrokers, cfg.KafkaConsumerTopic, cfg.KafkaProducerTopic, cfg.KafkaGroupID)
-	kafkaHandler := kafkahandler.NewPaymentHandler(paymentService)
-	go messageBus.Start(ctx, kafkaHandler)
-	server := &http.Server{Addr: ":" + cfg.HttpPort, Handler: mux}
-	log.Printf("Listening on port " + cfg.HttpPort)
+	if cfg.ReconcileInterval > 0 {
+		go scheduleReconciliation(ctx, application.ReconciliationService, cfg.ReconcileInterval, cfg.ReconcileReportDir)
+	}
+	messageBusDone := make(chan struct{})
+	go func() {
+		application.Start(ctx)
+		close(messageBusDone)
+	}()
+	if cfg.GrpcPort != "" {
+		listener, err := net.Listen("tcp", ":"+cfg.GrpcPort)
+		if err != nil {
+			log.Fatalf("failed to listen on grpc port: %v", err)
+		}
+		go func() {
+			log.Printf("Serving gRPC on port %s", cfg.GrpcPort)
+			err := application.GrpcServer.Serve(listener)
+			if err != nil {
+				log.Printf("grpc server stopped: %v", err)
+			}
+		}()
+	}
+	server := &http.Server{Addr: ":" + cfg.HttpPort, Handler: application.Handler}
+	go func() {
+		<-ctx.Done()
+		application.GrpcServer.GracefulStop()
+		err := server.Shutdown(context.Background())
+		if err != nil {
+			log.Printf("failed to shut down http server: %v", err)
+		}
+	}()
+	log.Printf("Listening on port %s", cfg.HttpPort)
 	err = server.ListenAndServe()
-	if err != nil {
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.Fatalf("failed to start http server: %v", err)
 	}
+	<-messageBusDone
+	log.Printf("Shut down gracefully")
 }
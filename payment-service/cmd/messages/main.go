@@ -0,0 +1,327 @@
+package main
+
+import (
+	"context"
+	"contracts"
+	"contracts/signing"
+	"contracts/transport"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"kafkacluster"
+	"log"
+	"maps"
+	"os"
+	"os/signal"
+	"payment-service/app"
+	"payment-service/internal/adapters/kafkahandler"
+	"payment-service/internal/config"
+	"payment-service/internal/infrastructure/kafka"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Команда messages читает топики Kafka и повторно отправляет из них сообщения.
+// Для tail и replay нужны только настройки Kafka: KAFKA_URL, KAFKA_REQUEST_TOPIC, TLS и SASL;
+// остальная конфигурация payment-service загружается только для replay -dry-run.
+func main() {
+	err := run(os.Args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// errLimitReached прекращает чтение топика, когда выбрано -limit сообщений.
+var errLimitReached = errors.New("message limit reached")
+
+// inspectedMessage — сообщение топика в выводе команды messages.
+// Тело разобрано в запрос или ответ; если разобрать его не удалось, выводится как есть.
+type inspectedMessage struct {
+	Topic     string                    `json:"topic"`
+	Partition int                       `json:"partition"`
+	Offset    int64                     `json:"offset"`
+	Time      time.Time                 `json:"time"`
+	Key       string                    `json:"key,omitempty"`
+	Headers   map[string]string         `json:"headers,omitempty"`
+	Request   *contracts.PaymentRequest `json:"request,omitempty"`
+	Result    *contracts.PaymentResult  `json:"result,omitempty"`
+	Value     string                    `json:"value,omitempty"`
+	Error     string                    `json:"error,omitempty"` // Ошибка разбора тела
+	Reply     *inspectedMessage         `json:"reply,omitempty"` // Ответ payment-service в режиме -dry-run; не записан в топик
+}
+
+// messageFilter отбирает сообщения по ключу и идентификатору корреляции; пустое поле не ограничивает выборку.
+type messageFilter struct {
+	key           string
+	correlationId string
+}
+
+// match сообщает, подходит ли сообщение под фильтр.
+func (f messageFilter) match(message *transport.Message) bool {
+	if f.key != "" && string(message.Key) != f.key {
+		return false
+	}
+	if f.correlationId != "" && message.Header(kafkahandler.CorrelationIdHeader) != f.correlationId {
+		return false
+	}
+	return true
+}
+
+// run выполняет команду messages.
+//
+// messages tail выводит сообщения топика (по умолчанию — топика запросов) в формате JSON,
+// по одному на строку, с разобранными запросами и ответами. Сообщения можно отобрать
+// по ключу, идентификатору корреляции и времени записи, а с флагом -follow — ждать новых.
+//
+// messages replay отправляет отобранные сообщения в топик -to (по умолчанию — топик запросов).
+// С флагом -dry-run сообщения не отправляются: payment-service обрабатывает каждый запрос
+// в транзакции, которая затем откатывается, и выводит ответ, который отправил бы.
+// С флагом -resign сообщения с устаревшей подписью подписываются заново ключом оператора
+// REPLAY_SIGNING_KEY (config.LoadReplaySigning): с прежней подписью переотправленный запрос
+// устаревает. Сообщения без действительной подписи пропускаются и записываются в журнал.
+func run(args []string) error {
+	if len(args) == 0 || (args[0] != "tail" && args[0] != "replay") {
+		return fmt.Errorf("usage: messages tail|replay [-topic T] [-key K] [-correlation-id ID] [-since T] [-until T] [-resign]")
+	}
+	requestTopic := os.Getenv("KAFKA_REQUEST_TOPIC")
+	flags := flag.NewFlagSet("messages "+args[0], flag.ContinueOnError)
+	topic := flags.String("topic", requestTopic, "топик, из которого читаются сообщения")
+	key := flags.String("key", "", "только сообщения с этим ключом")
+	correlationId := flags.String("correlation-id", "", "только сообщения с этим идентификатором корреляции")
+	since := flags.String("since", "", "только сообщения, записанные не раньше этого времени (RFC 3339)")
+	until := flags.String("until", "", "только сообщения, записанные не позже этого времени (RFC 3339)")
+	limit := flags.Int("limit", 0, "максимальное число сообщений; 0 — без ограничения")
+	follow := flags.Bool("follow", false, "tail: ждать новых сообщений после конца топика")
+	to := flags.String("to", requestTopic, "replay: топик, в который отправляются сообщения")
+	dryRun := flags.Bool("dry-run", false, "replay: не отправлять сообщения, а показать ответ payment-service")
+	resign := flags.Bool("resign", false, "replay: подписать заново сообщения с устаревшей подписью ключом REPLAY_SIGNING_KEY")
+	err := flags.Parse(args[1:])
+	if err != nil {
+		return err
+	}
+	if *topic == "" {
+		return errors.New("-topic or KAFKA_REQUEST_TOPIC is required")
+	}
+	if args[0] == "replay" && !*dryRun && *to == "" {
+		return errors.New("-to or KAFKA_REQUEST_TOPIC is required")
+	}
+	messageRange := kafka.MessageRange{Follow: *follow && args[0] == "tail"}
+	messageRange.Since, err = parseTime(*since)
+	if err != nil {
+		return fmt.Errorf("invalid -since: %w", err)
+	}
+	messageRange.Until, err = parseTime(*until)
+	if err != nil {
+		return fmt.Errorf("invalid -until: %w", err)
+	}
+	filter := messageFilter{key: *key, correlationId: *correlationId}
+	var resigner *signing.Resigner
+	if *resign {
+		replaySigning, err := config.LoadReplaySigning()
+		if err != nil {
+			return err
+		}
+		resigner = signing.NewResigner(signing.NewSigner(replaySigning.SigningKey),
+			signing.NewVerifier(replaySigning.VerificationKeys, replaySigning.SignatureMaxAge))
+	}
+
+	cluster, err := loadCluster()
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var handle func(message kafka.TopicMessage) error
+	encoder := json.NewEncoder(os.Stdout)
+	switch {
+	case args[0] == "tail":
+		handle = func(message kafka.TopicMessage) error {
+			return encoder.Encode(inspect(message))
+		}
+	case *dryRun:
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			return err
+		}
+		application, closeApp, err := newDryRunApp(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		defer closeApp()
+		handle = func(message kafka.TopicMessage) error {
+			replay, err := replayed(message.Message, resigner)
+			if err != nil {
+				log.Printf("messages: %s skipped: %v", message.Message.Id, err)
+				return nil
+			}
+			message.Message = replay
+			inspected := inspect(message)
+			reply, err := application.DryRun(ctx, message.Message)
+			if reply == nil && err != nil {
+				return fmt.Errorf("dry run of %s: %w", message.Message.Id, err)
+			}
+			if err != nil {
+				inspected.Error = err.Error()
+			}
+			replyTopic := message.Message.Header(transport.ReplyToHeader)
+			if replyTopic == "" {
+				replyTopic = cfg.KafkaProducerTopic
+			}
+			inspected.Reply = inspect(kafka.TopicMessage{Topic: replyTopic, Message: reply})
+			return encoder.Encode(inspected)
+		}
+	default:
+		producer := kafka.NewProducer(cluster, *to)
+		defer producer.Close()
+		handle = func(message kafka.TopicMessage) error {
+			replay, err := replayed(message.Message, resigner)
+			if err != nil {
+				log.Printf("messages: %s skipped: %v", message.Message.Id, err)
+				return nil
+			}
+			err = producer.Send(ctx, replay)
+			if err != nil {
+				return fmt.Errorf("replay of %s: %w", message.Message.Id, err)
+			}
+			log.Printf("messages: %s replayed to %s", message.Message.Id, *to)
+			return nil
+		}
+	}
+
+	selected := 0
+	err = kafka.ReadMessages(ctx, cluster, *topic, messageRange, func(message kafka.TopicMessage) error {
+		if !filter.match(message.Message) {
+			return nil
+		}
+		err := handle(message)
+		if err != nil {
+			return err
+		}
+		selected++
+		if *limit > 0 && selected >= *limit {
+			return errLimitReached
+		}
+		return nil
+	})
+	if errors.Is(err, errLimitReached) || (err != nil && ctx.Err() != nil) {
+		err = nil
+	}
+	log.Printf("messages: %d selected from %s", selected, *topic)
+	return err
+}
+
+// replayed возвращает сообщение в том виде, в каком оно будет записано при переотправке:
+// без времени записи и, если задан resigner, с новой подписью вместо устаревшей.
+// Для сообщения без действительной подписи возвращается ошибка (signing.Resigner.Resign).
+func replayed(message *transport.Message, resigner *signing.Resigner) (*transport.Message, error) {
+	replay := *message
+	replay.Time = time.Time{}
+	if resigner != nil {
+		replay.Headers = maps.Clone(message.Headers)
+		err := resigner.Resign(&replay)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &replay, nil
+}
+
+// loadCluster читает из окружения только настройки подключения к Kafka: KAFKA_URL, TLS и SASL.
+func loadCluster() (kafkacluster.Cluster, error) {
+	errs := make([]string, 0)
+	brokers := os.Getenv("KAFKA_URL")
+	if brokers == "" {
+		errs = append(errs, "KAFKA_URL is required")
+	}
+	security, securityErrs := kafkacluster.LoadSecurity()
+	errs = append(errs, securityErrs...)
+	if len(errs) > 0 {
+		return kafkacluster.Cluster{}, fmt.Errorf("config validation failed:\n  %s", strings.Join(errs, "\n  "))
+	}
+	cluster, err := kafkacluster.NewCluster(strings.Split(brokers, ";"), security)
+	if err != nil {
+		return kafkacluster.Cluster{}, fmt.Errorf("invalid kafka security settings: %w", err)
+	}
+	return cluster, nil
+}
+
+// newDryRunApp собирает payment-service для пробной обработки запросов (App.DryRun).
+// Сервис подключается к базе данных, но не к Kafka: шина сообщений заменена брокером в памяти,
+// поэтому ответы и события никуда не отправляются.
+func newDryRunApp(ctx context.Context, cfg *config.Config) (*app.App, func(), error) {
+	db, err := pgxpool.New(ctx, cfg.DatabaseURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to db: %w", err)
+	}
+	dryRunCfg := *cfg
+	dryRunCfg.MessageBus = "memory"
+	application, err := app.New(ctx, &dryRunCfg, db, transport.NewInMemory())
+	if err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+	return application, func() {
+		application.Close()
+		db.Close()
+	}, nil
+}
+
+// inspect разбирает сообщение топика для вывода.
+//
+// Сообщение в конверте (с заголовком contracts.ContentTypeHeader) разбирается как запрос
+// или ответ по типу конверта. Сообщение в прежнем формате считается запросом, если его тело —
+// JSON-объект, и ответом — в остальных случаях.
+func inspect(message kafka.TopicMessage) *inspectedMessage {
+	inspected := &inspectedMessage{
+		Topic:     message.Topic,
+		Partition: message.Partition,
+		Offset:    message.Offset,
+		Time:      message.Time,
+		Key:       string(message.Message.Key),
+		Headers:   message.Message.Headers,
+	}
+	value := message.Message.Value
+	encoding := contracts.Encoding(message.Message.Header(contracts.ContentTypeHeader))
+	if encoding == "" {
+		if len(value) > 0 && value[0] == '{' {
+			request, err := contracts.UnmarshalLegacyPaymentRequest(value)
+			if err == nil {
+				inspected.Request = &request
+				return inspected
+			}
+		}
+		result := contracts.ParseLegacyPaymentResult(string(value), message.Message.Header(kafkahandler.ReasonCodeHeader))
+		inspected.Result = &result
+		return inspected
+	}
+
+	var request contracts.PaymentRequest
+	_, err := contracts.Unmarshal(encoding, value, &request)
+	if err == nil {
+		inspected.Request = &request
+		return inspected
+	}
+	if errors.Is(err, contracts.ErrUnexpectedType) {
+		var result contracts.PaymentResult
+		_, err = contracts.Unmarshal(encoding, value, &result)
+		if err == nil {
+			inspected.Result = &result
+			return inspected
+		}
+	}
+	inspected.Value = string(value)
+	inspected.Error = err.Error()
+	return inspected
+}
+
+// parseTime разбирает время в формате RFC 3339; пустая строка означает нулевое время.
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
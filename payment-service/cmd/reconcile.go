@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"payment-service/internal/adapters/report"
+	"payment-service/internal/application/service"
+	"payment-service/internal/config"
+	"payment-service/internal/domain"
+	"payment-service/internal/infrastructure/postgres"
+	"time"
+)
+
+// runReconcile выполняет подкоманду reconcile.
+//
+// Без флага -apply пересчитывает балансы всех счетов по транзакциям и выводит
+// отчёт о расхождениях в формате JSON или CSV. С флагом -apply читает одобренный
+// администратором JSON-отчёт и записывает по нему корректирующие транзакции.
+func runReconcile(args []string) error {
+	flags := flag.NewFlagSet("reconcile", flag.ContinueOnError)
+	format := flags.String("format", report.FormatJSON, "формат отчёта: json или csv")
+	output := flags.String("output", "", "файл для отчёта; по умолчанию stdout")
+	apply := flags.String("apply", "", "одобренный JSON-отчёт, по которому нужно записать корректировки")
+	approvedBy := flags.String("approved-by", "", "администратор, одобривший корректировки (обязателен с -apply)")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if *apply != "" && *approvedBy == "" {
+		return fmt.Errorf("-approved-by is required with -apply")
+	}
+
+	databaseURL, err := config.LoadDatabaseURL()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	db, err := pgxpool.New(ctx, databaseURL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to db: %w", err)
+	}
+	defer db.Close()
+	reconciliationService, err := newReconciliationService(db)
+	if err != nil {
+		return err
+	}
+
+	if *apply != "" {
+		file, err := os.Open(*apply)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		mismatches, err := report.ReadMismatches(file)
+		if err != nil {
+			return err
+		}
+		adjustments, err := reconciliationService.Adjust(ctx, mismatches, *approvedBy)
+		log.Printf("reconciliation: %d of %d adjustments written", len(adjustments), len(mismatches))
+		return err
+	}
+
+	mismatches, err := reconciliationService.Reconcile(ctx)
+	if err != nil {
+		return err
+	}
+	var out io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+	return report.WriteMismatches(out, *format, mismatches)
+}
+
+// newReconciliationService собирает ReconciliationService поверх пула подключений.
+func newReconciliationService(db *pgxpool.Pool) (*service.ReconciliationService, error) {
+	accountRepo, err := postgres.NewAccountDb(db)
+	if err != nil {
+		return nil, err
+	}
+	transactionRepo, err := postgres.NewTransactionDb(db)
+	if err != nil {
+		return nil, err
+	}
+	transactor, err := postgres.NewTransactor(db)
+	if err != nil {
+		return nil, err
+	}
+	return service.NewReconciliationService(accountRepo, transactionRepo, transactor), nil
+}
+
+// scheduleReconciliation периодически запускает сверку балансов.
+// Отчёт записывается в каталог reportDir, а если он не задан — в лог.
+// Корректировки по расписанию не записываются: они требуют одобрения администратора.
+func scheduleReconciliation(ctx context.Context, reconciliationService *service.ReconciliationService, interval time.Duration, reportDir string) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			mismatches, err := reconciliationService.Reconcile(ctx)
+			if err != nil {
+				log.Printf("reconciliation failed: %v", err)
+				continue
+			}
+			log.Printf("reconciliation: %d mismatches found", len(mismatches))
+			if len(mismatches) == 0 {
+				continue
+			}
+			err = writeScheduledReport(now, reportDir, mismatches)
+			if err != nil {
+				log.Printf("failed to write reconciliation report: %v", err)
+			}
+		}
+	}
+}
+
+// writeScheduledReport записывает JSON-отчёт плановой сверки в файл с меткой времени.
+func writeScheduledReport(now time.Time, reportDir string, mismatches []domain.BalanceMismatch) error {
+	if reportDir == "" {
+		return report.WriteMismatches(log.Writer(), report.FormatJSON, mismatches)
+	}
+	path := filepath.Join(reportDir, "reconciliation-"+now.UTC().Format("20060102T150405Z")+".json")
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	log.Printf("reconciliation report written to %s", path)
+	return report.WriteMismatches(file, report.FormatJSON, mismatches)
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+	"log"
+	"payment-service/internal/application/service"
+	"time"
+)
+
+// scheduleBalanceSnapshots периодически фиксирует балансы всех счетов,
+// чтобы запросы баланса на момент времени не пересчитывали всю историю транзакций.
+func scheduleBalanceSnapshots(ctx context.Context, balanceService *service.BalanceService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			saved, err := balanceService.TakeSnapshots(ctx, now)
+			if err != nil {
+				log.Printf("balance snapshots failed after %d accounts: %v", saved, err)
+				continue
+			}
+			log.Printf("balance snapshots: %d accounts", saved)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"payment-service/internal/adapters/report"
+	"payment-service/internal/application/service"
+	"payment-service/internal/domain"
+	"time"
+)
+
+// statementCheckInterval — период проверки, выписаны ли выписки за прошедший месяц.
+const statementCheckInterval = time.Hour
+
+// statementFormats — форматы, в которых плановое задание выписывает выписки.
+var statementFormats = []string{report.FormatCSV, report.FormatOFX}
+
+// scheduleStatements при запуске и затем каждый statementCheckInterval выписывает
+// выписки по всем счетам за прошедший месяц в каталог dir/<YYYY-MM>.
+// Уже выписанные файлы не перезаписываются, поэтому перезапуск сервиса безопасен.
+func scheduleStatements(ctx context.Context, statementService *service.StatementService, dir string) {
+	ticker := time.NewTicker(statementCheckInterval)
+	defer ticker.Stop()
+	now := time.Now()
+	for {
+		written, err := writeStatements(ctx, statementService, dir, previousMonth(now))
+		if err != nil {
+			log.Printf("statements failed: %v", err)
+		} else if written > 0 {
+			log.Printf("statements: %d files written to %s", written, dir)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case now = <-ticker.C:
+		}
+	}
+}
+
+// writeStatements выписывает выписки за месяц month во всех форматах statementFormats
+// и возвращает число записанных файлов.
+func writeStatements(ctx context.Context, statementService *service.StatementService, dir string, month time.Time) (int, error) {
+	monthDir := filepath.Join(dir, month.Format(domain.StatementMonthFormat))
+	if complete, err := statementsComplete(monthDir); err != nil || complete {
+		return 0, err
+	}
+	statements, err := statementService.GetStatements(ctx, month)
+	if err != nil {
+		return 0, err
+	}
+	err = os.MkdirAll(monthDir, 0o755)
+	if err != nil {
+		return 0, err
+	}
+	written := 0
+	for _, statement := range statements {
+		for _, format := range statementFormats {
+			path := filepath.Join(monthDir, report.StatementFileName(statement, format))
+			if _, err := os.Stat(path); err == nil {
+				continue
+			}
+			err = writeStatementFile(path, format, statement)
+			if err != nil {
+				return written, err
+			}
+			written++
+		}
+	}
+	return written, markStatementsComplete(monthDir)
+}
+
+// writeStatementFile записывает выписку во временный файл и переименовывает его,
+// чтобы в каталоге не оставалось недописанных выписок.
+func writeStatementFile(path, format string, statement domain.Statement) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".statement-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	err = report.WriteStatement(tmp, format, statement)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// statementsCompleteMarker — файл, которым отмечается каталог месяца с выписанными выписками.
+const statementsCompleteMarker = ".complete"
+
+// statementsComplete сообщает, выписаны ли уже все выписки в каталог месяца.
+func statementsComplete(monthDir string) (bool, error) {
+	_, err := os.Stat(filepath.Join(monthDir, statementsCompleteMarker))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// markStatementsComplete отмечает каталог месяца как полностью выписанный.
+func markStatementsComplete(monthDir string) error {
+	return os.WriteFile(filepath.Join(monthDir, statementsCompleteMarker), nil, 0o644)
+}
+
+// previousMonth возвращает первый день месяца, предшествующего now, в UTC.
+func previousMonth(now time.Time) time.Time {
+	now = now.UTC()
+	return time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, time.UTC)
+}
@@ -17,7 +17,7 @@ const docTemplate = `{
     "paths": {
         "/accounts": {
             "post": {
-                "description": "Creates a new account",
+                "description": "Creates a new wallet for user. The first wallet of the user becomes default",
                 "summary": "Create account",
                 "parameters": [
                     {
@@ -69,56 +69,822 @@ const docTemplate = `{
                 }
             },
             "patch": {
+                "description": "Создаёт пополнение через платёжного провайдера. Баланс меняется только после подтверждения оплаты провайдером;\nдля подтверждения пользователь переходит по confirmation_url",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "accounts"
+                ],
+                "summary": "Пополнить счёт",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Top-up amount",
+                        "name": "amount",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/httphandler.TopUpRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/domain.TopUp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/accounts/{id}/balance": {
+            "get": {
+                "description": "Возвращает баланс счёта на момент at, рассчитанный по проведённым транзакциям с датой не позже at.\nБез параметра at возвращает баланс на текущий момент",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "accounts"
+                ],
+                "summary": "Баланс счёта на момент времени",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC 3339 timestamp, e.g. 2025-10-31T23:59:59Z",
+                        "name": "at",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.AccountBalance"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/accounts/{id}/default": {
+            "put": {
+                "description": "Marks wallet as the default one for its owner",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "accounts"
+                ],
+                "summary": "Make wallet default",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/httphandler.AccountResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/accounts/{id}/statements/{month}": {
+            "get": {
+                "description": "Возвращает выписку за календарный месяц (UTC): баланс на начало, проведённые транзакции и баланс на конец.\nВыписка за текущий месяц составляется на текущий момент",
+                "produces": [
+                    "text/csv",
+                    "application/x-ofx"
+                ],
+                "tags": [
+                    "accounts"
+                ],
+                "summary": "Выписка по счёту за месяц",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Month, e.g. 2025-10",
+                        "name": "month",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "csv (default) or ofx",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/accounts/{id}/top-ups": {
+            "get": {
+                "description": "Возвращает все пополнения кошелька, включая ожидающие подтверждения и неуспешные",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "accounts"
+                ],
+                "summary": "Пополнения счёта",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.TopUp"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/admin/accounts/{id}/credit-limit": {
+            "put": {
+                "description": "Задаёт кредитный лимит и беспроцентный льготный период для счёта (административный метод).\nЕсли grace_period_days не передан, льготный период не меняется.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Установить кредитный лимит",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Credit limit",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/httphandler.CreditLimitRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/httphandler.AccountResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/admin/accounts/{id}/limits": {
+            "get": {
+                "description": "Возвращает ограничения на платежи со счёта; нулевое значение означает, что ограничение не действует (административный метод)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Ограничения на списания",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.SpendingLimits"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    }
+                }
+            },
+            "put": {
+                "description": "Задаёт максимальную сумму платежа, дневной и месячный лимиты и максимальное число платежей в час.\nНулевое значение снимает соответствующее ограничение (административный метод)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Установить ограничения на списания",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Spending limits",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/httphandler.SpendingLimitsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.SpendingLimits"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет все ограничения на платежи со счёта (административный метод)",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Снять ограничения на списания",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/admin/accounts/{id}/status": {
+            "put": {
+                "description": "Замораживает, приостанавливает, активирует или закрывает счёт (административный метод).\nЗакрыть можно только счёт с нулевым балансом; при payout=true положительный остаток выплачивается перед закрытием.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Изменить статус счёта",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New status",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/httphandler.ChangeStatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/httphandler.AccountResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/admin/accounts/{id}/status-history": {
+            "get": {
+                "description": "Возвращает журнал изменений статуса счёта с авторами и причинами (административный метод)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "История статусов счёта",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.AccountStatusChange"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/admin/balances": {
+            "get": {
+                "description": "Отчёт на конец периода: балансы всех счетов, открытых не позже момента at, в формате JSON или CSV (административный метод)",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Балансы всех счетов на момент времени",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "RFC 3339 timestamp, e.g. 2025-10-31T23:59:59Z",
+                        "name": "at",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "json (default) or csv",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.AccountBalance"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/admin/reviews": {
+            "get": {
+                "description": "Возвращает операции, задержанные правилами проверки на мошенничество, вместе со сработавшими правилами (административный метод)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Очередь ручной проверки",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.TransactionReview"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/admin/reviews/{id}/approve": {
+            "post": {
+                "description": "Проводит задержанную операцию. Лимиты и баланс проверяются заново, поэтому операция может быть отклонённой (административный метод)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Разрешить задержанную операцию",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transaction ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Decision",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/httphandler.ReviewRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Transaction"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/admin/reviews/{id}/reject": {
+            "post": {
+                "description": "Отклоняет задержанную операцию с причиной REJECTED_BY_REVIEW; баланс счёта не меняется (административный метод)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Отклонить задержанную операцию",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transaction ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Decision",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/httphandler.ReviewRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Transaction"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/admin/top-ups": {
+            "get": {
+                "description": "Возвращает пополнения в указанном статусе, по умолчанию — ожидающие подтверждения (административный метод)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Пополнения по статусу",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "pending, succeeded or failed",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.TopUp"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/admin/transactions/{id}": {
+            "get": {
+                "description": "Возвращает транзакцию по ID; reversed_amount показывает уже отменённую часть суммы (административный метод)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Получить транзакцию",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "account id",
+                        "description": "Transaction ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Deposit amount",
-                        "name": "amount",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/httphandler.DepositRequest"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Transaction"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {}
                     }
                 }
             }
         },
-        "/users/{id}/account": {
-            "get": {
-                "summary": "Get users account by id",
+        "/admin/transactions/{id}/reversals": {
+            "post": {
+                "description": "Полностью или частично отменяет проведённую транзакцию: создаёт связанную транзакцию обратного направления.\nСумма отмены не может превышать ещё не отменённую часть транзакции (административный метод)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Отменить транзакцию",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
+                        "description": "Transaction ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Get by users id",
+                        "description": "Reversal",
                         "name": "data",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/httphandler.GetByUserIdRequest"
+                            "$ref": "#/definitions/httphandler.ReversalRequest"
                         }
                     }
                 ],
                 "responses": {
                     "201": {
                         "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Transaction"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/payments/callback": {
+            "post": {
+                "description": "Принимает подписанное уведомление провайдера о результате оплаты пополнения.\nПодпись тела запроса передаётся в заголовке X-Signature",
+                "tags": [
+                    "payments"
+                ],
+                "summary": "Уведомление платёжного провайдера",
+                "parameters": [
+                    {
+                        "description": "Callback",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/provider.Callback"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/ready": {
+            "get": {
+                "description": "Проверяет доступность брокера Kafka и топиков сервиса. Без Kafka сервис всегда готов",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Готовность сервиса",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/kafkacluster.Readiness"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/kafkacluster.Readiness"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/account": {
+            "get": {
+                "description": "Returns all wallets of the user, the default wallet goes first",
+                "summary": "Get users wallets by id",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/httphandler.AccountResponse"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {}
                     }
                 }
@@ -126,29 +892,682 @@ const docTemplate = `{
         }
     },
     "definitions": {
-        "httphandler.CreateAccountRequest": {
+        "domain.AccountBalance": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "description": "Идентификатор счёта",
+                    "type": "integer"
+                },
+                "at": {
+                    "description": "Момент, на который рассчитан баланс",
+                    "type": "string"
+                },
+                "balance": {
+                    "description": "Баланс на момент At",
+                    "type": "number"
+                },
+                "currency": {
+                    "description": "Валюта счёта",
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "Идентификатор владельца счёта",
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.AccountStatus": {
+            "type": "string",
+            "enum": [
+                "active",
+                "frozen",
+                "suspended",
+                "closed"
+            ],
+            "x-enum-comments": {
+                "AccountStatusActive": "Счёт активен: разрешены пополнения и списания",
+                "AccountStatusClosed": "Счёт закрыт окончательно",
+                "AccountStatusFrozen": "Счёт заморожен: списания запрещены, пополнения разрешены",
+                "AccountStatusSuspended": "Счёт приостановлен: запрещены любые операции"
+            },
+            "x-enum-descriptions": [
+                "Счёт активен: разрешены пополнения и списания",
+                "Счёт заморожен: списания запрещены, пополнения разрешены",
+                "Счёт приостановлен: запрещены любые операции",
+                "Счёт закрыт окончательно"
+            ],
+            "x-enum-varnames": [
+                "AccountStatusActive",
+                "AccountStatusFrozen",
+                "AccountStatusSuspended",
+                "AccountStatusClosed"
+            ]
+        },
+        "domain.AccountStatusChange": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "description": "Идентификатор счёта",
+                    "type": "integer"
+                },
+                "changed_at": {
+                    "description": "Дата изменения",
+                    "type": "string"
+                },
+                "changed_by": {
+                    "description": "Кто изменил статус",
+                    "type": "string"
+                },
+                "from_status": {
+                    "description": "Статус до изменения",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.AccountStatus"
+                        }
+                    ]
+                },
+                "id": {
+                    "description": "Уникальный идентификатор записи",
+                    "type": "integer"
+                },
+                "reason": {
+                    "description": "Причина изменения",
+                    "type": "string"
+                },
+                "to_status": {
+                    "description": "Статус после изменения",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.AccountStatus"
+                        }
+                    ]
+                }
+            }
+        },
+        "domain.ReasonCode": {
+            "type": "string",
+            "enum": [
+                "INVALID_AMOUNT",
+                "INSUFFICIENT_FUNDS",
+                "LIMIT_EXCEEDED",
+                "OPERATION_NOT_ALLOWED",
+                "ACCOUNT_NOT_FOUND",
+                "FOREIGN_ACCOUNT",
+                "REJECTED_BY_REVIEW",
+                "PENDING_REVIEW"
+            ],
+            "x-enum-varnames": [
+                "ReasonInvalidAmount",
+                "ReasonInsufficientFunds",
+                "ReasonLimitExceeded",
+                "ReasonOperationNotAllowed",
+                "ReasonAccountNotFound",
+                "ReasonForeignAccount",
+                "ReasonRejectedByReview",
+                "ReasonPendingReview"
+            ]
+        },
+        "domain.ReversalReason": {
+            "type": "string",
+            "enum": [
+                "CUSTOMER_REQUEST",
+                "DUPLICATE",
+                "FRAUD",
+                "CHARGEBACK",
+                "OPERATOR_ERROR"
+            ],
+            "x-enum-comments": {
+                "ReversalChargeback": "Опротестование платежа банком или платёжной системой",
+                "ReversalCustomerRequest": "Возврат по просьбе клиента",
+                "ReversalDuplicate": "Повторно проведённая операция",
+                "ReversalFraud": "Мошенническая операция",
+                "ReversalOperatorError": "Ошибка оператора"
+            },
+            "x-enum-descriptions": [
+                "Возврат по просьбе клиента",
+                "Повторно проведённая операция",
+                "Мошенническая операция",
+                "Опротестование платежа банком или платёжной системой",
+                "Ошибка оператора"
+            ],
+            "x-enum-varnames": [
+                "ReversalCustomerRequest",
+                "ReversalDuplicate",
+                "ReversalFraud",
+                "ReversalChargeback",
+                "ReversalOperatorError"
+            ]
+        },
+        "domain.ReviewStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "approved",
+                "rejected"
+            ],
+            "x-enum-comments": {
+                "ReviewStatusApproved": "Администратор разрешил операцию",
+                "ReviewStatusPending": "Операция ожидает решения администратора",
+                "ReviewStatusRejected": "Администратор отклонил операцию"
+            },
+            "x-enum-descriptions": [
+                "Операция ожидает решения администратора",
+                "Администратор разрешил операцию",
+                "Администратор отклонил операцию"
+            ],
+            "x-enum-varnames": [
+                "ReviewStatusPending",
+                "ReviewStatusApproved",
+                "ReviewStatusRejected"
+            ]
+        },
+        "domain.RiskFlag": {
+            "type": "string",
+            "enum": [
+                "large_amount",
+                "new_account_large",
+                "failed_attempts"
+            ],
+            "x-enum-comments": {
+                "RiskFlagFailedAttempts": "Серия отклонённых операций пользователя",
+                "RiskFlagLargeAmount": "Сумма операции превышает порог",
+                "RiskFlagNewAccountLarge": "Крупный платёж с недавно созданного счёта"
+            },
+            "x-enum-descriptions": [
+                "Сумма операции превышает порог",
+                "Крупный платёж с недавно созданного счёта",
+                "Серия отклонённых операций пользователя"
+            ],
+            "x-enum-varnames": [
+                "RiskFlagLargeAmount",
+                "RiskFlagNewAccountLarge",
+                "RiskFlagFailedAttempts"
+            ]
+        },
+        "domain.SpendingLimits": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "description": "Идентификатор счёта",
+                    "type": "integer"
+                },
+                "daily_limit": {
+                    "description": "Максимальная сумма платежей за календарный день",
+                    "type": "number"
+                },
+                "max_payment": {
+                    "description": "Максимальная сумма одного платежа",
+                    "type": "number"
+                },
+                "max_payments_per_hour": {
+                    "description": "Максимальное число платежей за последний час",
+                    "type": "integer"
+                },
+                "monthly_limit": {
+                    "description": "Максимальная сумма платежей за календарный месяц",
+                    "type": "number"
+                },
+                "updated_at": {
+                    "description": "Дата последнего изменения ограничений",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.TopUp": {
             "type": "object",
             "properties": {
+                "account_id": {
+                    "description": "Пополняемый кошелёк",
+                    "type": "integer"
+                },
+                "amount": {
+                    "description": "Сумма пополнения",
+                    "type": "number"
+                },
+                "confirmation_url": {
+                    "description": "Адрес, по которому пользователь подтверждает оплату",
+                    "type": "string"
+                },
+                "created_at": {
+                    "description": "Момент создания",
+                    "type": "string"
+                },
+                "failure_reason": {
+                    "description": "Причина ошибки оплаты",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Уникальный идентификатор пополнения",
+                    "type": "integer"
+                },
+                "provider": {
+                    "description": "Название платёжного провайдера",
+                    "type": "string"
+                },
+                "provider_ref": {
+                    "description": "Идентификатор платежа у провайдера",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Состояние пополнения",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.TopUpStatus"
+                        }
+                    ]
+                },
+                "transaction_id": {
+                    "description": "Транзакция пополнения (nil, пока оплата не подтверждена)",
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "description": "Момент последнего изменения состояния",
+                    "type": "string"
+                },
                 "user_id": {
+                    "description": "Владелец кошелька",
                     "type": "integer"
                 }
             }
         },
-        "httphandler.DepositRequest": {
+        "domain.TopUpStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "succeeded",
+                "failed"
+            ],
+            "x-enum-comments": {
+                "TopUpStatusFailed": "Провайдер сообщил об ошибке оплаты, баланс не изменён",
+                "TopUpStatusPending": "Ожидает подтверждения провайдером",
+                "TopUpStatusSucceeded": "Провайдер подтвердил оплату, баланс пополнен"
+            },
+            "x-enum-descriptions": [
+                "Ожидает подтверждения провайдером",
+                "Провайдер подтвердил оплату, баланс пополнен",
+                "Провайдер сообщил об ошибке оплаты, баланс не изменён"
+            ],
+            "x-enum-varnames": [
+                "TopUpStatusPending",
+                "TopUpStatusSucceeded",
+                "TopUpStatusFailed"
+            ]
+        },
+        "domain.Transaction": {
             "type": "object",
             "properties": {
+                "account_id": {
+                    "description": "Идентификатор кошелька; 0 — основной кошелёк пользователя",
+                    "type": "integer"
+                },
                 "amount": {
+                    "description": "Сумма операции",
+                    "type": "number"
+                },
+                "date": {
+                    "description": "Дата выполнения транзакции",
+                    "type": "string"
+                },
+                "failure_code": {
+                    "description": "Код причины отказа для отклонённой транзакции",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ReasonCode"
+                        }
+                    ]
+                },
+                "failure_reason": {
+                    "description": "Причина отказа для отклонённой транзакции",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Уникальный идентификатор транзакции",
+                    "type": "integer"
+                },
+                "is_deposit": {
+                    "description": "Направление операции: true — пополнение, false — снятие",
+                    "type": "boolean"
+                },
+                "reversal_of": {
+                    "description": "ID отменяемой транзакции (только для отмен)",
+                    "type": "integer"
+                },
+                "reversal_reason": {
+                    "description": "Код причины отмены (только для отмен)",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ReversalReason"
+                        }
+                    ]
+                },
+                "reversed_amount": {
+                    "description": "Уже отменённая часть суммы транзакции",
+                    "type": "number"
+                },
+                "status": {
+                    "description": "Результат обработки транзакции",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.TransactionStatus"
+                        }
+                    ]
+                },
+                "type": {
+                    "description": "Происхождение операции; пустое значение означает payment",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.TransactionType"
+                        }
+                    ]
+                },
+                "user_id": {
+                    "description": "Идентификатор пользователя, связанного с операцией",
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.TransactionReview": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "description": "Комментарий к решению",
+                    "type": "string"
+                },
+                "created_at": {
+                    "description": "Момент постановки в очередь",
+                    "type": "string"
+                },
+                "decided_at": {
+                    "description": "Момент принятия решения (nil, пока проверка не завершена)",
+                    "type": "string"
+                },
+                "decided_by": {
+                    "description": "Администратор, принявший решение",
+                    "type": "string"
+                },
+                "flags": {
+                    "description": "Сработавшие правила",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.RiskFlag"
+                    }
+                },
+                "status": {
+                    "description": "Состояние проверки",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ReviewStatus"
+                        }
+                    ]
+                },
+                "transaction": {
+                    "description": "Задержанная транзакция (заполняется при выдаче очереди)",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.Transaction"
+                        }
+                    ]
+                },
+                "transaction_id": {
+                    "description": "Идентификатор задержанной транзакции",
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.TransactionStatus": {
+            "type": "string",
+            "enum": [
+                "completed",
+                "declined",
+                "pending_review"
+            ],
+            "x-enum-comments": {
+                "TransactionStatusCompleted": "Транзакция проведена, баланс изменён",
+                "TransactionStatusDeclined": "Транзакция отклонена, баланс не изменён"
+            },
+            "x-enum-descriptions": [
+                "Транзакция проведена, баланс изменён",
+                "Транзакция отклонена, баланс не изменён",
+                ""
+            ],
+            "x-enum-varnames": [
+                "TransactionStatusCompleted",
+                "TransactionStatusDeclined",
+                "TransactionStatusPendingReview"
+            ]
+        },
+        "domain.TransactionType": {
+            "type": "string",
+            "enum": [
+                "payment",
+                "payout",
+                "manual",
+                "top_up",
+                "adjustment",
+                "reversal"
+            ],
+            "x-enum-comments": {
+                "TransactionTypeManual": "Ручное пополнение через HTTP API",
+                "TransactionTypePayment": "Операция, поступившая от других сервисов через Kafka",
+                "TransactionTypePayout": "Выплата остатка средств при закрытии счёта",
+                "TransactionTypeTopUp": "Пополнение, подтверждённое платёжным провайдером"
+            },
+            "x-enum-descriptions": [
+                "Операция, поступившая от других сервисов через Kafka",
+                "Выплата остатка средств при закрытии счёта",
+                "Ручное пополнение через HTTP API",
+                "Пополнение, подтверждённое платёжным провайдером",
+                "",
+                ""
+            ],
+            "x-enum-varnames": [
+                "TransactionTypePayment",
+                "TransactionTypePayout",
+                "TransactionTypeManual",
+                "TransactionTypeTopUp",
+                "TransactionTypeAdjustment",
+                "TransactionTypeReversal"
+            ]
+        },
+        "httphandler.AccountResponse": {
+            "type": "object",
+            "properties": {
+                "available_funds": {
                     "type": "number"
+                },
+                "balance": {
+                    "description": "Текущий баланс счёта (может быть отрицательным в пределах кредитного лимита)",
+                    "type": "number"
+                },
+                "creation_date": {
+                    "description": "Дата создания счёта",
+                    "type": "string"
+                },
+                "credit_limit": {
+                    "description": "Кредитный лимит — насколько баланс может уйти в минус",
+                    "type": "number"
+                },
+                "currency": {
+                    "description": "Валюта кошелька (код ISO 4217)",
+                    "type": "string"
+                },
+                "grace_period_days": {
+                    "description": "Длительность беспроцентного льготного периода в днях",
+                    "type": "integer"
+                },
+                "grace_period_end": {
+                    "type": "string"
+                },
+                "grace_period_expired": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "description": "Уникальный идентификатор счёта",
+                    "type": "integer"
+                },
+                "in_overdraft": {
+                    "type": "boolean"
+                },
+                "is_default": {
+                    "description": "Признак основного кошелька пользователя",
+                    "type": "boolean"
+                },
+                "name": {
+                    "description": "Название кошелька, уникальное в пределах пользователя",
+                    "type": "string"
+                },
+                "overdraft_since": {
+                    "description": "Момент ухода баланса в минус (nil, если баланс неотрицательный)",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Статус счёта в жизненном цикле",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.AccountStatus"
+                        }
+                    ]
+                },
+                "user_id": {
+                    "description": "Идентификатор пользователя, которому принадлежит счёт",
+                    "type": "integer"
+                }
+            }
+        },
+        "httphandler.ChangeStatusRequest": {
+            "type": "object",
+            "properties": {
+                "changed_by": {
+                    "type": "string"
+                },
+                "payout": {
+                    "type": "boolean"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
                 }
             }
         },
-        "httphandler.GetByUserIdRequest": {
+        "httphandler.CreateAccountRequest": {
             "type": "object",
             "properties": {
+                "currency": {
+                    "type": "string"
+                },
+                "is_default": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
                 "user_id": {
                     "type": "integer"
                 }
             }
+        },
+        "httphandler.CreditLimitRequest": {
+            "type": "object",
+            "properties": {
+                "credit_limit": {
+                    "type": "number"
+                },
+                "grace_period_days": {
+                    "description": "Без значения льготный период не меняется",
+                    "type": "integer"
+                }
+            }
+        },
+        "httphandler.ReversalRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "httphandler.ReviewRequest": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "type": "string"
+                },
+                "decided_by": {
+                    "type": "string"
+                }
+            }
+        },
+        "httphandler.SpendingLimitsRequest": {
+            "type": "object",
+            "properties": {
+                "daily_limit": {
+                    "type": "number"
+                },
+                "max_payment": {
+                    "type": "number"
+                },
+                "max_payments_per_hour": {
+                    "type": "integer"
+                },
+                "monthly_limit": {
+                    "type": "number"
+                }
+            }
+        },
+        "httphandler.TopUpRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                }
+            }
+        },
+        "kafkacluster.Readiness": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "Причина, по которой топики не удалось проверить",
+                    "type": "string"
+                },
+                "ready": {
+                    "description": "Сервис готов обрабатывать запросы",
+                    "type": "boolean"
+                },
+                "topics": {
+                    "description": "Состояние топиков: ok или описание ошибки",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "provider.Callback": {
+            "type": "object",
+            "properties": {
+                "failure_reason": {
+                    "description": "Причина отказа для неуспешной оплаты",
+                    "type": "string"
+                },
+                "reference": {
+                    "description": "Идентификатор платежа у провайдера",
+                    "type": "string"
+                },
+                "succeeded": {
+                    "description": "true — оплата прошла, false — отклонена",
+                    "type": "boolean"
+                },
+                "top_up_id": {
+                    "description": "ID пополнения, для которого создавался платёж",
+                    "type": "integer"
+                }
+            }
         }
     }
 }`
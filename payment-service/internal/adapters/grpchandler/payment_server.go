@@ -0,0 +1,142 @@
+package grpchandler
+
+import (
+	"context"
+	"contracts"
+	pb "contracts/pb/contracts/v1"
+	"errors"
+	"payment-service/internal/application/service"
+	"payment-service/internal/domain"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ErrorDomain — домен ошибок payment-service в деталях ошибки gRPC (errdetails.ErrorInfo).
+const ErrorDomain = "payment-service"
+
+// PaymentServer реализует gRPC-сервис PaymentService (pb.PaymentServiceServer)
+// поверх PaymentService и AccountService.
+type PaymentServer struct {
+	pb.UnimplementedPaymentServiceServer
+	paymentService *service.PaymentService
+	accountService *service.AccountService
+}
+
+// NewPaymentServer создаёт новый экземпляр PaymentServer.
+func NewPaymentServer(paymentService *service.PaymentService, accountService *service.AccountService) *PaymentServer {
+	return &PaymentServer{paymentService: paymentService, accountService: accountService}
+}
+
+// Charge проводит списание по запросу на оплату.
+// Отказ по бизнес-правилам и задержка до ручной проверки возвращаются в результате
+// со статусами contracts.PaymentDeclined и contracts.PaymentPendingReview,
+// остальные ошибки — кодами gRPC (см. statusError).
+func (s *PaymentServer) Charge(ctx context.Context, request *pb.PaymentRequest) (*pb.PaymentResult, error) {
+	if request.IsDeposit {
+		return nil, status.Error(codes.InvalidArgument, "charge must be a withdrawal")
+	}
+	payment := contracts.PaymentRequestFromProto(request)
+	err := s.paymentService.ProcessTransaction(ctx, domain.Transaction{
+		Id:        payment.Id,
+		UserId:    payment.UserId,
+		AccountId: payment.AccountId,
+		Amount:    payment.Amount,
+		Date:      payment.Date,
+	})
+	code := domain.DeclineReason(err)
+	if err != nil && code == "" {
+		return nil, statusError(err)
+	}
+	result := contracts.PaymentResult{TransactionId: payment.Id, Status: contracts.PaymentApproved}
+	if code != "" {
+		result.Status = contracts.PaymentDeclined
+		if code == domain.ReasonPendingReview {
+			result.Status = contracts.PaymentPendingReview
+		}
+		result.ErrorCode = contracts.ErrorCode(code)
+		result.Message = err.Error()
+	}
+	return contracts.PaymentResultToProto(result), nil
+}
+
+// Refund отменяет часть суммы проведённой транзакции и возвращает транзакцию отмены.
+func (s *PaymentServer) Refund(ctx context.Context, request *pb.RefundRequest) (*pb.RefundResponse, error) {
+	reason, err := domain.ParseReversalReason(request.Reason)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	reversal, err := s.paymentService.Reverse(ctx, int(request.TransactionId), request.Amount, reason)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &pb.RefundResponse{
+		TransactionId: int64(reversal.Id),
+		AccountId:     int64(reversal.AccountId),
+		IsDeposit:     reversal.IsDeposit,
+		Amount:        reversal.Amount,
+		Date:          timestamppb.New(reversal.Date),
+	}, nil
+}
+
+// GetBalance возвращает текущий баланс счёта.
+func (s *PaymentServer) GetBalance(ctx context.Context, request *pb.GetBalanceRequest) (*pb.Balance, error) {
+	account, err := s.accountService.GetAccount(ctx, int(request.AccountId))
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &pb.Balance{
+		AccountId:   int64(account.Id),
+		Currency:    account.Currency,
+		Balance:     account.Balance,
+		CreditLimit: account.CreditLimit,
+		Available:   account.AvailableFunds(),
+	}, nil
+}
+
+// GetAccount возвращает счёт по его идентификатору.
+func (s *PaymentServer) GetAccount(ctx context.Context, request *pb.GetAccountRequest) (*pb.Account, error) {
+	account, err := s.accountService.GetAccount(ctx, int(request.Id))
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &pb.Account{
+		Id:              int64(account.Id),
+		UserId:          int64(account.UserId),
+		Name:            account.Name,
+		Currency:        account.Currency,
+		IsDefault:       account.IsDefault,
+		Status:          string(account.Status),
+		Balance:         account.Balance,
+		CreditLimit:     account.CreditLimit,
+		GracePeriodDays: int32(account.GracePeriodDays),
+		CreationDate:    timestamppb.New(account.CreationDate),
+	}, nil
+}
+
+// statusError возвращает ошибку gRPC с кодом, соответствующим ошибке err.
+// Код причины отказа по бизнес-правилам передаётся в деталях ошибки (errdetails.ErrorInfo).
+func statusError(err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+	code := codes.Internal
+	switch {
+	case errors.Is(err, domain.ErrAccountNotFound), errors.Is(err, service.ErrTransactionNotFound):
+		code = codes.NotFound
+	case errors.Is(err, domain.ErrInvalidAmount):
+		code = codes.InvalidArgument
+	case domain.IsDecline(err), errors.Is(err, domain.ErrReversalExceeded):
+		code = codes.FailedPrecondition
+	}
+	st := status.New(code, err.Error())
+	if reason := domain.DeclineReason(err); reason != "" {
+		detailed, detailsErr := st.WithDetails(&errdetails.ErrorInfo{Reason: string(reason), Domain: ErrorDomain})
+		if detailsErr == nil {
+			st = detailed
+		}
+	}
+	return st.Err()
+}
@@ -0,0 +1,428 @@
+package grpchandler
+
+import (
+	"context"
+	pb "contracts/pb/contracts/v1"
+	"errors"
+	"math/rand"
+	"net"
+	"payment-service/internal/application/repository"
+	"payment-service/internal/application/service"
+	"payment-service/internal/domain"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type mockAccountRepository struct {
+	data map[int]domain.Account
+}
+
+func (m *mockAccountRepository) GetById(ctx context.Context, id int) (*domain.Account, error) {
+	acc, ok := m.data[id]
+	if !ok {
+		return nil, nil
+	}
+	return &acc, nil
+}
+
+func (m *mockAccountRepository) GetDefaultByUserId(ctx context.Context, userId int) (*domain.Account, error) {
+	for _, acc := range m.data {
+		if acc.UserId == userId && acc.IsDefault {
+			return &acc, nil
+		}
+	}
+	return nil, errors.New("user not found")
+}
+
+func (m *mockAccountRepository) GetUserAccounts(ctx context.Context, userId int) ([]domain.Account, error) {
+	accounts := make([]domain.Account, 0)
+	for _, acc := range m.data {
+		if acc.UserId == userId {
+			accounts = append(accounts, acc)
+		}
+	}
+	return accounts, nil
+}
+
+func (m *mockAccountRepository) GetAll(ctx context.Context) ([]domain.Account, error) {
+	accounts := make([]domain.Account, 0, len(m.data))
+	for _, acc := range m.data {
+		accounts = append(accounts, acc)
+	}
+	return accounts, nil
+}
+
+func (m *mockAccountRepository) SetDefault(ctx context.Context, userId int, accountId int) error {
+	for id, acc := range m.data {
+		if acc.UserId == userId {
+			acc.IsDefault = id == accountId
+			m.data[id] = acc
+		}
+	}
+	return nil
+}
+
+func (m *mockAccountRepository) Save(ctx context.Context, account *domain.Account) error {
+	m.data[account.Id] = *account
+	return nil
+}
+
+type mockTransactionRepository struct {
+	data map[int]domain.Transaction
+}
+
+func (m *mockTransactionRepository) Save(ctx context.Context, transaction *domain.Transaction) error {
+	if _, ok := m.data[transaction.Id]; ok {
+		return repository.ErrDuplicateTransaction
+	}
+	m.data[transaction.Id] = *transaction
+	return nil
+}
+
+func (m *mockTransactionRepository) GetById(ctx context.Context, id int) (*domain.Transaction, error) {
+	tx, ok := m.data[id]
+	if !ok {
+		return nil, nil
+	}
+	return &tx, nil
+}
+
+type mockTransactor struct{}
+
+func (m *mockTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (m *mockTransactionRepository) GetByAccountId(ctx context.Context, accountId int) ([]domain.Transaction, error) {
+	transactions := make([]domain.Transaction, 0)
+	for _, tx := range m.data {
+		if tx.AccountId == accountId {
+			transactions = append(transactions, tx)
+		}
+	}
+	return transactions, nil
+}
+
+func (m *mockTransactionRepository) GetSpendingActivity(ctx context.Context, accountId int, now time.Time) (*domain.SpendingActivity, error) {
+	dayStart, monthStart, hourStart := domain.SpendingWindows(now)
+	var activity domain.SpendingActivity
+	for _, tx := range m.data {
+		if tx.AccountId != accountId || tx.IsDeposit || tx.Type != domain.TransactionTypePayment || tx.Status != domain.TransactionStatusCompleted {
+			continue
+		}
+		if !tx.Date.Before(dayStart) {
+			activity.DailyTotal += tx.Amount
+		}
+		if !tx.Date.Before(monthStart) {
+			activity.MonthlyTotal += tx.Amount
+		}
+		if !tx.Date.Before(hourStart) {
+			activity.PaymentsLastHour++
+		}
+	}
+	return &activity, nil
+}
+
+func (m *mockTransactionRepository) GetCompletedBetween(ctx context.Context, accountId int, after, until time.Time) ([]domain.Transaction, error) {
+	return nil, nil
+}
+
+func (m *mockTransactionRepository) GetBalanceChange(ctx context.Context, accountId int, after, until time.Time) (float64, error) {
+	return 0, nil
+}
+
+func (m *mockTransactionRepository) GetOldestPendingReviewDate(ctx context.Context) (*time.Time, error) {
+	return nil, nil
+}
+
+func (m *mockTransactionRepository) CountDeclined(ctx context.Context, userId int, since time.Time) (int, error) {
+	count := 0
+	for _, tx := range m.data {
+		if tx.UserId == userId && tx.Status == domain.TransactionStatusDeclined && !tx.Date.Before(since) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *mockTransactionRepository) AddReversedAmount(ctx context.Context, id int, amount float64) error {
+	tx := m.data[id]
+	if tx.ReversedAmount+amount > tx.Amount {
+		return domain.ErrReversalExceeded
+	}
+	tx.ReversedAmount += amount
+	m.data[id] = tx
+	return nil
+}
+
+func (m *mockTransactionRepository) UpdateStatus(ctx context.Context, transaction *domain.Transaction) error {
+	m.data[transaction.Id] = *transaction
+	return nil
+}
+
+type mockSpendingLimitsRepository struct {
+	data map[int]domain.SpendingLimits
+}
+
+func (m *mockSpendingLimitsRepository) GetByAccountId(ctx context.Context, accountId int) (*domain.SpendingLimits, error) {
+	limits, ok := m.data[accountId]
+	if !ok {
+		return nil, nil
+	}
+	return &limits, nil
+}
+
+func (m *mockSpendingLimitsRepository) Save(ctx context.Context, limits *domain.SpendingLimits) error {
+	m.data[limits.AccountId] = *limits
+	return nil
+}
+
+func (m *mockSpendingLimitsRepository) Delete(ctx context.Context, accountId int) error {
+	delete(m.data, accountId)
+	return nil
+}
+
+type mockTransactionReviewRepository struct {
+	data map[int]domain.TransactionReview
+}
+
+func (m *mockTransactionReviewRepository) GetByTransactionId(ctx context.Context, transactionId int) (*domain.TransactionReview, error) {
+	review, ok := m.data[transactionId]
+	if !ok {
+		return nil, nil
+	}
+	return &review, nil
+}
+
+func (m *mockTransactionReviewRepository) GetPending(ctx context.Context) ([]domain.TransactionReview, error) {
+	reviews := make([]domain.TransactionReview, 0)
+	for _, review := range m.data {
+		if review.Status == domain.ReviewStatusPending {
+			reviews = append(reviews, review)
+		}
+	}
+	return reviews, nil
+}
+
+func (m *mockTransactionReviewRepository) Save(ctx context.Context, review *domain.TransactionReview) error {
+	m.data[review.TransactionId] = *review
+	return nil
+}
+
+type mockAccountStatusRepository struct{}
+
+func (m *mockAccountStatusRepository) Save(ctx context.Context, change *domain.AccountStatusChange) error {
+	return nil
+}
+
+func (m *mockAccountStatusRepository) GetByAccountId(ctx context.Context, accountId int) ([]domain.AccountStatusChange, error) {
+	return nil, nil
+}
+
+type mockAccountEventRepository struct{}
+
+func (m *mockAccountEventRepository) Save(ctx context.Context, event *domain.AccountEvent) error {
+	return nil
+}
+
+func (m *mockAccountEventRepository) GetUnpublished(ctx context.Context, limit int) ([]domain.AccountEvent, error) {
+	return nil, nil
+}
+
+func (m *mockAccountEventRepository) MarkPublished(ctx context.Context, ids []int64, at time.Time) error {
+	return nil
+}
+
+type mockBalanceSnapshotRepository struct{}
+
+func (m *mockBalanceSnapshotRepository) GetLatest(ctx context.Context, accountId int, at time.Time) (*domain.BalanceSnapshot, error) {
+	return nil, nil
+}
+
+func (m *mockBalanceSnapshotRepository) Save(ctx context.Context, snapshot *domain.BalanceSnapshot) error {
+	return nil
+}
+
+func (m *mockBalanceSnapshotRepository) DeleteFrom(ctx context.Context, accountId int, from time.Time) error {
+	return nil
+}
+
+func setupTestEnv(t *testing.T) (context.Context, *service.PaymentService, *service.AccountService) {
+	t.Helper()
+	ctx := context.Background()
+	accDb := &mockAccountRepository{data: make(map[int]domain.Account)}
+	txDb := &mockTransactionRepository{data: make(map[int]domain.Transaction)}
+	limitsDb := &mockSpendingLimitsRepository{data: make(map[int]domain.SpendingLimits)}
+	reviewDb := &mockTransactionReviewRepository{data: make(map[int]domain.TransactionReview)}
+	paymentService, _ := service.NewPaymentService(accDb, txDb, limitsDb, reviewDb, &mockAccountEventRepository{}, &mockBalanceSnapshotRepository{}, &mockTransactor{}, domain.DefaultRiskRules())
+	accService := service.NewAccountService(accDb, txDb, &mockAccountStatusRepository{}, limitsDb, &mockAccountEventRepository{}, &mockTransactor{})
+	return ctx, paymentService, accService
+}
+
+// deposit зачисляет amount на счёт acc входящим переводом через платёжный сервис.
+func deposit(ctx context.Context, paymentService *service.PaymentService, acc *domain.Account, amount float64) error {
+	return paymentService.Deposit(ctx, domain.Transaction{
+		Id:        rand.Intn(2147483645),
+		UserId:    acc.UserId,
+		AccountId: acc.Id,
+		IsDeposit: true,
+		Amount:    amount,
+		Date:      time.Now(),
+	})
+}
+
+// newTestClient запускает server на соединении в памяти и возвращает подключённый к нему клиент.
+func newTestClient(t *testing.T, server *PaymentServer) pb.PaymentServiceClient {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer()
+	pb.RegisterPaymentServiceServer(grpcServer, server)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("error connecting to server: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewPaymentServiceClient(conn)
+}
+
+// errorReason возвращает код причины из деталей ошибки gRPC.
+func errorReason(err error) string {
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return info.Reason
+		}
+	}
+	return ""
+}
+
+func TestPaymentServer_Charge(t *testing.T) {
+	ctx, paymentService, accService := setupTestEnv(t)
+	acc, _ := accService.CreateAccount(ctx, 123)
+	err := deposit(ctx, paymentService, acc, 1000)
+	if err != nil {
+		t.Fatalf("error depositing account: %v", err)
+	}
+	client := newTestClient(t, NewPaymentServer(paymentService, accService))
+	request := &pb.PaymentRequest{Id: 1, UserId: 123, Amount: 300, Date: timestamppb.Now()}
+
+	for range 2 {
+		result, err := client.Charge(ctx, request)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Status != pb.PaymentStatus_PAYMENT_STATUS_APPROVED || result.TransactionId != 1 {
+			t.Errorf("expected approved transaction 1, got %v", result)
+		}
+	}
+	balance, err := client.GetBalance(ctx, &pb.GetBalanceRequest{AccountId: int64(acc.Id)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if balance.Balance != 700 {
+		t.Errorf("expected balance 700 after a repeated charge, got %v", balance.Balance)
+	}
+}
+
+func TestPaymentServer_ChargeDeclined(t *testing.T) {
+	ctx, paymentService, accService := setupTestEnv(t)
+	acc, _ := accService.CreateAccount(ctx, 123)
+	err := deposit(ctx, paymentService, acc, 100)
+	if err != nil {
+		t.Fatalf("error depositing account: %v", err)
+	}
+	client := newTestClient(t, NewPaymentServer(paymentService, accService))
+
+	result, err := client.Charge(ctx, &pb.PaymentRequest{Id: 1, UserId: 123, Amount: 125, Date: timestamppb.Now()})
+	if err != nil {
+		t.Fatalf("decline must be returned without error, got %v", err)
+	}
+	if result.Status != pb.PaymentStatus_PAYMENT_STATUS_DECLINED {
+		t.Errorf("expected DECLINED, got %s", result.Status)
+	}
+	if result.ErrorCode != string(domain.ReasonInsufficientFunds) {
+		t.Errorf("expected error code %s, got %q", domain.ReasonInsufficientFunds, result.ErrorCode)
+	}
+
+	_, err = client.Charge(ctx, &pb.PaymentRequest{Id: 2, UserId: 123, IsDeposit: true, Amount: 10})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for a deposit, got %v", err)
+	}
+}
+
+func TestPaymentServer_Refund(t *testing.T) {
+	ctx, paymentService, accService := setupTestEnv(t)
+	acc, _ := accService.CreateAccount(ctx, 123)
+	err := deposit(ctx, paymentService, acc, 1000)
+	if err != nil {
+		t.Fatalf("error depositing account: %v", err)
+	}
+	client := newTestClient(t, NewPaymentServer(paymentService, accService))
+	_, err = client.Charge(ctx, &pb.PaymentRequest{Id: 1, UserId: 123, Amount: 300, Date: timestamppb.Now()})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	refund, err := client.Refund(ctx, &pb.RefundRequest{TransactionId: 1, Amount: 100, Reason: string(domain.ReversalCustomerRequest)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !refund.IsDeposit || refund.Amount != 100 || refund.AccountId != int64(acc.Id) {
+		t.Errorf("unexpected refund %v", refund)
+	}
+
+	tests := []struct {
+		name    string
+		request *pb.RefundRequest
+		code    codes.Code
+	}{
+		{"exceeds remaining amount", &pb.RefundRequest{TransactionId: 1, Amount: 250, Reason: "FRAUD"}, codes.FailedPrecondition},
+		{"unknown transaction", &pb.RefundRequest{TransactionId: 42, Amount: 10, Reason: "FRAUD"}, codes.NotFound},
+		{"unknown reason", &pb.RefundRequest{TransactionId: 1, Amount: 10, Reason: "GIFT"}, codes.InvalidArgument},
+		{"invalid amount", &pb.RefundRequest{TransactionId: 1, Amount: -5, Reason: "FRAUD"}, codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.Refund(ctx, tt.request)
+			if status.Code(err) != tt.code {
+				t.Errorf("expected %s, got %v", tt.code, err)
+			}
+		})
+	}
+}
+
+func TestPaymentServer_GetAccount(t *testing.T) {
+	ctx, paymentService, accService := setupTestEnv(t)
+	acc, _ := accService.CreateAccount(ctx, 123)
+	client := newTestClient(t, NewPaymentServer(paymentService, accService))
+
+	account, err := client.GetAccount(ctx, &pb.GetAccountRequest{Id: int64(acc.Id)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if account.UserId != 123 || account.Name != acc.Name || account.Status != string(acc.Status) {
+		t.Errorf("unexpected account %v", account)
+	}
+
+	_, err = client.GetAccount(ctx, &pb.GetAccountRequest{Id: 42})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound, got %v", err)
+	}
+	if reason := errorReason(err); reason != string(domain.ReasonAccountNotFound) {
+		t.Errorf("expected reason %s, got %q", domain.ReasonAccountNotFound, reason)
+	}
+}
@@ -3,11 +3,14 @@ package httphandler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"payment-service/internal/application/service"
+	"payment-service/internal/domain"
 	"strconv"
+	"time"
 )
 
 type AccountHandler struct {
@@ -43,43 +46,16 @@ func (h *AccountHandler) GetAccount(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	err = json.NewEncoder(w).Encode(account)
+	err = json.NewEncoder(w).Encode(newAccountResponse(account))
 	if err != nil {
 		log.Printf("Failed to encode account to JSON: %v", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
 
-// Deposit godoc
-// @Param id path int true "account id"
-// @Param amount body DepositRequest true "Deposit amount"
-// @Success 200 {object} interface{}
-// @Router /accounts/{id} [patch]
-func (h *AccountHandler) Deposit(w http.ResponseWriter, r *http.Request) {
-	idStr := r.PathValue("id")
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		http.Error(w, "Invalid ID format", http.StatusBadRequest)
-		return
-	}
-	depositRequest := DepositRequest{}
-	err = json.NewDecoder(r.Body).Decode(&depositRequest)
-	if err != nil {
-		http.Error(w, "Invalid input format", http.StatusBadRequest)
-		return
-	}
-
-	err = h.accountService.Deposit(h.ctx, id, depositRequest.Amount)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
-		return
-	}
-	w.WriteHeader(http.StatusOK)
-}
-
 // CreateAccount godoc
 // @Summary Create account
-// @Description Creates a new account
+// @Description Creates a new wallet for user. The first wallet of the user becomes default
 // @Param data body CreateAccountRequest true "Account info"
 // @Success 201 {object} interface{}
 // @Router /accounts [post]
@@ -90,12 +66,13 @@ func (h *AccountHandler) CreateAccount(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid input format", http.StatusBadRequest)
 		return
 	}
-	account, err := h.accountService.CreateAccount(h.ctx, createRequest.UserId)
+	account, err := h.accountService.CreateWallet(h.ctx, createRequest.UserId, createRequest.Name,
+		createRequest.Currency, createRequest.IsDefault)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusConflict)
 		return
 	}
-	err = json.NewEncoder(w).Encode(account)
+	err = json.NewEncoder(w).Encode(newAccountResponse(account))
 	if err != nil {
 		log.Printf("Failed to encode account to JSON: %v", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -103,10 +80,11 @@ func (h *AccountHandler) CreateAccount(w http.ResponseWriter, r *http.Request) {
 }
 
 // GetUsersAccount
-// @Summary Get users account by id
+// @Summary Get users wallets by id
+// @Description Returns all wallets of the user, the default wallet goes first
 // @Param        id   path      int  true  "User ID"
-// @Param data body GetByUserIdRequest true "Get by users id"
-// @Success 201 {object} interface{}
+// @Success 200 {array} AccountResponse
+// @Failure 404 {object} interface{}
 // @Router /users/{id}/account [get]
 func (h *AccountHandler) GetUsersAccount(w http.ResponseWriter, r *http.Request) {
 	userId, err := getIntPathValue(r, "id")
@@ -114,18 +92,271 @@ func (h *AccountHandler) GetUsersAccount(w http.ResponseWriter, r *http.Request)
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	account, err := h.accountService.GetUsersAccount(h.ctx, userId)
+	accounts, err := h.accountService.GetUsersAccounts(h.ctx, userId)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusNotFound)
 		return
 	}
-	err = json.NewEncoder(w).Encode(account)
+	response := make([]AccountResponse, 0, len(accounts))
+	for i := range accounts {
+		response = append(response, newAccountResponse(&accounts[i]))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(response)
 	if err != nil {
 		log.Printf("Failed to encode account to JSON: %v", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
 
+// SetDefaultAccount godoc
+// @Summary      Make wallet default
+// @Description  Marks wallet as the default one for its owner
+// @Tags         accounts
+// @Param        id   path      int  true  "Account ID"
+// @Produce      json
+// @Success      200  {object}  AccountResponse
+// @Failure      404  {object}  interface{}
+// @Router       /accounts/{id}/default [put]
+func (h *AccountHandler) SetDefaultAccount(w http.ResponseWriter, r *http.Request) {
+	id, err := getIntPathValue(r, "id")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	account, err := h.accountService.SetDefaultAccount(h.ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(newAccountResponse(account))
+	if err != nil {
+		log.Printf("Failed to encode account to JSON: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// SetCreditLimit godoc
+// @Summary      Установить кредитный лимит
+// @Description  Задаёт кредитный лимит и беспроцентный льготный период для счёта (административный метод).
+// @Description  Если grace_period_days не передан, льготный период не меняется.
+// @Tags         admin
+// @Param        id    path  int                 true  "Account ID"
+// @Param        data  body  CreditLimitRequest  true  "Credit limit"
+// @Produce      json
+// @Success      200  {object}  AccountResponse
+// @Failure      400  {object}  interface{}
+// @Failure      404  {object}  interface{}
+// @Router       /admin/accounts/{id}/credit-limit [put]
+func (h *AccountHandler) SetCreditLimit(w http.ResponseWriter, r *http.Request) {
+	id, err := getIntPathValue(r, "id")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limitRequest := CreditLimitRequest{}
+	err = json.NewDecoder(r.Body).Decode(&limitRequest)
+	if err != nil {
+		http.Error(w, "Invalid input format", http.StatusBadRequest)
+		return
+	}
+
+	account, err := h.accountService.SetCreditLimit(h.ctx, id, limitRequest.CreditLimit, limitRequest.GracePeriodDays)
+	switch {
+	case errors.Is(err, domain.ErrInvalidCreditLimit):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, domain.ErrAccountNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(newAccountResponse(account))
+	if err != nil {
+		log.Printf("Failed to encode account to JSON: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// ChangeAccountStatus godoc
+// @Summary      Изменить статус счёта
+// @Description  Замораживает, приостанавливает, активирует или закрывает счёт (административный метод).
+// @Description  Закрыть можно только счёт с нулевым балансом; при payout=true положительный остаток выплачивается перед закрытием.
+// @Tags         admin
+// @Param        id    path  int                   true  "Account ID"
+// @Param        data  body  ChangeStatusRequest   true  "New status"
+// @Produce      json
+// @Success      200  {object}  AccountResponse
+// @Failure      400  {object}  interface{}
+// @Failure      409  {object}  interface{}
+// @Router       /admin/accounts/{id}/status [put]
+func (h *AccountHandler) ChangeAccountStatus(w http.ResponseWriter, r *http.Request) {
+	id, err := getIntPathValue(r, "id")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	statusRequest := ChangeStatusRequest{}
+	err = json.NewDecoder(r.Body).Decode(&statusRequest)
+	if err != nil {
+		http.Error(w, "Invalid input format", http.StatusBadRequest)
+		return
+	}
+	status, err := domain.ParseAccountStatus(statusRequest.Status)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if statusRequest.ChangedBy == "" || statusRequest.Reason == "" {
+		http.Error(w, "changed_by and reason are required", http.StatusBadRequest)
+		return
+	}
+
+	account, err := h.accountService.ChangeStatus(h.ctx, id, status, statusRequest.ChangedBy,
+		statusRequest.Reason, statusRequest.Payout)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(newAccountResponse(account))
+	if err != nil {
+		log.Printf("Failed to encode account to JSON: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// GetStatusHistory godoc
+// @Summary      История статусов счёта
+// @Description  Возвращает журнал изменений статуса счёта с авторами и причинами (административный метод)
+// @Tags         admin
+// @Param        id   path  int  true  "Account ID"
+// @Produce      json
+// @Success      200  {array}   domain.AccountStatusChange
+// @Failure      404  {object}  interface{}
+// @Router       /admin/accounts/{id}/status-history [get]
+func (h *AccountHandler) GetStatusHistory(w http.ResponseWriter, r *http.Request) {
+	id, err := getIntPathValue(r, "id")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	history, err := h.accountService.GetStatusHistory(h.ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(history)
+	if err != nil {
+		log.Printf("Failed to encode status history to JSON: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// GetSpendingLimits godoc
+// @Summary      Ограничения на списания
+// @Description  Возвращает ограничения на платежи со счёта; нулевое значение означает, что ограничение не действует (административный метод)
+// @Tags         admin
+// @Param        id   path  int  true  "Account ID"
+// @Produce      json
+// @Success      200  {object}  domain.SpendingLimits
+// @Failure      404  {object}  interface{}
+// @Router       /admin/accounts/{id}/limits [get]
+func (h *AccountHandler) GetSpendingLimits(w http.ResponseWriter, r *http.Request) {
+	id, err := getIntPathValue(r, "id")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limits, err := h.accountService.GetSpendingLimits(h.ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(limits)
+	if err != nil {
+		log.Printf("Failed to encode spending limits to JSON: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// SetSpendingLimits godoc
+// @Summary      Установить ограничения на списания
+// @Description  Задаёт максимальную сумму платежа, дневной и месячный лимиты и максимальное число платежей в час.
+// @Description  Нулевое значение снимает соответствующее ограничение (административный метод)
+// @Tags         admin
+// @Param        id    path  int                    true  "Account ID"
+// @Param        data  body  SpendingLimitsRequest  true  "Spending limits"
+// @Produce      json
+// @Success      200  {object}  domain.SpendingLimits
+// @Failure      400  {object}  interface{}
+// @Failure      404  {object}  interface{}
+// @Router       /admin/accounts/{id}/limits [put]
+func (h *AccountHandler) SetSpendingLimits(w http.ResponseWriter, r *http.Request) {
+	id, err := getIntPathValue(r, "id")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var limitsRequest SpendingLimitsRequest
+	err = json.NewDecoder(r.Body).Decode(&limitsRequest)
+	if err != nil {
+		http.Error(w, "Invalid input format", http.StatusBadRequest)
+		return
+	}
+	limits := domain.SpendingLimits{
+		MaxPayment:         limitsRequest.MaxPayment,
+		DailyLimit:         limitsRequest.DailyLimit,
+		MonthlyLimit:       limitsRequest.MonthlyLimit,
+		MaxPaymentsPerHour: limitsRequest.MaxPaymentsPerHour,
+	}
+	err = limits.Validate()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	saved, err := h.accountService.SetSpendingLimits(h.ctx, id, limits)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(saved)
+	if err != nil {
+		log.Printf("Failed to encode spending limits to JSON: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// RemoveSpendingLimits godoc
+// @Summary      Снять ограничения на списания
+// @Description  Удаляет все ограничения на платежи со счёта (административный метод)
+// @Tags         admin
+// @Param        id   path  int  true  "Account ID"
+// @Success      204
+// @Failure      400  {object}  interface{}
+// @Router       /admin/accounts/{id}/limits [delete]
+func (h *AccountHandler) RemoveSpendingLimits(w http.ResponseWriter, r *http.Request) {
+	id, err := getIntPathValue(r, "id")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = h.accountService.RemoveSpendingLimits(h.ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func getIntPathValue(r *http.Request, key string) (int, error) {
 	valueStr := r.PathValue(key)
 	if valueStr == "" {
@@ -138,14 +369,49 @@ func getIntPathValue(r *http.Request, key string) (int, error) {
 	return valueInt, nil
 }
 
+// AccountResponse — представление счёта в HTTP-ответах.
+// Помимо полей счёта содержит вычисляемые данные о кредите: доступные средства
+// и состояние льготного периода, чтобы отрицательный баланс был явно виден клиенту.
+type AccountResponse struct {
+	domain.Account
+	AvailableFunds     float64    `json:"available_funds"`
+	InOverdraft        bool       `json:"in_overdraft"`
+	GracePeriodEnd     *time.Time `json:"grace_period_end"`
+	GracePeriodExpired bool       `json:"grace_period_expired"`
+}
+
+func newAccountResponse(account *domain.Account) AccountResponse {
+	return AccountResponse{
+		Account:            *account,
+		AvailableFunds:     account.AvailableFunds(),
+		InOverdraft:        account.InOverdraft(),
+		GracePeriodEnd:     account.GracePeriodEnd(),
+		GracePeriodExpired: account.IsGracePeriodExpired(time.Now()),
+	}
+}
+
 type CreateAccountRequest struct {
-	UserId int `json:"user_id"`
+	UserId    int    `json:"user_id"`
+	Name      string `json:"name"`
+	Currency  string `json:"currency"`
+	IsDefault bool   `json:"is_default"`
+}
+
+type CreditLimitRequest struct {
+	CreditLimit     float64 `json:"credit_limit"`
+	GracePeriodDays *int    `json:"grace_period_days,omitempty"` // Без значения льготный период не меняется
 }
 
-type DepositRequest struct {
-	Amount float64 `json:"amount"`
+type SpendingLimitsRequest struct {
+	MaxPayment         float64 `json:"max_payment"`
+	DailyLimit         float64 `json:"daily_limit"`
+	MonthlyLimit       float64 `json:"monthly_limit"`
+	MaxPaymentsPerHour int     `json:"max_payments_per_hour"`
 }
 
-type GetByUserIdRequest struct {
-	UserId int `json:"user_id"`
+type ChangeStatusRequest struct {
+	Status    string `json:"status"`
+	ChangedBy string `json:"changed_by"`
+	Reason    string `json:"reason"`
+	Payout    bool   `json:"payout"`
 }
@@ -5,12 +5,15 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"math/rand"
 	"net/http"
 	"net/http/httptest"
 	"payment-service/internal/application/service"
 	"payment-service/internal/domain"
+	"sort"
 	"strconv"
 	"testing"
+	"time"
 )
 
 type mockAccountRepository struct {
@@ -20,42 +23,253 @@ type mockAccountRepository struct {
 func (m *mockAccountRepository) GetById(ctx context.Context, id int) (*domain.Account, error) {
 	acc, ok := m.data[id]
 	if !ok {
-		return nil, errors.New("id not found")
+		return nil, domain.ErrAccountNotFound
 	}
 	return &acc, nil
 }
 
-func (m *mockAccountRepository) GetByUserId(ctx context.Context, userId int) (*domain.Account, error) {
+func (m *mockAccountRepository) GetDefaultByUserId(ctx context.Context, userId int) (*domain.Account, error) {
 	for _, acc := range m.data {
-		if acc.UserId == userId {
+		if acc.UserId == userId && acc.IsDefault {
 			return &acc, nil
 		}
 	}
 	return nil, errors.New("user not found")
 }
 
+func (m *mockAccountRepository) GetUserAccounts(ctx context.Context, userId int) ([]domain.Account, error) {
+	accounts := make([]domain.Account, 0)
+	for _, acc := range m.data {
+		if acc.UserId == userId {
+			accounts = append(accounts, acc)
+		}
+	}
+	return accounts, nil
+}
+
+func (m *mockAccountRepository) GetAll(ctx context.Context) ([]domain.Account, error) {
+	accounts := make([]domain.Account, 0, len(m.data))
+	for _, acc := range m.data {
+		accounts = append(accounts, acc)
+	}
+	return accounts, nil
+}
+
+func (m *mockAccountRepository) SetDefault(ctx context.Context, userId int, accountId int) error {
+	for id, acc := range m.data {
+		if acc.UserId == userId {
+			acc.IsDefault = id == accountId
+			m.data[id] = acc
+		}
+	}
+	return nil
+}
+
 func (m *mockAccountRepository) Save(ctx context.Context, account *domain.Account) error {
 	m.data[account.Id] = *account
 	return nil
 }
 
+type mockTransactionRepository struct {
+	data map[int]domain.Transaction
+}
+
+func (m *mockTransactionRepository) Save(ctx context.Context, transaction *domain.Transaction) error {
+	m.data[transaction.Id] = *transaction
+	return nil
+}
+
+func (m *mockTransactionRepository) GetById(ctx context.Context, id int) (*domain.Transaction, error) {
+	tx, ok := m.data[id]
+	if !ok {
+		return nil, nil
+	}
+	return &tx, nil
+}
+
+func (m *mockTransactionRepository) GetByAccountId(ctx context.Context, accountId int) ([]domain.Transaction, error) {
+	transactions := make([]domain.Transaction, 0)
+	for _, tx := range m.data {
+		if tx.AccountId == accountId {
+			transactions = append(transactions, tx)
+		}
+	}
+	return transactions, nil
+}
+
+func (m *mockTransactionRepository) GetSpendingActivity(ctx context.Context, accountId int, now time.Time) (*domain.SpendingActivity, error) {
+	dayStart, monthStart, hourStart := domain.SpendingWindows(now)
+	var activity domain.SpendingActivity
+	for _, tx := range m.data {
+		if tx.AccountId != accountId || tx.IsDeposit || tx.Type != domain.TransactionTypePayment || tx.Status != domain.TransactionStatusCompleted {
+			continue
+		}
+		if !tx.Date.Before(dayStart) {
+			activity.DailyTotal += tx.Amount
+		}
+		if !tx.Date.Before(monthStart) {
+			activity.MonthlyTotal += tx.Amount
+		}
+		if !tx.Date.Before(hourStart) {
+			activity.PaymentsLastHour++
+		}
+	}
+	return &activity, nil
+}
+
+func (m *mockTransactionRepository) GetCompletedBetween(ctx context.Context, accountId int, after, until time.Time) ([]domain.Transaction, error) {
+	result := make([]domain.Transaction, 0)
+	for _, tx := range m.data {
+		if tx.AccountId == accountId && tx.Status == domain.TransactionStatusCompleted && tx.Date.After(after) && !tx.Date.After(until) {
+			result = append(result, tx)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Date.Before(result[j].Date) })
+	return result, nil
+}
+
+func (m *mockTransactionRepository) GetBalanceChange(ctx context.Context, accountId int, after, until time.Time) (float64, error) {
+	var change float64
+	for _, tx := range m.data {
+		if tx.AccountId != accountId || tx.Status != domain.TransactionStatusCompleted || !tx.Date.After(after) || tx.Date.After(until) {
+			continue
+		}
+		if tx.IsDeposit {
+			change += tx.Amount
+		} else {
+			change -= tx.Amount
+		}
+	}
+	return change, nil
+}
+
+func (m *mockTransactionRepository) GetOldestPendingReviewDate(ctx context.Context) (*time.Time, error) {
+	return nil, nil
+}
+
+func (m *mockTransactionRepository) CountDeclined(ctx context.Context, userId int, since time.Time) (int, error) {
+	return 0, nil
+}
+
+func (m *mockTransactionRepository) AddReversedAmount(ctx context.Context, id int, amount float64) error {
+	tx := m.data[id]
+	if tx.ReversedAmount+amount > tx.Amount {
+		return domain.ErrReversalExceeded
+	}
+	tx.ReversedAmount += amount
+	m.data[id] = tx
+	return nil
+}
+
+func (m *mockTransactionRepository) UpdateStatus(ctx context.Context, transaction *domain.Transaction) error {
+	m.data[transaction.Id] = *transaction
+	return nil
+}
+
+type mockSpendingLimitsRepository struct {
+	data map[int]domain.SpendingLimits
+}
+
+func (m *mockSpendingLimitsRepository) GetByAccountId(ctx context.Context, accountId int) (*domain.SpendingLimits, error) {
+	limits, ok := m.data[accountId]
+	if !ok {
+		return nil, nil
+	}
+	return &limits, nil
+}
+
+func (m *mockSpendingLimitsRepository) Save(ctx context.Context, limits *domain.SpendingLimits) error {
+	m.data[limits.AccountId] = *limits
+	return nil
+}
+
+func (m *mockSpendingLimitsRepository) Delete(ctx context.Context, accountId int) error {
+	delete(m.data, accountId)
+	return nil
+}
+
+type mockAccountStatusRepository struct {
+	changes []domain.AccountStatusChange
+}
+
+func (m *mockAccountStatusRepository) Save(ctx context.Context, change *domain.AccountStatusChange) error {
+	m.changes = append(m.changes, *change)
+	return nil
+}
+
+func (m *mockAccountStatusRepository) GetByAccountId(ctx context.Context, accountId int) ([]domain.AccountStatusChange, error) {
+	result := make([]domain.AccountStatusChange, 0)
+	for _, change := range m.changes {
+		if change.AccountId == accountId {
+			result = append(result, change)
+		}
+	}
+	return result, nil
+}
+
+type mockAccountEventRepository struct{}
+
+func (m *mockAccountEventRepository) Save(ctx context.Context, event *domain.AccountEvent) error {
+	return nil
+}
+
+func (m *mockAccountEventRepository) GetUnpublished(ctx context.Context, limit int) ([]domain.AccountEvent, error) {
+	return nil, nil
+}
+
+func (m *mockAccountEventRepository) MarkPublished(ctx context.Context, ids []int64, at time.Time) error {
+	return nil
+}
+
 // --- Тесты ---
 
 func setupTestEnv(t *testing.T) (context.Context, *service.AccountService) {
+	t.Helper()
+	ctx, accService, _ := setupPaymentEnv(t)
+	return ctx, accService
+}
+
+// setupPaymentEnv собирает сервисы счетов и платежей над общими репозиториями,
+// чтобы пополнять счета в тестах через платёжный сервис.
+func setupPaymentEnv(t *testing.T) (context.Context, *service.AccountService, *service.PaymentService) {
 	t.Helper()
 	ctx := context.Background()
 	accDb := &mockAccountRepository{data: make(map[int]domain.Account)}
-	accService := service.NewAccountService(accDb)
-	return ctx, accService
+	txDb := &mockTransactionRepository{data: make(map[int]domain.Transaction)}
+	statusDb := &mockAccountStatusRepository{}
+	limitsDb := &mockSpendingLimitsRepository{data: make(map[int]domain.SpendingLimits)}
+	accService := service.NewAccountService(accDb, txDb, statusDb, limitsDb, &mockAccountEventRepository{}, &mockTransactor{})
+	paymentService, err := service.NewPaymentService(accDb, txDb, limitsDb, &mockTransactionReviewRepository{},
+		&mockAccountEventRepository{}, &mockBalanceSnapshotRepository{}, &mockTransactor{}, domain.RiskRules{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ctx, accService, paymentService
+}
+
+// deposit зачисляет amount на счёт acc входящим переводом через платёжный сервис.
+func deposit(t *testing.T, ctx context.Context, paymentService *service.PaymentService, acc *domain.Account, amount float64) {
+	t.Helper()
+	err := paymentService.Deposit(ctx, domain.Transaction{
+		Id:        rand.Intn(2147483645),
+		UserId:    acc.UserId,
+		AccountId: acc.Id,
+		IsDeposit: true,
+		Amount:    amount,
+		Date:      time.Now(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
 }
 
 func TestGetAccount_Success(t *testing.T) {
-	ctx, accService := setupTestEnv(t)
+	ctx, accService, paymentService := setupPaymentEnv(t)
 	acc, err := accService.CreateAccount(ctx, 1)
 	if err != nil {
 		t.Fatal(err)
 	}
-	_ = accService.Deposit(ctx, acc.Id, 100)
+	deposit(t, ctx, paymentService, acc, 100)
 	handler := NewAccountHandler(context.Background(), accService)
 	req := httptest.NewRequest(http.MethodGet, "/accounts/", nil)
 	req.SetPathValue("id", strconv.Itoa(acc.Id))
@@ -120,58 +334,76 @@ func TestGetUsersAccount_Success(t *testing.T) {
 	}
 }
 
-func TestGetUsersAccount_NoAccount(t *testing.T) {
-	_, accService := setupTestEnv(t)
+func TestGetUsersAccount_MultipleWallets(t *testing.T) {
+	ctx, accService := setupTestEnv(t)
+	_, err := accService.CreateAccount(ctx, 123)
+	if err != nil {
+		t.Fatalf("error creating account: %v", err)
+	}
+	_, err = accService.CreateWallet(ctx, 123, "bonus", "RUB", false)
+	if err != nil {
+		t.Fatalf("error creating wallet: %v", err)
+	}
 	handler := NewAccountHandler(context.Background(), accService)
 	req := httptest.NewRequest(http.MethodGet, "/users/{id}/account", nil)
 	req.SetPathValue("id", "123")
 	w := httptest.NewRecorder()
 	handler.GetUsersAccount(w, req)
-	if w.Code != http.StatusNotFound {
-		t.Errorf("expected 404, got %d", w.Code)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	var wallets []AccountResponse
+	if err := json.NewDecoder(w.Body).Decode(&wallets); err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if len(wallets) != 2 {
+		t.Fatalf("expected 2 wallets, got %d", len(wallets))
+	}
+	defaults := 0
+	for _, wallet := range wallets {
+		if wallet.IsDefault {
+			defaults++
+		}
+	}
+	if defaults != 1 {
+		t.Errorf("expected exactly one default wallet, got %d", defaults)
 	}
 }
 
-func TestDeposit_Success(t *testing.T) {
+func TestSetDefaultAccount_Success(t *testing.T) {
 	ctx, accService := setupTestEnv(t)
-	account, err := accService.CreateAccount(ctx, 123)
+	main, err := accService.CreateAccount(ctx, 123)
 	if err != nil {
-		t.Errorf("error creating account: %v", err)
+		t.Fatal(err)
+	}
+	bonus, err := accService.CreateWallet(ctx, 123, "bonus", "RUB", false)
+	if err != nil {
+		t.Fatal(err)
 	}
 	handler := NewAccountHandler(context.Background(), accService)
-
-	body := bytes.NewBufferString(`{"amount": 25}`)
-	req := httptest.NewRequest(http.MethodPatch, "/accounts/", body)
-	req.SetPathValue("id", strconv.Itoa(account.Id))
+	req := httptest.NewRequest(http.MethodPut, "/accounts/{id}/default", nil)
+	req.SetPathValue("id", strconv.Itoa(bonus.Id))
 	w := httptest.NewRecorder()
-
-	handler.Deposit(w, req)
-
+	handler.SetDefaultAccount(w, req)
 	if w.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d", w.Code)
 	}
-	account, err = accService.GetAccount(ctx, account.Id)
-	if err != nil {
-		t.Errorf("error getting account: %v", err)
-	}
-	if account.Balance != 25 {
-		t.Errorf("expected balance 25, got %v", account.Balance)
+	main, _ = accService.GetAccount(ctx, main.Id)
+	bonus, _ = accService.GetAccount(ctx, bonus.Id)
+	if main.IsDefault || !bonus.IsDefault {
+		t.Errorf("default wallet not switched: main=%v bonus=%v", main.IsDefault, bonus.IsDefault)
 	}
 }
 
-func TestDeposit_NoAccount(t *testing.T) {
+func TestGetUsersAccount_NoAccount(t *testing.T) {
 	_, accService := setupTestEnv(t)
 	handler := NewAccountHandler(context.Background(), accService)
-
-	body := bytes.NewBufferString(`{"amount": 25}`)
-	req := httptest.NewRequest(http.MethodPatch, "/accounts/", body)
+	req := httptest.NewRequest(http.MethodGet, "/users/{id}/account", nil)
 	req.SetPathValue("id", "123")
 	w := httptest.NewRecorder()
-
-	handler.Deposit(w, req)
-
+	handler.GetUsersAccount(w, req)
 	if w.Code != http.StatusNotFound {
-		t.Fatalf("expected 404, got %d", w.Code)
+		t.Errorf("expected 404, got %d", w.Code)
 	}
 }
 
@@ -204,12 +436,12 @@ func TestCreateAccount_Success(t *testing.T) {
 }
 
 func TestCreateAccount_AlreadyExists(t *testing.T) {
-	ctx, accService := setupTestEnv(t)
+	ctx, accService, paymentService := setupPaymentEnv(t)
 	acc, err := accService.CreateAccount(ctx, 123)
 	if err != nil {
 		t.Fatal(err)
 	}
-	_ = accService.Deposit(ctx, acc.Id, 100)
+	deposit(t, ctx, paymentService, acc, 100)
 	jsonReq, err := json.Marshal(CreateAccountRequest{UserId: 123})
 	if err != nil {
 		t.Fatal(err)
@@ -233,6 +465,166 @@ func TestCreateAccount_AlreadyExists(t *testing.T) {
 	}
 }
 
+func TestSetCreditLimit_Success(t *testing.T) {
+	ctx, accService := setupTestEnv(t)
+	account, err := accService.CreateAccount(ctx, 123)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := NewAccountHandler(context.Background(), accService)
+
+	body := bytes.NewBufferString(`{"credit_limit": 500, "grace_period_days": 14}`)
+	req := httptest.NewRequest(http.MethodPut, "/admin/accounts/", body)
+	req.SetPathValue("id", strconv.Itoa(account.Id))
+	w := httptest.NewRecorder()
+
+	handler.SetCreditLimit(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	var resp AccountResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if resp.CreditLimit != 500 || resp.GracePeriodDays != 14 {
+		t.Errorf("unexpected credit settings: %+v", resp)
+	}
+	if resp.AvailableFunds != 500 {
+		t.Errorf("expected available funds 500, got %v", resp.AvailableFunds)
+	}
+}
+
+func TestSetCreditLimit_NegativeLimit(t *testing.T) {
+	ctx, accService := setupTestEnv(t)
+	account, err := accService.CreateAccount(ctx, 123)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := NewAccountHandler(context.Background(), accService)
+
+	body := bytes.NewBufferString(`{"credit_limit": -1}`)
+	req := httptest.NewRequest(http.MethodPut, "/admin/accounts/", body)
+	req.SetPathValue("id", strconv.Itoa(account.Id))
+	w := httptest.NewRecorder()
+
+	handler.SetCreditLimit(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestSetCreditLimit_KeepsGracePeriod(t *testing.T) {
+	ctx, accService := setupTestEnv(t)
+	account, err := accService.CreateAccount(ctx, 123)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := NewAccountHandler(context.Background(), accService)
+
+	for _, body := range []string{`{"credit_limit": 500, "grace_period_days": 14}`, `{"credit_limit": 700}`} {
+		req := httptest.NewRequest(http.MethodPut, "/admin/accounts/", bytes.NewBufferString(body))
+		req.SetPathValue("id", strconv.Itoa(account.Id))
+		w := httptest.NewRecorder()
+		handler.SetCreditLimit(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", w.Code)
+		}
+	}
+
+	account, err = accService.GetAccount(ctx, account.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account.CreditLimit != 700 || account.GracePeriodDays != 14 {
+		t.Errorf("expected limit 700 with grace period 14 kept, got %+v", account)
+	}
+}
+
+func TestSetCreditLimit_AccountNotFound(t *testing.T) {
+	_, accService := setupTestEnv(t)
+	handler := NewAccountHandler(context.Background(), accService)
+
+	req := httptest.NewRequest(http.MethodPut, "/admin/accounts/", bytes.NewBufferString(`{"credit_limit": 500}`))
+	req.SetPathValue("id", "999999")
+	w := httptest.NewRecorder()
+	handler.SetCreditLimit(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+}
+
+func TestChangeAccountStatus_CloseWithPayout(t *testing.T) {
+	ctx, accService, paymentService := setupPaymentEnv(t)
+	account, err := accService.CreateAccount(ctx, 123)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deposit(t, ctx, paymentService, account, 40)
+	handler := NewAccountHandler(context.Background(), accService)
+
+	body := bytes.NewBufferString(`{"status": "closed", "changed_by": "admin", "reason": "customer request", "payout": true}`)
+	req := httptest.NewRequest(http.MethodPut, "/admin/accounts/", body)
+	req.SetPathValue("id", strconv.Itoa(account.Id))
+	w := httptest.NewRecorder()
+	handler.ChangeAccountStatus(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	account, _ = accService.GetAccount(ctx, account.Id)
+	if account.Status != domain.AccountStatusClosed || account.Balance != 0 {
+		t.Errorf("account not closed: %+v", account)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/admin/accounts/", nil)
+	req.SetPathValue("id", strconv.Itoa(account.Id))
+	w = httptest.NewRecorder()
+	handler.GetStatusHistory(w, req)
+	var history []domain.AccountStatusChange
+	if err := json.NewDecoder(w.Body).Decode(&history); err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if len(history) != 1 || history[0].ChangedBy != "admin" || history[0].Reason != "customer request" {
+		t.Errorf("unexpected history: %+v", history)
+	}
+}
+
+func TestChangeAccountStatus_CloseWithoutPayout(t *testing.T) {
+	ctx, accService, paymentService := setupPaymentEnv(t)
+	account, err := accService.CreateAccount(ctx, 123)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deposit(t, ctx, paymentService, account, 40)
+	handler := NewAccountHandler(context.Background(), accService)
+
+	body := bytes.NewBufferString(`{"status": "closed", "changed_by": "admin", "reason": "customer request"}`)
+	req := httptest.NewRequest(http.MethodPut, "/admin/accounts/", body)
+	req.SetPathValue("id", strconv.Itoa(account.Id))
+	w := httptest.NewRecorder()
+	handler.ChangeAccountStatus(w, req)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d", w.Code)
+	}
+}
+
+func TestChangeAccountStatus_InvalidStatus(t *testing.T) {
+	_, accService := setupTestEnv(t)
+	handler := NewAccountHandler(context.Background(), accService)
+
+	body := bytes.NewBufferString(`{"status": "deleted", "changed_by": "admin", "reason": "test"}`)
+	req := httptest.NewRequest(http.MethodPut, "/admin/accounts/", body)
+	req.SetPathValue("id", "1")
+	w := httptest.NewRecorder()
+	handler.ChangeAccountStatus(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
+
 func TestGetIntPathValue(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/accounts/10", nil)
 	req.SetPathValue("id", "10")
@@ -251,3 +643,63 @@ func TestGetIntPathValue(t *testing.T) {
 		t.Error("expected error for invalid int format")
 	}
 }
+
+func TestSpendingLimits_SetGetRemove(t *testing.T) {
+	ctx, accService := setupTestEnv(t)
+	account, err := accService.CreateAccount(ctx, 123)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := NewAccountHandler(context.Background(), accService)
+
+	body := bytes.NewBufferString(`{"max_payment": 500, "daily_limit": 1000, "max_payments_per_hour": 3}`)
+	req := httptest.NewRequest(http.MethodPut, "/admin/accounts/", body)
+	req.SetPathValue("id", strconv.Itoa(account.Id))
+	w := httptest.NewRecorder()
+	handler.SetSpendingLimits(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/admin/accounts/", nil)
+	req.SetPathValue("id", strconv.Itoa(account.Id))
+	w = httptest.NewRecorder()
+	handler.GetSpendingLimits(w, req)
+	var limits domain.SpendingLimits
+	if err := json.NewDecoder(w.Body).Decode(&limits); err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if limits.MaxPayment != 500 || limits.DailyLimit != 1000 || limits.MaxPaymentsPerHour != 3 {
+		t.Errorf("unexpected limits: %+v", limits)
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/admin/accounts/", nil)
+	req.SetPathValue("id", strconv.Itoa(account.Id))
+	w = httptest.NewRecorder()
+	handler.RemoveSpendingLimits(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", w.Code)
+	}
+	removed, _ := accService.GetSpendingLimits(ctx, account.Id)
+	if removed.MaxPayment != 0 || !removed.UpdatedAt.Equal(time.Time{}) {
+		t.Errorf("expected limits to be removed, got %+v", removed)
+	}
+}
+
+func TestSetSpendingLimits_Negative(t *testing.T) {
+	ctx, accService := setupTestEnv(t)
+	account, err := accService.CreateAccount(ctx, 123)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := NewAccountHandler(context.Background(), accService)
+
+	body := bytes.NewBufferString(`{"daily_limit": -1}`)
+	req := httptest.NewRequest(http.MethodPut, "/admin/accounts/", body)
+	req.SetPathValue("id", strconv.Itoa(account.Id))
+	w := httptest.NewRecorder()
+	handler.SetSpendingLimits(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
@@ -0,0 +1,125 @@
+package httphandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"payment-service/internal/adapters/report"
+	"payment-service/internal/application/service"
+	"payment-service/internal/domain"
+	"time"
+)
+
+type BalanceHandler struct {
+	balanceService *service.BalanceService
+	ctx            context.Context
+}
+
+func NewBalanceHandler(ctx context.Context, balanceService *service.BalanceService) *BalanceHandler {
+	return &BalanceHandler{balanceService: balanceService, ctx: ctx}
+}
+
+// GetBalance godoc
+// @Summary      Баланс счёта на момент времени
+// @Description  Возвращает баланс счёта на момент at, рассчитанный по проведённым транзакциям с датой не позже at.
+// @Description  Без параметра at возвращает баланс на текущий момент
+// @Tags         accounts
+// @Param        id  path   int     true   "Account ID"
+// @Param        at  query  string  false  "RFC 3339 timestamp, e.g. 2025-10-31T23:59:59Z"
+// @Produce      json
+// @Success      200  {object}  domain.AccountBalance
+// @Failure      400  {object}  interface{}
+// @Failure      404  {object}  interface{}
+// @Router       /accounts/{id}/balance [get]
+func (h *BalanceHandler) GetBalance(w http.ResponseWriter, r *http.Request) {
+	id, err := getIntPathValue(r, "id")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	at, err := getTimeQueryValue(r, "at")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	balance, err := h.balanceService.GetBalanceAt(h.ctx, id, at)
+	switch {
+	case errors.Is(err, service.ErrFutureBalanceTime):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, domain.ErrAccountNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(balance)
+	if err != nil {
+		log.Printf("Failed to encode balance to JSON: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// GetBalances godoc
+// @Summary      Балансы всех счетов на момент времени
+// @Description  Отчёт на конец периода: балансы всех счетов, открытых не позже момента at, в формате JSON или CSV (административный метод)
+// @Tags         admin
+// @Param        at      query  string  false  "RFC 3339 timestamp, e.g. 2025-10-31T23:59:59Z"
+// @Param        format  query  string  false  "json (default) or csv"
+// @Produce      json
+// @Produce      text/csv
+// @Success      200  {array}   domain.AccountBalance
+// @Failure      400  {object}  interface{}
+// @Router       /admin/balances [get]
+func (h *BalanceHandler) GetBalances(w http.ResponseWriter, r *http.Request) {
+	at, err := getTimeQueryValue(r, "at")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = report.FormatJSON
+	}
+	if format != report.FormatJSON && format != report.FormatCSV {
+		http.Error(w, fmt.Sprintf("unsupported report format: %q", format), http.StatusBadRequest)
+		return
+	}
+	balances, err := h.balanceService.GetBalancesAt(h.ctx, at)
+	if errors.Is(err, service.ErrFutureBalanceTime) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if format == report.FormatCSV {
+		w.Header().Set("Content-Type", "text/csv")
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	err = report.WriteBalances(w, format, balances)
+	if err != nil {
+		log.Printf("Failed to write balances report: %v", err)
+	}
+}
+
+// getTimeQueryValue разбирает момент времени в формате RFC 3339 из параметра запроса key.
+// Если параметр не задан, возвращает текущее время.
+func getTimeQueryValue(r *http.Request, key string) (time.Time, error) {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return time.Now(), nil
+	}
+	at, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s format, RFC 3339 expected", key)
+	}
+	return at, nil
+}
@@ -0,0 +1,74 @@
+package httphandler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"payment-service/internal/application/service"
+	"payment-service/internal/domain"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type mockBalanceSnapshotRepository struct{}
+
+func (m *mockBalanceSnapshotRepository) GetLatest(ctx context.Context, accountId int, at time.Time) (*domain.BalanceSnapshot, error) {
+	return nil, nil
+}
+
+func (m *mockBalanceSnapshotRepository) Save(ctx context.Context, snapshot *domain.BalanceSnapshot) error {
+	return nil
+}
+
+func (m *mockBalanceSnapshotRepository) DeleteFrom(ctx context.Context, accountId int, from time.Time) error {
+	return nil
+}
+
+func TestGetBalance_AtTime(t *testing.T) {
+	ctx := context.Background()
+	monthEnd := time.Date(2025, 9, 30, 23, 59, 59, 0, time.UTC)
+	accDb := &mockAccountRepository{data: map[int]domain.Account{
+		1: {Id: 1, UserId: 10, Currency: "RUB", Balance: 150, Status: domain.AccountStatusActive, CreationDate: monthEnd.AddDate(0, -1, 0)},
+	}}
+	txDb := &mockTransactionRepository{data: map[int]domain.Transaction{
+		1: {Id: 1, AccountId: 1, IsDeposit: true, Amount: 100, Status: domain.TransactionStatusCompleted, Date: monthEnd.AddDate(0, 0, -3)},
+		2: {Id: 2, AccountId: 1, IsDeposit: true, Amount: 50, Status: domain.TransactionStatusCompleted, Date: monthEnd.AddDate(0, 0, 3)},
+	}}
+	handler := NewBalanceHandler(ctx, service.NewBalanceService(accDb, txDb, &mockBalanceSnapshotRepository{}))
+
+	getBalance := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/accounts/1/balance"+query, nil)
+		req.SetPathValue("id", strconv.Itoa(1))
+		w := httptest.NewRecorder()
+		handler.GetBalance(w, req)
+		return w
+	}
+
+	w := getBalance("?at=2025-09-30T23:59:59Z")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var balance domain.AccountBalance
+	if err := json.NewDecoder(w.Body).Decode(&balance); err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if balance.Balance != 100 {
+		t.Errorf("expected balance 100 at month end, got %.2f", balance.Balance)
+	}
+	if w = getBalance("?at=yesterday"); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for invalid time, got %d", w.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/balances?at=2025-09-30T23:59:59Z&format=csv", nil)
+	w = httptest.NewRecorder()
+	handler.GetBalances(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), "1,10,RUB,100.00,2025-09-30T23:59:59Z") {
+		t.Errorf("unexpected report: %q", w.Body.String())
+	}
+}
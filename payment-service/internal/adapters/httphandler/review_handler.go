@@ -0,0 +1,115 @@
+package httphandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"payment-service/internal/application/service"
+	"payment-service/internal/domain"
+)
+
+type ReviewHandler struct {
+	paymentService *service.PaymentService
+	ctx            context.Context
+}
+
+func NewReviewHandler(ctx context.Context, paymentService *service.PaymentService) *ReviewHandler {
+	return &ReviewHandler{paymentService: paymentService, ctx: ctx}
+}
+
+// GetPendingReviews godoc
+// @Summary      Очередь ручной проверки
+// @Description  Возвращает операции, задержанные правилами проверки на мошенничество, вместе со сработавшими правилами (административный метод)
+// @Tags         admin
+// @Produce      json
+// @Success      200  {array}   domain.TransactionReview
+// @Failure      500  {object}  interface{}
+// @Router       /admin/reviews [get]
+func (h *ReviewHandler) GetPendingReviews(w http.ResponseWriter, r *http.Request) {
+	reviews, err := h.paymentService.GetPendingReviews(h.ctx)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(reviews)
+	if err != nil {
+		log.Printf("Failed to encode reviews to JSON: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// ApproveReview godoc
+// @Summary      Разрешить задержанную операцию
+// @Description  Проводит задержанную операцию. Лимиты и баланс проверяются заново, поэтому операция может быть отклонённой (административный метод)
+// @Tags         admin
+// @Param        id    path  int            true  "Transaction ID"
+// @Param        data  body  ReviewRequest  true  "Decision"
+// @Produce      json
+// @Success      200  {object}  domain.Transaction
+// @Failure      400  {object}  interface{}
+// @Failure      404  {object}  interface{}
+// @Failure      409  {object}  interface{}
+// @Router       /admin/reviews/{id}/approve [post]
+func (h *ReviewHandler) ApproveReview(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, h.paymentService.ApproveReview)
+}
+
+// RejectReview godoc
+// @Summary      Отклонить задержанную операцию
+// @Description  Отклоняет задержанную операцию с причиной REJECTED_BY_REVIEW; баланс счёта не меняется (административный метод)
+// @Tags         admin
+// @Param        id    path  int            true  "Transaction ID"
+// @Param        data  body  ReviewRequest  true  "Decision"
+// @Produce      json
+// @Success      200  {object}  domain.Transaction
+// @Failure      400  {object}  interface{}
+// @Failure      404  {object}  interface{}
+// @Failure      409  {object}  interface{}
+// @Router       /admin/reviews/{id}/reject [post]
+func (h *ReviewHandler) RejectReview(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, h.paymentService.RejectReview)
+}
+
+// decide разбирает запрос с решением администратора и применяет его через decision.
+func (h *ReviewHandler) decide(w http.ResponseWriter, r *http.Request,
+	decision func(ctx context.Context, transactionId int, decidedBy, comment string) (*domain.Transaction, error)) {
+	id, err := getIntPathValue(r, "id")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	reviewRequest := ReviewRequest{}
+	err = json.NewDecoder(r.Body).Decode(&reviewRequest)
+	if err != nil {
+		http.Error(w, "Invalid input format", http.StatusBadRequest)
+		return
+	}
+	if reviewRequest.DecidedBy == "" {
+		http.Error(w, "decided_by is required", http.StatusBadRequest)
+		return
+	}
+
+	transaction, err := decision(h.ctx, id, reviewRequest.DecidedBy, reviewRequest.Comment)
+	if errors.Is(err, service.ErrReviewNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(transaction)
+	if err != nil {
+		log.Printf("Failed to encode transaction to JSON: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+type ReviewRequest struct {
+	DecidedBy string `json:"decided_by"`
+	Comment   string `json:"comment"`
+}
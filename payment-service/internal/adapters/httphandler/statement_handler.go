@@ -0,0 +1,83 @@
+package httphandler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"payment-service/internal/adapters/report"
+	"payment-service/internal/application/service"
+	"payment-service/internal/domain"
+	"time"
+)
+
+// statementContentTypes — типы содержимого выписок по форматам.
+var statementContentTypes = map[string]string{
+	report.FormatCSV: "text/csv",
+	report.FormatOFX: "application/x-ofx",
+}
+
+type StatementHandler struct {
+	statementService *service.StatementService
+	ctx              context.Context
+}
+
+func NewStatementHandler(ctx context.Context, statementService *service.StatementService) *StatementHandler {
+	return &StatementHandler{statementService: statementService, ctx: ctx}
+}
+
+// GetStatement godoc
+// @Summary      Выписка по счёту за месяц
+// @Description  Возвращает выписку за календарный месяц (UTC): баланс на начало, проведённые транзакции и баланс на конец.
+// @Description  Выписка за текущий месяц составляется на текущий момент
+// @Tags         accounts
+// @Param        id      path   int     true   "Account ID"
+// @Param        month   path   string  true   "Month, e.g. 2025-10"
+// @Param        format  query  string  false  "csv (default) or ofx"
+// @Produce      text/csv
+// @Produce      application/x-ofx
+// @Success      200  {file}    file
+// @Failure      400  {object}  interface{}
+// @Failure      404  {object}  interface{}
+// @Router       /accounts/{id}/statements/{month} [get]
+func (h *StatementHandler) GetStatement(w http.ResponseWriter, r *http.Request) {
+	id, err := getIntPathValue(r, "id")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	month, err := time.Parse(domain.StatementMonthFormat, r.PathValue("month"))
+	if err != nil {
+		http.Error(w, "invalid month format, YYYY-MM expected", http.StatusBadRequest)
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = report.FormatCSV
+	}
+	contentType, ok := statementContentTypes[format]
+	if !ok {
+		http.Error(w, fmt.Sprintf("unsupported statement format: %q", format), http.StatusBadRequest)
+		return
+	}
+
+	statement, err := h.statementService.GetStatement(h.ctx, id, month)
+	switch {
+	case errors.Is(err, service.ErrFutureBalanceTime):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, domain.ErrAccountNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, report.StatementFileName(*statement, format)))
+	err = report.WriteStatement(w, format, *statement)
+	if err != nil {
+		log.Printf("Failed to write statement: %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package httphandler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"payment-service/internal/application/service"
+	"payment-service/internal/domain"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetStatement(t *testing.T) {
+	ctx := context.Background()
+	accDb := &mockAccountRepository{data: map[int]domain.Account{
+		1: {Id: 1, UserId: 10, Currency: "RUB", Status: domain.AccountStatusActive, CreationDate: time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)},
+	}}
+	txDb := &mockTransactionRepository{data: map[int]domain.Transaction{
+		1: {Id: 1, AccountId: 1, IsDeposit: true, Type: domain.TransactionTypeTopUp, Amount: 100, Status: domain.TransactionStatusCompleted,
+			Date: time.Date(2025, 8, 20, 0, 0, 0, 0, time.UTC)},
+		2: {Id: 2, AccountId: 1, Type: domain.TransactionTypePayment, Amount: 40, Status: domain.TransactionStatusCompleted,
+			Date: time.Date(2025, 9, 3, 0, 0, 0, 0, time.UTC)},
+	}}
+	balanceService := service.NewBalanceService(accDb, txDb, &mockBalanceSnapshotRepository{})
+	handler := NewStatementHandler(ctx, service.NewStatementService(accDb, txDb, balanceService))
+
+	getStatement := func(month, format string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/accounts/1/statements/"+month+"?format="+format, nil)
+		req.SetPathValue("id", "1")
+		req.SetPathValue("month", month)
+		w := httptest.NewRecorder()
+		handler.GetStatement(w, req)
+		return w
+	}
+
+	w := getStatement("2025-09", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w.Header().Get("Content-Disposition") != `attachment; filename="statement-1-2025-09.csv"` {
+		t.Errorf("unexpected Content-Disposition: %q", w.Header().Get("Content-Disposition"))
+	}
+	if !strings.Contains(w.Body.String(), ",opening_balance,,100.00,RUB") || !strings.Contains(w.Body.String(), ",closing_balance,,60.00,RUB") {
+		t.Errorf("unexpected statement: %s", w.Body.String())
+	}
+
+	w = getStatement("2025-09", "ofx")
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/x-ofx" {
+		t.Errorf("expected OFX statement, got %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	if w = getStatement("september", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for invalid month, got %d", w.Code)
+	}
+	if w = getStatement("2025-09", "pdf"); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for unsupported format, got %d", w.Code)
+	}
+}
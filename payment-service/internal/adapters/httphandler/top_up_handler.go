@@ -0,0 +1,171 @@
+package httphandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"payment-service/internal/application/provider"
+	"payment-service/internal/application/service"
+	"payment-service/internal/domain"
+)
+
+// maxCallbackSize — максимальный размер тела уведомления провайдера.
+const maxCallbackSize = 1 << 16
+
+type TopUpHandler struct {
+	topUpService *service.TopUpService
+	ctx          context.Context
+}
+
+func NewTopUpHandler(ctx context.Context, topUpService *service.TopUpService) *TopUpHandler {
+	return &TopUpHandler{topUpService: topUpService, ctx: ctx}
+}
+
+// CreateTopUp godoc
+// @Summary      Пополнить счёт
+// @Description  Создаёт пополнение через платёжного провайдера. Баланс меняется только после подтверждения оплаты провайдером;
+// @Description  для подтверждения пользователь переходит по confirmation_url
+// @Tags         accounts
+// @Param        id      path  int           true  "Account ID"
+// @Param        amount  body  TopUpRequest  true  "Top-up amount"
+// @Produce      json
+// @Success      202  {object}  domain.TopUp
+// @Failure      400  {object}  interface{}
+// @Failure      404  {object}  interface{}
+// @Failure      409  {object}  interface{}
+// @Router       /accounts/{id} [patch]
+func (h *TopUpHandler) CreateTopUp(w http.ResponseWriter, r *http.Request) {
+	id, err := getIntPathValue(r, "id")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	topUpRequest := TopUpRequest{}
+	err = json.NewDecoder(r.Body).Decode(&topUpRequest)
+	if err != nil {
+		http.Error(w, "Invalid input format", http.StatusBadRequest)
+		return
+	}
+
+	topUp, err := h.topUpService.CreateTopUp(h.ctx, id, topUpRequest.Amount)
+	switch {
+	case errors.Is(err, domain.ErrInvalidAmount):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, domain.ErrAccountNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, domain.ErrOperationNotAllowed):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	err = json.NewEncoder(w).Encode(topUp)
+	if err != nil {
+		log.Printf("Failed to encode top-up to JSON: %v", err)
+	}
+}
+
+// GetAccountTopUps godoc
+// @Summary      Пополнения счёта
+// @Description  Возвращает все пополнения кошелька, включая ожидающие подтверждения и неуспешные
+// @Tags         accounts
+// @Param        id   path  int  true  "Account ID"
+// @Produce      json
+// @Success      200  {array}   domain.TopUp
+// @Failure      400  {object}  interface{}
+// @Router       /accounts/{id}/top-ups [get]
+func (h *TopUpHandler) GetAccountTopUps(w http.ResponseWriter, r *http.Request) {
+	id, err := getIntPathValue(r, "id")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	topUps, err := h.topUpService.GetAccountTopUps(h.ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.writeTopUps(w, topUps)
+}
+
+// GetTopUps godoc
+// @Summary      Пополнения по статусу
+// @Description  Возвращает пополнения в указанном статусе, по умолчанию — ожидающие подтверждения (административный метод)
+// @Tags         admin
+// @Param        status  query  string  false  "pending, succeeded or failed"
+// @Produce      json
+// @Success      200  {array}   domain.TopUp
+// @Failure      400  {object}  interface{}
+// @Router       /admin/top-ups [get]
+func (h *TopUpHandler) GetTopUps(w http.ResponseWriter, r *http.Request) {
+	status := domain.TopUpStatusPending
+	if r.URL.Query().Has("status") {
+		var err error
+		status, err = domain.ParseTopUpStatus(r.URL.Query().Get("status"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	topUps, err := h.topUpService.GetTopUpsByStatus(h.ctx, status)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.writeTopUps(w, topUps)
+}
+
+// ProviderCallback godoc
+// @Summary      Уведомление платёжного провайдера
+// @Description  Принимает подписанное уведомление провайдера о результате оплаты пополнения.
+// @Description  Подпись тела запроса передаётся в заголовке X-Signature
+// @Tags         payments
+// @Param        data  body  provider.Callback  true  "Callback"
+// @Success      200
+// @Failure      401  {object}  interface{}
+// @Failure      404  {object}  interface{}
+// @Failure      409  {object}  interface{}
+// @Router       /payments/callback [post]
+func (h *TopUpHandler) ProviderCallback(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxCallbackSize))
+	if err != nil {
+		http.Error(w, "Invalid input format", http.StatusBadRequest)
+		return
+	}
+	topUp, err := h.topUpService.HandleCallback(h.ctx, body, r.Header.Get(provider.SignatureHeader))
+	switch {
+	case errors.Is(err, provider.ErrInvalidSignature):
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	case errors.Is(err, service.ErrTopUpNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	log.Printf("top-up %d for account %d is %s", topUp.Id, topUp.AccountId, topUp.Status)
+	w.WriteHeader(http.StatusOK)
+}
+
+// writeTopUps отправляет список пополнений в формате JSON.
+func (h *TopUpHandler) writeTopUps(w http.ResponseWriter, topUps []domain.TopUp) {
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(topUps)
+	if err != nil {
+		log.Printf("Failed to encode top-ups to JSON: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+type TopUpRequest struct {
+	Amount float64 `json:"amount"`
+}
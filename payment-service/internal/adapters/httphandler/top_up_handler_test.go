@@ -0,0 +1,104 @@
+package httphandler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"payment-service/internal/application/provider"
+	"payment-service/internal/application/service"
+	"payment-service/internal/domain"
+	"payment-service/internal/infrastructure/fakeprovider"
+	"strconv"
+	"testing"
+)
+
+type mockTopUpRepository struct {
+	data map[int]domain.TopUp
+}
+
+func (m *mockTopUpRepository) GetById(ctx context.Context, id int) (*domain.TopUp, error) {
+	topUp, ok := m.data[id]
+	if !ok {
+		return nil, nil
+	}
+	return &topUp, nil
+}
+
+func (m *mockTopUpRepository) GetByAccountId(ctx context.Context, accountId int) ([]domain.TopUp, error) {
+	result := make([]domain.TopUp, 0)
+	for _, topUp := range m.data {
+		if topUp.AccountId == accountId {
+			result = append(result, topUp)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockTopUpRepository) GetByStatus(ctx context.Context, status domain.TopUpStatus) ([]domain.TopUp, error) {
+	result := make([]domain.TopUp, 0)
+	for _, topUp := range m.data {
+		if topUp.Status == status {
+			result = append(result, topUp)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockTopUpRepository) Save(ctx context.Context, topUp *domain.TopUp) error {
+	m.data[topUp.Id] = *topUp
+	return nil
+}
+
+func TestTopUp_CreateAndCallback(t *testing.T) {
+	ctx := context.Background()
+	accDb := &mockAccountRepository{data: map[int]domain.Account{
+		1: {Id: 1, UserId: 10, Balance: 100, IsDefault: true, Status: domain.AccountStatusActive},
+	}}
+	txDb := &mockTransactionRepository{data: make(map[int]domain.Transaction)}
+	paymentProvider := fakeprovider.New("secret", "", "http://localhost")
+	topUpService, err := service.NewTopUpService(&mockTopUpRepository{data: make(map[int]domain.TopUp)}, accDb, txDb,
+		&mockAccountEventRepository{}, &mockTransactor{}, paymentProvider)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	handler := NewTopUpHandler(ctx, topUpService)
+
+	req := httptest.NewRequest(http.MethodPatch, "/accounts/", bytes.NewBufferString(`{"amount": 50}`))
+	req.SetPathValue("id", strconv.Itoa(1))
+	w := httptest.NewRecorder()
+	handler.CreateTopUp(w, req)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+	var topUp domain.TopUp
+	if err := json.NewDecoder(w.Body).Decode(&topUp); err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if topUp.Status != domain.TopUpStatusPending || accDb.data[1].Balance != 100 {
+		t.Fatalf("balance must not change before payment is confirmed: %+v", topUp)
+	}
+
+	body, _ := json.Marshal(provider.Callback{TopUpId: topUp.Id, Reference: topUp.ProviderRef, Succeeded: true})
+	callback := func(signature string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/payments/callback", bytes.NewBuffer(body))
+		req.Header.Set(provider.SignatureHeader, signature)
+		w := httptest.NewRecorder()
+		handler.ProviderCallback(w, req)
+		return w
+	}
+
+	if w = callback(fakeprovider.New("other", "", "").Sign(body)); w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for forged signature, got %d", w.Code)
+	}
+	if accDb.data[1].Balance != 100 {
+		t.Fatalf("forged callback changed balance: %.2f", accDb.data[1].Balance)
+	}
+	if w = callback(paymentProvider.Sign(body)); w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if accDb.data[1].Balance != 150 {
+		t.Errorf("expected balance 150, got %.2f", accDb.data[1].Balance)
+	}
+}
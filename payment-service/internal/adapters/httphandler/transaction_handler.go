@@ -0,0 +1,109 @@
+package httphandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"payment-service/internal/application/service"
+	"payment-service/internal/domain"
+)
+
+type TransactionHandler struct {
+	paymentService *service.PaymentService
+	ctx            context.Context
+}
+
+func NewTransactionHandler(ctx context.Context, paymentService *service.PaymentService) *TransactionHandler {
+	return &TransactionHandler{paymentService: paymentService, ctx: ctx}
+}
+
+// GetTransaction godoc
+// @Summary      Получить транзакцию
+// @Description  Возвращает транзакцию по ID; reversed_amount показывает уже отменённую часть суммы (административный метод)
+// @Tags         admin
+// @Param        id   path  int  true  "Transaction ID"
+// @Produce      json
+// @Success      200  {object}  domain.Transaction
+// @Failure      404  {object}  interface{}
+// @Router       /admin/transactions/{id} [get]
+func (h *TransactionHandler) GetTransaction(w http.ResponseWriter, r *http.Request) {
+	id, err := getIntPathValue(r, "id")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	transaction, err := h.paymentService.GetTransaction(h.ctx, id)
+	if errors.Is(err, service.ErrTransactionNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(transaction)
+	if err != nil {
+		log.Printf("Failed to encode transaction to JSON: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// ReverseTransaction godoc
+// @Summary      Отменить транзакцию
+// @Description  Полностью или частично отменяет проведённую транзакцию: создаёт связанную транзакцию обратного направления.
+// @Description  Сумма отмены не может превышать ещё не отменённую часть транзакции (административный метод)
+// @Tags         admin
+// @Param        id    path  int              true  "Transaction ID"
+// @Param        data  body  ReversalRequest  true  "Reversal"
+// @Produce      json
+// @Success      201  {object}  domain.Transaction
+// @Failure      400  {object}  interface{}
+// @Failure      404  {object}  interface{}
+// @Failure      409  {object}  interface{}
+// @Router       /admin/transactions/{id}/reversals [post]
+func (h *TransactionHandler) ReverseTransaction(w http.ResponseWriter, r *http.Request) {
+	id, err := getIntPathValue(r, "id")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	reversalRequest := ReversalRequest{}
+	err = json.NewDecoder(r.Body).Decode(&reversalRequest)
+	if err != nil {
+		http.Error(w, "Invalid input format", http.StatusBadRequest)
+		return
+	}
+	reason, err := domain.ParseReversalReason(reversalRequest.Reason)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	reversal, err := h.paymentService.Reverse(h.ctx, id, reversalRequest.Amount, reason)
+	if errors.Is(err, service.ErrTransactionNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, domain.ErrInvalidAmount) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(reversal)
+	if err != nil {
+		log.Printf("Failed to encode transaction to JSON: %v", err)
+	}
+}
+
+type ReversalRequest struct {
+	Amount float64 `json:"amount"`
+	Reason string  `json:"reason"`
+}
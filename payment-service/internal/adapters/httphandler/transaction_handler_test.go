@@ -0,0 +1,83 @@
+package httphandler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"payment-service/internal/application/service"
+	"payment-service/internal/domain"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type mockTransactionReviewRepository struct{}
+
+func (m *mockTransactionReviewRepository) GetByTransactionId(ctx context.Context, transactionId int) (*domain.TransactionReview, error) {
+	return nil, nil
+}
+
+func (m *mockTransactionReviewRepository) GetPending(ctx context.Context) ([]domain.TransactionReview, error) {
+	return nil, nil
+}
+
+func (m *mockTransactionReviewRepository) Save(ctx context.Context, review *domain.TransactionReview) error {
+	return nil
+}
+
+type mockTransactor struct{}
+
+func (m *mockTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func TestReverseTransaction(t *testing.T) {
+	ctx := context.Background()
+	accDb := &mockAccountRepository{data: map[int]domain.Account{
+		1: {Id: 1, UserId: 10, Balance: 700, IsDefault: true, Status: domain.AccountStatusActive},
+	}}
+	txDb := &mockTransactionRepository{data: map[int]domain.Transaction{
+		5: {Id: 5, UserId: 10, AccountId: 1, Type: domain.TransactionTypePayment, Amount: 300,
+			Status: domain.TransactionStatusCompleted, Date: time.Now()},
+	}}
+	limitsDb := &mockSpendingLimitsRepository{data: make(map[int]domain.SpendingLimits)}
+	paymentService, _ := service.NewPaymentService(accDb, txDb, limitsDb, &mockTransactionReviewRepository{},
+		&mockAccountEventRepository{}, &mockBalanceSnapshotRepository{}, &mockTransactor{}, domain.RiskRules{})
+	handler := NewTransactionHandler(ctx, paymentService)
+
+	reverse := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/admin/transactions/", bytes.NewBufferString(body))
+		req.SetPathValue("id", strconv.Itoa(5))
+		w := httptest.NewRecorder()
+		handler.ReverseTransaction(w, req)
+		return w
+	}
+
+	w := reverse(`{"amount": 100, "reason": "CUSTOMER_REQUEST"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if w = reverse(`{"amount": 100, "reason": "UNKNOWN"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for unknown reason, got %d", w.Code)
+	}
+	if w = reverse(`{"amount": 250, "reason": "FRAUD"}`); w.Code != http.StatusConflict {
+		t.Errorf("expected 409 for exceeding reversal, got %d", w.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/transactions/", nil)
+	req.SetPathValue("id", strconv.Itoa(5))
+	w = httptest.NewRecorder()
+	handler.GetTransaction(w, req)
+	var original domain.Transaction
+	if err := json.NewDecoder(w.Body).Decode(&original); err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if original.ReversedAmount != 100 {
+		t.Errorf("expected reversed amount 100, got %.2f", original.ReversedAmount)
+	}
+	if accDb.data[1].Balance != 800 {
+		t.Errorf("expected balance 800, got %.2f", accDb.data[1].Balance)
+	}
+}
@@ -2,32 +2,157 @@ package kafkahandler
 
 import (
 	"context"
-	"encoding/json"
-	"github.com/segmentio/kafka-go"
+	"contracts"
+	"contracts/signing"
+	"contracts/transport"
+	"errors"
+	"fmt"
 	"payment-service/internal/application/service"
 	"payment-service/internal/domain"
 )
 
-// NewPaymentHandler возвращает функцию-обработчик Kafka-сообщений,
-// которая десериализует JSON-тело сообщения в структуру domain.Transaction,
-// передаёт её в PaymentService для обработки,
-// и возвращает Kafka-ответ с результатом ("OK" или текст ошибки).
+// ReasonCodeHeader — заголовок ответа в прежнем формате с кодом причины отказа (domain.ReasonCode).
+// Передаётся только вместе с отказом по бизнес-правилам.
+const ReasonCodeHeader = "reason-code"
+
+// CorrelationIdHeader — заголовок с идентификатором корреляции запроса.
+// Возвращается в ответе без изменений, чтобы отправитель сопоставил ответ с запросом.
+const CorrelationIdHeader = "correlation-id"
+
+// ErrInvalidMessage возвращается для сообщения, которое не удалось разобрать.
+// Повторная обработка такого сообщения не поможет.
+var ErrInvalidMessage = errors.New("invalid message")
+
+// NewPaymentHandler возвращает функцию-обработчик сообщений шины (transport.Handler),
+// которая разбирает запрос на оплату (contracts.PaymentRequest),
+// передаёт транзакцию в PaymentService для обработки
+// и возвращает ответ с результатом (contracts.PaymentResult).
+// Ответ сохраняет ключ и идентификатор корреляции запроса.
+//
+// Запрос в конверте (с заголовком contracts.ContentTypeHeader) получает ответ в конверте
+// той же кодировки. Запрос без заголовка передан в прежнем формате: ответ на него —
+// строка "OK" или текст ошибки, а код причины отказа передаётся в заголовке ReasonCodeHeader.
+//
+// Если задан verifier, запрос без действительной подписи (signing.Verifier.Verify) не обрабатывается:
+// ответ на него содержит статус contracts.PaymentFailed с кодом contracts.ErrorInvalidSignature,
+// а ошибка оборачивает ErrInvalidMessage. Без verifier подписи не проверяются.
+// Запрос с действительной, но устаревшей подписью (signing.ErrStale) отклоняется так же,
+// только если его ещё нет в журнале обработанных сообщений: уже обработанный запрос
+// получает сохранённый ответ.
+//
+// Сообщение обрабатывается через InboxService: повторно доставленное сообщение
+// не обрабатывается заново, а получает сохранённый ответ.
 //
-// В случае ошибки десериализации или бизнес-логики сервис возвращает
-// сообщение с описанием проблемы и соответствующую ошибку.
-func NewPaymentHandler(service *service.PaymentService) func(ctx context.Context, message *kafka.Message) (*kafka.Message, error) {
-	return func(ctx context.Context, message *kafka.Message) (*kafka.Message, error) {
-		var tx domain.Transaction
-		err := json.Unmarshal(message.Value, &tx)
+// Отказ по бизнес-правилам (нехватка средств, превышение лимита и т.п.) — штатный
+// результат обработки: ответ содержит код и текст причины, а ошибка не возвращается.
+// В случае ошибки разбора или сбоя обработки сервис возвращает ответ со статусом
+// contracts.PaymentFailed и соответствующую ошибку.
+func NewPaymentHandler(paymentService *service.PaymentService, inboxService *service.InboxService,
+	verifier *signing.Verifier) transport.Handler {
+	return func(ctx context.Context, message *transport.Message) (*transport.Message, error) {
+		encoding := contracts.Encoding(message.Header(contracts.ContentTypeHeader))
+		var signatureErr error
+		if verifier != nil {
+			signatureErr = verifier.Verify(message)
+		}
+		// Подпись устаревшего сообщения действительна, поэтому его идентификатор можно искать в журнале.
+		if signatureErr != nil && !errors.Is(signatureErr, signing.ErrStale) {
+			result := contracts.PaymentResult{Status: contracts.PaymentFailed, ErrorCode: contracts.ErrorInvalidSignature, Message: signatureErr.Error()}
+			return newReply(message, encoding, result), fmt.Errorf("%w: %w", ErrInvalidMessage, signatureErr)
+		}
+		request, err := decodeRequest(encoding, message.Value)
 		if err != nil {
-			resp := "invalid JSON: " + err.Error()
-			return &kafka.Message{Key: message.Key, Value: []byte(resp)}, err
+			result := contracts.PaymentResult{Status: contracts.PaymentFailed, ErrorCode: contracts.ErrorInvalidMessage, Message: err.Error()}
+			return newReply(message, encoding, result), fmt.Errorf("%w: %w", ErrInvalidMessage, err)
+		}
+		if signatureErr != nil {
+			processed, err := inboxService.Get(ctx, messageId(message))
+			if err != nil {
+				result := contracts.PaymentResult{TransactionId: request.Id, Status: contracts.PaymentFailed, ErrorCode: contracts.ErrorInternal, Message: err.Error()}
+				return newReply(message, encoding, result), err
+			}
+			if processed == nil {
+				result := contracts.PaymentResult{TransactionId: request.Id, Status: contracts.PaymentFailed, ErrorCode: contracts.ErrorInvalidSignature, Message: signatureErr.Error()}
+				return newReply(message, encoding, result), fmt.Errorf("%w: %w", ErrInvalidMessage, signatureErr)
+			}
+			return processedReply(message, encoding, request, processed), nil
 		}
-		err = service.ProcessTransaction(ctx, tx)
+		processed, err := inboxService.Process(ctx, messageId(message), func(ctx context.Context) (domain.InboxMessage, error) {
+			err := paymentService.ProcessTransaction(ctx, domain.Transaction{
+				Id:        request.Id,
+				UserId:    request.UserId,
+				AccountId: request.AccountId,
+				IsDeposit: request.IsDeposit,
+				Amount:    request.Amount,
+				Date:      request.Date,
+			})
+			if code := domain.DeclineReason(err); code != "" {
+				return domain.InboxMessage{Response: "Error processing transaction: " + err.Error(), ReasonCode: code}, nil
+			}
+			if err != nil {
+				return domain.InboxMessage{}, err
+			}
+			return domain.InboxMessage{Response: "OK"}, nil
+		})
 		if err != nil {
-			response := "Error processing transaction: " + err.Error()
-			return &kafka.Message{Key: message.Key, Value: []byte(response)}, err
+			result := contracts.PaymentResult{TransactionId: request.Id, Status: contracts.PaymentFailed, ErrorCode: contracts.ErrorInternal, Message: err.Error()}
+			return newReply(message, encoding, result), err
 		}
-		return &kafka.Message{Key: message.Key, Value: []byte("OK")}, err
+		return processedReply(message, encoding, request, processed), nil
+	}
+}
+
+// processedReply возвращает ответ на запрос request с результатом обработки из журнала.
+func processedReply(message *transport.Message, encoding contracts.Encoding, request contracts.PaymentRequest,
+	processed *domain.InboxMessage) *transport.Message {
+	// Журнал хранит ответ в прежнем формате, из которого результат восстанавливается однозначно.
+	result := contracts.ParseLegacyPaymentResult(processed.Response, string(processed.ReasonCode))
+	result.TransactionId = request.Id
+	return newReply(message, encoding, result)
+}
+
+// decodeRequest разбирает запрос на оплату в кодировке encoding;
+// пустая кодировка означает запрос в прежнем формате без конверта.
+func decodeRequest(encoding contracts.Encoding, data []byte) (contracts.PaymentRequest, error) {
+	if encoding == "" {
+		return contracts.UnmarshalLegacyPaymentRequest(data)
+	}
+	var request contracts.PaymentRequest
+	_, err := contracts.Unmarshal(encoding, data, &request)
+	return request, err
+}
+
+// messageId возвращает идентификатор сообщения для журнала обработанных сообщений:
+// идентификатор корреляции, а если его нет — идентификатор доставки в транспорте.
+func messageId(message *transport.Message) string {
+	if correlationId := message.Header(CorrelationIdHeader); correlationId != "" {
+		return correlationId
+	}
+	return message.Id
+}
+
+// newReply возвращает ответ на запрос request с результатом result,
+// перенося ключ и заголовок CorrelationIdHeader запроса.
+// Результат кодируется в конверт кодировки encoding, а при пустой или неизвестной
+// кодировке — в прежнем формате без конверта.
+func newReply(request *transport.Message, encoding contracts.Encoding, result contracts.PaymentResult) *transport.Message {
+	correlationId := request.Header(CorrelationIdHeader)
+	reply := &transport.Message{Key: request.Key}
+	if correlationId != "" {
+		reply.SetHeader(CorrelationIdHeader, correlationId)
+	}
+	if encoding != "" {
+		value, err := contracts.Marshal(encoding, correlationId, &result)
+		if err == nil {
+			reply.Value = value
+			reply.SetHeader(contracts.ContentTypeHeader, string(encoding))
+			return reply
+		}
+	}
+	body, reasonCode := result.Legacy()
+	reply.Value = []byte(body)
+	if reasonCode != "" {
+		reply.SetHeader(ReasonCodeHeader, reasonCode)
 	}
+	return reply
 }
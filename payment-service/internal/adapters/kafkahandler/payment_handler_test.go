@@ -2,11 +2,16 @@ package kafkahandler
 
 import (
 	"context"
+	"contracts"
+	"contracts/signing"
+	"contracts/transport"
 	"encoding/json"
 	"errors"
-	"github.com/segmentio/kafka-go"
+	"math/rand"
+	"payment-service/internal/application/repository"
 	"payment-service/internal/application/service"
 	"payment-service/internal/domain"
+	"strconv"
 	"testing"
 	"time"
 )
@@ -23,15 +28,43 @@ func (m *mockAccountRepository) GetById(ctx context.Context, id int) (*domain.Ac
 	return &acc, nil
 }
 
-func (m *mockAccountRepository) GetByUserId(ctx context.Context, userId int) (*domain.Account, error) {
+func (m *mockAccountRepository) GetDefaultByUserId(ctx context.Context, userId int) (*domain.Account, error) {
 	for _, acc := range m.data {
-		if acc.UserId == userId {
+		if acc.UserId == userId && acc.IsDefault {
 			return &acc, nil
 		}
 	}
 	return nil, errors.New("user not found")
 }
 
+func (m *mockAccountRepository) GetUserAccounts(ctx context.Context, userId int) ([]domain.Account, error) {
+	accounts := make([]domain.Account, 0)
+	for _, acc := range m.data {
+		if acc.UserId == userId {
+			accounts = append(accounts, acc)
+		}
+	}
+	return accounts, nil
+}
+
+func (m *mockAccountRepository) GetAll(ctx context.Context) ([]domain.Account, error) {
+	accounts := make([]domain.Account, 0, len(m.data))
+	for _, acc := range m.data {
+		accounts = append(accounts, acc)
+	}
+	return accounts, nil
+}
+
+func (m *mockAccountRepository) SetDefault(ctx context.Context, userId int, accountId int) error {
+	for id, acc := range m.data {
+		if acc.UserId == userId {
+			acc.IsDefault = id == accountId
+			m.data[id] = acc
+		}
+	}
+	return nil
+}
+
 func (m *mockAccountRepository) Save(ctx context.Context, account *domain.Account) error {
 	m.data[account.Id] = *account
 	return nil
@@ -42,6 +75,9 @@ type mockTransactionRepository struct {
 }
 
 func (m *mockTransactionRepository) Save(ctx context.Context, transaction *domain.Transaction) error {
+	if _, ok := m.data[transaction.Id]; ok {
+		return repository.ErrDuplicateTransaction
+	}
 	m.data[transaction.Id] = *transaction
 	return nil
 }
@@ -49,29 +85,229 @@ func (m *mockTransactionRepository) Save(ctx context.Context, transaction *domai
 func (m *mockTransactionRepository) GetById(ctx context.Context, id int) (*domain.Transaction, error) {
 	tx, ok := m.data[id]
 	if !ok {
-		return nil, errors.New("id not found")
+		return nil, nil
 	}
 	return &tx, nil
 }
 
+type mockTransactor struct{}
+
+func (m *mockTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (m *mockTransactionRepository) GetByAccountId(ctx context.Context, accountId int) ([]domain.Transaction, error) {
+	transactions := make([]domain.Transaction, 0)
+	for _, tx := range m.data {
+		if tx.AccountId == accountId {
+			transactions = append(transactions, tx)
+		}
+	}
+	return transactions, nil
+}
+
+func (m *mockTransactionRepository) GetSpendingActivity(ctx context.Context, accountId int, now time.Time) (*domain.SpendingActivity, error) {
+	dayStart, monthStart, hourStart := domain.SpendingWindows(now)
+	var activity domain.SpendingActivity
+	for _, tx := range m.data {
+		if tx.AccountId != accountId || tx.IsDeposit || tx.Type != domain.TransactionTypePayment || tx.Status != domain.TransactionStatusCompleted {
+			continue
+		}
+		if !tx.Date.Before(dayStart) {
+			activity.DailyTotal += tx.Amount
+		}
+		if !tx.Date.Before(monthStart) {
+			activity.MonthlyTotal += tx.Amount
+		}
+		if !tx.Date.Before(hourStart) {
+			activity.PaymentsLastHour++
+		}
+	}
+	return &activity, nil
+}
+
+func (m *mockTransactionRepository) GetCompletedBetween(ctx context.Context, accountId int, after, until time.Time) ([]domain.Transaction, error) {
+	return nil, nil
+}
+
+func (m *mockTransactionRepository) GetBalanceChange(ctx context.Context, accountId int, after, until time.Time) (float64, error) {
+	return 0, nil
+}
+
+func (m *mockTransactionRepository) GetOldestPendingReviewDate(ctx context.Context) (*time.Time, error) {
+	return nil, nil
+}
+
+func (m *mockTransactionRepository) CountDeclined(ctx context.Context, userId int, since time.Time) (int, error) {
+	count := 0
+	for _, tx := range m.data {
+		if tx.UserId == userId && tx.Status == domain.TransactionStatusDeclined && !tx.Date.Before(since) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *mockTransactionRepository) AddReversedAmount(ctx context.Context, id int, amount float64) error {
+	tx := m.data[id]
+	if tx.ReversedAmount+amount > tx.Amount {
+		return domain.ErrReversalExceeded
+	}
+	tx.ReversedAmount += amount
+	m.data[id] = tx
+	return nil
+}
+
+func (m *mockTransactionRepository) UpdateStatus(ctx context.Context, transaction *domain.Transaction) error {
+	m.data[transaction.Id] = *transaction
+	return nil
+}
+
+type mockSpendingLimitsRepository struct {
+	data map[int]domain.SpendingLimits
+}
+
+func (m *mockSpendingLimitsRepository) GetByAccountId(ctx context.Context, accountId int) (*domain.SpendingLimits, error) {
+	limits, ok := m.data[accountId]
+	if !ok {
+		return nil, nil
+	}
+	return &limits, nil
+}
+
+func (m *mockSpendingLimitsRepository) Save(ctx context.Context, limits *domain.SpendingLimits) error {
+	m.data[limits.AccountId] = *limits
+	return nil
+}
+
+func (m *mockSpendingLimitsRepository) Delete(ctx context.Context, accountId int) error {
+	delete(m.data, accountId)
+	return nil
+}
+
+type mockTransactionReviewRepository struct {
+	data map[int]domain.TransactionReview
+}
+
+func (m *mockTransactionReviewRepository) GetByTransactionId(ctx context.Context, transactionId int) (*domain.TransactionReview, error) {
+	review, ok := m.data[transactionId]
+	if !ok {
+		return nil, nil
+	}
+	return &review, nil
+}
+
+func (m *mockTransactionReviewRepository) GetPending(ctx context.Context) ([]domain.TransactionReview, error) {
+	reviews := make([]domain.TransactionReview, 0)
+	for _, review := range m.data {
+		if review.Status == domain.ReviewStatusPending {
+			reviews = append(reviews, review)
+		}
+	}
+	return reviews, nil
+}
+
+func (m *mockTransactionReviewRepository) Save(ctx context.Context, review *domain.TransactionReview) error {
+	m.data[review.TransactionId] = *review
+	return nil
+}
+
+type mockAccountStatusRepository struct{}
+
+func (m *mockAccountStatusRepository) Save(ctx context.Context, change *domain.AccountStatusChange) error {
+	return nil
+}
+
+func (m *mockAccountStatusRepository) GetByAccountId(ctx context.Context, accountId int) ([]domain.AccountStatusChange, error) {
+	return nil, nil
+}
+
+type mockAccountEventRepository struct{}
+
+func (m *mockAccountEventRepository) Save(ctx context.Context, event *domain.AccountEvent) error {
+	return nil
+}
+
+func (m *mockAccountEventRepository) GetUnpublished(ctx context.Context, limit int) ([]domain.AccountEvent, error) {
+	return nil, nil
+}
+
+func (m *mockAccountEventRepository) MarkPublished(ctx context.Context, ids []int64, at time.Time) error {
+	return nil
+}
+
+type mockBalanceSnapshotRepository struct{}
+
+func (m *mockBalanceSnapshotRepository) GetLatest(ctx context.Context, accountId int, at time.Time) (*domain.BalanceSnapshot, error) {
+	return nil, nil
+}
+
+func (m *mockBalanceSnapshotRepository) Save(ctx context.Context, snapshot *domain.BalanceSnapshot) error {
+	return nil
+}
+
+func (m *mockBalanceSnapshotRepository) DeleteFrom(ctx context.Context, accountId int, from time.Time) error {
+	return nil
+}
+
+type mockInboxRepository struct {
+	data map[string]domain.InboxMessage
+}
+
+func (m *mockInboxRepository) GetById(ctx context.Context, messageId string) (*domain.InboxMessage, error) {
+	message, ok := m.data[messageId]
+	if !ok {
+		return nil, nil
+	}
+	return &message, nil
+}
+
+func (m *mockInboxRepository) Save(ctx context.Context, message *domain.InboxMessage) error {
+	m.data[message.MessageId] = *message
+	return nil
+}
+
+func newTestInbox(t *testing.T) *service.InboxService {
+	t.Helper()
+	inbox, err := service.NewInboxService(&mockInboxRepository{data: make(map[string]domain.InboxMessage)}, &mockTransactor{})
+	if err != nil {
+		t.Fatalf("error creating inbox service: %v", err)
+	}
+	return inbox
+}
+
 func setupTestEnv(t *testing.T) (context.Context, *service.PaymentService, *service.AccountService) {
 	t.Helper()
 	ctx := context.Background()
 	accDb := &mockAccountRepository{data: make(map[int]domain.Account)}
 	txDb := &mockTransactionRepository{data: make(map[int]domain.Transaction)}
-	paymentService, _ := service.NewPaymentService(accDb, txDb)
-	accService := service.NewAccountService(accDb)
+	limitsDb := &mockSpendingLimitsRepository{data: make(map[int]domain.SpendingLimits)}
+	reviewDb := &mockTransactionReviewRepository{data: make(map[int]domain.TransactionReview)}
+	paymentService, _ := service.NewPaymentService(accDb, txDb, limitsDb, reviewDb, &mockAccountEventRepository{}, &mockBalanceSnapshotRepository{}, &mockTransactor{}, domain.DefaultRiskRules())
+	accService := service.NewAccountService(accDb, txDb, &mockAccountStatusRepository{}, limitsDb, &mockAccountEventRepository{}, &mockTransactor{})
 	return ctx, paymentService, accService
 }
 
+// deposit зачисляет amount на счёт acc входящим переводом через платёжный сервис.
+func deposit(ctx context.Context, paymentService *service.PaymentService, acc *domain.Account, amount float64) error {
+	return paymentService.Deposit(ctx, domain.Transaction{
+		Id:        rand.Intn(2147483645),
+		UserId:    acc.UserId,
+		AccountId: acc.Id,
+		IsDeposit: true,
+		Amount:    amount,
+		Date:      time.Now(),
+	})
+}
+
 func TestPaymentHandler_Success(t *testing.T) {
 	ctx, paymentService, accService := setupTestEnv(t)
 	acc, _ := accService.CreateAccount(ctx, 123)
-	err := accService.Deposit(ctx, acc.Id, 10000)
+	err := deposit(ctx, paymentService, acc, 10000)
 	if err != nil {
 		t.Errorf("error depositing account: %v", err)
 	}
-	handler := NewPaymentHandler(paymentService)
+	handler := NewPaymentHandler(paymentService, newTestInbox(t), nil)
 	tx := &domain.Transaction{
 		Id:        1,
 		UserId:    acc.UserId,
@@ -80,7 +316,7 @@ func TestPaymentHandler_Success(t *testing.T) {
 		Date:      time.Now(),
 	}
 	txJson, _ := json.Marshal(tx)
-	msg := &kafka.Message{Key: []byte("123"), Value: txJson}
+	msg := &transport.Message{Key: []byte("123"), Value: txJson}
 	res, err := handler(ctx, msg)
 	if err != nil {
 		t.Errorf("error processing transaction: %v", err)
@@ -94,11 +330,11 @@ func TestPaymentHandler_Success(t *testing.T) {
 func TestPaymentHandler_Fail(t *testing.T) {
 	ctx, paymentService, accService := setupTestEnv(t)
 	acc, _ := accService.CreateAccount(ctx, 123)
-	err := accService.Deposit(ctx, acc.Id, 100)
+	err := deposit(ctx, paymentService, acc, 100)
 	if err != nil {
 		t.Errorf("error depositing account: %v", err)
 	}
-	handler := NewPaymentHandler(paymentService)
+	handler := NewPaymentHandler(paymentService, newTestInbox(t), nil)
 	tx := &domain.Transaction{
 		Id:        1,
 		UserId:    acc.UserId,
@@ -107,14 +343,395 @@ func TestPaymentHandler_Fail(t *testing.T) {
 		Date:      time.Now(),
 	}
 	txJson, _ := json.Marshal(tx)
-	msg := &kafka.Message{Key: []byte("123"), Value: txJson}
-	_, err = handler(ctx, msg)
-	if err == nil {
-		t.Errorf("expected error, got nil")
+	msg := &transport.Message{Key: []byte("123"), Value: txJson}
+	res, err := handler(ctx, msg)
+	if err != nil {
+		t.Errorf("decline must be replied without error, got %v", err)
+	}
+	if string(res.Value) == "OK" {
+		t.Errorf("expected decline, got OK")
 	}
-	acc, _ = accService.GetUsersAccount(ctx, 123)
+	if code := reasonCode(res); code != string(domain.ReasonInsufficientFunds) {
+		t.Errorf("expected reason code %s, got %q", domain.ReasonInsufficientFunds, code)
+	}
+	acc, _ = accService.GetAccount(ctx, acc.Id)
 	if acc.Balance != 100 {
 		t.Errorf("account balance got changed")
 	}
 	return
 }
+
+func TestPaymentHandler_Redelivery(t *testing.T) {
+	ctx, paymentService, accService := setupTestEnv(t)
+	acc, _ := accService.CreateAccount(ctx, 123)
+	err := deposit(ctx, paymentService, acc, 1000)
+	if err != nil {
+		t.Errorf("error depositing account: %v", err)
+	}
+	handler := NewPaymentHandler(paymentService, newTestInbox(t), nil)
+	tx := &domain.Transaction{
+		Id:        1,
+		UserId:    acc.UserId,
+		IsDeposit: false,
+		Amount:    300,
+		Date:      time.Now(),
+	}
+	txJson, _ := json.Marshal(tx)
+	msg := &transport.Message{Key: []byte("123"), Value: txJson}
+	for i := 0; i < 2; i++ {
+		res, err := handler(ctx, msg)
+		if err != nil {
+			t.Errorf("error processing transaction: %v", err)
+		}
+		if string(res.Value) != "OK" {
+			t.Errorf("expected OK, got %s", string(res.Value))
+		}
+	}
+	acc, _ = accService.GetAccount(ctx, acc.Id)
+	if acc.Balance != 700 {
+		t.Errorf("expected balance 700 after redelivery, got %.2f", acc.Balance)
+	}
+	return
+}
+
+func TestPaymentHandler_LimitExceeded(t *testing.T) {
+	ctx, paymentService, accService := setupTestEnv(t)
+	acc, _ := accService.CreateAccount(ctx, 123)
+	err := deposit(ctx, paymentService, acc, 10000)
+	if err != nil {
+		t.Errorf("error depositing account: %v", err)
+	}
+	_, err = accService.SetSpendingLimits(ctx, acc.Id, domain.SpendingLimits{MaxPayment: 500})
+	if err != nil {
+		t.Errorf("error setting spending limits: %v", err)
+	}
+	handler := NewPaymentHandler(paymentService, newTestInbox(t), nil)
+	tx := &domain.Transaction{
+		Id:        1,
+		UserId:    acc.UserId,
+		IsDeposit: false,
+		Amount:    999,
+		Date:      time.Now(),
+	}
+	txJson, _ := json.Marshal(tx)
+	res, err := handler(ctx, &transport.Message{Key: []byte("123"), Value: txJson})
+	if err != nil {
+		t.Errorf("decline must be replied without error, got %v", err)
+	}
+	if code := reasonCode(res); code != string(domain.ReasonLimitExceeded) {
+		t.Errorf("expected reason code %s, got %q", domain.ReasonLimitExceeded, code)
+	}
+	acc, _ = accService.GetAccount(ctx, acc.Id)
+	if acc.Balance != 10000 {
+		t.Errorf("account balance got changed")
+	}
+	return
+}
+
+func TestPaymentHandler_PendingReview(t *testing.T) {
+	ctx, paymentService, accService := setupTestEnv(t)
+	acc, _ := accService.CreateAccount(ctx, 123)
+	err := deposit(ctx, paymentService, acc, 50000)
+	if err != nil {
+		t.Errorf("error depositing account: %v", err)
+	}
+	handler := NewPaymentHandler(paymentService, newTestInbox(t), nil)
+	tx := &domain.Transaction{
+		Id:        1,
+		UserId:    acc.UserId,
+		IsDeposit: false,
+		Amount:    25000,
+		Date:      time.Now(),
+	}
+	txJson, _ := json.Marshal(tx)
+	// Повторная оплата после решения — новый запрос со своим идентификатором корреляции.
+	request := func(correlationId string) *transport.Message {
+		return &transport.Message{Key: []byte("123"), Value: txJson,
+			Headers: map[string]string{CorrelationIdHeader: correlationId}}
+	}
+	res, err := handler(ctx, request("request-1"))
+	if err != nil {
+		t.Errorf("pending review must be replied without error, got %v", err)
+	}
+	if code := reasonCode(res); code != string(domain.ReasonPendingReview) {
+		t.Errorf("expected reason code %s, got %q", domain.ReasonPendingReview, code)
+	}
+	acc, _ = accService.GetAccount(ctx, acc.Id)
+	if acc.Balance != 50000 {
+		t.Errorf("account balance got changed before review")
+	}
+
+	_, err = paymentService.ApproveReview(ctx, tx.Id, "admin", "")
+	if err != nil {
+		t.Fatalf("error approving review: %v", err)
+	}
+	res, err = handler(ctx, request("request-2"))
+	if err != nil {
+		t.Errorf("error processing transaction: %v", err)
+	}
+	if string(res.Value) != "OK" {
+		t.Errorf("expected OK after approval, got %s", string(res.Value))
+	}
+	acc, _ = accService.GetAccount(ctx, acc.Id)
+	if acc.Balance != 25000 {
+		t.Errorf("expected balance 25000 after approval, got %.2f", acc.Balance)
+	}
+	return
+}
+
+func TestPaymentHandler_EchoesCorrelationId(t *testing.T) {
+	ctx, paymentService, accService := setupTestEnv(t)
+	acc, _ := accService.CreateAccount(ctx, 123)
+	err := deposit(ctx, paymentService, acc, 100)
+	if err != nil {
+		t.Errorf("error depositing account: %v", err)
+	}
+	handler := NewPaymentHandler(paymentService, newTestInbox(t), nil)
+	for i, amount := range []float64{60, 60} {
+		tx := &domain.Transaction{Id: i + 1, UserId: acc.UserId, Amount: amount, Date: time.Now()}
+		txJson, _ := json.Marshal(tx)
+		correlationId := "request-" + strconv.Itoa(i)
+		res, _ := handler(ctx, &transport.Message{
+			Key:     []byte("123"),
+			Value:   txJson,
+			Headers: map[string]string{CorrelationIdHeader: correlationId},
+		})
+		if string(res.Key) != "123" {
+			t.Errorf("expected key 123, got %s", string(res.Key))
+		}
+		if got := res.Header(CorrelationIdHeader); got != correlationId {
+			t.Errorf("expected correlation id %s, got %q", correlationId, got)
+		}
+	}
+}
+
+func TestPaymentHandler_InvalidMessage(t *testing.T) {
+	ctx, paymentService, _ := setupTestEnv(t)
+	handler := NewPaymentHandler(paymentService, newTestInbox(t), nil)
+	res, err := handler(ctx, &transport.Message{Key: []byte("123"), Value: []byte("not json")})
+	if !errors.Is(err, ErrInvalidMessage) {
+		t.Errorf("expected ErrInvalidMessage, got %v", err)
+	}
+	if res == nil || string(res.Key) != "123" {
+		t.Errorf("expected error reply, got %+v", res)
+	}
+}
+
+func TestPaymentHandler_InboxDeduplicatesRedelivery(t *testing.T) {
+	ctx, paymentService, accService := setupTestEnv(t)
+	acc, _ := accService.CreateAccount(ctx, 123)
+	err := deposit(ctx, paymentService, acc, 100)
+	if err != nil {
+		t.Errorf("error depositing account: %v", err)
+	}
+	handler := NewPaymentHandler(paymentService, newTestInbox(t), nil)
+	headers := map[string]string{CorrelationIdHeader: "request-1"}
+	for _, amount := range []float64{60, 80} {
+		tx := &domain.Transaction{Id: int(amount), UserId: acc.UserId, Amount: amount, Date: time.Now()}
+		txJson, _ := json.Marshal(tx)
+		res, err := handler(ctx, &transport.Message{Key: []byte("123"), Value: txJson, Headers: headers})
+		if err != nil {
+			t.Errorf("error processing transaction: %v", err)
+		}
+		if string(res.Value) != "OK" {
+			t.Errorf("expected stored OK reply, got %s", string(res.Value))
+		}
+	}
+	acc, _ = accService.GetAccount(ctx, acc.Id)
+	if acc.Balance != 40 {
+		t.Errorf("expected message to be processed once, balance %.2f", acc.Balance)
+	}
+}
+
+func TestPaymentHandler_Envelope(t *testing.T) {
+	for _, encoding := range []contracts.Encoding{contracts.EncodingJSON, contracts.EncodingProtobuf} {
+		t.Run(string(encoding), func(t *testing.T) {
+			ctx, paymentService, accService := setupTestEnv(t)
+			acc, _ := accService.CreateAccount(ctx, 123)
+			err := deposit(ctx, paymentService, acc, 100)
+			if err != nil {
+				t.Errorf("error depositing account: %v", err)
+			}
+			handler := NewPaymentHandler(paymentService, newTestInbox(t), nil)
+			want := []contracts.PaymentResult{
+				{TransactionId: 1, Status: contracts.PaymentApproved},
+				{TransactionId: 2, Status: contracts.PaymentDeclined, ErrorCode: contracts.ErrorInsufficientFunds},
+			}
+			for i, expected := range want {
+				correlationId := "request-" + strconv.Itoa(i)
+				value, err := contracts.Marshal(encoding, correlationId, &contracts.PaymentRequest{
+					Id: expected.TransactionId, UserId: acc.UserId, Amount: 60, Date: time.Now(),
+				})
+				if err != nil {
+					t.Fatalf("error encoding request: %v", err)
+				}
+				res, err := handler(ctx, &transport.Message{Key: []byte("123"), Value: value, Headers: map[string]string{
+					CorrelationIdHeader:         correlationId,
+					contracts.ContentTypeHeader: string(encoding),
+				}})
+				if err != nil {
+					t.Errorf("error processing transaction: %v", err)
+				}
+				if got := res.Header(contracts.ContentTypeHeader); got != string(encoding) {
+					t.Errorf("expected reply encoding %s, got %q", encoding, got)
+				}
+				var result contracts.PaymentResult
+				envelope, err := contracts.Unmarshal(encoding, res.Value, &result)
+				if err != nil {
+					t.Fatalf("error decoding reply: %v", err)
+				}
+				if envelope.CorrelationId != correlationId {
+					t.Errorf("expected correlation id %s, got %q", correlationId, envelope.CorrelationId)
+				}
+				if result.Status != expected.Status || result.ErrorCode != expected.ErrorCode || result.TransactionId != expected.TransactionId {
+					t.Errorf("expected result %+v, got %+v", expected, result)
+				}
+			}
+		})
+	}
+}
+
+func TestPaymentHandler_EnvelopeUnsupportedVersion(t *testing.T) {
+	ctx, paymentService, _ := setupTestEnv(t)
+	handler := NewPaymentHandler(paymentService, newTestInbox(t), nil)
+	value := []byte(`{"type":"payment.request","version":2,"correlation_id":"request-1","payload":{"id":1}}`)
+	res, err := handler(ctx, &transport.Message{Key: []byte("123"), Value: value, Headers: map[string]string{
+		contracts.ContentTypeHeader: string(contracts.EncodingJSON),
+	}})
+	if !errors.Is(err, ErrInvalidMessage) || !errors.Is(err, contracts.ErrUnsupportedVersion) {
+		t.Errorf("expected ErrInvalidMessage for unsupported version, got %v", err)
+	}
+	var result contracts.PaymentResult
+	_, err = contracts.Unmarshal(contracts.EncodingJSON, res.Value, &result)
+	if err != nil {
+		t.Fatalf("error decoding reply: %v", err)
+	}
+	if result.Status != contracts.PaymentFailed || result.ErrorCode != contracts.ErrorInvalidMessage {
+		t.Errorf("expected FAILED with INVALID_MESSAGE, got %+v", result)
+	}
+}
+
+func reasonCode(message *transport.Message) string {
+	return message.Header(ReasonCodeHeader)
+}
+
+func TestPaymentHandler_VerifiesSignature(t *testing.T) {
+	ctx, paymentService, accService := setupTestEnv(t)
+	acc, _ := accService.CreateAccount(ctx, 123)
+	err := deposit(ctx, paymentService, acc, 100)
+	if err != nil {
+		t.Errorf("error depositing account: %v", err)
+	}
+	key := signing.Key{Id: "k1", Algorithm: signing.HMACSHA256, Material: []byte("0123456789abcdef0123456789abcdef")}
+	handler := NewPaymentHandler(paymentService, newTestInbox(t), signing.NewVerifier([]signing.Key{key}, time.Minute))
+	newRequest := func(id int) *transport.Message {
+		value, err := contracts.Marshal(contracts.EncodingJSON, "request-"+strconv.Itoa(id), &contracts.PaymentRequest{
+			Id: id, UserId: acc.UserId, Amount: 10, Date: time.Now(),
+		})
+		if err != nil {
+			t.Fatalf("error encoding request: %v", err)
+		}
+		return &transport.Message{Key: []byte("123"), Value: value, Headers: map[string]string{
+			CorrelationIdHeader:         "request-" + strconv.Itoa(id),
+			contracts.ContentTypeHeader: string(contracts.EncodingJSON),
+		}}
+	}
+	decode := func(res *transport.Message) contracts.PaymentResult {
+		var result contracts.PaymentResult
+		_, err := contracts.Unmarshal(contracts.EncodingJSON, res.Value, &result)
+		if err != nil {
+			t.Fatalf("error decoding reply: %v", err)
+		}
+		return result
+	}
+
+	signed := newRequest(1)
+	signing.NewSigner(key).Sign(signed)
+	res, err := handler(ctx, signed)
+	if err != nil {
+		t.Fatalf("error processing signed transaction: %v", err)
+	}
+	if result := decode(res); result.Status != contracts.PaymentApproved {
+		t.Errorf("expected signed request to be approved, got %+v", result)
+	}
+
+	res, err = handler(ctx, newRequest(2))
+	if !errors.Is(err, ErrInvalidMessage) || !errors.Is(err, signing.ErrUnsigned) {
+		t.Errorf("expected ErrInvalidMessage for unsigned request, got %v", err)
+	}
+	if result := decode(res); result.Status != contracts.PaymentFailed || result.ErrorCode != contracts.ErrorInvalidSignature {
+		t.Errorf("expected FAILED with INVALID_SIGNATURE, got %+v", result)
+	}
+
+	tampered := newRequest(3)
+	signing.NewSigner(key).Sign(tampered)
+	tampered.Key = []byte("456")
+	_, err = handler(ctx, tampered)
+	if !errors.Is(err, signing.ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature for tampered request, got %v", err)
+	}
+	balance, _ := accService.GetAccount(ctx, acc.Id)
+	if balance.Balance != 90 {
+		t.Errorf("expected only the signed request to be charged, balance %.2f", balance.Balance)
+	}
+}
+
+func TestPaymentHandler_StaleSignature(t *testing.T) {
+	ctx, paymentService, accService := setupTestEnv(t)
+	acc, _ := accService.CreateAccount(ctx, 123)
+	err := deposit(ctx, paymentService, acc, 100)
+	if err != nil {
+		t.Errorf("error depositing account: %v", err)
+	}
+	key := signing.Key{Id: "k1", Algorithm: signing.HMACSHA256, Material: []byte("0123456789abcdef0123456789abcdef")}
+	maxAge := 50 * time.Millisecond
+	handler := NewPaymentHandler(paymentService, newTestInbox(t), signing.NewVerifier([]signing.Key{key}, maxAge))
+	newRequest := func(id int) *transport.Message {
+		value, err := contracts.Marshal(contracts.EncodingJSON, "request-"+strconv.Itoa(id), &contracts.PaymentRequest{
+			Id: id, UserId: acc.UserId, Amount: 10, Date: time.Now(),
+		})
+		if err != nil {
+			t.Fatalf("error encoding request: %v", err)
+		}
+		message := &transport.Message{Key: []byte("123"), Value: value, Headers: map[string]string{
+			CorrelationIdHeader:         "request-" + strconv.Itoa(id),
+			contracts.ContentTypeHeader: string(contracts.EncodingJSON),
+		}}
+		signing.NewSigner(key).Sign(message)
+		message.Time = time.Now()
+		return message
+	}
+
+	// Отставание потребителя: запрос обрабатывается позже maxAge после подписи и записи.
+	lagging := newRequest(1)
+	time.Sleep(2 * maxAge)
+	_, err = handler(ctx, lagging)
+	if err != nil {
+		t.Fatalf("expected lagging request to be processed, got %v", err)
+	}
+
+	// Переотправка обработанного запроса: он записан заново позже maxAge после подписи.
+	replayed := *lagging
+	replayed.Time = time.Now()
+	res, err := handler(ctx, &replayed)
+	if err != nil {
+		t.Fatalf("expected stored reply for replayed request, got %v", err)
+	}
+	var result contracts.PaymentResult
+	_, err = contracts.Unmarshal(contracts.EncodingJSON, res.Value, &result)
+	if err != nil || result.Status != contracts.PaymentApproved || result.TransactionId != 1 {
+		t.Errorf("expected stored APPROVED reply, got %+v, %v", result, err)
+	}
+
+	// Необработанный запрос с прежней подписью нужно переотправить с новой подписью.
+	stale := newRequest(2)
+	stale.Time = stale.Time.Add(2 * maxAge)
+	_, err = handler(ctx, stale)
+	if !errors.Is(err, ErrInvalidMessage) || !errors.Is(err, signing.ErrStale) {
+		t.Errorf("expected ErrInvalidMessage with ErrStale for stale request, got %v", err)
+	}
+	balance, _ := accService.GetAccount(ctx, acc.Id)
+	if balance.Balance != 90 {
+		t.Errorf("expected the request to be charged once, balance %.2f", balance.Balance)
+	}
+}
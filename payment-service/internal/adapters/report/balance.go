@@ -0,0 +1,43 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"payment-service/internal/domain"
+	"strconv"
+	"time"
+)
+
+// WriteBalances записывает отчёт о балансах счетов на момент времени в w в формате JSON или CSV.
+// Возвращает ошибку, если формат не поддерживается.
+func WriteBalances(w io.Writer, format string, balances []domain.AccountBalance) error {
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(balances)
+	case FormatCSV:
+		writer := csv.NewWriter(w)
+		err := writer.Write([]string{"account_id", "user_id", "currency", "balance", "at"})
+		if err != nil {
+			return err
+		}
+		for _, b := range balances {
+			err = writer.Write([]string{
+				strconv.Itoa(b.AccountId),
+				strconv.Itoa(b.UserId),
+				b.Currency,
+				formatAmount(b.Balance),
+				b.At.UTC().Format(time.RFC3339),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	}
+	return fmt.Errorf("unsupported report format: %q", format)
+}
@@ -0,0 +1,23 @@
+package report
+
+import (
+	"bytes"
+	"payment-service/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestWriteBalances_CSV(t *testing.T) {
+	at := time.Date(2025, 10, 31, 23, 59, 59, 0, time.UTC)
+	balances := []domain.AccountBalance{{AccountId: 1, UserId: 5, Currency: "RUB", Balance: 150.5, At: at}}
+
+	var buf bytes.Buffer
+	err := WriteBalances(&buf, FormatCSV, balances)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "account_id,user_id,currency,balance,at\n1,5,RUB,150.50,2025-10-31T23:59:59Z\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}
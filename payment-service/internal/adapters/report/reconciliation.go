@@ -0,0 +1,65 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"payment-service/internal/domain"
+	"strconv"
+)
+
+// Поддерживаемые форматы отчётов.
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+	FormatOFX  = "ofx" // Только для выписок по счетам
+)
+
+// WriteMismatches записывает отчёт о расхождениях балансов в w в формате JSON или CSV.
+// Возвращает ошибку, если формат не поддерживается.
+func WriteMismatches(w io.Writer, format string, mismatches []domain.BalanceMismatch) error {
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(mismatches)
+	case FormatCSV:
+		writer := csv.NewWriter(w)
+		err := writer.Write([]string{"account_id", "user_id", "balance", "expected", "difference"})
+		if err != nil {
+			return err
+		}
+		for _, m := range mismatches {
+			err = writer.Write([]string{
+				strconv.Itoa(m.AccountId),
+				strconv.Itoa(m.UserId),
+				formatAmount(m.Balance),
+				formatAmount(m.Expected),
+				formatAmount(m.Difference),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	}
+	return fmt.Errorf("unsupported report format: %q", format)
+}
+
+// ReadMismatches читает отчёт о расхождениях в формате JSON,
+// ранее записанный WriteMismatches.
+func ReadMismatches(r io.Reader) ([]domain.BalanceMismatch, error) {
+	var mismatches []domain.BalanceMismatch
+	err := json.NewDecoder(r).Decode(&mismatches)
+	if err != nil {
+		return nil, fmt.Errorf("invalid report: %w", err)
+	}
+	return mismatches, nil
+}
+
+// formatAmount форматирует сумму с точностью до копеек.
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
@@ -0,0 +1,45 @@
+package report
+
+import (
+	"bytes"
+	"payment-service/internal/domain"
+	"testing"
+)
+
+func TestWriteMismatches_CSV(t *testing.T) {
+	mismatches := []domain.BalanceMismatch{{AccountId: 1, UserId: 5, Balance: 80, Expected: 100, Difference: -20}}
+
+	var buf bytes.Buffer
+	err := WriteMismatches(&buf, FormatCSV, mismatches)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "account_id,user_id,balance,expected,difference\n1,5,80.00,100.00,-20.00\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestWriteMismatches_JSONRoundTrip(t *testing.T) {
+	mismatches := []domain.BalanceMismatch{{AccountId: 1, UserId: 5, Balance: 80, Expected: 100, Difference: -20}}
+
+	var buf bytes.Buffer
+	err := WriteMismatches(&buf, FormatJSON, mismatches)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := ReadMismatches(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 1 || got[0] != mismatches[0] {
+		t.Errorf("expected %+v, got %+v", mismatches, got)
+	}
+}
+
+func TestWriteMismatches_UnsupportedFormat(t *testing.T) {
+	err := WriteMismatches(&bytes.Buffer{}, "xml", nil)
+	if err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
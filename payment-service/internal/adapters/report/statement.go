@@ -0,0 +1,167 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"payment-service/internal/domain"
+	"strconv"
+	"time"
+)
+
+// ofxBankId — идентификатор банка в OFX-выписках.
+const ofxBankId = "simple-go-store"
+
+// WriteStatement записывает выписку по счёту в w в формате CSV или OFX.
+// Возвращает ошибку, если формат не поддерживается.
+func WriteStatement(w io.Writer, format string, statement domain.Statement) error {
+	switch format {
+	case FormatCSV:
+		return writeStatementCSV(w, statement)
+	case FormatOFX:
+		return writeStatementOFX(w, statement)
+	}
+	return fmt.Errorf("unsupported statement format: %q", format)
+}
+
+// StatementFileName возвращает имя файла выписки, например statement-1-2025-10.csv.
+func StatementFileName(statement domain.Statement, format string) string {
+	return fmt.Sprintf("statement-%d-%s.%s", statement.AccountId,
+		statement.PeriodEnd.UTC().Format(domain.StatementMonthFormat), format)
+}
+
+// writeStatementCSV записывает выписку одной таблицей: строка баланса на начало,
+// строки транзакций с балансом после каждой и строка баланса на конец.
+func writeStatementCSV(w io.Writer, statement domain.Statement) error {
+	writer := csv.NewWriter(w)
+	rows := [][]string{
+		{"date", "transaction_id", "type", "amount", "balance", "currency"},
+		{formatDate(statement.PeriodStart), "", "opening_balance", "", formatAmount(statement.OpeningBalance), statement.Currency},
+	}
+	for _, entry := range statement.Entries {
+		rows = append(rows, []string{
+			formatDate(entry.Date),
+			strconv.Itoa(entry.TransactionId),
+			string(entry.Type),
+			formatAmount(entry.Amount),
+			formatAmount(entry.Balance),
+			statement.Currency,
+		})
+	}
+	rows = append(rows, []string{formatDate(statement.PeriodEnd), "", "closing_balance", "", formatAmount(statement.ClosingBalance), statement.Currency})
+	err := writer.WriteAll(rows)
+	if err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+// formatDate форматирует дату в RFC 3339 в UTC.
+func formatDate(date time.Time) string {
+	return date.UTC().Format(time.RFC3339)
+}
+
+// ofxDocument — банковская выписка в формате OFX 2.2.
+type ofxDocument struct {
+	XMLName xml.Name `xml:"OFX"`
+	SignOn  struct {
+		Response struct {
+			Status   ofxStatus `xml:"STATUS"`
+			Server   string    `xml:"DTSERVER"`
+			Language string    `xml:"LANGUAGE"`
+		} `xml:"SONRS"`
+	} `xml:"SIGNONMSGSRSV1"`
+	Bank struct {
+		Transaction struct {
+			Uid       string    `xml:"TRNUID"`
+			Status    ofxStatus `xml:"STATUS"`
+			Statement struct {
+				Currency string `xml:"CURDEF"`
+				Account  struct {
+					BankId    string `xml:"BANKID"`
+					AccountId string `xml:"ACCTID"`
+					Type      string `xml:"ACCTTYPE"`
+				} `xml:"BANKACCTFROM"`
+				Transactions struct {
+					Start   string           `xml:"DTSTART"`
+					End     string           `xml:"DTEND"`
+					Entries []ofxTransaction `xml:"STMTTRN"`
+				} `xml:"BANKTRANLIST"`
+				LedgerBalance ofxBalance `xml:"LEDGERBAL"`
+			} `xml:"STMTRS"`
+		} `xml:"STMTTRNRS"`
+	} `xml:"BANKMSGSRSV1"`
+}
+
+type ofxStatus struct {
+	Code     int    `xml:"CODE"`
+	Severity string `xml:"SEVERITY"`
+}
+
+type ofxTransaction struct {
+	Type   string `xml:"TRNTYPE"`
+	Posted string `xml:"DTPOSTED"`
+	Amount string `xml:"TRNAMT"`
+	Id     string `xml:"FITID"`
+	Name   string `xml:"NAME"`
+}
+
+type ofxBalance struct {
+	Amount string `xml:"BALAMT"`
+	AsOf   string `xml:"DTASOF"`
+}
+
+// writeStatementOFX записывает выписку в формате OFX 2.2 для импорта в учётные программы.
+// Баланс на начало периода в OFX не передаётся: он следует из баланса на конец и транзакций.
+func writeStatementOFX(w io.Writer, statement domain.Statement) error {
+	var doc ofxDocument
+	ok := ofxStatus{Code: 0, Severity: "INFO"}
+	doc.SignOn.Response.Status = ok
+	doc.SignOn.Response.Server = formatOFXDate(time.Now())
+	doc.SignOn.Response.Language = "RUS"
+
+	response := &doc.Bank.Transaction
+	response.Uid = fmt.Sprintf("%d-%s", statement.AccountId, statement.PeriodEnd.UTC().Format(domain.StatementMonthFormat))
+	response.Status = ok
+	stmt := &response.Statement
+	stmt.Currency = statement.Currency
+	stmt.Account.BankId = ofxBankId
+	stmt.Account.AccountId = strconv.Itoa(statement.AccountId)
+	stmt.Account.Type = "CHECKING"
+	stmt.Transactions.Start = formatOFXDate(statement.PeriodStart)
+	stmt.Transactions.End = formatOFXDate(statement.PeriodEnd)
+	for _, entry := range statement.Entries {
+		trnType := "CREDIT"
+		if entry.Amount < 0 {
+			trnType = "DEBIT"
+		}
+		stmt.Transactions.Entries = append(stmt.Transactions.Entries, ofxTransaction{
+			Type:   trnType,
+			Posted: formatOFXDate(entry.Date),
+			Amount: formatAmount(entry.Amount),
+			Id:     strconv.Itoa(entry.TransactionId),
+			Name:   string(entry.Type),
+		})
+	}
+	stmt.LedgerBalance = ofxBalance{Amount: formatAmount(statement.ClosingBalance), AsOf: formatOFXDate(statement.PeriodEnd)}
+
+	_, err := io.WriteString(w, xml.Header+
+		`<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>`+"\n")
+	if err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	err = encoder.Encode(doc)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+// formatOFXDate форматирует дату в формате OFX в UTC.
+func formatOFXDate(date time.Time) string {
+	return date.UTC().Format("20060102150405") + "[0:GMT]"
+}
@@ -0,0 +1,72 @@
+package report
+
+import (
+	"bytes"
+	"encoding/xml"
+	"payment-service/internal/domain"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testStatement() domain.Statement {
+	start, end := domain.StatementPeriod(time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC))
+	opening := domain.AccountBalance{AccountId: 1, UserId: 5, Currency: "RUB", Balance: 100, At: start}
+	return domain.NewStatement(opening, []domain.Transaction{
+		{Id: 7, IsDeposit: true, Type: domain.TransactionTypeTopUp, Amount: 50, Status: domain.TransactionStatusCompleted,
+			Date: time.Date(2025, 10, 5, 12, 0, 0, 0, time.UTC)},
+		{Id: 8, Type: domain.TransactionTypePayment, Amount: 30, Status: domain.TransactionStatusCompleted,
+			Date: time.Date(2025, 10, 6, 12, 0, 0, 0, time.UTC)},
+	}, end)
+}
+
+func TestWriteStatement_CSV(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteStatement(&buf, FormatCSV, testStatement())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "date,transaction_id,type,amount,balance,currency\n" +
+		"2025-09-30T23:59:59Z,,opening_balance,,100.00,RUB\n" +
+		"2025-10-05T12:00:00Z,7,top_up,50.00,150.00,RUB\n" +
+		"2025-10-06T12:00:00Z,8,payment,-30.00,120.00,RUB\n" +
+		"2025-10-31T23:59:59Z,,closing_balance,,120.00,RUB\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestWriteStatement_OFX(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteStatement(&buf, FormatOFX, testStatement())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, `<?OFX OFXHEADER="200" VERSION="220"`) {
+		t.Errorf("missing OFX header: %s", out)
+	}
+
+	var doc ofxDocument
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid OFX document: %v", err)
+	}
+	stmt := doc.Bank.Transaction.Statement
+	if stmt.Account.AccountId != "1" || stmt.Currency != "RUB" || stmt.Transactions.Start != "20250930235959[0:GMT]" {
+		t.Errorf("unexpected statement: %+v", stmt)
+	}
+	entries := stmt.Transactions.Entries
+	if len(entries) != 2 || entries[0].Type != "CREDIT" || entries[1].Type != "DEBIT" || entries[1].Amount != "-30.00" || entries[1].Id != "8" {
+		t.Errorf("unexpected transactions: %+v", entries)
+	}
+	if stmt.LedgerBalance.Amount != "120.00" {
+		t.Errorf("unexpected ledger balance: %+v", stmt.LedgerBalance)
+	}
+}
+
+func TestWriteStatement_UnsupportedFormat(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteStatement(&buf, FormatJSON, testStatement()); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
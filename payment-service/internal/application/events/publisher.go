@@ -0,0 +1,13 @@
+package events
+
+import (
+	"context"
+	"payment-service/internal/domain"
+)
+
+// Publisher определяет интерфейс публикации доменных событий счетов для других сервисов.
+type Publisher interface {
+	// Publish публикует события в переданном порядке.
+	// События одного счёта должны доставляться потребителям в том же порядке.
+	Publish(ctx context.Context, events []domain.AccountEvent) error
+}
@@ -0,0 +1,15 @@
+package messaging
+
+import (
+	"context"
+	"contracts/transport"
+)
+
+// MessageBus определяет интерфейс получения запросов от других сервисов и отправки ответов на них.
+// Реализуется поверх Kafka и поверх брокера в памяти процесса.
+type MessageBus interface {
+	// Start передаёт полученные запросы обработчику handler и отправляет его ответы,
+	// пока не будет отменён контекст. После отмены дожидается обработки
+	// уже полученных запросов и возвращается.
+	Start(ctx context.Context, handler transport.Handler)
+}
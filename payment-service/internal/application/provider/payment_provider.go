@@ -0,0 +1,41 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"payment-service/internal/domain"
+)
+
+// SignatureHeader — заголовок HTTP-уведомления провайдера с подписью тела запроса.
+const SignatureHeader = "X-Signature"
+
+// ErrInvalidSignature возвращается, если подпись уведомления провайдера не прошла проверку.
+var ErrInvalidSignature = errors.New("invalid callback signature")
+
+// Payment — платёж, зарегистрированный у провайдера для пополнения счёта.
+type Payment struct {
+	Reference       string // Идентификатор платежа у провайдера
+	ConfirmationURL string // Адрес, по которому пользователь подтверждает оплату
+}
+
+// Callback — уведомление провайдера о результате оплаты.
+type Callback struct {
+	TopUpId       int    `json:"top_up_id"`      // ID пополнения, для которого создавался платёж
+	Reference     string `json:"reference"`      // Идентификатор платежа у провайдера
+	Succeeded     bool   `json:"succeeded"`      // true — оплата прошла, false — отклонена
+	FailureReason string `json:"failure_reason"` // Причина отказа для неуспешной оплаты
+}
+
+// PaymentProvider определяет интерфейс внешнего платёжного провайдера,
+// через которого пользователи пополняют счета.
+type PaymentProvider interface {
+	// Name возвращает название провайдера.
+	Name() string
+
+	// CreatePayment регистрирует у провайдера платёж для пополнения topUp.
+	CreatePayment(ctx context.Context, topUp domain.TopUp) (*Payment, error)
+
+	// ParseCallback проверяет подпись уведомления и разбирает его тело.
+	// Возвращает ErrInvalidSignature, если подпись не совпадает.
+	ParseCallback(body []byte, signature string) (*Callback, error)
+}
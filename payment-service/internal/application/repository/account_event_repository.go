@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+	"payment-service/internal/domain"
+	"time"
+)
+
+// AccountEventRepository определяет интерфейс журнала исходящих событий счетов (outbox).
+// События сохраняются в той же транзакции хранилища, что и изменения счёта,
+// и публикуются только после её фиксации.
+type AccountEventRepository interface {
+	// Save сохраняет событие для последующей публикации и заполняет его Id.
+	Save(ctx context.Context, event *domain.AccountEvent) error
+
+	// GetUnpublished возвращает до limit неопубликованных событий в порядке Id.
+	// Внутри транзакции возвращённые события блокируются до её завершения,
+	// а заблокированные другими транзакциями пропускаются.
+	GetUnpublished(ctx context.Context, limit int) ([]domain.AccountEvent, error)
+
+	// MarkPublished отмечает события ids опубликованными в момент at.
+	MarkPublished(ctx context.Context, ids []int64, at time.Time) error
+}
@@ -10,8 +10,18 @@ type AccountRepository interface {
 	// GetById возвращает счёт по его ID.
 	GetById(ctx context.Context, id int) (*domain.Account, error)
 
-	// GetByUserId возвращает счёт, принадлежащий конкретному пользователю.
-	GetByUserId(ctx context.Context, userId int) (*domain.Account, error)
+	// GetDefaultByUserId возвращает основной счёт пользователя.
+	GetDefaultByUserId(ctx context.Context, userId int) (*domain.Account, error)
+
+	// GetUserAccounts возвращает все счета пользователя.
+	GetUserAccounts(ctx context.Context, userId int) ([]domain.Account, error)
+
+	// GetAll возвращает все счета.
+	GetAll(ctx context.Context) ([]domain.Account, error)
+
+	// SetDefault делает счёт accountId основным для пользователя userId,
+	// снимая этот признак с остальных его счетов.
+	SetDefault(ctx context.Context, userId int, accountId int) error
 
 	// Save сохраняет или обновляет данные счёта.
 	Save(ctx context.Context, account *domain.Account) error
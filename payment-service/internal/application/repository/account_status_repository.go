@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+	"payment-service/internal/domain"
+)
+
+// AccountStatusRepository определяет интерфейс для работы с журналом изменений статусов счетов.
+type AccountStatusRepository interface {
+	// Save добавляет запись об изменении статуса в журнал.
+	Save(ctx context.Context, change *domain.AccountStatusChange) error
+
+	// GetByAccountId возвращает историю изменений статуса счёта в хронологическом порядке.
+	GetByAccountId(ctx context.Context, accountId int) ([]domain.AccountStatusChange, error)
+}
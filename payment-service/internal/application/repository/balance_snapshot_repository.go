@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"payment-service/internal/domain"
+	"time"
+)
+
+// BalanceSnapshotRepository определяет интерфейс для работы со снимками балансов счетов.
+type BalanceSnapshotRepository interface {
+	// GetLatest возвращает последний снимок баланса счёта, сделанный не позже момента at.
+	// Возвращает nil, nil если такого снимка нет.
+	GetLatest(ctx context.Context, accountId int, at time.Time) (*domain.BalanceSnapshot, error)
+
+	// Save сохраняет снимок баланса.
+	// Если снимок счёта на тот же момент уже существует — перезаписывает его.
+	Save(ctx context.Context, snapshot *domain.BalanceSnapshot) error
+
+	// DeleteFrom удаляет снимки баланса счёта, сделанные в момент from или позже.
+	DeleteFrom(ctx context.Context, accountId int, from time.Time) error
+}
@@ -0,0 +1,7 @@
+package repository
+
+import "errors"
+
+// ErrDuplicateTransaction возвращается при попытке сохранить транзакцию,
+// идентификатор которой уже есть в хранилище.
+var ErrDuplicateTransaction = errors.New("transaction already exists")
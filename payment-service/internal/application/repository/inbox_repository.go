@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"payment-service/internal/domain"
+)
+
+// InboxRepository определяет интерфейс для работы с журналом обработанных входящих сообщений.
+type InboxRepository interface {
+	// GetById возвращает запись об обработке сообщения по его идентификатору.
+	// Внутри транзакции строка блокируется до её завершения.
+	// Возвращает nil, nil если сообщение ещё не обработано.
+	GetById(ctx context.Context, messageId string) (*domain.InboxMessage, error)
+
+	// Save сохраняет запись об обработке сообщения.
+	Save(ctx context.Context, message *domain.InboxMessage) error
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+	"payment-service/internal/domain"
+)
+
+// SpendingLimitsRepository определяет интерфейс для работы с ограничениями на списания со счетов.
+type SpendingLimitsRepository interface {
+	// GetByAccountId возвращает ограничения счёта или nil, если они не заданы.
+	GetByAccountId(ctx context.Context, accountId int) (*domain.SpendingLimits, error)
+
+	// Save сохраняет или обновляет ограничения счёта.
+	Save(ctx context.Context, limits *domain.SpendingLimits) error
+
+	// Delete снимает все ограничения со счёта.
+	Delete(ctx context.Context, accountId int) error
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+	"payment-service/internal/domain"
+)
+
+// TopUpRepository определяет интерфейс для работы с пополнениями через платёжного провайдера.
+type TopUpRepository interface {
+	// GetById возвращает пополнение по его ID.
+	// Возвращает nil, nil если пополнение не найдено.
+	GetById(ctx context.Context, id int) (*domain.TopUp, error)
+
+	// GetByAccountId возвращает пополнения кошелька, начиная с последних.
+	GetByAccountId(ctx context.Context, accountId int) ([]domain.TopUp, error)
+
+	// GetByStatus возвращает пополнения в статусе status, начиная с последних.
+	GetByStatus(ctx context.Context, status domain.TopUpStatus) ([]domain.TopUp, error)
+
+	// Save сохраняет пополнение.
+	// Если пополнение уже существует — обновляет его состояние.
+	Save(ctx context.Context, topUp *domain.TopUp) error
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"payment-service/internal/domain"
+	"time"
 )
 
 // TransactionRepository определяет интерфейс для работы с транзакциями.
@@ -10,6 +11,37 @@ type TransactionRepository interface {
 	// GetById возвращает транзакцию по её ID.
 	GetById(ctx context.Context, id int) (*domain.Transaction, error)
 
+	// GetByAccountId возвращает все транзакции счёта в порядке их проведения.
+	GetByAccountId(ctx context.Context, accountId int) ([]domain.Transaction, error)
+
+	// GetCompletedBetween возвращает проведённые транзакции счёта с датой в промежутке (after, until]
+	// в порядке их проведения.
+	GetCompletedBetween(ctx context.Context, accountId int, after, until time.Time) ([]domain.Transaction, error)
+
+	// GetSpendingActivity возвращает сумму и число проведённых платежей со счёта
+	// в окнах, заданных domain.SpendingWindows для момента now.
+	GetSpendingActivity(ctx context.Context, accountId int, now time.Time) (*domain.SpendingActivity, error)
+
+	// GetBalanceChange возвращает изменение баланса счёта по проведённым транзакциям,
+	// дата которых лежит в промежутке (after, until].
+	GetBalanceChange(ctx context.Context, accountId int, after, until time.Time) (float64, error)
+
+	// GetOldestPendingReviewDate возвращает дату самой ранней транзакции, ожидающей ручной проверки.
+	// Возвращает nil, если таких транзакций нет.
+	GetOldestPendingReviewDate(ctx context.Context) (*time.Time, error)
+
+	// CountDeclined возвращает число отклонённых транзакций пользователя начиная с момента since.
+	CountDeclined(ctx context.Context, userId int, since time.Time) (int, error)
+
+	// UpdateStatus сохраняет результат обработки ранее сохранённой транзакции.
+	UpdateStatus(ctx context.Context, transaction *domain.Transaction) error
+
+	// AddReversedAmount увеличивает отменённую часть суммы транзакции id на amount.
+	// Возвращает domain.ErrReversalExceeded, если отменённая часть превысила бы сумму транзакции,
+	// поэтому одна и та же сумма не может быть отменена дважды даже при параллельных запросах.
+	AddReversedAmount(ctx context.Context, id int, amount float64) error
+
 	// Save сохраняет новую транзакцию в хранилище.
+	// Возвращает ErrDuplicateTransaction, если транзакция с таким ID уже сохранена.
 	Save(ctx context.Context, transaction *domain.Transaction) error
 }
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+	"payment-service/internal/domain"
+)
+
+// TransactionReviewRepository определяет интерфейс для работы с очередью ручной проверки операций.
+type TransactionReviewRepository interface {
+	// GetByTransactionId возвращает запись проверки транзакции или nil, если её нет.
+	GetByTransactionId(ctx context.Context, transactionId int) (*domain.TransactionReview, error)
+
+	// GetPending возвращает операции, ожидающие решения, начиная с самых ранних.
+	GetPending(ctx context.Context) ([]domain.TransactionReview, error)
+
+	// Save сохраняет или обновляет запись проверки.
+	Save(ctx context.Context, review *domain.TransactionReview) error
+}
@@ -0,0 +1,12 @@
+package repository
+
+import "context"
+
+// Transactor определяет интерфейс для выполнения нескольких операций с репозиториями
+// в одной транзакции хранилища.
+type Transactor interface {
+	// WithinTransaction выполняет fn в транзакции хранилища.
+	// Репозитории, вызванные с переданным в fn контекстом, работают в этой транзакции.
+	// Если fn возвращает ошибку, все изменения откатываются.
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -11,21 +11,48 @@ import (
 
 // AccountService предоставляет бизнес-логику для работы со счетами пользователей.
 type AccountService struct {
-	accountDb repository.AccountRepository
+	accountDb     repository.AccountRepository
+	transactionDb repository.TransactionRepository
+	statusDb      repository.AccountStatusRepository
+	limitsDb      repository.SpendingLimitsRepository
+	eventDb       repository.AccountEventRepository
+	transactor    repository.Transactor
 }
 
 // NewAccountService создаёт новый экземпляр AccountService.
-func NewAccountService(accountDb repository.AccountRepository) *AccountService {
-	return &AccountService{accountDb: accountDb}
+func NewAccountService(accountDb repository.AccountRepository, transactionDb repository.TransactionRepository,
+	statusDb repository.AccountStatusRepository, limitsDb repository.SpendingLimitsRepository,
+	eventDb repository.AccountEventRepository, transactor repository.Transactor) *AccountService {
+	return &AccountService{accountDb: accountDb, transactionDb: transactionDb, statusDb: statusDb, limitsDb: limitsDb,
+		eventDb: eventDb, transactor: transactor}
 }
 
-// CreateAccount создаёт новый счёт для пользователя.
-// Идентификатор генерируется случайно (временно), баланс устанавливается в 0.
-// Если сохранение не удалось — возвращает ошибку.
+// CreateAccount создаёт для пользователя основной кошелёк с названием и валютой по умолчанию.
+// Если такой кошелёк уже существует — возвращает ошибку.
 func (as *AccountService) CreateAccount(ctx context.Context, userID int) (*domain.Account, error) {
-	dupe, err := as.accountDb.GetByUserId(ctx, userID)
-	if err == nil && dupe != nil {
-		return nil, errors.New("account with that user_id already exists")
+	return as.CreateWallet(ctx, userID, domain.DefaultWalletName, domain.DefaultCurrency, false)
+}
+
+// CreateWallet создаёт новый именованный кошелёк пользователя.
+// Идентификатор генерируется случайно (временно), баланс устанавливается в 0.
+// Первый кошелёк пользователя всегда становится основным; для последующих
+// признак основного устанавливается, только если передан isDefault.
+// Если кошелёк с таким названием уже есть или сохранение не удалось — возвращает ошибку.
+func (as *AccountService) CreateWallet(ctx context.Context, userID int, name, currency string, isDefault bool) (*domain.Account, error) {
+	if name == "" {
+		name = domain.DefaultWalletName
+	}
+	if currency == "" {
+		currency = domain.DefaultCurrency
+	}
+	existing, err := as.accountDb.GetUserAccounts(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, wallet := range existing {
+		if wallet.Name == name {
+			return nil, errors.New("account with that name already exists for user")
+		}
 	}
 	id := rand.Intn(2147483645) // TODO: change to go-uuid
 	for range 5 {
@@ -36,12 +63,30 @@ func (as *AccountService) CreateAccount(ctx context.Context, userID int) (*domai
 		id++
 	}
 	account := &domain.Account{
-		Id:           id,
-		UserId:       userID,
-		Balance:      0,
-		CreationDate: time.Now(),
+		Id:              id,
+		UserId:          userID,
+		Name:            name,
+		Currency:        currency,
+		IsDefault:       len(existing) == 0,
+		Status:          domain.AccountStatusActive,
+		Balance:         0,
+		GracePeriodDays: domain.DefaultGracePeriodDays,
+		CreationDate:    time.Now(),
 	}
-	err = as.accountDb.Save(ctx, account)
+	err = as.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		err := as.accountDb.Save(ctx, account)
+		if err != nil {
+			return err
+		}
+		if isDefault && !account.IsDefault {
+			err = as.accountDb.SetDefault(ctx, userID, account.Id)
+			if err != nil {
+				return err
+			}
+			account.IsDefault = true
+		}
+		return recordEvent(ctx, as.eventDb, domain.NewAccountCreatedEvent(*account, account.CreationDate))
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -51,6 +96,32 @@ func (as *AccountService) CreateAccount(ctx context.Context, userID int) (*domai
 // GetAccount возвращает счёт по его идентификатору.
 // Если счёт не найден, возвращает ошибку.
 func (as *AccountService) GetAccount(ctx context.Context, id int) (*domain.Account, error) {
+	account, err := as.accountDb.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, domain.ErrAccountNotFound
+	}
+	return account, nil
+}
+
+// GetUsersAccounts возвращает все кошельки пользователя по его userId.
+// Если у пользователя нет ни одного кошелька — возвращает ошибку.
+func (as *AccountService) GetUsersAccounts(ctx context.Context, userId int) ([]domain.Account, error) {
+	accounts, err := as.accountDb.GetUserAccounts(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if len(accounts) == 0 {
+		return nil, errors.New("account not found")
+	}
+	return accounts, nil
+}
+
+// SetDefaultAccount делает кошелёк основным для его владельца.
+// Если кошелёк не найден — возвращает ошибку.
+func (as *AccountService) SetDefaultAccount(ctx context.Context, id int) (*domain.Account, error) {
 	account, err := as.accountDb.GetById(ctx, id)
 	if err != nil {
 		return nil, err
@@ -58,38 +129,182 @@ func (as *AccountService) GetAccount(ctx context.Context, id int) (*domain.Accou
 	if account == nil {
 		return nil, errors.New("account not found")
 	}
+	if account.Status == domain.AccountStatusClosed {
+		return nil, errors.New("closed account can not be default")
+	}
+	err = as.accountDb.SetDefault(ctx, account.UserId, account.Id)
+	if err != nil {
+		return nil, err
+	}
+	account.IsDefault = true
 	return account, nil
 }
 
-// GetUsersAccount возвращает счёт пользователя по его userId.
+// SetCreditLimit устанавливает кредитный лимит и льготный период для счёта.
+// Если gracePeriodDays равен nil, льготный период счёта не меняется.
+// Счёт изменяется в транзакции с блокировкой строки, чтобы не затереть баланс,
+// изменённый параллельным платежом.
+// Если счёт не найден (domain.ErrAccountNotFound) или параметры некорректны
+// (domain.ErrInvalidCreditLimit) — возвращает ошибку.
+func (as *AccountService) SetCreditLimit(ctx context.Context, id int, limit float64, gracePeriodDays *int) (*domain.Account, error) {
+	var account *domain.Account
+	err := as.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		account, err = as.accountDb.GetById(ctx, id)
+		if err != nil {
+			return err
+		}
+		if account == nil {
+			return domain.ErrAccountNotFound
+		}
+		grace := account.GracePeriodDays
+		if gracePeriodDays != nil {
+			grace = *gracePeriodDays
+		}
+		err = account.SetCreditLimit(limit, grace)
+		if err != nil {
+			return err
+		}
+		return as.accountDb.Save(ctx, account)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// ChangeStatus переводит счёт в новый статус и записывает изменение в журнал
+// вместе с автором и причиной.
+// Для закрытия счёта баланс должен быть нулевым; если передан payout, положительный
+// остаток предварительно выплачивается и фиксируется транзакцией типа payout.
+// Если счёт не найден или переход недопустим — возвращает ошибку.
+func (as *AccountService) ChangeStatus(ctx context.Context, id int, status domain.AccountStatus, changedBy, reason string, payout bool) (*domain.Account, error) {
+	var account *domain.Account
+	err := as.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		account, err = as.accountDb.GetById(ctx, id)
+		if err != nil {
+			return err
+		}
+		if account == nil {
+			return errors.New("account not found")
+		}
+		var payoutTransaction *domain.Transaction
+		if status == domain.AccountStatusClosed && payout && account.Balance > 0 {
+			payoutTransaction, err = newPayoutTransaction(account)
+			if err != nil {
+				return err
+			}
+		}
+		change, err := account.ChangeStatus(status, changedBy, reason)
+		if err != nil {
+			return err
+		}
+		if payoutTransaction != nil {
+			err = as.transactionDb.Save(ctx, payoutTransaction)
+			if err != nil {
+				return err
+			}
+		}
+		err = as.accountDb.Save(ctx, account)
+		if err != nil {
+			return err
+		}
+		err = as.statusDb.Save(ctx, change)
+		if err != nil {
+			return err
+		}
+		if payoutTransaction != nil {
+			err = recordEvent(ctx, as.eventDb, domain.NewBalanceChangedEvent(*account, *payoutTransaction, change.ChangedAt))
+			if err != nil {
+				return err
+			}
+		}
+		return recordEvent(ctx, as.eventDb, domain.NewStatusChangedEvent(*account, *change))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// GetStatusHistory возвращает журнал изменений статуса счёта.
 // Если счёт не найден — возвращает ошибку.
-func (as *AccountService) GetUsersAccount(ctx context.Context, userId int) (*domain.Account, error) {
-	account, err := as.accountDb.GetByUserId(ctx, userId)
+func (as *AccountService) GetStatusHistory(ctx context.Context, id int) ([]domain.AccountStatusChange, error) {
+	account, err := as.accountDb.GetById(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 	if account == nil {
 		return nil, errors.New("account not found")
 	}
-	return account, nil
+	return as.statusDb.GetByAccountId(ctx, id)
 }
 
-// Deposit пополняет баланс счёта на указанную сумму.
-// Если счёт не найден или сумма отрицательная — возвращает ошибку.
-func (as *AccountService) Deposit(ctx context.Context, id int, amount float64) error {
+// GetSpendingLimits возвращает ограничения на списания со счёта.
+// Если ограничения не заданы, возвращает пустые ограничения.
+// Если счёт не найден — возвращает ошибку.
+func (as *AccountService) GetSpendingLimits(ctx context.Context, id int) (*domain.SpendingLimits, error) {
 	account, err := as.accountDb.GetById(ctx, id)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if account == nil {
-		return errors.New("account not found")
+		return nil, errors.New("account not found")
 	}
-	err = account.Deposit(amount)
+	limits, err := as.limitsDb.GetByAccountId(ctx, id)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	err = as.accountDb.Save(ctx, account)
-	return err
+	if limits == nil {
+		limits = &domain.SpendingLimits{AccountId: id}
+	}
+	return limits, nil
+}
+
+// SetSpendingLimits заменяет ограничения на списания со счёта.
+// Если счёт не найден или ограничения отрицательные — возвращает ошибку.
+func (as *AccountService) SetSpendingLimits(ctx context.Context, id int, limits domain.SpendingLimits) (*domain.SpendingLimits, error) {
+	err := limits.Validate()
+	if err != nil {
+		return nil, err
+	}
+	account, err := as.accountDb.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, errors.New("account not found")
+	}
+	limits.AccountId = id
+	limits.UpdatedAt = time.Now()
+	err = as.limitsDb.Save(ctx, &limits)
+	if err != nil {
+		return nil, err
+	}
+	return &limits, nil
 }
 
-//func (as *AccountService) DeleteAccount(ctx context.Context, id int) error {} TODO
+// RemoveSpendingLimits снимает все ограничения на списания со счёта.
+func (as *AccountService) RemoveSpendingLimits(ctx context.Context, id int) error {
+	return as.limitsDb.Delete(ctx, id)
+}
+
+// newPayoutTransaction обнуляет положительный остаток счёта и возвращает транзакцию выплаты.
+// Идентификатор транзакции генерируется случайно.
+func newPayoutTransaction(account *domain.Account) (*domain.Transaction, error) {
+	amount, err := account.PayOut()
+	if err != nil {
+		return nil, err
+	}
+	return &domain.Transaction{
+		Id:        rand.Intn(2147483645),
+		UserId:    account.UserId,
+		AccountId: account.Id,
+		IsDeposit: false,
+		Type:      domain.TransactionTypePayout,
+		Amount:    amount,
+		Status:    domain.TransactionStatusCompleted,
+		Date:      time.Now(),
+	}, nil
+}
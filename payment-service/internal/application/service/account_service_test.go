@@ -4,14 +4,17 @@ import (
 	"context"
 	"errors"
 	"payment-service/internal/domain"
+	"slices"
 	"testing"
-	"time"
 )
 
 type mockAccountRepository struct {
-	getByIdFunc     func(ctx context.Context, id int) (*domain.Account, error)
-	getByUserIdFunc func(ctx context.Context, userId int) (*domain.Account, error)
-	saveFunc        func(ctx context.Context, account *domain.Account) error
+	getByIdFunc            func(ctx context.Context, id int) (*domain.Account, error)
+	getDefaultByUserIdFunc func(ctx context.Context, userId int) (*domain.Account, error)
+	getUserAccountsFunc    func(ctx context.Context, userId int) ([]domain.Account, error)
+	getAllFunc             func(ctx context.Context) ([]domain.Account, error)
+	setDefaultFunc         func(ctx context.Context, userId int, accountId int) error
+	saveFunc               func(ctx context.Context, account *domain.Account) error
 }
 
 func (m *mockAccountRepository) GetById(ctx context.Context, id int) (*domain.Account, error) {
@@ -21,13 +24,34 @@ func (m *mockAccountRepository) GetById(ctx context.Context, id int) (*domain.Ac
 	return nil, nil
 }
 
-func (m *mockAccountRepository) GetByUserId(ctx context.Context, userId int) (*domain.Account, error) {
-	if m.getByUserIdFunc != nil {
-		return m.getByUserIdFunc(ctx, userId)
+func (m *mockAccountRepository) GetDefaultByUserId(ctx context.Context, userId int) (*domain.Account, error) {
+	if m.getDefaultByUserIdFunc != nil {
+		return m.getDefaultByUserIdFunc(ctx, userId)
 	}
 	return nil, nil
 }
 
+func (m *mockAccountRepository) GetUserAccounts(ctx context.Context, userId int) ([]domain.Account, error) {
+	if m.getUserAccountsFunc != nil {
+		return m.getUserAccountsFunc(ctx, userId)
+	}
+	return nil, nil
+}
+
+func (m *mockAccountRepository) GetAll(ctx context.Context) ([]domain.Account, error) {
+	if m.getAllFunc != nil {
+		return m.getAllFunc(ctx)
+	}
+	return nil, nil
+}
+
+func (m *mockAccountRepository) SetDefault(ctx context.Context, userId int, accountId int) error {
+	if m.setDefaultFunc != nil {
+		return m.setDefaultFunc(ctx, userId, accountId)
+	}
+	return nil
+}
+
 func (m *mockAccountRepository) Save(ctx context.Context, account *domain.Account) error {
 	if m.saveFunc != nil {
 		return m.saveFunc(ctx, account)
@@ -35,83 +59,175 @@ func (m *mockAccountRepository) Save(ctx context.Context, account *domain.Accoun
 	return nil
 }
 
-func TestAccountService_Deposit(t *testing.T) {
-	ctx := context.Background()
-	account := &domain.Account{Id: 1, Balance: 100, CreationDate: time.Now()}
+type mockAccountStatusRepository struct {
+	changes []domain.AccountStatusChange
+}
 
-	tests := []struct {
-		name       string
-		amount     float64
-		setupRepo  func() *mockAccountRepository
-		wantErr    bool
-		finalValue float64
-	}{
-		{
-			name:   "успешное пополнение",
-			amount: 50,
-			setupRepo: func() *mockAccountRepository {
-				return &mockAccountRepository{
-					getByIdFunc: func(ctx context.Context, id int) (*domain.Account, error) {
-						return account, nil
-					},
-					saveFunc: func(ctx context.Context, acc *domain.Account) error {
-						if acc.Balance != 150 {
-							t.Errorf("ожидался баланс 150, получен %.2f", acc.Balance)
-						}
-						return nil
-					},
-				}
-			},
-			wantErr:    false,
-			finalValue: 150,
+func (m *mockAccountStatusRepository) Save(ctx context.Context, change *domain.AccountStatusChange) error {
+	m.changes = append(m.changes, *change)
+	return nil
+}
+
+func (m *mockAccountStatusRepository) GetByAccountId(ctx context.Context, accountId int) ([]domain.AccountStatusChange, error) {
+	result := make([]domain.AccountStatusChange, 0)
+	for _, change := range m.changes {
+		if change.AccountId == accountId {
+			result = append(result, change)
+		}
+	}
+	return result, nil
+}
+
+// inTransaction — ключ контекста, которым markingTransactor отмечает код внутри транзакции.
+type inTransaction struct{}
+
+// markingTransactor выполняет fn с контекстом, отмеченным ключом inTransaction.
+type markingTransactor struct{}
+
+func (markingTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(context.WithValue(ctx, inTransaction{}, true))
+}
+
+func TestAccountService_SetCreditLimit(t *testing.T) {
+	ctx := context.Background()
+	var saved *domain.Account
+	repo := &mockAccountRepository{
+		getByIdFunc: func(ctx context.Context, id int) (*domain.Account, error) {
+			if ctx.Value(inTransaction{}) == nil {
+				t.Error("счёт прочитан вне транзакции")
+			}
+			return &domain.Account{Id: id, Balance: 0, GracePeriodDays: 20}, nil
 		},
-		{
-			name:   "отрицательная сумма — ошибка",
-			amount: -10,
-			setupRepo: func() *mockAccountRepository {
-				return &mockAccountRepository{
-					getByIdFunc: func(ctx context.Context, id int) (*domain.Account, error) {
-						return account, nil
-					},
-				}
-			},
-			wantErr: true,
+		saveFunc: func(ctx context.Context, acc *domain.Account) error {
+			if ctx.Value(inTransaction{}) == nil {
+				t.Error("счёт сохранён вне транзакции")
+			}
+			saved = acc
+			return nil
 		},
-		{
-			name:   "счёт не найден",
-			amount: 10,
-			setupRepo: func() *mockAccountRepository {
-				return &mockAccountRepository{
-					getByIdFunc: func(ctx context.Context, id int) (*domain.Account, error) {
-						return nil, nil
-					},
-				}
-			},
-			wantErr: true,
+	}
+	svc := NewAccountService(repo, &mockTransactionRepo{}, &mockAccountStatusRepository{}, &mockSpendingLimitsRepo{}, &mockAccountEventRepo{}, markingTransactor{})
+
+	gracePeriodDays := 45
+	account, err := svc.SetCreditLimit(ctx, 1, 1000, &gracePeriodDays)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if saved == nil || saved.CreditLimit != 1000 || saved.GracePeriodDays != 45 {
+		t.Errorf("лимит не сохранён: %+v", saved)
+	}
+	if account.AvailableFunds() != 1000 {
+		t.Errorf("ожидались доступные средства 1000, получено %.2f", account.AvailableFunds())
+	}
+
+	_, err = svc.SetCreditLimit(ctx, 1, 500, nil)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if saved.CreditLimit != 500 || saved.GracePeriodDays != 20 {
+		t.Errorf("без льготного периода ожидался прежний период 20: %+v", saved)
+	}
+
+	_, err = svc.SetCreditLimit(ctx, 1, -5, &gracePeriodDays)
+	if !errors.Is(err, domain.ErrInvalidCreditLimit) {
+		t.Errorf("ожидалась ошибка ErrInvalidCreditLimit для отрицательного лимита, получено %v", err)
+	}
+}
+
+func TestAccountService_CreateWallet(t *testing.T) {
+	ctx := context.Background()
+	existing := []domain.Account{{Id: 1, UserId: 7, Name: domain.DefaultWalletName, IsDefault: true}}
+	defaultSet := false
+	repo := &mockAccountRepository{
+		getByIdFunc: func(ctx context.Context, id int) (*domain.Account, error) {
+			return nil, errors.New("account not found")
 		},
-		{
-			name:   "ошибка при сохранении",
-			amount: 10,
-			setupRepo: func() *mockAccountRepository {
-				return &mockAccountRepository{
-					getByIdFunc: func(ctx context.Context, id int) (*domain.Account, error) {
-						return account, nil
-					},
-					saveFunc: func(ctx context.Context, acc *domain.Account) error {
-						return errors.New("save error")
-					},
-				}
-			},
-			wantErr: true,
+		getUserAccountsFunc: func(ctx context.Context, userId int) ([]domain.Account, error) {
+			return existing, nil
+		},
+		setDefaultFunc: func(ctx context.Context, userId int, accountId int) error {
+			defaultSet = true
+			return nil
 		},
 	}
+	svc := NewAccountService(repo, &mockTransactionRepo{}, &mockAccountStatusRepository{}, &mockSpendingLimitsRepo{}, &mockAccountEventRepo{}, &mockTransactor{})
+
+	wallet, err := svc.CreateWallet(ctx, 7, "bonus", "", true)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if wallet.Name != "bonus" || wallet.Currency != domain.DefaultCurrency {
+		t.Errorf("неожиданный кошелёк: %+v", wallet)
+	}
+	if !defaultSet || !wallet.IsDefault {
+		t.Error("кошелёк не стал основным")
+	}
+
+	_, err = svc.CreateWallet(ctx, 7, domain.DefaultWalletName, "", false)
+	if err == nil {
+		t.Error("ожидалась ошибка для дублирующегося названия")
+	}
+}
+
+func TestAccountService_ChangeStatus(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		balance     float64
+		status      domain.AccountStatus
+		payout      bool
+		wantErr     bool
+		wantPayout  float64
+		wantHistory int
+		wantEvents  []domain.AccountEventType
+	}{
+		{name: "заморозка счёта", balance: 100, status: domain.AccountStatusFrozen, wantHistory: 1,
+			wantEvents: []domain.AccountEventType{domain.AccountEventStatusChanged}},
+		{name: "закрытие с ненулевым балансом без выплаты", balance: 100, status: domain.AccountStatusClosed, wantErr: true},
+		{name: "закрытие с выплатой остатка", balance: 100, status: domain.AccountStatusClosed, payout: true, wantPayout: 100, wantHistory: 1,
+			wantEvents: []domain.AccountEventType{domain.AccountEventDebited, domain.AccountEventStatusChanged}},
+		{name: "закрытие с задолженностью", balance: -10, status: domain.AccountStatusClosed, payout: true, wantErr: true},
+	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			svc := NewAccountService(tt.setupRepo())
-			err := svc.Deposit(ctx, 1, tt.amount)
+			account := &domain.Account{Id: 1, UserId: 10, Balance: tt.balance, CreditLimit: 50, Status: domain.AccountStatusActive}
+			repo := &mockAccountRepository{
+				getByIdFunc: func(ctx context.Context, id int) (*domain.Account, error) {
+					return account, nil
+				},
+			}
+			var payouts []domain.Transaction
+			txRepo := &mockTransactionRepo{
+				saveFunc: func(ctx context.Context, tx *domain.Transaction) error {
+					payouts = append(payouts, *tx)
+					return nil
+				},
+			}
+			statusRepo := &mockAccountStatusRepository{}
+			eventRepo := &mockAccountEventRepo{}
+			svc := NewAccountService(repo, txRepo, statusRepo, &mockSpendingLimitsRepo{}, eventRepo, &mockTransactor{})
+
+			_, err := svc.ChangeStatus(ctx, 1, tt.status, "admin", "test", tt.payout)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("ожидалась ошибка=%v, получено %v", tt.wantErr, err)
+				t.Fatalf("ожидалась ошибка=%v, получено %v", tt.wantErr, err)
+			}
+			if len(statusRepo.changes) != tt.wantHistory {
+				t.Errorf("ожидалось записей в журнале: %d, получено %d", tt.wantHistory, len(statusRepo.changes))
+			}
+			if !slices.Equal(eventRepo.types(), tt.wantEvents) {
+				t.Errorf("ожидались события %v, получено %v", tt.wantEvents, eventRepo.types())
+			}
+			if tt.wantPayout > 0 {
+				if len(payouts) != 1 || payouts[0].Amount != tt.wantPayout || payouts[0].Type != domain.TransactionTypePayout {
+					t.Errorf("неожиданная выплата: %+v", payouts)
+				}
+				if account.Balance != 0 || account.Status != domain.AccountStatusClosed {
+					t.Errorf("счёт не закрыт: %+v", account)
+				}
+			} else if len(payouts) != 0 {
+				t.Errorf("неожиданная выплата: %+v", payouts)
 			}
 		})
 	}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"payment-service/internal/application/repository"
+	"payment-service/internal/domain"
+	"time"
+)
+
+// ErrFutureBalanceTime возвращается при запросе баланса на момент в будущем.
+var ErrFutureBalanceTime = errors.New("balance time is in the future")
+
+// BalanceService рассчитывает балансы счетов на произвольный момент времени
+// по истории транзакций и периодически фиксирует снимки балансов,
+// чтобы не пересчитывать историю целиком.
+type BalanceService struct {
+	accountDb     repository.AccountRepository
+	transactionDb repository.TransactionRepository
+	snapshotDb    repository.BalanceSnapshotRepository
+}
+
+// NewBalanceService создаёт новый экземпляр BalanceService.
+func NewBalanceService(accountDb repository.AccountRepository, transactionDb repository.TransactionRepository,
+	snapshotDb repository.BalanceSnapshotRepository) *BalanceService {
+	return &BalanceService{accountDb: accountDb, transactionDb: transactionDb, snapshotDb: snapshotDb}
+}
+
+// GetBalanceAt возвращает баланс счёта accountId на момент at.
+// Учитываются проведённые транзакции с датой не позже at.
+// Если счёт не найден или момент at в будущем — возвращает ошибку.
+func (bs *BalanceService) GetBalanceAt(ctx context.Context, accountId int, at time.Time) (*domain.AccountBalance, error) {
+	if at.After(time.Now()) {
+		return nil, ErrFutureBalanceTime
+	}
+	account, err := bs.accountDb.GetById(ctx, accountId)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, domain.ErrAccountNotFound
+	}
+	return bs.balanceAt(ctx, *account, at)
+}
+
+// GetBalancesAt возвращает балансы всех счетов, открытых не позже момента at,
+// для отчёта на конец периода.
+func (bs *BalanceService) GetBalancesAt(ctx context.Context, at time.Time) ([]domain.AccountBalance, error) {
+	if at.After(time.Now()) {
+		return nil, ErrFutureBalanceTime
+	}
+	accounts, err := bs.accountDb.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	balances := make([]domain.AccountBalance, 0, len(accounts))
+	for _, account := range accounts {
+		if account.CreationDate.After(at) {
+			continue
+		}
+		balance, err := bs.balanceAt(ctx, account, at)
+		if err != nil {
+			return nil, err
+		}
+		balances = append(balances, *balance)
+	}
+	return balances, nil
+}
+
+// TakeSnapshots фиксирует балансы всех счетов и возвращает число сохранённых снимков.
+// Снимок делается на момент now, но не позже самой ранней транзакции, ожидающей ручной проверки:
+// одобренная позже транзакция сохраняет исходную дату и иначе не попала бы в уже сделанный снимок.
+func (bs *BalanceService) TakeSnapshots(ctx context.Context, now time.Time) (int, error) {
+	takenAt := now.Truncate(time.Microsecond)
+	oldestPending, err := bs.transactionDb.GetOldestPendingReviewDate(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if oldestPending != nil && !oldestPending.After(takenAt) {
+		takenAt = oldestPending.Add(-time.Microsecond)
+	}
+	accounts, err := bs.accountDb.GetAll(ctx)
+	if err != nil {
+		return 0, err
+	}
+	saved := 0
+	for _, account := range accounts {
+		if account.CreationDate.After(takenAt) {
+			continue
+		}
+		balance, err := bs.balanceAt(ctx, account, takenAt)
+		if err != nil {
+			return saved, err
+		}
+		err = bs.snapshotDb.Save(ctx, &domain.BalanceSnapshot{
+			AccountId: account.Id,
+			Balance:   balance.Balance,
+			TakenAt:   takenAt,
+			CreatedAt: now,
+		})
+		if err != nil {
+			return saved, err
+		}
+		saved++
+	}
+	return saved, nil
+}
+
+// balanceAt рассчитывает баланс счёта на момент at от ближайшего предшествующего снимка.
+func (bs *BalanceService) balanceAt(ctx context.Context, account domain.Account, at time.Time) (*domain.AccountBalance, error) {
+	snapshot, err := bs.snapshotDb.GetLatest(ctx, account.Id, at)
+	if err != nil {
+		return nil, err
+	}
+	var after time.Time
+	if snapshot != nil {
+		after = snapshot.TakenAt
+	}
+	change, err := bs.transactionDb.GetBalanceChange(ctx, account.Id, after, at)
+	if err != nil {
+		return nil, err
+	}
+	balance := domain.BalanceAt(account, snapshot, change, at)
+	return &balance, nil
+}
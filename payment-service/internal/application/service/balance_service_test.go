@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"payment-service/internal/domain"
+	"testing"
+	"time"
+)
+
+type mockBalanceSnapshotRepo struct {
+	snapshots []domain.BalanceSnapshot
+}
+
+func (m *mockBalanceSnapshotRepo) GetLatest(ctx context.Context, accountId int, at time.Time) (*domain.BalanceSnapshot, error) {
+	var latest *domain.BalanceSnapshot
+	for i, snapshot := range m.snapshots {
+		if snapshot.AccountId != accountId || snapshot.TakenAt.After(at) {
+			continue
+		}
+		if latest == nil || snapshot.TakenAt.After(latest.TakenAt) {
+			latest = &m.snapshots[i]
+		}
+	}
+	return latest, nil
+}
+
+func (m *mockBalanceSnapshotRepo) Save(ctx context.Context, snapshot *domain.BalanceSnapshot) error {
+	m.snapshots = append(m.snapshots, *snapshot)
+	return nil
+}
+
+func (m *mockBalanceSnapshotRepo) DeleteFrom(ctx context.Context, accountId int, from time.Time) error {
+	kept := m.snapshots[:0]
+	for _, snapshot := range m.snapshots {
+		if snapshot.AccountId != accountId || snapshot.TakenAt.Before(from) {
+			kept = append(kept, snapshot)
+		}
+	}
+	m.snapshots = kept
+	return nil
+}
+
+// ledgerRepo возвращает репозиторий транзакций, считающий изменение баланса по transactions.
+func ledgerRepo(transactions []domain.Transaction) *mockTransactionRepo {
+	return &mockTransactionRepo{
+		getBalanceChangeFunc: func(ctx context.Context, accountId int, after, until time.Time) (float64, error) {
+			var period []domain.Transaction
+			for _, tx := range transactions {
+				if tx.AccountId == accountId && tx.Date.After(after) && !tx.Date.After(until) {
+					period = append(period, tx)
+				}
+			}
+			return domain.LedgerBalance(period), nil
+		},
+	}
+}
+
+func TestBalanceService_GetBalanceAt(t *testing.T) {
+	ctx := context.Background()
+	monthEnd := time.Date(2025, 9, 30, 23, 59, 59, 0, time.UTC)
+	account := domain.Account{Id: 1, UserId: 10, Currency: "RUB", Balance: 70, CreationDate: monthEnd.AddDate(0, -1, 0)}
+	transactions := []domain.Transaction{
+		{Id: 1, AccountId: 1, IsDeposit: true, Amount: 100, Status: domain.TransactionStatusCompleted, Date: monthEnd.AddDate(0, 0, -10)},
+		{Id: 2, AccountId: 1, Amount: 40, Status: domain.TransactionStatusCompleted, Date: monthEnd.AddDate(0, 0, -5)},
+		{Id: 3, AccountId: 1, Amount: 500, Status: domain.TransactionStatusDeclined, Date: monthEnd.AddDate(0, 0, -4)},
+		{Id: 4, AccountId: 1, IsDeposit: true, Amount: 10, Status: domain.TransactionStatusCompleted, Date: monthEnd.Add(time.Second)},
+	}
+	accounts := &mockAccountRepository{
+		getByIdFunc: func(ctx context.Context, id int) (*domain.Account, error) {
+			if id != account.Id {
+				return nil, domain.ErrAccountNotFound
+			}
+			return &account, nil
+		},
+		getAllFunc: func(ctx context.Context) ([]domain.Account, error) {
+			return []domain.Account{account, {Id: 2, CreationDate: monthEnd.Add(time.Hour)}}, nil
+		},
+	}
+	snapshots := &mockBalanceSnapshotRepo{}
+	svc := NewBalanceService(accounts, ledgerRepo(transactions), snapshots)
+
+	balance, err := svc.GetBalanceAt(ctx, 1, monthEnd)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if balance.Balance != 60 || balance.Currency != "RUB" || !balance.At.Equal(monthEnd) {
+		t.Errorf("неожиданный баланс на конец месяца: %+v", balance)
+	}
+
+	// Снимок сокращает пересчёт, но не меняет результат.
+	snapshots.snapshots = append(snapshots.snapshots, domain.BalanceSnapshot{AccountId: 1, Balance: 100, TakenAt: monthEnd.AddDate(0, 0, -7)})
+	balance, err = svc.GetBalanceAt(ctx, 1, monthEnd)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if balance.Balance != 60 {
+		t.Errorf("ожидался баланс 60 от снимка, получен %.2f", balance.Balance)
+	}
+
+	balances, err := svc.GetBalancesAt(ctx, monthEnd)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if len(balances) != 1 || balances[0].AccountId != 1 {
+		t.Errorf("счёт, открытый после конца периода, не должен попадать в отчёт: %+v", balances)
+	}
+
+	_, err = svc.GetBalanceAt(ctx, 1, time.Now().Add(time.Hour))
+	if !errors.Is(err, ErrFutureBalanceTime) {
+		t.Errorf("ожидалась ErrFutureBalanceTime, получено %v", err)
+	}
+	_, err = svc.GetBalanceAt(ctx, 2, monthEnd)
+	if !errors.Is(err, domain.ErrAccountNotFound) {
+		t.Errorf("ожидалась ErrAccountNotFound, получено %v", err)
+	}
+}
+
+func TestBalanceService_TakeSnapshots(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	account := domain.Account{Id: 1, UserId: 10, CreationDate: now.AddDate(0, -1, 0)}
+	transactions := []domain.Transaction{
+		{Id: 1, AccountId: 1, IsDeposit: true, Amount: 100, Status: domain.TransactionStatusCompleted, Date: now.Add(-2 * time.Hour)},
+		{Id: 2, AccountId: 1, IsDeposit: true, Amount: 30, Status: domain.TransactionStatusCompleted, Date: now.Add(-30 * time.Minute)},
+	}
+	txRepo := ledgerRepo(transactions)
+	pendingSince := now.Add(-time.Hour)
+	txRepo.getOldestPendingFunc = func(ctx context.Context) (*time.Time, error) {
+		return &pendingSince, nil
+	}
+	accounts := &mockAccountRepository{
+		getAllFunc: func(ctx context.Context) ([]domain.Account, error) {
+			return []domain.Account{account}, nil
+		},
+	}
+	snapshots := &mockBalanceSnapshotRepo{}
+	svc := NewBalanceService(accounts, txRepo, snapshots)
+
+	saved, err := svc.TakeSnapshots(ctx, now)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if saved != 1 || len(snapshots.snapshots) != 1 {
+		t.Fatalf("ожидался один снимок, получено %d", saved)
+	}
+	snapshot := snapshots.snapshots[0]
+	if !snapshot.TakenAt.Before(pendingSince) || snapshot.Balance != 100 {
+		t.Errorf("снимок должен предшествовать операции на проверке: %+v", snapshot)
+	}
+
+	balance, err := svc.GetBalancesAt(ctx, now)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if balance[0].Balance != 130 {
+		t.Errorf("ожидался баланс 130, получен %.2f", balance[0].Balance)
+	}
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"payment-service/internal/application/events"
+	"payment-service/internal/application/repository"
+	"payment-service/internal/domain"
+	"time"
+)
+
+// EventRelay публикует события счетов, зафиксированные в журнале исходящих событий.
+// Событие попадает в журнал в одной транзакции с изменением счёта, поэтому публикуются
+// только зафиксированные изменения. Доставка — не менее одного раза: при сбое после публикации
+// событие будет опубликовано повторно, и потребители должны пропускать повторы по Id.
+type EventRelay struct {
+	eventDb    repository.AccountEventRepository
+	transactor repository.Transactor
+	publisher  events.Publisher
+}
+
+// NewEventRelay создаёт новый экземпляр EventRelay.
+// Возвращает ошибку, если репозиторий или издатель не инициализированы.
+func NewEventRelay(eventDb repository.AccountEventRepository, transactor repository.Transactor,
+	publisher events.Publisher) (*EventRelay, error) {
+	if eventDb == nil || transactor == nil || publisher == nil {
+		return nil, fmt.Errorf("nil repository")
+	}
+	return &EventRelay{eventDb: eventDb, transactor: transactor, publisher: publisher}, nil
+}
+
+// PublishPending публикует до limit неопубликованных событий и возвращает их число.
+// События блокируются на время публикации, поэтому несколько экземпляров сервиса
+// не публикуют одно событие одновременно.
+func (er *EventRelay) PublishPending(ctx context.Context, limit int) (int, error) {
+	published := 0
+	err := er.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		pending, err := er.eventDb.GetUnpublished(ctx, limit)
+		if err != nil || len(pending) == 0 {
+			return err
+		}
+		err = er.publisher.Publish(ctx, pending)
+		if err != nil {
+			return err
+		}
+		ids := make([]int64, 0, len(pending))
+		for _, event := range pending {
+			ids = append(ids, event.Id)
+		}
+		published = len(ids)
+		return er.eventDb.MarkPublished(ctx, ids, time.Now())
+	})
+	if err != nil {
+		return 0, err
+	}
+	return published, nil
+}
+
+// recordEvent сохраняет событие счёта в журнал исходящих событий.
+// Вызывается в транзакции хранилища вместе с изменением счёта, чтобы событие
+// было опубликовано только после её фиксации.
+func recordEvent(ctx context.Context, eventDb repository.AccountEventRepository, event domain.AccountEvent) error {
+	return eventDb.Save(ctx, &event)
+}
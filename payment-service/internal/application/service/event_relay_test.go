@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"payment-service/internal/domain"
+	"testing"
+	"time"
+)
+
+type mockAccountEventRepo struct {
+	events    []domain.AccountEvent
+	published map[int64]time.Time
+}
+
+func (m *mockAccountEventRepo) Save(ctx context.Context, event *domain.AccountEvent) error {
+	event.Id = int64(len(m.events) + 1)
+	m.events = append(m.events, *event)
+	return nil
+}
+
+func (m *mockAccountEventRepo) GetUnpublished(ctx context.Context, limit int) ([]domain.AccountEvent, error) {
+	result := make([]domain.AccountEvent, 0)
+	for _, event := range m.events {
+		if _, ok := m.published[event.Id]; !ok && len(result) < limit {
+			result = append(result, event)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockAccountEventRepo) MarkPublished(ctx context.Context, ids []int64, at time.Time) error {
+	if m.published == nil {
+		m.published = make(map[int64]time.Time)
+	}
+	for _, id := range ids {
+		m.published[id] = at
+	}
+	return nil
+}
+
+// types возвращает типы сохранённых событий в порядке записи.
+func (m *mockAccountEventRepo) types() []domain.AccountEventType {
+	types := make([]domain.AccountEventType, 0, len(m.events))
+	for _, event := range m.events {
+		types = append(types, event.Type)
+	}
+	return types
+}
+
+type mockPublisher struct {
+	published []domain.AccountEvent
+	err       error
+}
+
+func (m *mockPublisher) Publish(ctx context.Context, events []domain.AccountEvent) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.published = append(m.published, events...)
+	return nil
+}
+
+func TestNewEventRelay_NilDependencies(t *testing.T) {
+	_, err := NewEventRelay(nil, &mockTransactor{}, &mockPublisher{})
+	if err == nil {
+		t.Error("ожидалась ошибка для пустого репозитория")
+	}
+	_, err = NewEventRelay(&mockAccountEventRepo{}, &mockTransactor{}, nil)
+	if err == nil {
+		t.Error("ожидалась ошибка для пустого издателя")
+	}
+}
+
+func TestEventRelay_PublishPending(t *testing.T) {
+	ctx := context.Background()
+	eventDb := &mockAccountEventRepo{}
+	for i := 0; i < 3; i++ {
+		_ = eventDb.Save(ctx, &domain.AccountEvent{Type: domain.AccountEventCredited, AccountId: 1})
+	}
+	publisher := &mockPublisher{}
+	relay, err := NewEventRelay(eventDb, &mockTransactor{}, publisher)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+
+	published, err := relay.PublishPending(ctx, 2)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if published != 2 || len(publisher.published) != 2 || publisher.published[0].Id != 1 {
+		t.Errorf("неожиданная публикация: %d, %+v", published, publisher.published)
+	}
+	published, _ = relay.PublishPending(ctx, 2)
+	if published != 1 || publisher.published[2].Id != 3 {
+		t.Errorf("ожидалось опубликовать оставшееся событие, получено %d", published)
+	}
+	published, _ = relay.PublishPending(ctx, 2)
+	if published != 0 || len(publisher.published) != 3 {
+		t.Errorf("события не должны публиковаться повторно: %+v", publisher.published)
+	}
+}
+
+func TestEventRelay_PublishPending_PublisherError(t *testing.T) {
+	ctx := context.Background()
+	eventDb := &mockAccountEventRepo{}
+	_ = eventDb.Save(ctx, &domain.AccountEvent{Type: domain.AccountEventCreated, AccountId: 1})
+	relay, _ := NewEventRelay(eventDb, &mockTransactor{}, &mockPublisher{err: errors.New("broker unavailable")})
+
+	published, err := relay.PublishPending(ctx, 10)
+	if err == nil || published != 0 {
+		t.Fatalf("ожидалась ошибка публикации, получено %d, %v", published, err)
+	}
+	if len(eventDb.published) != 0 {
+		t.Error("событие не должно отмечаться опубликованным при ошибке")
+	}
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"payment-service/internal/application/repository"
+	"payment-service/internal/domain"
+	"time"
+)
+
+// InboxService обеспечивает однократную обработку входящих сообщений.
+// Брокер может доставить сообщение повторно, например после сбоя до фиксации смещения.
+type InboxService struct {
+	inboxDb    repository.InboxRepository
+	transactor repository.Transactor
+}
+
+// NewInboxService создаёт новый экземпляр InboxService.
+// Возвращает ошибку, если репозиторий не инициализирован.
+func NewInboxService(inboxDb repository.InboxRepository, transactor repository.Transactor) (*InboxService, error) {
+	if inboxDb == nil || transactor == nil {
+		return nil, fmt.Errorf("nil repository")
+	}
+	return &InboxService{inboxDb: inboxDb, transactor: transactor}, nil
+}
+
+// Get возвращает сохранённый ответ на сообщение messageId
+// или nil, если сообщение ещё не обработано.
+func (is *InboxService) Get(ctx context.Context, messageId string) (*domain.InboxMessage, error) {
+	return is.inboxDb.GetById(ctx, messageId)
+}
+
+// Process обрабатывает сообщение messageId функцией handle и сохраняет её ответ.
+// handle выполняется в одной транзакции с записью в журнал, поэтому результат обработки
+// и отметка о ней фиксируются вместе. Для уже обработанного сообщения handle не вызывается,
+// а возвращается сохранённый ответ. Если handle вернула ошибку, ничего не сохраняется
+// и сообщение может быть обработано повторно.
+func (is *InboxService) Process(ctx context.Context, messageId string,
+	handle func(ctx context.Context) (domain.InboxMessage, error)) (*domain.InboxMessage, error) {
+	var result *domain.InboxMessage
+	err := is.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		processed, err := is.inboxDb.GetById(ctx, messageId)
+		if err != nil {
+			return err
+		}
+		if processed != nil {
+			result = processed
+			return nil
+		}
+		message, err := handle(ctx)
+		if err != nil {
+			return err
+		}
+		message.MessageId = messageId
+		message.ProcessedAt = time.Now()
+		err = is.inboxDb.Save(ctx, &message)
+		if err != nil {
+			return err
+		}
+		result = &message
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"payment-service/internal/domain"
+	"testing"
+)
+
+type mockInboxRepo struct {
+	messages map[string]domain.InboxMessage
+}
+
+func (m *mockInboxRepo) GetById(ctx context.Context, messageId string) (*domain.InboxMessage, error) {
+	message, ok := m.messages[messageId]
+	if !ok {
+		return nil, nil
+	}
+	return &message, nil
+}
+
+func (m *mockInboxRepo) Save(ctx context.Context, message *domain.InboxMessage) error {
+	m.messages[message.MessageId] = *message
+	return nil
+}
+
+func TestInboxService_Process(t *testing.T) {
+	ctx := context.Background()
+	inboxDb := &mockInboxRepo{messages: map[string]domain.InboxMessage{}}
+	svc, err := NewInboxService(inboxDb, &mockTransactor{})
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	processed, err := svc.Get(ctx, "request-1")
+	if err != nil || processed != nil {
+		t.Errorf("необработанное сообщение не должно быть в журнале, получено %+v, %v", processed, err)
+	}
+	calls := 0
+	handle := func(ctx context.Context) (domain.InboxMessage, error) {
+		calls++
+		return domain.InboxMessage{Response: "Error processing transaction: insufficient funds", ReasonCode: domain.ReasonInsufficientFunds}, nil
+	}
+
+	for range 2 {
+		message, err := svc.Process(ctx, "request-1", handle)
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if message.MessageId != "request-1" || message.ReasonCode != domain.ReasonInsufficientFunds || message.ProcessedAt.IsZero() {
+			t.Errorf("неожиданный ответ: %+v", message)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("повторное сообщение не должно обрабатываться, вызовов: %d", calls)
+	}
+	processed, err = svc.Get(ctx, "request-1")
+	if err != nil || processed == nil || processed.ReasonCode != domain.ReasonInsufficientFunds {
+		t.Errorf("ожидался сохранённый ответ, получено %+v, %v", processed, err)
+	}
+}
+
+func TestInboxService_Process_HandlerError(t *testing.T) {
+	ctx := context.Background()
+	inboxDb := &mockInboxRepo{messages: map[string]domain.InboxMessage{}}
+	svc, _ := NewInboxService(inboxDb, &mockTransactor{})
+
+	_, err := svc.Process(ctx, "request-1", func(ctx context.Context) (domain.InboxMessage, error) {
+		return domain.InboxMessage{}, errors.New("database unavailable")
+	})
+	if err == nil {
+		t.Fatal("ожидалась ошибка обработки")
+	}
+	if len(inboxDb.messages) != 0 {
+		t.Error("необработанное сообщение не должно попадать в журнал")
+	}
+	message, err := svc.Process(ctx, "request-1", func(ctx context.Context) (domain.InboxMessage, error) {
+		return domain.InboxMessage{Response: "OK"}, nil
+	})
+	if err != nil || message.Response != "OK" {
+		t.Errorf("ожидалась повторная обработка, получено %+v, %v", message, err)
+	}
+}
@@ -2,25 +2,55 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math/rand"
 	"payment-service/internal/application/repository"
 	"payment-service/internal/domain"
+	"time"
 )
 
+// ErrReviewNotFound возвращается, если операция не попадала в очередь ручной проверки.
+var ErrReviewNotFound = errors.New("review not found")
+
+// ErrTransactionNotFound возвращается, если транзакция с указанным ID не найдена.
+var ErrTransactionNotFound = errors.New("transaction not found")
+
 // PaymentService отвечает за обработку транзакций (пополнение и списание средств)
 // и взаимодействие между счетами и историей транзакций.
+// Каждая транзакция обрабатывается ровно один раз: повторная обработка
+// транзакции с тем же ID возвращает исходный результат.
+// Подозрительные операции задерживаются до решения администратора.
 type PaymentService struct {
 	accountRepository     repository.AccountRepository
 	transactionRepository repository.TransactionRepository
+	limitsRepository      repository.SpendingLimitsRepository
+	reviewRepository      repository.TransactionReviewRepository
+	eventRepository       repository.AccountEventRepository
+	snapshotRepository    repository.BalanceSnapshotRepository
+	transactor            repository.Transactor
+	riskRules             domain.RiskRules
 }
 
 // NewPaymentService создаёт новый экземпляр PaymentService.
 // Возвращает ошибку, если один из репозиториев не инициализирован.
-func NewPaymentService(accountsDb repository.AccountRepository, transactionsDb repository.TransactionRepository) (*PaymentService, error) {
-	if accountsDb == nil || transactionsDb == nil {
+func NewPaymentService(accountsDb repository.AccountRepository, transactionsDb repository.TransactionRepository,
+	limitsDb repository.SpendingLimitsRepository, reviewsDb repository.TransactionReviewRepository,
+	eventsDb repository.AccountEventRepository, snapshotsDb repository.BalanceSnapshotRepository, transactor repository.Transactor,
+	riskRules domain.RiskRules) (*PaymentService, error) {
+	if accountsDb == nil || transactionsDb == nil || limitsDb == nil || reviewsDb == nil || eventsDb == nil || snapshotsDb == nil || transactor == nil {
 		return nil, fmt.Errorf("nil repository")
 	}
-	return &PaymentService{accountRepository: accountsDb, transactionRepository: transactionsDb}, nil
+	return &PaymentService{
+		accountRepository:     accountsDb,
+		transactionRepository: transactionsDb,
+		limitsRepository:      limitsDb,
+		reviewRepository:      reviewsDb,
+		eventRepository:       eventsDb,
+		snapshotRepository:    snapshotsDb,
+		transactor:            transactor,
+		riskRules:             riskRules,
+	}, nil
 }
 
 // ProcessTransaction выбирает нужную операцию — Deposit или Withdraw —
@@ -33,51 +63,372 @@ func (service *PaymentService) ProcessTransaction(ctx context.Context, transacti
 }
 
 // Withdraw выполняет списание средств со счёта пользователя.
-// Проверяет, что транзакция не является депозитом, и что на балансе достаточно средств.
+// Проверяет, что транзакция не является депозитом, что платёж укладывается
+// в ограничения счёта и что на балансе достаточно средств.
 func (service *PaymentService) Withdraw(ctx context.Context, transaction domain.Transaction) error {
 	if transaction.IsDeposit {
 		return fmt.Errorf("transaction is not withdrawal")
 	}
+	return service.process(ctx, transaction)
+}
 
-	account, err := service.accountRepository.GetByUserId(ctx, transaction.UserId)
-	if err != nil || account == nil {
-		return err
+// Deposit выполняет пополнение счёта пользователя.
+// Проверяет, что транзакция не является списанием.
+func (service *PaymentService) Deposit(ctx context.Context, transaction domain.Transaction) error {
+	if !transaction.IsDeposit {
+		return fmt.Errorf("transaction is not deposit")
+	}
+	return service.process(ctx, transaction)
+}
+
+// GetTransaction возвращает транзакцию по её ID вместе с уже отменённой частью суммы.
+func (service *PaymentService) GetTransaction(ctx context.Context, id int) (*domain.Transaction, error) {
+	transaction, err := service.transactionRepository.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if transaction == nil {
+		return nil, ErrTransactionNotFound
+	}
+	return transaction, nil
+}
+
+// Reverse отменяет amount из суммы проведённой транзакции transactionId.
+// Создаёт связанную с ней транзакцию обратного направления по тому же кошельку и возвращает её.
+// Отменённая часть суммы учитывается в исходной транзакции, поэтому её нельзя отменить повторно.
+// Возвращает ошибку, если транзакция не найдена, не может быть отменена,
+// сумма превышает ещё не отменённую часть или баланс не позволяет провести отмену.
+func (service *PaymentService) Reverse(ctx context.Context, transactionId int, amount float64,
+	reason domain.ReversalReason) (*domain.Transaction, error) {
+	var reversal domain.Transaction
+	err := service.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		original, err := service.transactionRepository.GetById(ctx, transactionId)
+		if err != nil {
+			return err
+		}
+		if original == nil {
+			return ErrTransactionNotFound
+		}
+		account, err := service.accountFor(ctx, original)
+		if err != nil {
+			return err
+		}
+		reversal, err = original.Reverse(rand.Intn(2147483645), amount, reason, time.Now())
+		if err != nil {
+			return err
+		}
+		if reversal.IsDeposit {
+			err = account.Deposit(reversal.Amount)
+		} else {
+			err = account.Withdraw(reversal.Amount)
+		}
+		if err != nil {
+			return err
+		}
+		err = service.transactionRepository.AddReversedAmount(ctx, original.Id, reversal.Amount)
+		if err != nil {
+			return err
+		}
+		err = service.transactionRepository.Save(ctx, &reversal)
+		if err != nil {
+			return err
+		}
+		err = service.accountRepository.Save(ctx, account)
+		if err != nil {
+			return err
+		}
+		return recordEvent(ctx, service.eventRepository, domain.NewBalanceChangedEvent(*account, reversal, time.Now()))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &reversal, nil
+}
+
+// GetPendingReviews возвращает операции, ожидающие решения администратора,
+// вместе с данными задержанных транзакций.
+func (service *PaymentService) GetPendingReviews(ctx context.Context) ([]domain.TransactionReview, error) {
+	reviews, err := service.reviewRepository.GetPending(ctx)
+	if err != nil {
+		return nil, err
 	}
-	err = account.Withdraw(transaction.Amount)
+	for i := range reviews {
+		reviews[i].Transaction, err = service.transactionRepository.GetById(ctx, reviews[i].TransactionId)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return reviews, nil
+}
+
+// ApproveReview проводит задержанную операцию по решению администратора.
+// Операция проходит обычные проверки лимитов и баланса, но не повторную проверку на мошенничество,
+// поэтому итоговая транзакция может оказаться как проведённой, так и отклонённой.
+// Решение публикуется событием PaymentReviewed, по которому order-service завершает оплату заказа.
+// Возвращает ошибку, если операция не ожидает решения или не указан администратор.
+func (service *PaymentService) ApproveReview(ctx context.Context, transactionId int, decidedBy, comment string) (*domain.Transaction, error) {
+	var transaction *domain.Transaction
+	err := service.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var review *domain.TransactionReview
+		var err error
+		review, transaction, err = service.pendingReview(ctx, transactionId)
+		if err != nil {
+			return err
+		}
+		err = review.Decide(domain.ReviewStatusApproved, decidedBy, comment)
+		if err != nil {
+			return err
+		}
+		account, err := service.accountFor(ctx, transaction)
+		if err == nil {
+			_, err = service.settle(ctx, transaction, account, service.transactionRepository.UpdateStatus)
+		} else if domain.IsDecline(err) {
+			transaction.Decline(err)
+			err = service.transactionRepository.UpdateStatus(ctx, transaction)
+		}
+		if err != nil {
+			return err
+		}
+		err = service.reviewRepository.Save(ctx, review)
+		if err != nil {
+			return err
+		}
+		return service.recordReviewDecision(ctx, transaction)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transaction, nil
+}
+
+// RejectReview отклоняет задержанную операцию по решению администратора.
+// Баланс счёта не меняется, решение публикуется событием PaymentReviewed.
+// Возвращает ошибку, если операция не ожидает решения
+// или не указан администратор.
+func (service *PaymentService) RejectReview(ctx context.Context, transactionId int, decidedBy, comment string) (*domain.Transaction, error) {
+	var transaction *domain.Transaction
+	err := service.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var review *domain.TransactionReview
+		var err error
+		review, transaction, err = service.pendingReview(ctx, transactionId)
+		if err != nil {
+			return err
+		}
+		err = review.Decide(domain.ReviewStatusRejected, decidedBy, comment)
+		if err != nil {
+			return err
+		}
+		transaction.Decline(domain.ErrRejectedByReview)
+		err = service.transactionRepository.UpdateStatus(ctx, transaction)
+		if err != nil {
+			return err
+		}
+		err = service.reviewRepository.Save(ctx, review)
+		if err != nil {
+			return err
+		}
+		return service.recordReviewDecision(ctx, transaction)
+	})
 	if err != nil {
+		return nil, err
+	}
+	return transaction, nil
+}
+
+// process проводит транзакцию в одной транзакции хранилища.
+// Если транзакция с таким ID уже обработана, возвращает её исходный результат без повторного применения.
+// Подозрительная операция сохраняется в очередь ручной проверки и возвращается как ErrPendingReview.
+// Отказ по бизнес-правилам сохраняется вместе с причиной и возвращается как ошибка.
+func (service *PaymentService) process(ctx context.Context, transaction domain.Transaction) error {
+	if transaction.Type == "" {
+		transaction.Type = domain.TransactionTypePayment
+	}
+
+	var outcome error
+	err := service.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		processed, err := service.transactionRepository.GetById(ctx, transaction.Id)
+		if err != nil {
+			return err
+		}
+		if processed != nil {
+			outcome = processed.Outcome()
+			return nil
+		}
+
+		account, err := service.accountFor(ctx, &transaction)
+		if err == nil {
+			var flags []domain.RiskFlag
+			flags, err = service.screen(ctx, transaction, account)
+			if err != nil {
+				return err
+			}
+			if len(flags) > 0 {
+				outcome = domain.ErrPendingReview
+				return service.holdForReview(ctx, &transaction, flags)
+			}
+		}
+		if err != nil {
+			if !domain.IsDecline(err) {
+				return err
+			}
+			outcome = err
+			transaction.Decline(err)
+			return service.transactionRepository.Save(ctx, &transaction)
+		}
+		outcome, err = service.settle(ctx, &transaction, account, service.transactionRepository.Save)
 		return err
+	})
+	if errors.Is(err, repository.ErrDuplicateTransaction) {
+		// Параллельная обработка той же транзакции успела завершиться раньше.
+		processed, getErr := service.transactionRepository.GetById(ctx, transaction.Id)
+		if getErr != nil || processed == nil {
+			return err
+		}
+		return processed.Outcome()
 	}
-	err = service.transactionRepository.Save(ctx, &transaction)
 	if err != nil {
 		return err
 	}
-	return service.accountRepository.Save(ctx, account)
+	return outcome
 }
 
-// Deposit выполняет пополнение счёта пользователя.
-// Проверяет, что транзакция уникальна и не является списанием.
-func (service *PaymentService) Deposit(ctx context.Context, transaction domain.Transaction) error {
-	if !transaction.IsDeposit {
-		return fmt.Errorf("transaction is not deposit")
+// settle применяет операцию транзакции к кошельку account и фиксирует результат через store.
+// Отказ по бизнес-правилам сохраняется в транзакции и возвращается как outcome, баланс при этом не меняется.
+// Ошибка err возвращается только при сбое хранилища.
+func (service *PaymentService) settle(ctx context.Context, transaction *domain.Transaction, account *domain.Account,
+	store func(ctx context.Context, transaction *domain.Transaction) error) (outcome error, err error) {
+	if transaction.IsDeposit {
+		err = account.Deposit(transaction.Amount)
+	} else {
+		err = service.checkLimits(ctx, account, transaction.Amount)
+		if err == nil {
+			err = account.Withdraw(transaction.Amount)
+		}
+	}
+	if err != nil {
+		if !domain.IsDecline(err) {
+			return nil, err
+		}
+		transaction.Decline(err)
+		return err, store(ctx, transaction)
+	}
+
+	transaction.Complete()
+	err = store(ctx, transaction)
+	if err != nil {
+		return nil, err
+	}
+	// Транзакция сохраняет дату запроса и может оказаться раньше уже сделанных снимков
+	// (повторная доставка, переотправка или одобрение после проверки).
+	// Такие снимки больше не отражают баланс и пересчитываются при следующем снимке.
+	err = service.snapshotRepository.DeleteFrom(ctx, account.Id, transaction.Date)
+	if err != nil {
+		return nil, err
+	}
+	err = service.accountRepository.Save(ctx, account)
+	if err != nil {
+		return nil, err
 	}
+	return nil, recordEvent(ctx, service.eventRepository, domain.NewBalanceChangedEvent(*account, *transaction, time.Now()))
+}
 
-	// Проверяем, не существует ли уже транзакция с таким ID
-	transactionFromDb, err := service.transactionRepository.GetById(ctx, transaction.Id)
-	if err != nil || transactionFromDb != nil {
+// screen проверяет операцию по правилам обнаружения мошенничества и возвращает сработавшие флаги.
+func (service *PaymentService) screen(ctx context.Context, transaction domain.Transaction, account *domain.Account) ([]domain.RiskFlag, error) {
+	now := time.Now()
+	failedAttempts := 0
+	if service.riskRules.MaxFailedAttempts > 0 {
+		var err error
+		failedAttempts, err = service.transactionRepository.CountDeclined(ctx, transaction.UserId, now.Add(-service.riskRules.FailedAttemptsWindow))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return service.riskRules.Screen(transaction, *account, failedAttempts, now), nil
+}
+
+// holdForReview сохраняет транзакцию как задержанную и ставит её в очередь ручной проверки.
+func (service *PaymentService) holdForReview(ctx context.Context, transaction *domain.Transaction, flags []domain.RiskFlag) error {
+	transaction.HoldForReview()
+	err := service.transactionRepository.Save(ctx, transaction)
+	if err != nil {
 		return err
 	}
+	return service.reviewRepository.Save(ctx, &domain.TransactionReview{
+		TransactionId: transaction.Id,
+		Flags:         flags,
+		Status:        domain.ReviewStatusPending,
+		CreatedAt:     time.Now(),
+	})
+}
 
-	account, err := service.accountRepository.GetByUserId(ctx, transaction.UserId)
+// recordReviewDecision записывает в журнал событий решение по задержанной операции transaction.
+// Событие относится к кошельку транзакции, который был найден при её задержании.
+func (service *PaymentService) recordReviewDecision(ctx context.Context, transaction *domain.Transaction) error {
+	account, err := service.accountRepository.GetById(ctx, transaction.AccountId)
 	if err != nil {
 		return err
 	}
-	err = account.Deposit(transaction.Amount)
+	if account == nil {
+		return domain.ErrAccountNotFound
+	}
+	return recordEvent(ctx, service.eventRepository, domain.NewPaymentReviewedEvent(*account, *transaction, time.Now()))
+}
+
+// pendingReview возвращает запись проверки и задержанную транзакцию.
+// Возвращает ошибку, если транзакция не найдена или уже не ожидает решения.
+func (service *PaymentService) pendingReview(ctx context.Context, transactionId int) (*domain.TransactionReview, *domain.Transaction, error) {
+	transaction, err := service.transactionRepository.GetById(ctx, transactionId)
+	if err != nil {
+		return nil, nil, err
+	}
+	review, err := service.reviewRepository.GetByTransactionId(ctx, transactionId)
 	if err != nil {
+		return nil, nil, err
+	}
+	if transaction == nil || review == nil {
+		return nil, nil, ErrReviewNotFound
+	}
+	if transaction.Status != domain.TransactionStatusPendingReview {
+		return nil, nil, fmt.Errorf("transaction is already %s", transaction.Status)
+	}
+	return review, transaction, nil
+}
+
+// checkLimits проверяет, что платёж на сумму amount укладывается в ограничения счёта.
+// Если ограничения не заданы, платёж разрешён.
+func (service *PaymentService) checkLimits(ctx context.Context, account *domain.Account, amount float64) error {
+	limits, err := service.limitsRepository.GetByAccountId(ctx, account.Id)
+	if err != nil || limits == nil {
 		return err
 	}
-	err = service.transactionRepository.Save(ctx, &transaction)
+	activity, err := service.transactionRepository.GetSpendingActivity(ctx, account.Id, time.Now())
 	if err != nil {
 		return err
 	}
-	return service.accountRepository.Save(ctx, account)
+	return limits.Check(amount, *activity)
+}
+
+// accountFor возвращает кошелёк, с которым проводится транзакция:
+// явно указанный в AccountId или основной кошелёк пользователя.
+// Заполняет AccountId транзакции найденным кошельком.
+// Возвращает ошибку, если кошелёк не найден или принадлежит другому пользователю.
+func (service *PaymentService) accountFor(ctx context.Context, transaction *domain.Transaction) (*domain.Account, error) {
+	var account *domain.Account
+	var err error
+	if transaction.AccountId == 0 {
+		account, err = service.accountRepository.GetDefaultByUserId(ctx, transaction.UserId)
+	} else {
+		account, err = service.accountRepository.GetById(ctx, transaction.AccountId)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, domain.ErrAccountNotFound
+	}
+	if account.UserId != transaction.UserId {
+		return nil, fmt.Errorf("account %d, user %d: %w", account.Id, transaction.UserId, domain.ErrForeignAccount)
+	}
+	transaction.AccountId = account.Id
+	return account, nil
 }
@@ -3,22 +3,37 @@ package service
 import (
 	"context"
 	"errors"
+	"payment-service/internal/application/repository"
 	"payment-service/internal/domain"
 	"testing"
+	"time"
 )
 
 type mockAccountRepo struct {
-	getByUserIdFunc func(ctx context.Context, id int) (*domain.Account, error)
-	saveFunc        func(ctx context.Context, acc *domain.Account) error
+	getByIdFunc            func(ctx context.Context, id int) (*domain.Account, error)
+	getDefaultByUserIdFunc func(ctx context.Context, id int) (*domain.Account, error)
+	saveFunc               func(ctx context.Context, acc *domain.Account) error
 }
 
-func (m *mockAccountRepo) GetByUserId(ctx context.Context, id int) (*domain.Account, error) {
-	if m.getByUserIdFunc != nil {
-		return m.getByUserIdFunc(ctx, id)
+func (m *mockAccountRepo) GetDefaultByUserId(ctx context.Context, id int) (*domain.Account, error) {
+	if m.getDefaultByUserIdFunc != nil {
+		return m.getDefaultByUserIdFunc(ctx, id)
 	}
 	return nil, nil
 }
+func (m *mockAccountRepo) GetUserAccounts(ctx context.Context, id int) ([]domain.Account, error) {
+	return nil, nil
+}
+func (m *mockAccountRepo) GetAll(ctx context.Context) ([]domain.Account, error) {
+	return nil, nil
+}
+func (m *mockAccountRepo) SetDefault(ctx context.Context, userId int, accountId int) error {
+	return nil
+}
 func (m *mockAccountRepo) GetById(ctx context.Context, id int) (*domain.Account, error) {
+	if m.getByIdFunc != nil {
+		return m.getByIdFunc(ctx, id)
+	}
 	return nil, nil
 }
 func (m *mockAccountRepo) Save(ctx context.Context, acc *domain.Account) error {
@@ -29,8 +44,16 @@ func (m *mockAccountRepo) Save(ctx context.Context, acc *domain.Account) error {
 }
 
 type mockTransactionRepo struct {
-	getByIdFunc func(ctx context.Context, id int) (*domain.Transaction, error)
-	saveFunc    func(ctx context.Context, tx *domain.Transaction) error
+	getByIdFunc             func(ctx context.Context, id int) (*domain.Transaction, error)
+	getByAccountIdFunc      func(ctx context.Context, accountId int) ([]domain.Transaction, error)
+	getSpendingActivityFunc func(ctx context.Context, accountId int, now time.Time) (*domain.SpendingActivity, error)
+	getCompletedFunc        func(ctx context.Context, accountId int, after, until time.Time) ([]domain.Transaction, error)
+	getBalanceChangeFunc    func(ctx context.Context, accountId int, after, until time.Time) (float64, error)
+	getOldestPendingFunc    func(ctx context.Context) (*time.Time, error)
+	countDeclinedFunc       func(ctx context.Context, userId int, since time.Time) (int, error)
+	saveFunc                func(ctx context.Context, tx *domain.Transaction) error
+	updateStatusFunc        func(ctx context.Context, tx *domain.Transaction) error
+	addReversedAmountFunc   func(ctx context.Context, id int, amount float64) error
 }
 
 func (m *mockTransactionRepo) GetById(ctx context.Context, id int) (*domain.Transaction, error) {
@@ -40,6 +63,48 @@ func (m *mockTransactionRepo) GetById(ctx context.Context, id int) (*domain.Tran
 	return nil, nil
 }
 
+func (m *mockTransactionRepo) GetByAccountId(ctx context.Context, accountId int) ([]domain.Transaction, error) {
+	if m.getByAccountIdFunc != nil {
+		return m.getByAccountIdFunc(ctx, accountId)
+	}
+	return nil, nil
+}
+
+func (m *mockTransactionRepo) GetSpendingActivity(ctx context.Context, accountId int, now time.Time) (*domain.SpendingActivity, error) {
+	if m.getSpendingActivityFunc != nil {
+		return m.getSpendingActivityFunc(ctx, accountId, now)
+	}
+	return &domain.SpendingActivity{}, nil
+}
+
+func (m *mockTransactionRepo) GetCompletedBetween(ctx context.Context, accountId int, after, until time.Time) ([]domain.Transaction, error) {
+	if m.getCompletedFunc != nil {
+		return m.getCompletedFunc(ctx, accountId, after, until)
+	}
+	return nil, nil
+}
+
+func (m *mockTransactionRepo) GetBalanceChange(ctx context.Context, accountId int, after, until time.Time) (float64, error) {
+	if m.getBalanceChangeFunc != nil {
+		return m.getBalanceChangeFunc(ctx, accountId, after, until)
+	}
+	return 0, nil
+}
+
+func (m *mockTransactionRepo) GetOldestPendingReviewDate(ctx context.Context) (*time.Time, error) {
+	if m.getOldestPendingFunc != nil {
+		return m.getOldestPendingFunc(ctx)
+	}
+	return nil, nil
+}
+
+func (m *mockTransactionRepo) CountDeclined(ctx context.Context, userId int, since time.Time) (int, error) {
+	if m.countDeclinedFunc != nil {
+		return m.countDeclinedFunc(ctx, userId, since)
+	}
+	return 0, nil
+}
+
 func (m *mockTransactionRepo) Save(ctx context.Context, tx *domain.Transaction) error {
 	if m.saveFunc != nil {
 		return m.saveFunc(ctx, tx)
@@ -47,9 +112,84 @@ func (m *mockTransactionRepo) Save(ctx context.Context, tx *domain.Transaction)
 	return nil
 }
 
+func (m *mockTransactionRepo) AddReversedAmount(ctx context.Context, id int, amount float64) error {
+	if m.addReversedAmountFunc != nil {
+		return m.addReversedAmountFunc(ctx, id, amount)
+	}
+	return nil
+}
+
+func (m *mockTransactionRepo) UpdateStatus(ctx context.Context, tx *domain.Transaction) error {
+	if m.updateStatusFunc != nil {
+		return m.updateStatusFunc(ctx, tx)
+	}
+	return nil
+}
+
+type mockSpendingLimitsRepo struct {
+	limits map[int]domain.SpendingLimits
+}
+
+func (m *mockSpendingLimitsRepo) GetByAccountId(ctx context.Context, accountId int) (*domain.SpendingLimits, error) {
+	limits, ok := m.limits[accountId]
+	if !ok {
+		return nil, nil
+	}
+	return &limits, nil
+}
+
+func (m *mockSpendingLimitsRepo) Save(ctx context.Context, limits *domain.SpendingLimits) error {
+	if m.limits == nil {
+		m.limits = make(map[int]domain.SpendingLimits)
+	}
+	m.limits[limits.AccountId] = *limits
+	return nil
+}
+
+func (m *mockSpendingLimitsRepo) Delete(ctx context.Context, accountId int) error {
+	delete(m.limits, accountId)
+	return nil
+}
+
+type mockTransactionReviewRepo struct {
+	reviews map[int]domain.TransactionReview
+}
+
+func (m *mockTransactionReviewRepo) GetByTransactionId(ctx context.Context, transactionId int) (*domain.TransactionReview, error) {
+	review, ok := m.reviews[transactionId]
+	if !ok {
+		return nil, nil
+	}
+	return &review, nil
+}
+
+func (m *mockTransactionReviewRepo) GetPending(ctx context.Context) ([]domain.TransactionReview, error) {
+	reviews := make([]domain.TransactionReview, 0)
+	for _, review := range m.reviews {
+		if review.Status == domain.ReviewStatusPending {
+			reviews = append(reviews, review)
+		}
+	}
+	return reviews, nil
+}
+
+func (m *mockTransactionReviewRepo) Save(ctx context.Context, review *domain.TransactionReview) error {
+	if m.reviews == nil {
+		m.reviews = make(map[int]domain.TransactionReview)
+	}
+	m.reviews[review.TransactionId] = *review
+	return nil
+}
+
+type mockTransactor struct{}
+
+func (m *mockTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
 func TestPaymentService_Deposit(t *testing.T) {
 	ctx := context.Background()
-	account := &domain.Account{Id: 1, UserId: 10, Balance: 100}
+	account := &domain.Account{Id: 1, UserId: 10, Balance: 100, Status: domain.AccountStatusActive}
 
 	tests := []struct {
 		name      string
@@ -62,7 +202,7 @@ func TestPaymentService_Deposit(t *testing.T) {
 			tx:   domain.Transaction{Id: 1, UserId: 10, IsDeposit: true, Amount: 50},
 			setupMock: func() (*mockAccountRepo, *mockTransactionRepo) {
 				return &mockAccountRepo{
-						getByUserIdFunc: func(ctx context.Context, id int) (*domain.Account, error) {
+						getDefaultByUserIdFunc: func(ctx context.Context, id int) (*domain.Account, error) {
 							return account, nil
 						},
 						saveFunc: func(ctx context.Context, acc *domain.Account) error {
@@ -84,7 +224,7 @@ func TestPaymentService_Deposit(t *testing.T) {
 			tx:   domain.Transaction{Id: 1, UserId: 10, IsDeposit: true, Amount: 50},
 			setupMock: func() (*mockAccountRepo, *mockTransactionRepo) {
 				return &mockAccountRepo{
-						getByUserIdFunc: func(ctx context.Context, id int) (*domain.Account, error) {
+						getDefaultByUserIdFunc: func(ctx context.Context, id int) (*domain.Account, error) {
 							return account, nil
 						},
 					}, &mockTransactionRepo{
@@ -111,7 +251,7 @@ func TestPaymentService_Deposit(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			accRepo, txRepo := tt.setupMock()
-			svc, _ := NewPaymentService(accRepo, txRepo)
+			svc, _ := NewPaymentService(accRepo, txRepo, &mockSpendingLimitsRepo{}, &mockTransactionReviewRepo{}, &mockAccountEventRepo{}, &mockBalanceSnapshotRepo{}, &mockTransactor{}, domain.RiskRules{})
 			err := svc.Deposit(ctx, tt.tx)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("ожидалась ошибка=%v, получено %v", tt.wantErr, err)
@@ -119,3 +259,400 @@ func TestPaymentService_Deposit(t *testing.T) {
 		})
 	}
 }
+
+func TestPaymentService_Withdraw_ChosenWallet(t *testing.T) {
+	ctx := context.Background()
+	main := &domain.Account{Id: 1, UserId: 10, Balance: 100, IsDefault: true, Status: domain.AccountStatusActive}
+	bonus := &domain.Account{Id: 2, UserId: 10, Balance: 500, Status: domain.AccountStatusActive}
+	foreign := &domain.Account{Id: 3, UserId: 99, Balance: 500, Status: domain.AccountStatusActive}
+	accounts := map[int]*domain.Account{1: main, 2: bonus, 3: foreign}
+
+	accRepo := &mockAccountRepo{
+		getByIdFunc: func(ctx context.Context, id int) (*domain.Account, error) {
+			return accounts[id], nil
+		},
+		getDefaultByUserIdFunc: func(ctx context.Context, id int) (*domain.Account, error) {
+			return main, nil
+		},
+	}
+	var savedTx *domain.Transaction
+	txRepo := &mockTransactionRepo{
+		saveFunc: func(ctx context.Context, tx *domain.Transaction) error {
+			savedTx = tx
+			return nil
+		},
+	}
+	svc, _ := NewPaymentService(accRepo, txRepo, &mockSpendingLimitsRepo{}, &mockTransactionReviewRepo{}, &mockAccountEventRepo{}, &mockBalanceSnapshotRepo{}, &mockTransactor{}, domain.RiskRules{})
+
+	err := svc.Withdraw(ctx, domain.Transaction{Id: 1, UserId: 10, AccountId: 2, Amount: 300})
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if bonus.Balance != 200 || main.Balance != 100 {
+		t.Errorf("списание не с того кошелька: main=%.2f bonus=%.2f", main.Balance, bonus.Balance)
+	}
+	if savedTx == nil || savedTx.AccountId != 2 {
+		t.Errorf("в транзакции не сохранён кошелёк: %+v", savedTx)
+	}
+
+	err = svc.Withdraw(ctx, domain.Transaction{Id: 2, UserId: 10, AccountId: 3, Amount: 10})
+	if err == nil {
+		t.Error("ожидалась ошибка при списании с чужого кошелька")
+	}
+
+	err = svc.Withdraw(ctx, domain.Transaction{Id: 3, UserId: 10, Amount: 50})
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if main.Balance != 50 {
+		t.Errorf("ожидалось списание с основного кошелька, баланс %.2f", main.Balance)
+	}
+}
+
+func TestPaymentService_Withdraw_InvalidatesSnapshots(t *testing.T) {
+	ctx := context.Background()
+	date := time.Date(2025, 9, 30, 12, 0, 0, 0, time.UTC)
+	account := &domain.Account{Id: 1, UserId: 10, Balance: 100, IsDefault: true, Status: domain.AccountStatusActive}
+	accRepo := &mockAccountRepo{
+		getDefaultByUserIdFunc: func(ctx context.Context, id int) (*domain.Account, error) {
+			return account, nil
+		},
+	}
+	snapshotRepo := &mockBalanceSnapshotRepo{snapshots: []domain.BalanceSnapshot{
+		{AccountId: 1, Balance: 100, TakenAt: date.Add(-time.Hour)},
+		{AccountId: 1, Balance: 100, TakenAt: date.Add(time.Hour)},
+		{AccountId: 2, Balance: 50, TakenAt: date.Add(time.Hour)},
+	}}
+	svc, _ := NewPaymentService(accRepo, &mockTransactionRepo{}, &mockSpendingLimitsRepo{}, &mockTransactionReviewRepo{}, &mockAccountEventRepo{}, snapshotRepo, &mockTransactor{}, domain.RiskRules{})
+
+	err := svc.Withdraw(ctx, domain.Transaction{Id: 1, UserId: 10, Amount: 30, Date: date})
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if len(snapshotRepo.snapshots) != 2 || !snapshotRepo.snapshots[0].TakenAt.Before(date) || snapshotRepo.snapshots[1].AccountId != 2 {
+		t.Errorf("ожидалось удаление только снимка счёта 1 после даты транзакции, осталось %+v", snapshotRepo.snapshots)
+	}
+}
+
+func TestPaymentService_Withdraw_Duplicate(t *testing.T) {
+	ctx := context.Background()
+	account := &domain.Account{Id: 1, UserId: 10, Balance: 100, IsDefault: true, Status: domain.AccountStatusActive}
+	saved := make(map[int]domain.Transaction)
+
+	accRepo := &mockAccountRepo{
+		getDefaultByUserIdFunc: func(ctx context.Context, id int) (*domain.Account, error) {
+			return account, nil
+		},
+	}
+	txRepo := &mockTransactionRepo{
+		getByIdFunc: func(ctx context.Context, id int) (*domain.Transaction, error) {
+			tx, ok := saved[id]
+			if !ok {
+				return nil, nil
+			}
+			return &tx, nil
+		},
+		saveFunc: func(ctx context.Context, tx *domain.Transaction) error {
+			saved[tx.Id] = *tx
+			return nil
+		},
+	}
+	svc, _ := NewPaymentService(accRepo, txRepo, &mockSpendingLimitsRepo{}, &mockTransactionReviewRepo{}, &mockAccountEventRepo{}, &mockBalanceSnapshotRepo{}, &mockTransactor{}, domain.RiskRules{})
+
+	for i := 0; i < 2; i++ {
+		err := svc.Withdraw(ctx, domain.Transaction{Id: 1, UserId: 10, Amount: 60})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+	}
+	if account.Balance != 40 {
+		t.Errorf("повторное списание изменило баланс: %.2f", account.Balance)
+	}
+
+	first := svc.Withdraw(ctx, domain.Transaction{Id: 2, UserId: 10, Amount: 60})
+	if !errors.Is(first, domain.ErrInsufficientFunds) {
+		t.Fatalf("ожидался отказ из-за нехватки средств, получено %v", first)
+	}
+	if saved[2].Status != domain.TransactionStatusDeclined {
+		t.Errorf("отклонённая транзакция не сохранена: %+v", saved[2])
+	}
+	account.Balance = 1000
+	again := svc.Withdraw(ctx, domain.Transaction{Id: 2, UserId: 10, Amount: 60})
+	if again == nil || again.Error() != first.Error() {
+		t.Errorf("ожидался исходный отказ %q, получено %v", first, again)
+	}
+	if account.Balance != 1000 {
+		t.Errorf("повторная обработка отклонённой транзакции изменила баланс: %.2f", account.Balance)
+	}
+}
+
+func TestPaymentService_Withdraw_ConcurrentDuplicate(t *testing.T) {
+	ctx := context.Background()
+	account := &domain.Account{Id: 1, UserId: 10, Balance: 100, IsDefault: true, Status: domain.AccountStatusActive}
+	var stored *domain.Transaction
+
+	accRepo := &mockAccountRepo{
+		getDefaultByUserIdFunc: func(ctx context.Context, id int) (*domain.Account, error) {
+			return account, nil
+		},
+		saveFunc: func(ctx context.Context, acc *domain.Account) error {
+			t.Error("баланс не должен сохраняться при конфликте транзакции")
+			return nil
+		},
+	}
+	txRepo := &mockTransactionRepo{
+		getByIdFunc: func(ctx context.Context, id int) (*domain.Transaction, error) {
+			return stored, nil
+		},
+		saveFunc: func(ctx context.Context, tx *domain.Transaction) error {
+			// Параллельный обработчик сохранил ту же транзакцию раньше.
+			stored = &domain.Transaction{Id: tx.Id, Status: domain.TransactionStatusCompleted}
+			return repository.ErrDuplicateTransaction
+		},
+	}
+	svc, _ := NewPaymentService(accRepo, txRepo, &mockSpendingLimitsRepo{}, &mockTransactionReviewRepo{}, &mockAccountEventRepo{}, &mockBalanceSnapshotRepo{}, &mockTransactor{}, domain.RiskRules{})
+
+	err := svc.Withdraw(ctx, domain.Transaction{Id: 1, UserId: 10, Amount: 60})
+	if err != nil {
+		t.Fatalf("ожидался исходный результат без ошибки, получено %v", err)
+	}
+}
+
+func TestPaymentService_Withdraw_SpendingLimits(t *testing.T) {
+	ctx := context.Background()
+	account := &domain.Account{Id: 1, UserId: 10, Balance: 10000, IsDefault: true, Status: domain.AccountStatusActive}
+	activity := &domain.SpendingActivity{DailyTotal: 900, MonthlyTotal: 4500, PaymentsLastHour: 2}
+
+	accRepo := &mockAccountRepo{
+		getDefaultByUserIdFunc: func(ctx context.Context, id int) (*domain.Account, error) {
+			return account, nil
+		},
+	}
+	txRepo := &mockTransactionRepo{
+		getSpendingActivityFunc: func(ctx context.Context, accountId int, now time.Time) (*domain.SpendingActivity, error) {
+			return activity, nil
+		},
+	}
+
+	tests := []struct {
+		name    string
+		limits  domain.SpendingLimits
+		amount  float64
+		wantErr error
+	}{
+		{name: "без ограничений", amount: 100},
+		{name: "в пределах ограничений", limits: domain.SpendingLimits{MaxPayment: 500, DailyLimit: 1000, MonthlyLimit: 5000, MaxPaymentsPerHour: 3}, amount: 100},
+		{name: "превышена сумма платежа", limits: domain.SpendingLimits{MaxPayment: 500}, amount: 600, wantErr: domain.ErrLimitExceeded},
+		{name: "превышен дневной лимит", limits: domain.SpendingLimits{DailyLimit: 1000}, amount: 200, wantErr: domain.ErrLimitExceeded},
+		{name: "превышен месячный лимит", limits: domain.SpendingLimits{MonthlyLimit: 5000}, amount: 600, wantErr: domain.ErrLimitExceeded},
+		{name: "превышено число платежей в час", limits: domain.SpendingLimits{MaxPaymentsPerHour: 2}, amount: 1, wantErr: domain.ErrLimitExceeded},
+		{name: "недостаточно средств", limits: domain.SpendingLimits{MaxPayment: 50000}, amount: 20000, wantErr: domain.ErrInsufficientFunds},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			account.Balance = 10000
+			limitsRepo := &mockSpendingLimitsRepo{}
+			if tt.limits != (domain.SpendingLimits{}) {
+				tt.limits.AccountId = account.Id
+				_ = limitsRepo.Save(ctx, &tt.limits)
+			}
+			svc, _ := NewPaymentService(accRepo, txRepo, limitsRepo, &mockTransactionReviewRepo{}, &mockAccountEventRepo{}, &mockBalanceSnapshotRepo{}, &mockTransactor{}, domain.RiskRules{})
+
+			err := svc.Withdraw(ctx, domain.Transaction{Id: i + 1, UserId: 10, Amount: tt.amount})
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("неожиданная ошибка: %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ожидалась ошибка %v, получено %v", tt.wantErr, err)
+			}
+			if tt.wantErr != nil && account.Balance != 10000 {
+				t.Errorf("при отказе баланс изменился: %.2f", account.Balance)
+			}
+		})
+	}
+}
+
+func TestPaymentService_ReviewQueue(t *testing.T) {
+	ctx := context.Background()
+	account := &domain.Account{Id: 1, UserId: 10, Balance: 1000, IsDefault: true, Status: domain.AccountStatusActive,
+		CreationDate: time.Now().Add(-30 * 24 * time.Hour)}
+	saved := make(map[int]domain.Transaction)
+
+	accRepo := &mockAccountRepo{
+		getDefaultByUserIdFunc: func(ctx context.Context, id int) (*domain.Account, error) {
+			return account, nil
+		},
+		getByIdFunc: func(ctx context.Context, id int) (*domain.Account, error) {
+			return account, nil
+		},
+	}
+	declined := 0
+	txRepo := &mockTransactionRepo{
+		getByIdFunc: func(ctx context.Context, id int) (*domain.Transaction, error) {
+			tx, ok := saved[id]
+			if !ok {
+				return nil, nil
+			}
+			return &tx, nil
+		},
+		countDeclinedFunc: func(ctx context.Context, userId int, since time.Time) (int, error) {
+			return declined, nil
+		},
+		saveFunc: func(ctx context.Context, tx *domain.Transaction) error {
+			saved[tx.Id] = *tx
+			return nil
+		},
+		updateStatusFunc: func(ctx context.Context, tx *domain.Transaction) error {
+			saved[tx.Id] = *tx
+			return nil
+		},
+	}
+	reviewRepo := &mockTransactionReviewRepo{}
+	eventRepo := &mockAccountEventRepo{}
+	rules := domain.RiskRules{LargeAmount: 500, MaxFailedAttempts: 3, FailedAttemptsWindow: time.Hour}
+	svc, _ := NewPaymentService(accRepo, txRepo, &mockSpendingLimitsRepo{}, reviewRepo, eventRepo, &mockBalanceSnapshotRepo{}, &mockTransactor{}, rules)
+
+	err := svc.Withdraw(ctx, domain.Transaction{Id: 1, UserId: 10, Amount: 100})
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+
+	err = svc.Withdraw(ctx, domain.Transaction{Id: 2, UserId: 10, Amount: 600})
+	if !errors.Is(err, domain.ErrPendingReview) {
+		t.Fatalf("ожидалась задержка до проверки, получено %v", err)
+	}
+	if account.Balance != 900 || saved[2].Status != domain.TransactionStatusPendingReview {
+		t.Errorf("задержанная операция изменила баланс или не сохранена: %.2f %+v", account.Balance, saved[2])
+	}
+	pending, _ := svc.GetPendingReviews(ctx)
+	if len(pending) != 1 || pending[0].Transaction == nil || pending[0].Flags[0] != domain.RiskFlagLargeAmount {
+		t.Fatalf("неожиданная очередь проверки: %+v", pending)
+	}
+	err = svc.Withdraw(ctx, domain.Transaction{Id: 2, UserId: 10, Amount: 600})
+	if !errors.Is(err, domain.ErrPendingReview) {
+		t.Errorf("повторная обработка должна вернуть исходный результат, получено %v", err)
+	}
+
+	_, err = svc.ApproveReview(ctx, 2, "", "")
+	if err == nil {
+		t.Error("ожидалась ошибка без указания администратора")
+	}
+	tx, err := svc.ApproveReview(ctx, 2, "admin", "звонок клиенту")
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if tx.Status != domain.TransactionStatusCompleted || account.Balance != 300 {
+		t.Errorf("операция не проведена после одобрения: %+v, баланс %.2f", tx, account.Balance)
+	}
+	if reviewRepo.reviews[2].Status != domain.ReviewStatusApproved || reviewRepo.reviews[2].DecidedBy != "admin" {
+		t.Errorf("решение не сохранено: %+v", reviewRepo.reviews[2])
+	}
+	reviewed := eventRepo.events[len(eventRepo.events)-1]
+	if reviewed.Type != domain.AccountEventPaymentReviewed || *reviewed.TransactionId != 2 || reviewed.TransactionStatus != domain.TransactionStatusCompleted {
+		t.Errorf("решение не опубликовано событием: %+v", reviewed)
+	}
+	_, err = svc.RejectReview(ctx, 2, "admin", "")
+	if err == nil {
+		t.Error("ожидалась ошибка для уже рассмотренной операции")
+	}
+
+	declined = 3
+	err = svc.Withdraw(ctx, domain.Transaction{Id: 3, UserId: 10, Amount: 10})
+	if !errors.Is(err, domain.ErrPendingReview) {
+		t.Fatalf("ожидалась задержка после серии отказов, получено %v", err)
+	}
+	tx, err = svc.RejectReview(ctx, 3, "admin", "мошенничество")
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if tx.Status != domain.TransactionStatusDeclined || tx.FailureCode != domain.ReasonRejectedByReview || account.Balance != 300 {
+		t.Errorf("операция не отклонена: %+v, баланс %.2f", tx, account.Balance)
+	}
+	reviewed = eventRepo.events[len(eventRepo.events)-1]
+	if reviewed.Type != domain.AccountEventPaymentReviewed || *reviewed.TransactionId != 3 ||
+		reviewed.TransactionStatus != domain.TransactionStatusDeclined || reviewed.FailureCode != domain.ReasonRejectedByReview {
+		t.Errorf("отказ не опубликован событием: %+v", reviewed)
+	}
+	err = svc.Withdraw(ctx, domain.Transaction{Id: 3, UserId: 10, Amount: 10})
+	if domain.DeclineReason(err) != domain.ReasonRejectedByReview {
+		t.Errorf("ожидался отказ по решению проверки, получено %v", err)
+	}
+}
+
+func TestPaymentService_Reverse(t *testing.T) {
+	ctx := context.Background()
+	account := &domain.Account{Id: 1, UserId: 10, Balance: 700, IsDefault: true, Status: domain.AccountStatusActive}
+	saved := map[int]domain.Transaction{
+		1: {Id: 1, UserId: 10, AccountId: 1, Type: domain.TransactionTypePayment, Amount: 300, Status: domain.TransactionStatusCompleted},
+		2: {Id: 2, UserId: 10, AccountId: 1, Type: domain.TransactionTypePayment, Amount: 50, Status: domain.TransactionStatusDeclined},
+	}
+
+	accRepo := &mockAccountRepo{
+		getByIdFunc: func(ctx context.Context, id int) (*domain.Account, error) {
+			return account, nil
+		},
+	}
+	txRepo := &mockTransactionRepo{
+		getByIdFunc: func(ctx context.Context, id int) (*domain.Transaction, error) {
+			tx, ok := saved[id]
+			if !ok {
+				return nil, nil
+			}
+			return &tx, nil
+		},
+		addReversedAmountFunc: func(ctx context.Context, id int, amount float64) error {
+			tx := saved[id]
+			if tx.ReversedAmount+amount > tx.Amount {
+				return domain.ErrReversalExceeded
+			}
+			tx.ReversedAmount += amount
+			saved[id] = tx
+			return nil
+		},
+		saveFunc: func(ctx context.Context, tx *domain.Transaction) error {
+			saved[tx.Id] = *tx
+			return nil
+		},
+	}
+	svc, _ := NewPaymentService(accRepo, txRepo, &mockSpendingLimitsRepo{}, &mockTransactionReviewRepo{}, &mockAccountEventRepo{}, &mockBalanceSnapshotRepo{}, &mockTransactor{}, domain.RiskRules{})
+
+	reversal, err := svc.Reverse(ctx, 1, 100, domain.ReversalCustomerRequest)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if !reversal.IsDeposit || reversal.ReversalOf == nil || *reversal.ReversalOf != 1 || reversal.ReversalReason != domain.ReversalCustomerRequest {
+		t.Errorf("неожиданная отменяющая транзакция: %+v", reversal)
+	}
+	if account.Balance != 800 || saved[1].ReversedAmount != 100 {
+		t.Errorf("отмена не учтена: баланс %.2f, отменено %.2f", account.Balance, saved[1].ReversedAmount)
+	}
+
+	_, err = svc.Reverse(ctx, 1, 250, domain.ReversalFraud)
+	if !errors.Is(err, domain.ErrReversalExceeded) {
+		t.Errorf("ожидался отказ при отмене сверх суммы, получено %v", err)
+	}
+	_, err = svc.Reverse(ctx, 1, 200, domain.ReversalFraud)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	_, err = svc.Reverse(ctx, 1, 0.01, domain.ReversalFraud)
+	if !errors.Is(err, domain.ErrReversalExceeded) {
+		t.Errorf("ожидался отказ при повторной отмене, получено %v", err)
+	}
+	_, err = svc.Reverse(ctx, 2, 50, domain.ReversalDuplicate)
+	if !errors.Is(err, domain.ErrOperationNotAllowed) {
+		t.Errorf("ожидался отказ для отклонённой транзакции, получено %v", err)
+	}
+	_, err = svc.Reverse(ctx, reversal.Id, 10, domain.ReversalDuplicate)
+	if !errors.Is(err, domain.ErrOperationNotAllowed) {
+		t.Errorf("ожидался отказ для отмены отмены, получено %v", err)
+	}
+	_, err = svc.Reverse(ctx, 99, 10, domain.ReversalDuplicate)
+	if !errors.Is(err, ErrTransactionNotFound) {
+		t.Errorf("ожидалась ошибка для неизвестной транзакции, получено %v", err)
+	}
+	if account.Balance != 1000 {
+		t.Errorf("ожидался баланс 1000 после полной отмены, получен %.2f", account.Balance)
+	}
+}
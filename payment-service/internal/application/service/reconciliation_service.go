@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand"
+	"payment-service/internal/application/repository"
+	"payment-service/internal/domain"
+	"time"
+)
+
+// ReconciliationService сверяет сохранённые балансы счетов с историей транзакций
+// и по одобрению администратора записывает корректирующие транзакции.
+type ReconciliationService struct {
+	accountDb     repository.AccountRepository
+	transactionDb repository.TransactionRepository
+	transactor    repository.Transactor
+}
+
+// NewReconciliationService создаёт новый экземпляр ReconciliationService.
+func NewReconciliationService(accountDb repository.AccountRepository, transactionDb repository.TransactionRepository,
+	transactor repository.Transactor) *ReconciliationService {
+	return &ReconciliationService{accountDb: accountDb, transactionDb: transactionDb, transactor: transactor}
+}
+
+// Reconcile пересчитывает баланс каждого счёта по транзакциям
+// и возвращает список найденных расхождений.
+func (rs *ReconciliationService) Reconcile(ctx context.Context) ([]domain.BalanceMismatch, error) {
+	accounts, err := rs.accountDb.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	mismatches := make([]domain.BalanceMismatch, 0)
+	for _, account := range accounts {
+		transactions, err := rs.transactionDb.GetByAccountId(ctx, account.Id)
+		if err != nil {
+			return nil, err
+		}
+		if mismatch := domain.Reconcile(account, transactions); mismatch != nil {
+			mismatches = append(mismatches, *mismatch)
+		}
+	}
+	return mismatches, nil
+}
+
+// Adjust записывает корректирующие транзакции для одобренных расхождений.
+// Перед записью расхождение пересчитывается под блокировкой счёта: если оно исчезло
+// или изменилось с момента отчёта, корректировка пропускается.
+// Возвращает записанные транзакции; если не указан администратор — ошибку.
+func (rs *ReconciliationService) Adjust(ctx context.Context, mismatches []domain.BalanceMismatch, approvedBy string) ([]domain.Transaction, error) {
+	if approvedBy == "" {
+		return nil, errors.New("approved_by is required")
+	}
+	adjustments := make([]domain.Transaction, 0, len(mismatches))
+	for _, approved := range mismatches {
+		var adjustment *domain.Transaction
+		err := rs.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			account, err := rs.accountDb.GetById(ctx, approved.AccountId)
+			if err != nil {
+				return err
+			}
+			transactions, err := rs.transactionDb.GetByAccountId(ctx, account.Id)
+			if err != nil {
+				return err
+			}
+			current := domain.Reconcile(*account, transactions)
+			if current == nil || current.Difference != approved.Difference {
+				log.Printf("reconciliation: account %d changed since report, adjustment skipped", approved.AccountId)
+				return nil
+			}
+			tx := current.Adjustment(rand.Intn(2147483645), time.Now())
+			adjustment = &tx
+			return rs.transactionDb.Save(ctx, adjustment)
+		})
+		if err != nil {
+			return adjustments, err
+		}
+		if adjustment != nil {
+			log.Printf("reconciliation: adjustment %d of %.2f for account %d approved by %s",
+				adjustment.Id, approved.Difference, adjustment.AccountId, approvedBy)
+			adjustments = append(adjustments, *adjustment)
+		}
+	}
+	return adjustments, nil
+}
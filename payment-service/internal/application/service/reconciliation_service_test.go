@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"payment-service/internal/domain"
+	"testing"
+)
+
+func TestReconciliationService_ReconcileAndAdjust(t *testing.T) {
+	ctx := context.Background()
+	accounts := map[int]*domain.Account{
+		1: {Id: 1, UserId: 10, Balance: 100},
+		2: {Id: 2, UserId: 20, Balance: 50},
+	}
+	ledger := map[int][]domain.Transaction{
+		1: {{Id: 1, AccountId: 1, IsDeposit: true, Amount: 100, Status: domain.TransactionStatusCompleted}},
+		2: {{Id: 2, AccountId: 2, IsDeposit: true, Amount: 70, Status: domain.TransactionStatusCompleted}},
+	}
+
+	accRepo := &mockAccountRepository{
+		getAllFunc: func(ctx context.Context) ([]domain.Account, error) {
+			return []domain.Account{*accounts[1], *accounts[2]}, nil
+		},
+		getByIdFunc: func(ctx context.Context, id int) (*domain.Account, error) {
+			return accounts[id], nil
+		},
+		saveFunc: func(ctx context.Context, account *domain.Account) error {
+			t.Error("сверка не должна менять баланс счёта")
+			return nil
+		},
+	}
+	txRepo := &mockTransactionRepo{
+		getByAccountIdFunc: func(ctx context.Context, accountId int) ([]domain.Transaction, error) {
+			return ledger[accountId], nil
+		},
+		saveFunc: func(ctx context.Context, tx *domain.Transaction) error {
+			ledger[tx.AccountId] = append(ledger[tx.AccountId], *tx)
+			return nil
+		},
+	}
+	svc := NewReconciliationService(accRepo, txRepo, &mockTransactor{})
+
+	mismatches, err := svc.Reconcile(ctx)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if len(mismatches) != 1 || mismatches[0].AccountId != 2 || mismatches[0].Difference != -20 {
+		t.Fatalf("неожиданные расхождения: %+v", mismatches)
+	}
+
+	_, err = svc.Adjust(ctx, mismatches, "")
+	if err == nil {
+		t.Error("ожидалась ошибка без одобрения администратора")
+	}
+
+	adjustments, err := svc.Adjust(ctx, mismatches, "admin")
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if len(adjustments) != 1 || adjustments[0].IsDeposit || adjustments[0].Amount != 20 {
+		t.Errorf("неожиданные корректировки: %+v", adjustments)
+	}
+
+	mismatches, _ = svc.Reconcile(ctx)
+	if len(mismatches) != 0 {
+		t.Errorf("после корректировки остались расхождения: %+v", mismatches)
+	}
+
+	adjustments, err = svc.Adjust(ctx, []domain.BalanceMismatch{{AccountId: 2, Difference: -20}}, "admin")
+	if err != nil || len(adjustments) != 0 {
+		t.Errorf("устаревшее расхождение не должно корректироваться: %+v, %v", adjustments, err)
+	}
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"payment-service/internal/application/repository"
+	"payment-service/internal/domain"
+	"time"
+)
+
+// StatementService составляет выписки по счетам за календарный месяц.
+// Балансы на начало периода рассчитываются через BalanceService, поэтому используют снимки балансов.
+type StatementService struct {
+	accountDb      repository.AccountRepository
+	transactionDb  repository.TransactionRepository
+	balanceService *BalanceService
+}
+
+// NewStatementService создаёт новый экземпляр StatementService.
+func NewStatementService(accountDb repository.AccountRepository, transactionDb repository.TransactionRepository,
+	balanceService *BalanceService) *StatementService {
+	return &StatementService{accountDb: accountDb, transactionDb: transactionDb, balanceService: balanceService}
+}
+
+// GetStatement возвращает выписку по счёту accountId за месяц month.
+// Выписка за текущий месяц составляется на момент now.
+// Если счёт не найден или месяц ещё не начался — возвращает ошибку.
+func (ss *StatementService) GetStatement(ctx context.Context, accountId int, month time.Time) (*domain.Statement, error) {
+	start, end, err := statementPeriod(month)
+	if err != nil {
+		return nil, err
+	}
+	account, err := ss.accountDb.GetById(ctx, accountId)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, domain.ErrAccountNotFound
+	}
+	return ss.statement(ctx, *account, start, end)
+}
+
+// GetStatements возвращает выписки за месяц month по всем счетам, открытым до его окончания.
+func (ss *StatementService) GetStatements(ctx context.Context, month time.Time) ([]domain.Statement, error) {
+	start, end, err := statementPeriod(month)
+	if err != nil {
+		return nil, err
+	}
+	accounts, err := ss.accountDb.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	statements := make([]domain.Statement, 0, len(accounts))
+	for _, account := range accounts {
+		if account.CreationDate.After(end) {
+			continue
+		}
+		statement, err := ss.statement(ctx, account, start, end)
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, *statement)
+	}
+	return statements, nil
+}
+
+// statement составляет выписку по счёту за промежуток (start, end].
+func (ss *StatementService) statement(ctx context.Context, account domain.Account, start, end time.Time) (*domain.Statement, error) {
+	opening, err := ss.balanceService.balanceAt(ctx, account, start)
+	if err != nil {
+		return nil, err
+	}
+	transactions, err := ss.transactionDb.GetCompletedBetween(ctx, account.Id, start, end)
+	if err != nil {
+		return nil, err
+	}
+	statement := domain.NewStatement(*opening, transactions, end)
+	return &statement, nil
+}
+
+// statementPeriod возвращает границы выписки за месяц month.
+// Для текущего месяца конец периода ограничивается текущим моментом.
+func statementPeriod(month time.Time) (time.Time, time.Time, error) {
+	start, end := domain.StatementPeriod(month)
+	now := time.Now().Truncate(time.Microsecond)
+	if start.After(now) {
+		return time.Time{}, time.Time{}, ErrFutureBalanceTime
+	}
+	if end.After(now) {
+		end = now
+	}
+	return start, end, nil
+}
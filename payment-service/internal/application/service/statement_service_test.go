@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"payment-service/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestStatementService_GetStatements(t *testing.T) {
+	ctx := context.Background()
+	month := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	accounts := []domain.Account{
+		{Id: 1, UserId: 10, Currency: "RUB", CreationDate: month.AddDate(0, -2, 0)},
+		{Id: 2, UserId: 20, Currency: "RUB", CreationDate: month.AddDate(0, 0, 10)},
+		{Id: 3, UserId: 30, Currency: "RUB", CreationDate: month.AddDate(0, 1, 1)},
+	}
+	transactions := []domain.Transaction{
+		{Id: 1, AccountId: 1, IsDeposit: true, Amount: 100, Status: domain.TransactionStatusCompleted, Date: month.AddDate(0, -1, 0)},
+		{Id: 2, AccountId: 1, Amount: 40, Status: domain.TransactionStatusCompleted, Date: month.AddDate(0, 0, 3)},
+		{Id: 3, AccountId: 2, IsDeposit: true, Amount: 25, Status: domain.TransactionStatusCompleted, Date: month.AddDate(0, 0, 12)},
+		{Id: 4, AccountId: 1, IsDeposit: true, Amount: 10, Status: domain.TransactionStatusCompleted, Date: month.AddDate(0, 1, 2)},
+	}
+	txRepo := ledgerRepo(transactions)
+	txRepo.getCompletedFunc = func(ctx context.Context, accountId int, after, until time.Time) ([]domain.Transaction, error) {
+		var result []domain.Transaction
+		for _, tx := range transactions {
+			if tx.AccountId == accountId && tx.Date.After(after) && !tx.Date.After(until) {
+				result = append(result, tx)
+			}
+		}
+		return result, nil
+	}
+	accountDb := &mockAccountRepository{
+		getByIdFunc: func(ctx context.Context, id int) (*domain.Account, error) {
+			return &accounts[id-1], nil
+		},
+		getAllFunc: func(ctx context.Context) ([]domain.Account, error) {
+			return accounts, nil
+		},
+	}
+	svc := NewStatementService(accountDb, txRepo, NewBalanceService(accountDb, txRepo, &mockBalanceSnapshotRepo{}))
+
+	statements, err := svc.GetStatements(ctx, month)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if len(statements) != 2 {
+		t.Fatalf("ожидались выписки по двум счетам, получено %d", len(statements))
+	}
+	first := statements[0]
+	if first.OpeningBalance != 100 || first.ClosingBalance != 60 || len(first.Entries) != 1 || first.Entries[0].TransactionId != 2 {
+		t.Errorf("неожиданная выписка по счёту 1: %+v", first)
+	}
+	if statements[1].OpeningBalance != 0 || statements[1].ClosingBalance != 25 {
+		t.Errorf("неожиданная выписка по счёту 2: %+v", statements[1])
+	}
+
+	statement, err := svc.GetStatement(ctx, 1, month.AddDate(0, 1, 0))
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if statement.OpeningBalance != first.ClosingBalance {
+		t.Errorf("баланс на начало месяца должен совпадать с балансом на конец предыдущего: %.2f", statement.OpeningBalance)
+	}
+
+	_, err = svc.GetStatement(ctx, 1, time.Now().AddDate(0, 2, 0))
+	if !errors.Is(err, ErrFutureBalanceTime) {
+		t.Errorf("ожидалась ErrFutureBalanceTime, получено %v", err)
+	}
+}
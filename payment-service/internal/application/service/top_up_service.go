@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"payment-service/internal/application/provider"
+	"payment-service/internal/application/repository"
+	"payment-service/internal/domain"
+	"time"
+)
+
+// ErrTopUpNotFound возвращается, если пополнение с указанным ID не найдено.
+var ErrTopUpNotFound = errors.New("top-up not found")
+
+// TopUpService отвечает за пополнение счетов через внешнего платёжного провайдера.
+// Пополнение создаётся в статусе pending, а баланс меняется только после
+// подписанного уведомления провайдера об успешной оплате.
+type TopUpService struct {
+	topUpRepository       repository.TopUpRepository
+	accountRepository     repository.AccountRepository
+	transactionRepository repository.TransactionRepository
+	eventRepository       repository.AccountEventRepository
+	transactor            repository.Transactor
+	provider              provider.PaymentProvider
+}
+
+// NewTopUpService создаёт новый экземпляр TopUpService.
+// Возвращает ошибку, если один из репозиториев или провайдер не инициализирован.
+func NewTopUpService(topUpsDb repository.TopUpRepository, accountsDb repository.AccountRepository,
+	transactionsDb repository.TransactionRepository, eventsDb repository.AccountEventRepository,
+	transactor repository.Transactor, paymentProvider provider.PaymentProvider) (*TopUpService, error) {
+	if topUpsDb == nil || accountsDb == nil || transactionsDb == nil || eventsDb == nil || transactor == nil || paymentProvider == nil {
+		return nil, fmt.Errorf("nil repository")
+	}
+	return &TopUpService{
+		topUpRepository:       topUpsDb,
+		accountRepository:     accountsDb,
+		transactionRepository: transactionsDb,
+		eventRepository:       eventsDb,
+		transactor:            transactor,
+		provider:              paymentProvider,
+	}, nil
+}
+
+// CreateTopUp создаёт намерение пополнить кошелёк accountId на сумму amount
+// и регистрирует платёж у провайдера.
+// Возвращает пополнение с адресом подтверждения оплаты.
+// Если кошелёк не найден, сумма не положительна или статус счёта запрещает пополнения — возвращает ошибку.
+func (service *TopUpService) CreateTopUp(ctx context.Context, accountId int, amount float64) (*domain.TopUp, error) {
+	if amount <= 0 {
+		return nil, domain.ErrInvalidAmount
+	}
+	account, err := service.accountRepository.GetById(ctx, accountId)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, domain.ErrAccountNotFound
+	}
+	if !account.CanDeposit() {
+		return nil, fmt.Errorf("deposits are not allowed for %s account: %w", account.Status, domain.ErrOperationNotAllowed)
+	}
+
+	now := time.Now()
+	topUp := &domain.TopUp{
+		Id:        rand.Intn(2147483645),
+		AccountId: account.Id,
+		UserId:    account.UserId,
+		Amount:    amount,
+		Status:    domain.TopUpStatusPending,
+		Provider:  service.provider.Name(),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	// Пополнение сохраняется до обращения к провайдеру, чтобы уведомление о платеже
+	// не могло прийти раньше, чем появится запись о нём.
+	err = service.topUpRepository.Save(ctx, topUp)
+	if err != nil {
+		return nil, err
+	}
+	payment, err := service.provider.CreatePayment(ctx, *topUp)
+	if err != nil {
+		failErr := topUp.Fail(err.Error(), time.Now())
+		if failErr == nil {
+			failErr = service.topUpRepository.Save(ctx, topUp)
+		}
+		return nil, errors.Join(fmt.Errorf("payment provider: %w", err), failErr)
+	}
+	topUp.ProviderRef = payment.Reference
+	topUp.ConfirmationURL = payment.ConfirmationURL
+	err = service.topUpRepository.Save(ctx, topUp)
+	if err != nil {
+		return nil, err
+	}
+	return topUp, nil
+}
+
+// HandleCallback проверяет подпись уведомления провайдера и применяет результат оплаты.
+// При успешной оплате баланс пополняется транзакцией типа top_up.
+// Повторное уведомление с тем же результатом ничего не меняет.
+// Возвращает provider.ErrInvalidSignature, если подпись не прошла проверку.
+func (service *TopUpService) HandleCallback(ctx context.Context, body []byte, signature string) (*domain.TopUp, error) {
+	callback, err := service.provider.ParseCallback(body, signature)
+	if err != nil {
+		return nil, err
+	}
+
+	var topUp *domain.TopUp
+	err = service.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		topUp, err = service.topUpRepository.GetById(ctx, callback.TopUpId)
+		if err != nil {
+			return err
+		}
+		if topUp == nil {
+			return ErrTopUpNotFound
+		}
+		if topUp.ProviderRef != callback.Reference {
+			return fmt.Errorf("payment reference %q does not match top-up %d", callback.Reference, topUp.Id)
+		}
+		if topUp.Status != domain.TopUpStatusPending {
+			// Провайдеры повторяют уведомления, пока не получат ответ; повтор не должен менять баланс.
+			if (topUp.Status == domain.TopUpStatusSucceeded) == callback.Succeeded {
+				return nil
+			}
+			return fmt.Errorf("top-up %d is already %s: %w", topUp.Id, topUp.Status, domain.ErrOperationNotAllowed)
+		}
+		if !callback.Succeeded {
+			err = topUp.Fail(callback.FailureReason, time.Now())
+			if err != nil {
+				return err
+			}
+			return service.topUpRepository.Save(ctx, topUp)
+		}
+		return service.credit(ctx, topUp)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return topUp, nil
+}
+
+// GetAccountTopUps возвращает все пополнения кошелька, включая ожидающие и неуспешные.
+func (service *TopUpService) GetAccountTopUps(ctx context.Context, accountId int) ([]domain.TopUp, error) {
+	return service.topUpRepository.GetByAccountId(ctx, accountId)
+}
+
+// GetTopUpsByStatus возвращает пополнения в статусе status.
+func (service *TopUpService) GetTopUpsByStatus(ctx context.Context, status domain.TopUpStatus) ([]domain.TopUp, error) {
+	return service.topUpRepository.GetByStatus(ctx, status)
+}
+
+// credit зачисляет подтверждённое пополнение на баланс и связывает его с транзакцией.
+func (service *TopUpService) credit(ctx context.Context, topUp *domain.TopUp) error {
+	account, err := service.accountRepository.GetById(ctx, topUp.AccountId)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	err = account.Deposit(topUp.Amount)
+	if err != nil {
+		// Деньги у провайдера уже списаны, поэтому отказ фиксируется в пополнении для ручного разбора.
+		failErr := topUp.Fail(err.Error(), now)
+		if failErr != nil {
+			return failErr
+		}
+		return service.topUpRepository.Save(ctx, topUp)
+	}
+	transaction := &domain.Transaction{
+		Id:        rand.Intn(2147483645),
+		UserId:    topUp.UserId,
+		AccountId: topUp.AccountId,
+		IsDeposit: true,
+		Type:      domain.TransactionTypeTopUp,
+		Amount:    topUp.Amount,
+		Date:      now,
+	}
+	transaction.Complete()
+	err = service.transactionRepository.Save(ctx, transaction)
+	if err != nil {
+		return err
+	}
+	err = service.accountRepository.Save(ctx, account)
+	if err != nil {
+		return err
+	}
+	err = recordEvent(ctx, service.eventRepository, domain.NewBalanceChangedEvent(*account, *transaction, now))
+	if err != nil {
+		return err
+	}
+	err = topUp.Succeed(transaction.Id, now)
+	if err != nil {
+		return err
+	}
+	return service.topUpRepository.Save(ctx, topUp)
+}
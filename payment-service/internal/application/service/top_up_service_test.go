@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"payment-service/internal/application/provider"
+	"payment-service/internal/domain"
+	"payment-service/internal/infrastructure/fakeprovider"
+	"testing"
+	"time"
+)
+
+type mockTopUpRepo struct {
+	topUps map[int]domain.TopUp
+}
+
+func (m *mockTopUpRepo) GetById(ctx context.Context, id int) (*domain.TopUp, error) {
+	topUp, ok := m.topUps[id]
+	if !ok {
+		return nil, nil
+	}
+	return &topUp, nil
+}
+
+func (m *mockTopUpRepo) GetByAccountId(ctx context.Context, accountId int) ([]domain.TopUp, error) {
+	result := make([]domain.TopUp, 0)
+	for _, topUp := range m.topUps {
+		if topUp.AccountId == accountId {
+			result = append(result, topUp)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockTopUpRepo) GetByStatus(ctx context.Context, status domain.TopUpStatus) ([]domain.TopUp, error) {
+	result := make([]domain.TopUp, 0)
+	for _, topUp := range m.topUps {
+		if topUp.Status == status {
+			result = append(result, topUp)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockTopUpRepo) Save(ctx context.Context, topUp *domain.TopUp) error {
+	m.topUps[topUp.Id] = *topUp
+	return nil
+}
+
+type failingProvider struct {
+	*fakeprovider.Provider
+}
+
+func (p failingProvider) CreatePayment(ctx context.Context, topUp domain.TopUp) (*provider.Payment, error) {
+	return nil, errors.New("provider unavailable")
+}
+
+func signedCallback(t *testing.T, p *fakeprovider.Provider, callback provider.Callback) ([]byte, string) {
+	t.Helper()
+	body, err := json.Marshal(callback)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	return body, p.Sign(body)
+}
+
+func newTopUpTestService(t *testing.T, account *domain.Account, paymentProvider provider.PaymentProvider) (*TopUpService, *mockTopUpRepo, *[]domain.Transaction) {
+	t.Helper()
+	topUps := &mockTopUpRepo{topUps: map[int]domain.TopUp{}}
+	accounts := &mockAccountRepo{
+		getByIdFunc: func(ctx context.Context, id int) (*domain.Account, error) {
+			if id != account.Id {
+				return nil, nil
+			}
+			return account, nil
+		},
+	}
+	var transactions []domain.Transaction
+	txRepo := &mockTransactionRepo{
+		saveFunc: func(ctx context.Context, tx *domain.Transaction) error {
+			transactions = append(transactions, *tx)
+			return nil
+		},
+	}
+	svc, err := NewTopUpService(topUps, accounts, txRepo, &mockAccountEventRepo{}, &mockTransactor{}, paymentProvider)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	return svc, topUps, &transactions
+}
+
+func TestTopUpService_CreateTopUp(t *testing.T) {
+	ctx := context.Background()
+	account := &domain.Account{Id: 1, UserId: 10, Balance: 100, Status: domain.AccountStatusActive, CreationDate: time.Now()}
+	svc, topUps, transactions := newTopUpTestService(t, account, fakeprovider.New("secret", "", "http://localhost"))
+
+	topUp, err := svc.CreateTopUp(ctx, 1, 50)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if topUp.Status != domain.TopUpStatusPending || topUp.ProviderRef == "" || topUp.ConfirmationURL == "" {
+		t.Errorf("неожиданное пополнение: %+v", topUp)
+	}
+	if topUps.topUps[topUp.Id].ProviderRef != topUp.ProviderRef {
+		t.Error("ссылка на платёж не сохранена")
+	}
+	if account.Balance != 100 || len(*transactions) != 0 {
+		t.Error("баланс не должен меняться до подтверждения оплаты")
+	}
+
+	_, err = svc.CreateTopUp(ctx, 1, -5)
+	if !errors.Is(err, domain.ErrInvalidAmount) {
+		t.Errorf("ожидалась ErrInvalidAmount, получено %v", err)
+	}
+	_, err = svc.CreateTopUp(ctx, 2, 50)
+	if !errors.Is(err, domain.ErrAccountNotFound) {
+		t.Errorf("ожидалась ErrAccountNotFound, получено %v", err)
+	}
+}
+
+func TestTopUpService_CreateTopUp_ProviderError(t *testing.T) {
+	ctx := context.Background()
+	account := &domain.Account{Id: 1, UserId: 10, Status: domain.AccountStatusActive}
+	svc, topUps, _ := newTopUpTestService(t, account, failingProvider{fakeprovider.New("secret", "", "")})
+
+	_, err := svc.CreateTopUp(ctx, 1, 50)
+	if err == nil {
+		t.Fatal("ожидалась ошибка провайдера")
+	}
+	failed, _ := topUps.GetByStatus(ctx, domain.TopUpStatusFailed)
+	if len(failed) != 1 || failed[0].FailureReason == "" {
+		t.Errorf("неуспешное пополнение не сохранено: %+v", failed)
+	}
+}
+
+func TestTopUpService_HandleCallback(t *testing.T) {
+	ctx := context.Background()
+	p := fakeprovider.New("secret", "", "")
+
+	t.Run("успешная оплата пополняет баланс один раз", func(t *testing.T) {
+		account := &domain.Account{Id: 1, UserId: 10, Balance: 100, Status: domain.AccountStatusActive}
+		svc, _, transactions := newTopUpTestService(t, account, p)
+		topUp, err := svc.CreateTopUp(ctx, 1, 50)
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		body, signature := signedCallback(t, p, provider.Callback{TopUpId: topUp.Id, Reference: topUp.ProviderRef, Succeeded: true})
+
+		for range 2 {
+			topUp, err = svc.HandleCallback(ctx, body, signature)
+			if err != nil {
+				t.Fatalf("неожиданная ошибка: %v", err)
+			}
+		}
+		if account.Balance != 150 {
+			t.Errorf("ожидался баланс 150, получен %.2f", account.Balance)
+		}
+		if len(*transactions) != 1 || (*transactions)[0].Type != domain.TransactionTypeTopUp {
+			t.Fatalf("неожиданные транзакции: %+v", *transactions)
+		}
+		if topUp.Status != domain.TopUpStatusSucceeded || topUp.TransactionId == nil || *topUp.TransactionId != (*transactions)[0].Id {
+			t.Errorf("неожиданное пополнение: %+v", topUp)
+		}
+
+		failBody, failSignature := signedCallback(t, p, provider.Callback{TopUpId: topUp.Id, Reference: topUp.ProviderRef})
+		_, err = svc.HandleCallback(ctx, failBody, failSignature)
+		if !errors.Is(err, domain.ErrOperationNotAllowed) {
+			t.Errorf("ожидалась ErrOperationNotAllowed, получено %v", err)
+		}
+	})
+
+	t.Run("отказ провайдера не меняет баланс", func(t *testing.T) {
+		account := &domain.Account{Id: 1, UserId: 10, Balance: 100, Status: domain.AccountStatusActive}
+		svc, _, transactions := newTopUpTestService(t, account, p)
+		topUp, _ := svc.CreateTopUp(ctx, 1, 50)
+		body, signature := signedCallback(t, p, provider.Callback{TopUpId: topUp.Id, Reference: topUp.ProviderRef, FailureReason: "card declined"})
+
+		topUp, err := svc.HandleCallback(ctx, body, signature)
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if topUp.Status != domain.TopUpStatusFailed || topUp.FailureReason != "card declined" {
+			t.Errorf("неожиданное пополнение: %+v", topUp)
+		}
+		if account.Balance != 100 || len(*transactions) != 0 {
+			t.Error("баланс не должен меняться при отказе")
+		}
+	})
+
+	t.Run("неверная подпись и неизвестное пополнение", func(t *testing.T) {
+		account := &domain.Account{Id: 1, UserId: 10, Status: domain.AccountStatusActive}
+		svc, _, _ := newTopUpTestService(t, account, p)
+		body, _ := signedCallback(t, p, provider.Callback{TopUpId: 5, Reference: "fake-5", Succeeded: true})
+
+		_, err := svc.HandleCallback(ctx, body, "deadbeef")
+		if !errors.Is(err, provider.ErrInvalidSignature) {
+			t.Errorf("ожидалась ErrInvalidSignature, получено %v", err)
+		}
+		_, err = svc.HandleCallback(ctx, body, p.Sign(body))
+		if !errors.Is(err, ErrTopUpNotFound) {
+			t.Errorf("ожидалась ErrTopUpNotFound, получено %v", err)
+		}
+	})
+}
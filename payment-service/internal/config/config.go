@@ -1,19 +1,48 @@
 package config
 
 import (
+	"contracts/signing"
 	"fmt"
+	"kafkacluster"
 	"os"
+	"payment-service/internal/domain"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // Config содержит все конфигурационные параметры приложения
 type Config struct {
-	HttpPort           string   // Порт для HTTP сервера
-	DatabaseURL        string   // URL для подключения к базе данных
-	KafkaBrokers       []string // Список брокеров Kafka
-	KafkaConsumerTopic string   // Топик для потребления сообщений
-	KafkaProducerTopic string   // Топик для производства сообщений
-	KafkaGroupID       string   // Group ID для Kafka consumer
+	HttpPort           string                // Порт для HTTP сервера
+	GrpcPort           string                // Порт для gRPC сервера; пустое значение — gRPC API отключён
+	DatabaseURL        string                // URL для подключения к базе данных
+	MessageBus         string                // Транспорт сообщений между сервисами: kafka или memory (в памяти процесса)
+	KafkaBrokers       []string              // Список брокеров Kafka
+	KafkaConsumerTopic string                // Топик для потребления сообщений
+	KafkaProducerTopic string                // Топик для производства сообщений
+	KafkaGroupID       string                // Group ID для Kafka consumer
+	KafkaEventsTopic   string                // Топик для доменных событий счетов
+	KafkaDeadLetters   string                // Топик для сообщений, обработка которых не удалась после всех попыток
+	KafkaMaxAttempts   int                   // Число попыток обработки сообщения, включая первую
+	KafkaRetryBackoff  time.Duration         // Пауза перед повторной обработкой; удваивается с каждой попыткой
+	KafkaMaxBackoff    time.Duration         // Максимальная пауза между попытками обработки
+	KafkaWorkers       int                   // Число параллельных обработчиков сообщений
+	KafkaWorkerQueue   int                   // Длина очереди сообщений каждого обработчика
+	KafkaCreateTopics  bool                  // Создавать недостающие топики при запуске
+	KafkaPartitions    int                   // Число партиций создаваемых топиков
+	KafkaReplication   int                   // Фактор репликации создаваемых топиков
+	KafkaSecurity      kafkacluster.Security // Параметры TLS и SASL для подключения к Kafka
+	VerificationKeys   []signing.Key         // Ключи проверки подписи запросов на оплату; пустой список — подписи не проверяются (для kafka только с REQUEST_SIGNATURES=disabled)
+	SignatureMaxAge    time.Duration         // Максимальный возраст подписи запроса на оплату
+	ReconcileInterval  time.Duration         // Период плановой сверки балансов; 0 — сверка по расписанию отключена
+	ReconcileReportDir string                // Каталог для отчётов плановой сверки; пустое значение — вывод в лог
+	SnapshotInterval   time.Duration         // Период снимков балансов счетов; 0 — снимки не делаются
+	StatementDir       string                // Каталог для ежемесячных выписок по счетам; пустое значение — выписки по расписанию отключены
+	RiskRules          domain.RiskRules      // Правила проверки операций на мошенничество
+	PaymentProvider    string                // Платёжный провайдер для пополнений; пока поддерживается только fake
+	FakeProviderPages  bool                  // Монтировать страницу подтверждения оплаты fake-провайдера; только для локального запуска
+	ProviderSecret     string                // Секрет для проверки подписи уведомлений провайдера
+	PublicURL          string                // Внешний адрес сервиса для уведомлений провайдера и страниц подтверждения оплаты
 }
 
 // mustGetEnv получает значение обязательной переменной окружения или возвращает ошибку если она пустая
@@ -25,6 +54,163 @@ func mustGetEnv(key string) (string, error) {
 	return value, nil
 }
 
+// getDurationEnv получает необязательную длительность из переменной окружения.
+// Возвращает 0, если переменная не задана, или ошибку, если значение некорректно.
+func getDurationEnv(key string) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return 0, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative duration", key)
+	}
+	return duration, nil
+}
+
+// getFloatEnv получает необязательное неотрицательное число из переменной окружения.
+// Возвращает fallback, если переменная не задана, или ошибку, если значение некорректно.
+func getFloatEnv(key string, fallback float64) (float64, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || number < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative number", key)
+	}
+	return number, nil
+}
+
+// getIntEnv получает необязательное неотрицательное целое число из переменной окружения.
+// Возвращает fallback, если переменная не задана, или ошибку, если значение некорректно.
+func getIntEnv(key string, fallback int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer", key)
+	}
+	return number, nil
+}
+
+// getBoolEnv получает необязательное логическое значение из переменной окружения.
+// Возвращает false, если переменная не задана, или ошибку, если значение некорректно.
+func getBoolEnv(key string) (bool, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return false, nil
+	}
+	flag, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%s must be a boolean", key)
+	}
+	return flag, nil
+}
+
+// loadRiskRules загружает правила проверки на мошенничество.
+// Незаданные переменные окружения заменяются значениями domain.DefaultRiskRules.
+func loadRiskRules() (domain.RiskRules, []string) {
+	rules := domain.DefaultRiskRules()
+	errs := make([]string, 0)
+	var err error
+
+	rules.LargeAmount, err = getFloatEnv("RISK_LARGE_AMOUNT", rules.LargeAmount)
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+	rules.NewAccountAmount, err = getFloatEnv("RISK_NEW_ACCOUNT_AMOUNT", rules.NewAccountAmount)
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+	rules.MaxFailedAttempts, err = getIntEnv("RISK_MAX_FAILED_ATTEMPTS", rules.MaxFailedAttempts)
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+	if os.Getenv("RISK_NEW_ACCOUNT_AGE") != "" {
+		rules.NewAccountAge, err = getDurationEnv("RISK_NEW_ACCOUNT_AGE")
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if os.Getenv("RISK_FAILED_ATTEMPTS_WINDOW") != "" {
+		rules.FailedAttemptsWindow, err = getDurationEnv("RISK_FAILED_ATTEMPTS_WINDOW")
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	return rules, errs
+}
+
+// loadVerification загружает ключи проверки подписи запросов на оплату
+// и максимальный возраст подписи.
+func loadVerification() ([]signing.Key, time.Duration, []string) {
+	errs := make([]string, 0)
+	var verificationKeys []signing.Key
+	var err error
+	if value := os.Getenv("REQUEST_VERIFICATION_KEYS"); value != "" {
+		verificationKeys, err = signing.ParseKeys(value)
+		if err != nil {
+			errs = append(errs, "REQUEST_VERIFICATION_KEYS: "+err.Error())
+		}
+	}
+
+	signatureMaxAge := 5 * time.Minute
+	if os.Getenv("REQUEST_SIGNATURE_MAX_AGE") != "" {
+		signatureMaxAge, err = getDurationEnv("REQUEST_SIGNATURE_MAX_AGE")
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+		if signatureMaxAge == 0 {
+			errs = append(errs, "REQUEST_SIGNATURE_MAX_AGE must be positive")
+		}
+	}
+	return verificationKeys, signatureMaxAge, errs
+}
+
+// ReplaySigning содержит параметры повторной подписи переотправляемых запросов на оплату.
+type ReplaySigning struct {
+	SigningKey       signing.Key   // Ключ оператора; его ключ проверки должен быть в VerificationKeys
+	VerificationKeys []signing.Key // Ключи проверки подписи запросов на оплату
+	SignatureMaxAge  time.Duration // Максимальный возраст подписи запроса на оплату
+}
+
+// LoadReplaySigning загружает только параметры повторной подписи:
+// REPLAY_SIGNING_KEY, REQUEST_VERIFICATION_KEYS и REQUEST_SIGNATURE_MAX_AGE.
+// Используется консольными командами переотправки запросов.
+func LoadReplaySigning() (ReplaySigning, error) {
+	errs := make([]string, 0)
+	var signingKey signing.Key
+	value, err := mustGetEnv("REPLAY_SIGNING_KEY")
+	if err == nil {
+		signingKey, err = signing.ParseKey(value)
+		if err != nil {
+			err = fmt.Errorf("REPLAY_SIGNING_KEY: %w", err)
+		}
+	}
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+	verificationKeys, signatureMaxAge, verificationErrs := loadVerification()
+	errs = append(errs, verificationErrs...)
+	if len(verificationKeys) == 0 && len(verificationErrs) == 0 {
+		errs = append(errs, "REQUEST_VERIFICATION_KEYS is required")
+	}
+
+	if len(errs) > 0 {
+		return ReplaySigning{}, fmt.Errorf("config validation failed:\n  %s", strings.Join(errs, "\n  "))
+	}
+	return ReplaySigning{SigningKey: signingKey, VerificationKeys: verificationKeys, SignatureMaxAge: signatureMaxAge}, nil
+}
+
+// LoadDatabaseURL загружает только URL базы данных.
+// Используется консольными командами, которым не нужны HTTP и Kafka.
+func LoadDatabaseURL() (string, error) {
+	return mustGetEnv("DATABASE_URL")
+}
+
 // LoadConfig загружает конфигурацию из переменных окружения и возвращает Config
 // Возвращает ошибку если какие-то обязательные переменные не установлены
 func LoadConfig() (*Config, error) {
@@ -40,9 +226,19 @@ func LoadConfig() (*Config, error) {
 		errs = append(errs, err.Error())
 	}
 
-	brokers, err := mustGetEnv("KAFKA_URL")
-	if err != nil {
-		errs = append(errs, err.Error())
+	messageBus := os.Getenv("MESSAGE_BUS")
+	if messageBus == "" {
+		messageBus = "kafka"
+	}
+	if messageBus != "kafka" && messageBus != "memory" {
+		errs = append(errs, "MESSAGE_BUS must be kafka or memory")
+	}
+
+	// Брокер Kafka и группа потребителей нужны только для транспорта kafka;
+	// имена топиков используются и брокером в памяти.
+	brokers := os.Getenv("KAFKA_URL")
+	if brokers == "" && messageBus == "kafka" {
+		errs = append(errs, "KAFKA_URL is required")
 	}
 
 	consumerTopic, err := mustGetEnv("KAFKA_REQUEST_TOPIC")
@@ -55,20 +251,178 @@ func LoadConfig() (*Config, error) {
 		errs = append(errs, err.Error())
 	}
 
-	groupID, err := mustGetEnv("KAFKA_GROUP_ID")
+	groupID := os.Getenv("KAFKA_GROUP_ID")
+	if groupID == "" && messageBus == "kafka" {
+		errs = append(errs, "KAFKA_GROUP_ID is required")
+	}
+
+	eventsTopic := os.Getenv("KAFKA_EVENTS_TOPIC")
+	if eventsTopic == "" {
+		eventsTopic = "account-events"
+	}
+
+	deadLetterTopic := os.Getenv("KAFKA_DEAD_LETTER_TOPIC")
+	if deadLetterTopic == "" {
+		deadLetterTopic = consumerTopic + "-dlq"
+	}
+
+	maxAttempts, err := getIntEnv("KAFKA_MAX_ATTEMPTS", 3)
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+	if maxAttempts == 0 {
+		errs = append(errs, "KAFKA_MAX_ATTEMPTS must be positive")
+	}
+
+	retryBackoff := 200 * time.Millisecond
+	if os.Getenv("KAFKA_RETRY_BACKOFF") != "" {
+		retryBackoff, err = getDurationEnv("KAFKA_RETRY_BACKOFF")
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	maxBackoff := 5 * time.Second
+	if os.Getenv("KAFKA_RETRY_MAX_BACKOFF") != "" {
+		maxBackoff, err = getDurationEnv("KAFKA_RETRY_MAX_BACKOFF")
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	workers, err := getIntEnv("KAFKA_WORKERS", 8)
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+	if workers == 0 {
+		errs = append(errs, "KAFKA_WORKERS must be positive")
+	}
+
+	workerQueue, err := getIntEnv("KAFKA_WORKER_QUEUE", 16)
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	createTopics, err := getBoolEnv("KAFKA_CREATE_TOPICS")
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	partitions, err := getIntEnv("KAFKA_TOPIC_PARTITIONS", 1)
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+	if partitions == 0 {
+		errs = append(errs, "KAFKA_TOPIC_PARTITIONS must be positive")
+	}
+
+	replication, err := getIntEnv("KAFKA_TOPIC_REPLICATION", 1)
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+	if replication == 0 {
+		errs = append(errs, "KAFKA_TOPIC_REPLICATION must be positive")
+	}
+
+	reconcileInterval, err := getDurationEnv("RECONCILE_INTERVAL")
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	snapshotInterval, err := getDurationEnv("BALANCE_SNAPSHOT_INTERVAL")
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	paymentProvider := os.Getenv("PAYMENT_PROVIDER")
+	if paymentProvider == "" {
+		paymentProvider = "fake"
+	}
+	if paymentProvider != "fake" {
+		errs = append(errs, fmt.Sprintf("unsupported PAYMENT_PROVIDER %q", paymentProvider))
+	}
+
+	fakeProviderPages, err := getBoolEnv("FAKE_PROVIDER_PAGES")
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+	if fakeProviderPages && paymentProvider != "fake" {
+		errs = append(errs, "FAKE_PROVIDER_PAGES requires PAYMENT_PROVIDER=fake")
+	}
+
+	providerSecret, err := mustGetEnv("PAYMENT_PROVIDER_SECRET")
 	if err != nil {
 		errs = append(errs, err.Error())
 	}
 
+	publicURL := os.Getenv("PUBLIC_URL")
+	if publicURL == "" {
+		publicURL = "http://localhost:" + httpPort
+	}
+
+	riskRules, riskErrs := loadRiskRules()
+	errs = append(errs, riskErrs...)
+
+	kafkaSecurity, securityErrs := kafkacluster.LoadSecurity()
+	errs = append(errs, securityErrs...)
+
+	verificationKeys, signatureMaxAge, verificationErrs := loadVerification()
+	errs = append(errs, verificationErrs...)
+
+	// Без ключей проверки подписи запросов не проверяются; для транспорта kafka
+	// это нужно разрешить явно через REQUEST_SIGNATURES=disabled.
+	signatures := os.Getenv("REQUEST_SIGNATURES")
+	if signatures == "" && messageBus == "kafka" {
+		signatures = "required"
+	}
+	switch signatures {
+	case "required":
+		if os.Getenv("REQUEST_VERIFICATION_KEYS") == "" {
+			errs = append(errs, "REQUEST_VERIFICATION_KEYS is required unless REQUEST_SIGNATURES=disabled")
+		}
+	case "disabled":
+		if os.Getenv("REQUEST_VERIFICATION_KEYS") != "" {
+			errs = append(errs, "REQUEST_VERIFICATION_KEYS must be empty when REQUEST_SIGNATURES=disabled")
+		}
+	case "":
+		// Для транспорта memory подписи необязательны.
+	default:
+		errs = append(errs, "REQUEST_SIGNATURES must be required or disabled")
+	}
+
 	if len(errs) > 0 {
 		return nil, fmt.Errorf("config validation failed:\n  %s", strings.Join(errs, "\n  "))
 	}
 	return &Config{
 		HttpPort:           httpPort,
+		GrpcPort:           os.Getenv("GRPC_PORT"),
 		DatabaseURL:        db,
+		MessageBus:         messageBus,
 		KafkaBrokers:       strings.Split(brokers, ";"),
 		KafkaConsumerTopic: consumerTopic,
 		KafkaProducerTopic: producerTopic,
 		KafkaGroupID:       groupID,
+		KafkaEventsTopic:   eventsTopic,
+		KafkaDeadLetters:   deadLetterTopic,
+		KafkaMaxAttempts:   maxAttempts,
+		KafkaRetryBackoff:  retryBackoff,
+		KafkaMaxBackoff:    maxBackoff,
+		KafkaWorkers:       workers,
+		KafkaWorkerQueue:   workerQueue,
+		KafkaCreateTopics:  createTopics,
+		KafkaPartitions:    partitions,
+		KafkaReplication:   replication,
+		KafkaSecurity:      kafkaSecurity,
+		VerificationKeys:   verificationKeys,
+		SignatureMaxAge:    signatureMaxAge,
+		ReconcileInterval:  reconcileInterval,
+		ReconcileReportDir: os.Getenv("RECONCILE_REPORT_DIR"),
+		SnapshotInterval:   snapshotInterval,
+		StatementDir:       os.Getenv("STATEMENT_DIR"),
+		RiskRules:          riskRules,
+		PaymentProvider:    paymentProvider,
+		FakeProviderPages:  fakeProviderPages,
+		ProviderSecret:     providerSecret,
+		PublicURL:          publicURL,
 	}, nil
 }
@@ -1,10 +1,14 @@
 package config
 
 import (
+	"encoding/base64"
+	"kafkacluster"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestLoadConfig_Success(t *testing.T) {
@@ -14,6 +18,8 @@ func TestLoadConfig_Success(t *testing.T) {
 	_ = os.Setenv("KAFKA_REQUEST_TOPIC", "requests")
 	_ = os.Setenv("KAFKA_RESPONSE_TOPIC", "responses")
 	_ = os.Setenv("KAFKA_GROUP_ID", "app-group")
+	_ = os.Setenv("PAYMENT_PROVIDER_SECRET", "secret")
+	_ = os.Setenv("REQUEST_SIGNATURES", "disabled")
 
 	defer func() {
 		_ = os.Unsetenv("HTTP_PORT")
@@ -22,6 +28,8 @@ func TestLoadConfig_Success(t *testing.T) {
 		_ = os.Unsetenv("KAFKA_REQUEST_TOPIC")
 		_ = os.Unsetenv("KAFKA_RESPONSE_TOPIC")
 		_ = os.Unsetenv("KAFKA_GROUP_ID")
+		_ = os.Unsetenv("PAYMENT_PROVIDER_SECRET")
+		_ = os.Unsetenv("REQUEST_SIGNATURES")
 	}()
 
 	config, err := LoadConfig()
@@ -53,6 +61,23 @@ func TestLoadConfig_Success(t *testing.T) {
 	if config.KafkaGroupID != "app-group" {
 		t.Errorf("Expected group ID 'app-group', got %s", config.KafkaGroupID)
 	}
+
+	if config.KafkaDeadLetters != "requests-dlq" || config.KafkaMaxAttempts != 3 {
+		t.Errorf("Unexpected dead letter defaults: %s, %d attempts", config.KafkaDeadLetters, config.KafkaMaxAttempts)
+	}
+
+	if config.KafkaWorkers != 8 || config.KafkaWorkerQueue != 16 {
+		t.Errorf("Unexpected worker pool defaults: %d workers, queue %d", config.KafkaWorkers, config.KafkaWorkerQueue)
+	}
+
+	if config.GrpcPort != "" {
+		t.Errorf("Expected gRPC API to be disabled by default, got port %s", config.GrpcPort)
+	}
+
+	if config.KafkaCreateTopics || config.KafkaPartitions != 1 || config.KafkaReplication != 1 {
+		t.Errorf("Unexpected topic defaults: create %v, %d partitions, replication %d",
+			config.KafkaCreateTopics, config.KafkaPartitions, config.KafkaReplication)
+	}
 }
 
 func TestLoadConfig_MissingRequired(t *testing.T) {
@@ -84,7 +109,7 @@ func TestLoadConfig_AllMissing(t *testing.T) {
 	}
 
 	errorMsg := err.Error()
-	requiredVars := []string{"HTTP_PORT", "DATABASE_URL", "KAFKA_URL", "KAFKA_REQUEST_TOPIC", "KAFKA_RESPONSE_TOPIC", "KAFKA_GROUP_ID"}
+	requiredVars := []string{"HTTP_PORT", "DATABASE_URL", "KAFKA_URL", "KAFKA_REQUEST_TOPIC", "KAFKA_RESPONSE_TOPIC", "KAFKA_GROUP_ID", "PAYMENT_PROVIDER_SECRET"}
 
 	for _, varName := range requiredVars {
 		if !strings.Contains(errorMsg, varName) {
@@ -100,6 +125,8 @@ func TestLoadConfig_KafkaBrokersParsing(t *testing.T) {
 	_ = os.Setenv("KAFKA_REQUEST_TOPIC", "requests")
 	_ = os.Setenv("KAFKA_RESPONSE_TOPIC", "responses")
 	_ = os.Setenv("KAFKA_GROUP_ID", "group")
+	_ = os.Setenv("PAYMENT_PROVIDER_SECRET", "secret")
+	_ = os.Setenv("REQUEST_SIGNATURES", "disabled")
 
 	defer func() {
 		_ = os.Unsetenv("HTTP_PORT")
@@ -108,6 +135,8 @@ func TestLoadConfig_KafkaBrokersParsing(t *testing.T) {
 		_ = os.Unsetenv("KAFKA_REQUEST_TOPIC")
 		_ = os.Unsetenv("KAFKA_RESPONSE_TOPIC")
 		_ = os.Unsetenv("KAFKA_GROUP_ID")
+		_ = os.Unsetenv("PAYMENT_PROVIDER_SECRET")
+		_ = os.Unsetenv("REQUEST_SIGNATURES")
 	}()
 
 	config, err := LoadConfig()
@@ -140,3 +169,213 @@ func TestMustGetEnv(t *testing.T) {
 		t.Errorf("Unexpected error message: %v", err)
 	}
 }
+
+func TestLoadConfig_MemoryMessageBus(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("HTTP_PORT", "8080")
+	_ = os.Setenv("DATABASE_URL", "postgres://localhost:5432/db")
+	_ = os.Setenv("MESSAGE_BUS", "memory")
+	_ = os.Setenv("KAFKA_REQUEST_TOPIC", "requests")
+	_ = os.Setenv("KAFKA_RESPONSE_TOPIC", "responses")
+	_ = os.Setenv("PAYMENT_PROVIDER_SECRET", "secret")
+	defer os.Clearenv()
+
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Expected no error without Kafka broker for memory bus, got %v", err)
+	}
+	if config.MessageBus != "memory" {
+		t.Errorf("Expected memory message bus, got %s", config.MessageBus)
+	}
+
+	_ = os.Setenv("MESSAGE_BUS", "rabbitmq")
+	_, err = LoadConfig()
+	if err == nil || !strings.Contains(err.Error(), "MESSAGE_BUS") {
+		t.Errorf("Expected error for unsupported message bus, got %v", err)
+	}
+}
+
+func TestLoadConfig_TopicProvisioning(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("HTTP_PORT", "8080")
+	_ = os.Setenv("DATABASE_URL", "postgres://localhost:5432/db")
+	_ = os.Setenv("KAFKA_URL", "kafka:9092")
+	_ = os.Setenv("KAFKA_REQUEST_TOPIC", "requests")
+	_ = os.Setenv("KAFKA_RESPONSE_TOPIC", "responses")
+	_ = os.Setenv("KAFKA_GROUP_ID", "app-group")
+	_ = os.Setenv("PAYMENT_PROVIDER_SECRET", "secret")
+	_ = os.Setenv("REQUEST_SIGNATURES", "disabled")
+	_ = os.Setenv("KAFKA_CREATE_TOPICS", "true")
+	_ = os.Setenv("KAFKA_TOPIC_PARTITIONS", "3")
+	_ = os.Setenv("KAFKA_TOPIC_REPLICATION", "2")
+	defer os.Clearenv()
+
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !config.KafkaCreateTopics || config.KafkaPartitions != 3 || config.KafkaReplication != 2 {
+		t.Errorf("Unexpected topic settings: create %v, %d partitions, replication %d",
+			config.KafkaCreateTopics, config.KafkaPartitions, config.KafkaReplication)
+	}
+
+	_ = os.Setenv("KAFKA_CREATE_TOPICS", "sometimes")
+	_ = os.Setenv("KAFKA_TOPIC_PARTITIONS", "0")
+	_, err = LoadConfig()
+	if err == nil || !strings.Contains(err.Error(), "KAFKA_CREATE_TOPICS") || !strings.Contains(err.Error(), "KAFKA_TOPIC_PARTITIONS") {
+		t.Errorf("Expected errors for invalid topic settings, got %v", err)
+	}
+}
+
+func TestLoadConfig_FakeProviderPages(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("HTTP_PORT", "8080")
+	_ = os.Setenv("DATABASE_URL", "postgres://localhost:5432/db")
+	_ = os.Setenv("KAFKA_URL", "kafka:9092")
+	_ = os.Setenv("KAFKA_REQUEST_TOPIC", "requests")
+	_ = os.Setenv("KAFKA_RESPONSE_TOPIC", "responses")
+	_ = os.Setenv("KAFKA_GROUP_ID", "app-group")
+	_ = os.Setenv("PAYMENT_PROVIDER_SECRET", "secret")
+	_ = os.Setenv("REQUEST_SIGNATURES", "disabled")
+	defer os.Clearenv()
+
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if config.FakeProviderPages {
+		t.Errorf("Expected fake provider pages to be disabled by default")
+	}
+
+	_ = os.Setenv("FAKE_PROVIDER_PAGES", "true")
+	config, err = LoadConfig()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !config.FakeProviderPages {
+		t.Errorf("Expected fake provider pages to be enabled")
+	}
+
+	_ = os.Setenv("PAYMENT_PROVIDER", "stripe")
+	_, err = LoadConfig()
+	if err == nil || !strings.Contains(err.Error(), "FAKE_PROVIDER_PAGES requires PAYMENT_PROVIDER=fake") {
+		t.Errorf("Expected error for fake provider pages with another provider, got %v", err)
+	}
+}
+
+func TestLoadConfig_KafkaSecurity(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("HTTP_PORT", "8080")
+	_ = os.Setenv("DATABASE_URL", "postgres://localhost:5432/db")
+	_ = os.Setenv("KAFKA_URL", "kafka:9093")
+	_ = os.Setenv("KAFKA_REQUEST_TOPIC", "requests")
+	_ = os.Setenv("KAFKA_RESPONSE_TOPIC", "responses")
+	_ = os.Setenv("KAFKA_GROUP_ID", "app-group")
+	_ = os.Setenv("PAYMENT_PROVIDER_SECRET", "secret")
+	_ = os.Setenv("REQUEST_SIGNATURES", "disabled")
+	defer os.Clearenv()
+
+	// Разбор и проверка параметров подробно проверяются в contracts/kafkacluster.
+	_ = os.Setenv("KAFKA_SASL_MECHANISM", "plain")
+	_ = os.Setenv("KAFKA_SASL_USERNAME", "payment")
+	_ = os.Setenv("KAFKA_SASL_PASSWORD", "password")
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := kafkacluster.Security{SASLMechanism: kafkacluster.SASLPlain, SASLUsername: "payment", SASLPassword: "password"}
+	if config.KafkaSecurity != expected {
+		t.Errorf("Expected security %+v, got %+v", expected, config.KafkaSecurity)
+	}
+
+	_ = os.Setenv("KAFKA_TLS_CA_FILE", filepath.Join(t.TempDir(), "ca.pem"))
+	_ = os.Setenv("KAFKA_SASL_MECHANISM", "GSSAPI")
+	_, err = LoadConfig()
+	if err == nil {
+		t.Fatal("Expected error for inconsistent Kafka security settings, got nil")
+	}
+	for _, message := range []string{"KAFKA_TLS_CA_FILE requires KAFKA_TLS=true", "KAFKA_SASL_MECHANISM must be"} {
+		if !strings.Contains(err.Error(), message) {
+			t.Errorf("Error should mention %q, got: %v", message, err)
+		}
+	}
+}
+func TestLoadConfig_VerificationKeys(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("HTTP_PORT", "8080")
+	_ = os.Setenv("DATABASE_URL", "postgres://localhost:5432/db")
+	_ = os.Setenv("KAFKA_URL", "kafka:9092")
+	_ = os.Setenv("KAFKA_REQUEST_TOPIC", "requests")
+	_ = os.Setenv("KAFKA_RESPONSE_TOPIC", "responses")
+	_ = os.Setenv("KAFKA_GROUP_ID", "app-group")
+	_ = os.Setenv("PAYMENT_PROVIDER_SECRET", "secret")
+	defer os.Clearenv()
+
+	// Для транспорта kafka ключи обязательны, если подписи не отключены явно.
+	_, err := LoadConfig()
+	if err == nil || !strings.Contains(err.Error(), "REQUEST_VERIFICATION_KEYS is required unless REQUEST_SIGNATURES=disabled") {
+		t.Errorf("Expected error for missing verification keys, got %v", err)
+	}
+
+	_ = os.Setenv("REQUEST_SIGNATURES", "disabled")
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(config.VerificationKeys) != 0 || config.SignatureMaxAge != 5*time.Minute {
+		t.Errorf("Unexpected signature defaults: %d keys, max age %s", len(config.VerificationKeys), config.SignatureMaxAge)
+	}
+
+	secret := base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+	_ = os.Setenv("REQUEST_VERIFICATION_KEYS", "2025-10:hmac-sha256:"+secret)
+	_, err = LoadConfig()
+	if err == nil || !strings.Contains(err.Error(), "REQUEST_VERIFICATION_KEYS must be empty when REQUEST_SIGNATURES=disabled") {
+		t.Errorf("Expected error for verification keys with disabled signatures, got %v", err)
+	}
+
+	_ = os.Setenv("REQUEST_SIGNATURES", "optional")
+	_, err = LoadConfig()
+	if err == nil || !strings.Contains(err.Error(), "REQUEST_SIGNATURES must be required or disabled") {
+		t.Errorf("Expected error for unsupported REQUEST_SIGNATURES, got %v", err)
+	}
+
+	_ = os.Unsetenv("REQUEST_SIGNATURES")
+
+	_ = os.Setenv("REQUEST_VERIFICATION_KEYS", "2025-10:hmac-sha256:"+secret+";2025-11:hmac-sha256:"+secret)
+	_ = os.Setenv("REQUEST_SIGNATURE_MAX_AGE", "1m")
+	config, err = LoadConfig()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(config.VerificationKeys) != 2 || config.VerificationKeys[1].Id != "2025-11" || config.SignatureMaxAge != time.Minute {
+		t.Errorf("Unexpected verification keys %+v, max age %s", config.VerificationKeys, config.SignatureMaxAge)
+	}
+
+	_ = os.Setenv("REQUEST_VERIFICATION_KEYS", "2025-10:hmac-sha256")
+	_ = os.Setenv("REQUEST_SIGNATURE_MAX_AGE", "0s")
+	_, err = LoadConfig()
+	if err == nil || !strings.Contains(err.Error(), "REQUEST_VERIFICATION_KEYS") || !strings.Contains(err.Error(), "REQUEST_SIGNATURE_MAX_AGE") {
+		t.Errorf("Expected errors for invalid signature settings, got %v", err)
+	}
+}
+
+func TestLoadReplaySigning(t *testing.T) {
+	os.Clearenv()
+	defer os.Clearenv()
+
+	_, err := LoadReplaySigning()
+	if err == nil || !strings.Contains(err.Error(), "REPLAY_SIGNING_KEY is required") || !strings.Contains(err.Error(), "REQUEST_VERIFICATION_KEYS is required") {
+		t.Errorf("Expected errors for missing replay signing settings, got %v", err)
+	}
+
+	secret := base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+	_ = os.Setenv("REPLAY_SIGNING_KEY", "operator:hmac-sha256:"+secret)
+	_ = os.Setenv("REQUEST_VERIFICATION_KEYS", "2025-10:hmac-sha256:"+secret+";operator:hmac-sha256:"+secret)
+	replaySigning, err := LoadReplaySigning()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if replaySigning.SigningKey.Id != "operator" || len(replaySigning.VerificationKeys) != 2 || replaySigning.SignatureMaxAge != 5*time.Minute {
+		t.Errorf("Unexpected replay signing settings %+v", replaySigning)
+	}
+}
@@ -1,38 +1,124 @@
 package domain
 
 import (
+	"errors"
 	"fmt"
 	"time"
 )
 
-// Account представляет счёт пользователя.
-// Хранит информацию о текущем балансе и дате создания.
+const (
+	// DefaultWalletName — название кошелька, создаваемого по умолчанию.
+	DefaultWalletName = "main"
+	// DefaultCurrency — валюта кошелька по умолчанию.
+	DefaultCurrency = "RUB"
+	// DefaultGracePeriodDays — беспроцентный льготный период по умолчанию (в днях),
+	// в течение которого счёт может находиться в минусе.
+	DefaultGracePeriodDays = 30
+)
+
+// Account представляет счёт (кошелёк) пользователя.
+// У пользователя может быть несколько именованных кошельков, один из которых — основной.
+// Хранит информацию о текущем балансе, кредитном лимите и дате создания.
 type Account struct {
-	Id           int       `json:"id"`            // Уникальный идентификатор счёта
-	UserId       int       `json:"user_id"`       // Идентификатор пользователя, которому принадлежит счёт
-	Balance      float64   `json:"balance"`       // Текущий баланс счёта
-	CreationDate time.Time `json:"creation_date"` // Дата создания счёта
+	Id              int           `json:"id"`                // Уникальный идентификатор счёта
+	UserId          int           `json:"user_id"`           // Идентификатор пользователя, которому принадлежит счёт
+	Name            string        `json:"name"`              // Название кошелька, уникальное в пределах пользователя
+	Currency        string        `json:"currency"`          // Валюта кошелька (код ISO 4217)
+	IsDefault       bool          `json:"is_default"`        // Признак основного кошелька пользователя
+	Status          AccountStatus `json:"status"`            // Статус счёта в жизненном цикле
+	Balance         float64       `json:"balance"`           // Текущий баланс счёта (может быть отрицательным в пределах кредитного лимита)
+	CreditLimit     float64       `json:"credit_limit"`      // Кредитный лимит — насколько баланс может уйти в минус
+	GracePeriodDays int           `json:"grace_period_days"` // Длительность беспроцентного льготного периода в днях
+	OverdraftSince  *time.Time    `json:"overdraft_since"`   // Момент ухода баланса в минус (nil, если баланс неотрицательный)
+	CreationDate    time.Time     `json:"creation_date"`     // Дата создания счёта
 }
 
 // Deposit увеличивает баланс счёта на указанную сумму.
-// Возвращает ошибку, если сумма отрицательная.
+// Возвращает ошибку, если сумма отрицательная или статус счёта запрещает пополнения.
 func (a *Account) Deposit(amount float64) error {
 	if amount < 0 {
-		return fmt.Errorf("amount must be not negative")
+		return ErrInvalidAmount
+	}
+	if !a.CanDeposit() {
+		return fmt.Errorf("deposits are not allowed for %s account: %w", a.Status, ErrOperationNotAllowed)
 	}
 	a.Balance += amount
+	a.trackOverdraft(time.Now())
 	return nil
 }
 
 // Withdraw уменьшает баланс счёта на указанную сумму.
-// Возвращает ошибку, если сумма отрицательная или средств недостаточно.
+// Баланс может уйти в минус, но не ниже кредитного лимита.
+// Возвращает ошибку, если сумма отрицательная, средств недостаточно
+// или статус счёта запрещает списания.
 func (a *Account) Withdraw(amount float64) error {
 	if amount < 0 {
-		return fmt.Errorf("amount must be not negative")
+		return ErrInvalidAmount
 	}
-	if a.Balance-amount < 0 {
-		return fmt.Errorf("not enough balance for withdraw")
+	if !a.CanWithdraw() {
+		return fmt.Errorf("withdrawals are not allowed for %s account: %w", a.Status, ErrOperationNotAllowed)
+	}
+	if amount > a.AvailableFunds() {
+		return ErrInsufficientFunds
 	}
 	a.Balance -= amount
+	a.trackOverdraft(time.Now())
+	return nil
+}
+
+// ErrInvalidCreditLimit означает отрицательный кредитный лимит или льготный период.
+var ErrInvalidCreditLimit = errors.New("invalid credit limit")
+
+// SetCreditLimit устанавливает кредитный лимит и длительность льготного периода.
+// Возвращает ошибку ErrInvalidCreditLimit, если лимит или льготный период отрицательные.
+// Уменьшение лимита ниже текущего долга допустимо — в этом случае
+// новые списания будут запрещены до погашения задолженности.
+func (a *Account) SetCreditLimit(limit float64, gracePeriodDays int) error {
+	if limit < 0 {
+		return fmt.Errorf("%w: credit limit must be not negative", ErrInvalidCreditLimit)
+	}
+	if gracePeriodDays < 0 {
+		return fmt.Errorf("%w: grace period must be not negative", ErrInvalidCreditLimit)
+	}
+	a.CreditLimit = limit
+	a.GracePeriodDays = gracePeriodDays
 	return nil
 }
+
+// AvailableFunds возвращает сумму, доступную для списания с учётом кредитного лимита.
+func (a *Account) AvailableFunds() float64 {
+	return a.Balance + a.CreditLimit
+}
+
+// InOverdraft сообщает, находится ли баланс счёта в минусе.
+func (a *Account) InOverdraft() bool {
+	return a.Balance < 0
+}
+
+// GracePeriodEnd возвращает момент окончания льготного периода
+// или nil, если счёт не в минусе.
+func (a *Account) GracePeriodEnd() *time.Time {
+	if a.OverdraftSince == nil {
+		return nil
+	}
+	end := a.OverdraftSince.AddDate(0, 0, a.GracePeriodDays)
+	return &end
+}
+
+// IsGracePeriodExpired сообщает, истёк ли льготный период на момент now.
+func (a *Account) IsGracePeriodExpired(now time.Time) bool {
+	end := a.GracePeriodEnd()
+	return end != nil && now.After(*end)
+}
+
+// trackOverdraft фиксирует момент ухода баланса в минус
+// и сбрасывает его, когда задолженность погашена.
+func (a *Account) trackOverdraft(now time.Time) {
+	if !a.InOverdraft() {
+		a.OverdraftSince = nil
+		return
+	}
+	if a.OverdraftSince == nil {
+		a.OverdraftSince = &now
+	}
+}
@@ -0,0 +1,84 @@
+package domain
+
+import "time"
+
+// AccountEventType описывает тип доменного события счёта.
+type AccountEventType string
+
+const (
+	AccountEventCreated         AccountEventType = "AccountCreated"       // Открыт новый кошелёк
+	AccountEventCredited        AccountEventType = "AccountCredited"      // Баланс увеличен проведённой транзакцией
+	AccountEventDebited         AccountEventType = "AccountDebited"       // Баланс уменьшен проведённой транзакцией
+	AccountEventStatusChanged   AccountEventType = "AccountStatusChanged" // Изменён статус счёта
+	AccountEventPaymentReviewed AccountEventType = "PaymentReviewed"      // Принято решение по операции, задержанной до ручной проверки
+)
+
+// AccountEvent — доменное событие счёта, публикуемое для других сервисов после фиксации изменений.
+// События одного счёта публикуются в порядке Id.
+type AccountEvent struct {
+	Id                int64             `json:"id"`                           // Уникальный возрастающий идентификатор события
+	Type              AccountEventType  `json:"type"`                         // Тип события
+	AccountId         int               `json:"account_id"`                   // Идентификатор счёта
+	UserId            int               `json:"user_id"`                      // Идентификатор владельца счёта
+	Currency          string            `json:"currency"`                     // Валюта счёта
+	Balance           float64           `json:"balance"`                      // Баланс счёта после события
+	Amount            float64           `json:"amount,omitempty"`             // Сумма транзакции (для AccountCredited, AccountDebited и PaymentReviewed)
+	TransactionId     *int              `json:"transaction_id,omitempty"`     // Транзакция, изменившая баланс или задержанная до ручной проверки
+	Status            AccountStatus     `json:"status"`                       // Статус счёта после события
+	PreviousStatus    AccountStatus     `json:"previous_status,omitempty"`    // Статус до изменения (для AccountStatusChanged)
+	TransactionStatus TransactionStatus `json:"transaction_status,omitempty"` // Итоговый статус транзакции (для PaymentReviewed)
+	FailureCode       ReasonCode        `json:"failure_code,omitempty"`       // Код причины отказа (для отклонённой операции в PaymentReviewed)
+	OccurredAt        time.Time         `json:"occurred_at"`                  // Время события
+}
+
+// NewAccountCreatedEvent возвращает событие открытия кошелька account.
+func NewAccountCreatedEvent(account Account, now time.Time) AccountEvent {
+	return newAccountEvent(AccountEventCreated, account, now)
+}
+
+// NewBalanceChangedEvent возвращает событие изменения баланса account проведённой транзакцией:
+// AccountCredited для пополнения и AccountDebited для списания.
+func NewBalanceChangedEvent(account Account, transaction Transaction, now time.Time) AccountEvent {
+	eventType := AccountEventDebited
+	if transaction.IsDeposit {
+		eventType = AccountEventCredited
+	}
+	event := newAccountEvent(eventType, account, now)
+	event.Amount = transaction.Amount
+	transactionId := transaction.Id
+	event.TransactionId = &transactionId
+	return event
+}
+
+// NewStatusChangedEvent возвращает событие изменения статуса account по записи журнала change.
+func NewStatusChangedEvent(account Account, change AccountStatusChange) AccountEvent {
+	event := newAccountEvent(AccountEventStatusChanged, account, change.ChangedAt)
+	event.PreviousStatus = change.FromStatus
+	return event
+}
+
+// NewPaymentReviewedEvent возвращает событие решения по операции transaction,
+// задержанной до ручной проверки: итоговый статус транзакции и код причины отказа.
+// По нему order-service завершает оплату заказа.
+func NewPaymentReviewedEvent(account Account, transaction Transaction, now time.Time) AccountEvent {
+	event := newAccountEvent(AccountEventPaymentReviewed, account, now)
+	event.Amount = transaction.Amount
+	transactionId := transaction.Id
+	event.TransactionId = &transactionId
+	event.TransactionStatus = transaction.Status
+	event.FailureCode = transaction.FailureCode
+	return event
+}
+
+// newAccountEvent заполняет общие поля события по текущему состоянию счёта.
+func newAccountEvent(eventType AccountEventType, account Account, now time.Time) AccountEvent {
+	return AccountEvent{
+		Type:       eventType,
+		AccountId:  account.Id,
+		UserId:     account.UserId,
+		Currency:   account.Currency,
+		Balance:    account.Balance,
+		Status:     account.Status,
+		OccurredAt: now,
+	}
+}
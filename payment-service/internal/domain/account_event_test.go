@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"contracts"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestNewBalanceChangedEvent(t *testing.T) {
+	now := time.Now()
+	account := Account{Id: 1, UserId: 10, Currency: "RUB", Balance: 150, Status: AccountStatusActive}
+
+	event := NewBalanceChangedEvent(account, Transaction{Id: 7, IsDeposit: true, Amount: 50}, now)
+	if event.Type != AccountEventCredited || event.Amount != 50 || event.Balance != 150 {
+		t.Errorf("Unexpected event: %+v", event)
+	}
+	if event.TransactionId == nil || *event.TransactionId != 7 || event.AccountId != 1 || event.UserId != 10 {
+		t.Errorf("Unexpected event: %+v", event)
+	}
+
+	event = NewBalanceChangedEvent(account, Transaction{Id: 8, Amount: 30}, now)
+	if event.Type != AccountEventDebited || event.Amount != 30 {
+		t.Errorf("Unexpected event: %+v", event)
+	}
+}
+
+func TestNewStatusChangedEvent(t *testing.T) {
+	changedAt := time.Now()
+	account := Account{Id: 1, UserId: 10, Currency: "RUB", Status: AccountStatusFrozen}
+	change := AccountStatusChange{AccountId: 1, FromStatus: AccountStatusActive, ToStatus: AccountStatusFrozen, ChangedAt: changedAt}
+
+	event := NewStatusChangedEvent(account, change)
+	if event.Type != AccountEventStatusChanged || event.Status != AccountStatusFrozen || event.PreviousStatus != AccountStatusActive {
+		t.Errorf("Unexpected event: %+v", event)
+	}
+	if !event.OccurredAt.Equal(changedAt) || event.TransactionId != nil {
+		t.Errorf("Unexpected event: %+v", event)
+	}
+}
+
+func TestNewPaymentReviewedEvent(t *testing.T) {
+	now := time.Now()
+	account := Account{Id: 1, UserId: 10, Currency: "RUB", Balance: 100, Status: AccountStatusActive}
+	transaction := Transaction{Id: 7, AccountId: 1, UserId: 10, Amount: 50}
+	transaction.Decline(ErrRejectedByReview)
+
+	event := NewPaymentReviewedEvent(account, transaction, now)
+	if event.Type != AccountEventPaymentReviewed || event.TransactionId == nil || *event.TransactionId != 7 || event.Amount != 50 {
+		t.Errorf("Unexpected event: %+v", event)
+	}
+	if event.TransactionStatus != TransactionStatusDeclined || event.FailureCode != ReasonRejectedByReview {
+		t.Errorf("Unexpected decision: %s %s", event.TransactionStatus, event.FailureCode)
+	}
+
+	// order-service читает событие по контракту contracts.PaymentReviewed.
+	value, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var reviewed contracts.PaymentReviewed
+	err = json.Unmarshal(value, &reviewed)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := contracts.PaymentReviewed{TransactionId: 7, TransactionStatus: contracts.TransactionDeclined, FailureCode: contracts.ErrorRejectedByReview}
+	if reviewed != expected {
+		t.Errorf("Expected %+v, got %+v", expected, reviewed)
+	}
+}
@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// AccountStatus описывает состояние жизненного цикла счёта.
+type AccountStatus string
+
+const (
+	AccountStatusActive    AccountStatus = "active"    // Счёт активен: разрешены пополнения и списания
+	AccountStatusFrozen    AccountStatus = "frozen"    // Счёт заморожен: списания запрещены, пополнения разрешены
+	AccountStatusSuspended AccountStatus = "suspended" // Счёт приостановлен: запрещены любые операции
+	AccountStatusClosed    AccountStatus = "closed"    // Счёт закрыт окончательно
+)
+
+// ParseAccountStatus проверяет строку и преобразует её в AccountStatus.
+// Возвращает ошибку, если статус неизвестен.
+func ParseAccountStatus(value string) (AccountStatus, error) {
+	status := AccountStatus(value)
+	switch status {
+	case AccountStatusActive, AccountStatusFrozen, AccountStatusSuspended, AccountStatusClosed:
+		return status, nil
+	}
+	return "", fmt.Errorf("unknown account status: %q", value)
+}
+
+// AccountStatusChange — запись журнала изменений статуса счёта.
+// Фиксирует, кто и по какой причине изменил статус.
+type AccountStatusChange struct {
+	Id         int           `json:"id"`          // Уникальный идентификатор записи
+	AccountId  int           `json:"account_id"`  // Идентификатор счёта
+	FromStatus AccountStatus `json:"from_status"` // Статус до изменения
+	ToStatus   AccountStatus `json:"to_status"`   // Статус после изменения
+	ChangedBy  string        `json:"changed_by"`  // Кто изменил статус
+	Reason     string        `json:"reason"`      // Причина изменения
+	ChangedAt  time.Time     `json:"changed_at"`  // Дата изменения
+}
+
+// CanDeposit сообщает, разрешены ли пополнения счёта в текущем статусе.
+func (a *Account) CanDeposit() bool {
+	return a.Status == AccountStatusActive || a.Status == AccountStatusFrozen
+}
+
+// CanWithdraw сообщает, разрешены ли списания со счёта в текущем статусе.
+func (a *Account) CanWithdraw() bool {
+	return a.Status == AccountStatusActive
+}
+
+// ChangeStatus переводит счёт в новый статус и возвращает запись для журнала изменений.
+// Закрытый счёт нельзя переоткрыть; закрыть можно только счёт с нулевым балансом.
+// Возвращает ошибку, если переход недопустим или не указаны автор и причина.
+func (a *Account) ChangeStatus(to AccountStatus, changedBy, reason string) (*AccountStatusChange, error) {
+	if changedBy == "" || reason == "" {
+		return nil, fmt.Errorf("changed_by and reason are required")
+	}
+	if a.Status == to {
+		return nil, fmt.Errorf("account is already %s", to)
+	}
+	if a.Status == AccountStatusClosed {
+		return nil, fmt.Errorf("account is closed")
+	}
+	if to == AccountStatusClosed && a.Balance != 0 {
+		return nil, fmt.Errorf("account balance must be zero to close it")
+	}
+	change := &AccountStatusChange{
+		AccountId:  a.Id,
+		FromStatus: a.Status,
+		ToStatus:   to,
+		ChangedBy:  changedBy,
+		Reason:     reason,
+		ChangedAt:  time.Now(),
+	}
+	a.Status = to
+	return change, nil
+}
+
+// PayOut обнуляет положительный баланс счёта перед закрытием и возвращает выплаченную сумму.
+// Выплата разрешена в любом статусе, кроме закрытого.
+// Возвращает ошибку, если на счёте есть задолженность.
+func (a *Account) PayOut() (float64, error) {
+	if a.Status == AccountStatusClosed {
+		return 0, fmt.Errorf("account is closed")
+	}
+	if a.Balance < 0 {
+		return 0, fmt.Errorf("account has outstanding debt")
+	}
+	amount := a.Balance
+	a.Balance = 0
+	a.trackOverdraft(time.Now())
+	return amount, nil
+}
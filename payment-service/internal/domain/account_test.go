@@ -2,10 +2,11 @@ package domain
 
 import (
 	"testing"
+	"time"
 )
 
 func TestAccountDeposit(t *testing.T) {
-	account := &Account{Balance: 100.0}
+	account := &Account{Balance: 100.0, Status: AccountStatusActive}
 
 	err := account.Deposit(50.0)
 	if err != nil {
@@ -22,7 +23,7 @@ func TestAccountDeposit(t *testing.T) {
 }
 
 func TestAccountWithdraw(t *testing.T) {
-	account := &Account{Balance: 100.0}
+	account := &Account{Balance: 100.0, Status: AccountStatusActive}
 
 	err := account.Withdraw(30.0)
 	if err != nil {
@@ -44,7 +45,7 @@ func TestAccountWithdraw(t *testing.T) {
 }
 
 func TestAccountEdgeCases(t *testing.T) {
-	account := &Account{Balance: 0.0}
+	account := &Account{Balance: 0.0, Status: AccountStatusActive}
 
 	err := account.Withdraw(10.0)
 	if err == nil {
@@ -56,3 +57,142 @@ func TestAccountEdgeCases(t *testing.T) {
 		t.Errorf("Deposit zero should not error: %v", err)
 	}
 }
+
+func TestAccountWithdrawWithCreditLimit(t *testing.T) {
+	account := &Account{Balance: 100.0, CreditLimit: 50.0, GracePeriodDays: 30, Status: AccountStatusActive}
+
+	err := account.Withdraw(130.0)
+	if err != nil {
+		t.Errorf("Withdraw within credit limit failed: %v", err)
+	}
+	if account.Balance != -30.0 {
+		t.Errorf("Expected -30.0, got %v", account.Balance)
+	}
+	if account.OverdraftSince == nil {
+		t.Error("Expected overdraft to be tracked")
+	}
+
+	err = account.Withdraw(30.0)
+	if err == nil {
+		t.Error("Expected error when exceeding credit limit")
+	}
+	if account.Balance != -30.0 {
+		t.Errorf("Balance changed after failed withdraw: %v", account.Balance)
+	}
+
+	err = account.Deposit(30.0)
+	if err != nil {
+		t.Errorf("Deposit failed: %v", err)
+	}
+	if account.OverdraftSince != nil {
+		t.Error("Expected overdraft to be cleared after repayment")
+	}
+}
+
+func TestAccountGracePeriod(t *testing.T) {
+	since := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	account := &Account{Balance: -10.0, CreditLimit: 100.0, GracePeriodDays: 10, OverdraftSince: &since}
+
+	end := account.GracePeriodEnd()
+	if end == nil || !end.Equal(since.AddDate(0, 0, 10)) {
+		t.Fatalf("Unexpected grace period end: %v", end)
+	}
+	if account.IsGracePeriodExpired(since.AddDate(0, 0, 5)) {
+		t.Error("Grace period should not be expired yet")
+	}
+	if !account.IsGracePeriodExpired(since.AddDate(0, 0, 11)) {
+		t.Error("Grace period should be expired")
+	}
+
+	account = &Account{Balance: 10.0}
+	if account.GracePeriodEnd() != nil || account.IsGracePeriodExpired(time.Now()) {
+		t.Error("Account without overdraft should not have grace period")
+	}
+}
+
+func TestAccountSetCreditLimit(t *testing.T) {
+	account := &Account{}
+
+	err := account.SetCreditLimit(500.0, 15)
+	if err != nil {
+		t.Errorf("SetCreditLimit failed: %v", err)
+	}
+	if account.CreditLimit != 500.0 || account.GracePeriodDays != 15 {
+		t.Errorf("Unexpected limits: %v, %v", account.CreditLimit, account.GracePeriodDays)
+	}
+
+	if account.SetCreditLimit(-1.0, 15) == nil {
+		t.Error("Expected error for negative credit limit")
+	}
+	if account.SetCreditLimit(100.0, -1) == nil {
+		t.Error("Expected error for negative grace period")
+	}
+}
+
+func TestAccountStatusRestrictions(t *testing.T) {
+	account := &Account{Balance: 100.0, Status: AccountStatusFrozen}
+	if err := account.Deposit(10.0); err != nil {
+		t.Errorf("Deposit to frozen account should be allowed: %v", err)
+	}
+	if err := account.Withdraw(10.0); err == nil {
+		t.Error("Expected error when withdrawing from frozen account")
+	}
+
+	account.Status = AccountStatusSuspended
+	if err := account.Deposit(10.0); err == nil {
+		t.Error("Expected error when depositing to suspended account")
+	}
+	if err := account.Withdraw(10.0); err == nil {
+		t.Error("Expected error when withdrawing from suspended account")
+	}
+	if account.Balance != 110.0 {
+		t.Errorf("Expected 110.0, got %v", account.Balance)
+	}
+}
+
+func TestAccountChangeStatus(t *testing.T) {
+	account := &Account{Id: 5, Balance: 50.0, Status: AccountStatusActive}
+
+	change, err := account.ChangeStatus(AccountStatusFrozen, "admin", "suspicious activity")
+	if err != nil {
+		t.Fatalf("ChangeStatus failed: %v", err)
+	}
+	if change.FromStatus != AccountStatusActive || change.ToStatus != AccountStatusFrozen || change.AccountId != 5 {
+		t.Errorf("Unexpected status change: %+v", change)
+	}
+
+	if _, err = account.ChangeStatus(AccountStatusActive, "", "no author"); err == nil {
+		t.Error("Expected error when author is missing")
+	}
+	if _, err = account.ChangeStatus(AccountStatusClosed, "admin", "close"); err == nil {
+		t.Error("Expected error when closing account with non-zero balance")
+	}
+
+	amount, err := account.PayOut()
+	if err != nil || amount != 50.0 || account.Balance != 0 {
+		t.Fatalf("PayOut failed: amount=%v balance=%v err=%v", amount, account.Balance, err)
+	}
+	if _, err = account.ChangeStatus(AccountStatusClosed, "admin", "close"); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if _, err = account.ChangeStatus(AccountStatusActive, "admin", "reopen"); err == nil {
+		t.Error("Expected error when reopening closed account")
+	}
+}
+
+func TestAccountPayOutWithDebt(t *testing.T) {
+	account := &Account{Balance: -20.0, CreditLimit: 100.0, Status: AccountStatusActive}
+	if _, err := account.PayOut(); err == nil {
+		t.Error("Expected error for payout with outstanding debt")
+	}
+}
+
+func TestParseAccountStatus(t *testing.T) {
+	status, err := ParseAccountStatus("frozen")
+	if err != nil || status != AccountStatusFrozen {
+		t.Errorf("Unexpected result: %v, %v", status, err)
+	}
+	if _, err = ParseAccountStatus("deleted"); err == nil {
+		t.Error("Expected error for unknown status")
+	}
+}
@@ -0,0 +1,39 @@
+package domain
+
+import "time"
+
+// BalanceSnapshot — зафиксированный баланс счёта на момент TakenAt.
+// Баланс на произвольный момент считается от ближайшего предшествующего снимка,
+// поэтому не требует пересчёта всей истории транзакций.
+type BalanceSnapshot struct {
+	AccountId int       `json:"account_id"` // Идентификатор счёта
+	Balance   float64   `json:"balance"`    // Баланс по проведённым транзакциям на момент TakenAt
+	TakenAt   time.Time `json:"taken_at"`   // Момент, на который зафиксирован баланс
+	CreatedAt time.Time `json:"created_at"` // Время создания снимка
+}
+
+// AccountBalance — баланс счёта на момент At, рассчитанный по истории транзакций.
+type AccountBalance struct {
+	AccountId int       `json:"account_id"` // Идентификатор счёта
+	UserId    int       `json:"user_id"`    // Идентификатор владельца счёта
+	Currency  string    `json:"currency"`   // Валюта счёта
+	Balance   float64   `json:"balance"`    // Баланс на момент At
+	At        time.Time `json:"at"`         // Момент, на который рассчитан баланс
+}
+
+// BalanceAt рассчитывает баланс счёта на момент at по ближайшему предшествующему снимку
+// и изменению баланса по проведённым транзакциям после него.
+// Если снимка нет, change должен покрывать всю историю счёта.
+func BalanceAt(account Account, snapshot *BalanceSnapshot, change float64, at time.Time) AccountBalance {
+	var base float64
+	if snapshot != nil {
+		base = snapshot.Balance
+	}
+	return AccountBalance{
+		AccountId: account.Id,
+		UserId:    account.UserId,
+		Currency:  account.Currency,
+		Balance:   roundCents(base + change),
+		At:        at,
+	}
+}
@@ -0,0 +1,81 @@
+package domain
+
+import "errors"
+
+// Ошибки бизнес-правил. Транзакция, отклонённая по одной из этих причин,
+// сохраняется со статусом declined, а её повторная обработка
+// возвращает исходный результат без повторного применения.
+var (
+	ErrInvalidAmount       = errors.New("amount must be not negative")
+	ErrInsufficientFunds   = errors.New("not enough balance for withdraw")
+	ErrLimitExceeded       = errors.New("spending limit exceeded")
+	ErrOperationNotAllowed = errors.New("operation is not allowed")
+	ErrAccountNotFound     = errors.New("account not found")
+	ErrForeignAccount      = errors.New("account does not belong to user")
+	ErrRejectedByReview    = errors.New("transaction rejected after manual review")
+)
+
+// ErrPendingReview означает, что операция задержана для ручной проверки
+// и будет проведена или отклонена по решению администратора.
+var ErrPendingReview = errors.New("transaction is pending manual review")
+
+// ReasonCode — машиночитаемый код причины отказа в проведении транзакции.
+type ReasonCode string
+
+const (
+	ReasonInvalidAmount       ReasonCode = "INVALID_AMOUNT"
+	ReasonInsufficientFunds   ReasonCode = "INSUFFICIENT_FUNDS"
+	ReasonLimitExceeded       ReasonCode = "LIMIT_EXCEEDED"
+	ReasonOperationNotAllowed ReasonCode = "OPERATION_NOT_ALLOWED"
+	ReasonAccountNotFound     ReasonCode = "ACCOUNT_NOT_FOUND"
+	ReasonForeignAccount      ReasonCode = "FOREIGN_ACCOUNT"
+	ReasonRejectedByReview    ReasonCode = "REJECTED_BY_REVIEW"
+	ReasonPendingReview       ReasonCode = "PENDING_REVIEW"
+)
+
+// declineErrors сопоставляет ошибки, означающие отказ в проведении транзакции
+// или её задержку до ручной проверки, с кодами причин.
+var declineErrors = []struct {
+	err  error
+	code ReasonCode
+}{
+	{ErrInvalidAmount, ReasonInvalidAmount},
+	{ErrInsufficientFunds, ReasonInsufficientFunds},
+	{ErrLimitExceeded, ReasonLimitExceeded},
+	{ErrOperationNotAllowed, ReasonOperationNotAllowed},
+	{ErrAccountNotFound, ReasonAccountNotFound},
+	{ErrForeignAccount, ReasonForeignAccount},
+	{ErrRejectedByReview, ReasonRejectedByReview},
+	{ErrPendingReview, ReasonPendingReview},
+}
+
+// declinedError — отказ, восстановленный из сохранённой транзакции.
+type declinedError struct {
+	code   ReasonCode
+	reason string
+}
+
+func (e declinedError) Error() string {
+	return e.reason
+}
+
+// DeclineReason возвращает код причины отказа по бизнес-правилам или задержки операции.
+// Для временных сбоев инфраструктуры и прочих ошибок возвращает пустую строку.
+func DeclineReason(err error) ReasonCode {
+	var declined declinedError
+	if errors.As(err, &declined) {
+		return declined.code
+	}
+	for _, decline := range declineErrors {
+		if errors.Is(err, decline.err) {
+			return decline.code
+		}
+	}
+	return ""
+}
+
+// IsDecline сообщает, является ли ошибка отказом по бизнес-правилам,
+// а не временным сбоем инфраструктуры.
+func IsDecline(err error) bool {
+	return DeclineReason(err) != ""
+}
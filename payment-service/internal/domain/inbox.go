@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// InboxMessage — запись об обработанном входящем сообщении и ответе на него.
+// Повторно доставленное сообщение не обрабатывается заново: отправителю возвращается сохранённый ответ.
+type InboxMessage struct {
+	MessageId   string     // Идентификатор сообщения
+	Response    string     // Текст ответа
+	ReasonCode  ReasonCode // Код причины отказа; пустой, если операция проведена
+	ProcessedAt time.Time  // Время обработки
+}
@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+// BalanceMismatch описывает расхождение между сохранённым балансом счёта
+// и балансом, рассчитанным по истории транзакций.
+type BalanceMismatch struct {
+	AccountId  int     `json:"account_id"` // Идентификатор счёта
+	UserId     int     `json:"user_id"`    // Идентификатор владельца счёта
+	Balance    float64 `json:"balance"`    // Сохранённый баланс счёта
+	Expected   float64 `json:"expected"`   // Баланс, рассчитанный по транзакциям
+	Difference float64 `json:"difference"` // Разница Balance - Expected
+}
+
+// LedgerBalance рассчитывает баланс по истории транзакций.
+// Учитываются только проведённые транзакции; результат округляется до копеек.
+func LedgerBalance(transactions []Transaction) float64 {
+	var sum float64
+	for _, tx := range transactions {
+		if tx.Status != TransactionStatusCompleted {
+			continue
+		}
+		if tx.IsDeposit {
+			sum += tx.Amount
+		} else {
+			sum -= tx.Amount
+		}
+	}
+	return roundCents(sum)
+}
+
+// Reconcile сверяет баланс счёта с его историей транзакций.
+// Возвращает nil, если баланс совпадает с рассчитанным.
+func Reconcile(account Account, transactions []Transaction) *BalanceMismatch {
+	expected := LedgerBalance(transactions)
+	difference := roundCents(account.Balance - expected)
+	if difference == 0 {
+		return nil
+	}
+	return &BalanceMismatch{
+		AccountId:  account.Id,
+		UserId:     account.UserId,
+		Balance:    account.Balance,
+		Expected:   expected,
+		Difference: difference,
+	}
+}
+
+// Adjustment возвращает корректирующую транзакцию, после которой
+// история транзакций сходится с сохранённым балансом. Баланс счёта не меняется.
+func (m BalanceMismatch) Adjustment(id int, now time.Time) Transaction {
+	return Transaction{
+		Id:        id,
+		UserId:    m.UserId,
+		AccountId: m.AccountId,
+		IsDeposit: m.Difference > 0,
+		Type:      TransactionTypeAdjustment,
+		Amount:    math.Abs(m.Difference),
+		Status:    TransactionStatusCompleted,
+		Date:      now,
+	}
+}
+
+// roundCents округляет сумму до копеек, чтобы погрешность вычислений
+// с плавающей точкой не давала ложных расхождений.
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLedgerBalance(t *testing.T) {
+	transactions := []Transaction{
+		{IsDeposit: true, Amount: 100.1, Status: TransactionStatusCompleted},
+		{IsDeposit: true, Amount: 0.2, Status: TransactionStatusCompleted},
+		{IsDeposit: false, Amount: 30, Status: TransactionStatusCompleted},
+		{IsDeposit: false, Amount: 500, Status: TransactionStatusDeclined},
+	}
+
+	got := LedgerBalance(transactions)
+	if got != 70.3 {
+		t.Errorf("Expected 70.3, got %v", got)
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	transactions := []Transaction{{IsDeposit: true, Amount: 100, Status: TransactionStatusCompleted}}
+
+	if mismatch := Reconcile(Account{Id: 1, Balance: 100}, transactions); mismatch != nil {
+		t.Errorf("Expected no mismatch, got %+v", mismatch)
+	}
+
+	mismatch := Reconcile(Account{Id: 1, UserId: 5, Balance: 80}, transactions)
+	if mismatch == nil {
+		t.Fatal("Expected mismatch")
+	}
+	if mismatch.Expected != 100 || mismatch.Difference != -20 {
+		t.Errorf("Unexpected mismatch: %+v", mismatch)
+	}
+
+	adjustment := mismatch.Adjustment(7, time.Now())
+	if adjustment.IsDeposit || adjustment.Amount != 20 || adjustment.Type != TransactionTypeAdjustment {
+		t.Errorf("Unexpected adjustment: %+v", adjustment)
+	}
+	if LedgerBalance(append(transactions, adjustment)) != 80 {
+		t.Error("Expected adjustment to reconcile ledger with balance")
+	}
+}
@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrReversalExceeded означает, что сумма отмены превышает ещё не отменённую часть транзакции.
+var ErrReversalExceeded = errors.New("reversal exceeds remaining transaction amount")
+
+// ReversalReason — код причины отмены проведённой транзакции.
+type ReversalReason string
+
+const (
+	ReversalCustomerRequest ReversalReason = "CUSTOMER_REQUEST" // Возврат по просьбе клиента
+	ReversalDuplicate       ReversalReason = "DUPLICATE"        // Повторно проведённая операция
+	ReversalFraud           ReversalReason = "FRAUD"            // Мошенническая операция
+	ReversalChargeback      ReversalReason = "CHARGEBACK"       // Опротестование платежа банком или платёжной системой
+	ReversalOperatorError   ReversalReason = "OPERATOR_ERROR"   // Ошибка оператора
+)
+
+// ParseReversalReason преобразует строку в ReversalReason.
+// Возвращает ошибку, если код причины неизвестен.
+func ParseReversalReason(value string) (ReversalReason, error) {
+	reason := ReversalReason(value)
+	switch reason {
+	case ReversalCustomerRequest, ReversalDuplicate, ReversalFraud, ReversalChargeback, ReversalOperatorError:
+		return reason, nil
+	}
+	return "", fmt.Errorf("unknown reversal reason: %q", value)
+}
+
+// RemainingAmount возвращает часть суммы транзакции, которую ещё можно отменить.
+func (t *Transaction) RemainingAmount() float64 {
+	return roundCents(t.Amount - t.ReversedAmount)
+}
+
+// Reverse создаёт транзакцию id, отменяющую amount из суммы t, и учитывает её в ReversedAmount.
+// Отмена проводится в обратном направлении по тому же кошельку.
+// Возвращает ошибку, если t не проведена, сама является отменой
+// или amount не положителен либо превышает ещё не отменённую сумму.
+func (t *Transaction) Reverse(id int, amount float64, reason ReversalReason, now time.Time) (Transaction, error) {
+	if t.Status != TransactionStatusCompleted || t.Type == TransactionTypeReversal {
+		return Transaction{}, fmt.Errorf("%s %s transaction cannot be reversed: %w", t.Status, t.Type, ErrOperationNotAllowed)
+	}
+	amount = roundCents(amount)
+	if amount <= 0 {
+		return Transaction{}, ErrInvalidAmount
+	}
+	if amount > t.RemainingAmount() {
+		return Transaction{}, fmt.Errorf("%.2f of %.2f left: %w", t.RemainingAmount(), t.Amount, ErrReversalExceeded)
+	}
+	t.ReversedAmount = roundCents(t.ReversedAmount + amount)
+	originalId := t.Id
+	return Transaction{
+		Id:             id,
+		UserId:         t.UserId,
+		AccountId:      t.AccountId,
+		IsDeposit:      !t.IsDeposit,
+		Type:           TransactionTypeReversal,
+		Amount:         amount,
+		Status:         TransactionStatusCompleted,
+		ReversalOf:     &originalId,
+		ReversalReason: reason,
+		Date:           now,
+	}, nil
+}
@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTransaction_Reverse(t *testing.T) {
+	original := Transaction{Id: 1, UserId: 5, AccountId: 2, IsDeposit: true, Type: TransactionTypeManual, Amount: 100, Status: TransactionStatusCompleted}
+
+	reversal, err := original.Reverse(2, 30.004, ReversalChargeback, time.Now())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if reversal.IsDeposit || reversal.Amount != 30 || *reversal.ReversalOf != 1 || reversal.Type != TransactionTypeReversal {
+		t.Errorf("Unexpected reversal: %+v", reversal)
+	}
+	if original.ReversedAmount != 30 || original.RemainingAmount() != 70 {
+		t.Errorf("Unexpected reversed amount: %v", original.ReversedAmount)
+	}
+
+	transactions := []Transaction{original, reversal}
+	if got := LedgerBalance(transactions); got != 70 {
+		t.Errorf("Expected ledger balance 70, got %v", got)
+	}
+
+	if _, err := original.Reverse(3, 70.01, ReversalChargeback, time.Now()); !errors.Is(err, ErrReversalExceeded) {
+		t.Errorf("Expected ErrReversalExceeded, got %v", err)
+	}
+	if _, err := original.Reverse(3, -1, ReversalChargeback, time.Now()); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("Expected ErrInvalidAmount, got %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// RiskFlag — название сработавшего правила проверки на мошенничество.
+type RiskFlag string
+
+const (
+	RiskFlagLargeAmount     RiskFlag = "large_amount"      // Сумма операции превышает порог
+	RiskFlagNewAccountLarge RiskFlag = "new_account_large" // Крупный платёж с недавно созданного счёта
+	RiskFlagFailedAttempts  RiskFlag = "failed_attempts"   // Серия отклонённых операций пользователя
+)
+
+// RiskRules — параметры правил проверки операций на мошенничество.
+// Нулевое значение параметра отключает соответствующее правило.
+type RiskRules struct {
+	LargeAmount          float64       // Порог суммы пополнения или платежа
+	NewAccountAge        time.Duration // Возраст счёта, до которого он считается новым
+	NewAccountAmount     float64       // Порог суммы платежа с нового счёта
+	MaxFailedAttempts    int           // Число отклонённых операций пользователя, после которого операции задерживаются
+	FailedAttemptsWindow time.Duration // Окно, в котором считаются отклонённые операции
+}
+
+// DefaultRiskRules возвращает правила проверки на мошенничество по умолчанию.
+func DefaultRiskRules() RiskRules {
+	return RiskRules{
+		LargeAmount:          100000,
+		NewAccountAge:        7 * 24 * time.Hour,
+		NewAccountAmount:     20000,
+		MaxFailedAttempts:    5,
+		FailedAttemptsWindow: time.Hour,
+	}
+}
+
+// Screen проверяет операцию по правилам и возвращает сработавшие флаги.
+// failedAttempts — число отклонённых операций пользователя за окно FailedAttemptsWindow.
+// Пустой результат означает, что операцию можно проводить без ручной проверки.
+func (r RiskRules) Screen(transaction Transaction, account Account, failedAttempts int, now time.Time) []RiskFlag {
+	flags := make([]RiskFlag, 0)
+	if r.LargeAmount > 0 && transaction.Amount >= r.LargeAmount {
+		flags = append(flags, RiskFlagLargeAmount)
+	}
+	if !transaction.IsDeposit && r.NewAccountAge > 0 && r.NewAccountAmount > 0 &&
+		now.Sub(account.CreationDate) < r.NewAccountAge && transaction.Amount >= r.NewAccountAmount {
+		flags = append(flags, RiskFlagNewAccountLarge)
+	}
+	if r.MaxFailedAttempts > 0 && failedAttempts >= r.MaxFailedAttempts {
+		flags = append(flags, RiskFlagFailedAttempts)
+	}
+	return flags
+}
+
+// ReviewStatus описывает состояние ручной проверки операции.
+type ReviewStatus string
+
+const (
+	ReviewStatusPending  ReviewStatus = "pending"  // Операция ожидает решения администратора
+	ReviewStatusApproved ReviewStatus = "approved" // Администратор разрешил операцию
+	ReviewStatusRejected ReviewStatus = "rejected" // Администратор отклонил операцию
+)
+
+// TransactionReview — запись очереди ручной проверки задержанной операции.
+type TransactionReview struct {
+	TransactionId int          `json:"transaction_id"`        // Идентификатор задержанной транзакции
+	Flags         []RiskFlag   `json:"flags"`                 // Сработавшие правила
+	Status        ReviewStatus `json:"status"`                // Состояние проверки
+	CreatedAt     time.Time    `json:"created_at"`            // Момент постановки в очередь
+	DecidedBy     string       `json:"decided_by"`            // Администратор, принявший решение
+	Comment       string       `json:"comment"`               // Комментарий к решению
+	DecidedAt     *time.Time   `json:"decided_at"`            // Момент принятия решения (nil, пока проверка не завершена)
+	Transaction   *Transaction `json:"transaction,omitempty"` // Задержанная транзакция (заполняется при выдаче очереди)
+}
+
+// Decide фиксирует решение администратора по задержанной операции.
+// Возвращает ошибку, если решение уже принято, не указан администратор
+// или status не является решением (approved или rejected).
+func (r *TransactionReview) Decide(status ReviewStatus, decidedBy, comment string) error {
+	if decidedBy == "" {
+		return fmt.Errorf("decided_by is required")
+	}
+	if status != ReviewStatusApproved && status != ReviewStatusRejected {
+		return fmt.Errorf("unknown review decision %q", status)
+	}
+	if r.Status != ReviewStatusPending {
+		return fmt.Errorf("review is already %s", r.Status)
+	}
+	now := time.Now()
+	r.Status = status
+	r.DecidedBy = decidedBy
+	r.Comment = comment
+	r.DecidedAt = &now
+	return nil
+}
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRiskRules_Screen(t *testing.T) {
+	now := time.Now()
+	rules := RiskRules{LargeAmount: 1000, NewAccountAge: 24 * time.Hour, NewAccountAmount: 200, MaxFailedAttempts: 3}
+	oldAccount := Account{CreationDate: now.Add(-48 * time.Hour)}
+	newAccount := Account{CreationDate: now.Add(-time.Hour)}
+
+	tests := []struct {
+		name           string
+		transaction    Transaction
+		account        Account
+		failedAttempts int
+		want           []RiskFlag
+	}{
+		{name: "small payment", transaction: Transaction{Amount: 100}, account: oldAccount, want: []RiskFlag{}},
+		{name: "large payment", transaction: Transaction{Amount: 1000}, account: oldAccount, want: []RiskFlag{RiskFlagLargeAmount}},
+		{name: "new account large payment", transaction: Transaction{Amount: 300}, account: newAccount, want: []RiskFlag{RiskFlagNewAccountLarge}},
+		{name: "new account deposit", transaction: Transaction{Amount: 300, IsDeposit: true}, account: newAccount, want: []RiskFlag{}},
+		{name: "failed attempts", transaction: Transaction{Amount: 1}, account: oldAccount, failedAttempts: 3,
+			want: []RiskFlag{RiskFlagFailedAttempts}},
+		{name: "all rules", transaction: Transaction{Amount: 5000}, account: newAccount, failedAttempts: 5,
+			want: []RiskFlag{RiskFlagLargeAmount, RiskFlagNewAccountLarge, RiskFlagFailedAttempts}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rules.Screen(tt.transaction, tt.account, tt.failedAttempts, now)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	if flags := (RiskRules{}).Screen(Transaction{Amount: 1e9}, newAccount, 100, now); len(flags) != 0 {
+		t.Errorf("Expected zero rules to be disabled, got %v", flags)
+	}
+}
+
+func TestTransactionReview_Decide(t *testing.T) {
+	review := TransactionReview{TransactionId: 1, Status: ReviewStatusPending}
+
+	if err := review.Decide(ReviewStatusApproved, "", ""); err == nil {
+		t.Error("Expected error without decided_by")
+	}
+	if err := review.Decide(ReviewStatusPending, "admin", ""); err == nil {
+		t.Error("Expected error for pending decision")
+	}
+	if err := review.Decide(ReviewStatusRejected, "admin", "fraud"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if review.Status != ReviewStatusRejected || review.DecidedBy != "admin" || review.DecidedAt == nil {
+		t.Errorf("Unexpected review: %+v", review)
+	}
+	if err := review.Decide(ReviewStatusApproved, "admin", ""); err == nil {
+		t.Error("Expected error for already decided review")
+	}
+}
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// SpendingLimits описывает ограничения на списания со счёта.
+// Нулевое значение ограничения означает, что оно не действует.
+type SpendingLimits struct {
+	AccountId          int       `json:"account_id"`            // Идентификатор счёта
+	MaxPayment         float64   `json:"max_payment"`           // Максимальная сумма одного платежа
+	DailyLimit         float64   `json:"daily_limit"`           // Максимальная сумма платежей за календарный день
+	MonthlyLimit       float64   `json:"monthly_limit"`         // Максимальная сумма платежей за календарный месяц
+	MaxPaymentsPerHour int       `json:"max_payments_per_hour"` // Максимальное число платежей за последний час
+	UpdatedAt          time.Time `json:"updated_at"`            // Дата последнего изменения ограничений
+}
+
+// SpendingActivity — платежи со счёта за окна, в которых действуют ограничения.
+type SpendingActivity struct {
+	DailyTotal       float64 // Сумма платежей с начала текущего дня
+	MonthlyTotal     float64 // Сумма платежей с начала текущего месяца
+	PaymentsLastHour int     // Число платежей за последний час
+}
+
+// SpendingWindows возвращает начало окон, в которых считаются ограничения:
+// текущего календарного дня, текущего календарного месяца и последнего часа.
+func SpendingWindows(now time.Time) (dayStart, monthStart, hourStart time.Time) {
+	year, month, day := now.Date()
+	dayStart = time.Date(year, month, day, 0, 0, 0, 0, now.Location())
+	monthStart = time.Date(year, month, 1, 0, 0, 0, 0, now.Location())
+	hourStart = now.Add(-time.Hour)
+	return dayStart, monthStart, hourStart
+}
+
+// Validate проверяет, что ограничения не отрицательные.
+func (l *SpendingLimits) Validate() error {
+	if l.MaxPayment < 0 || l.DailyLimit < 0 || l.MonthlyLimit < 0 || l.MaxPaymentsPerHour < 0 {
+		return fmt.Errorf("spending limits must be not negative")
+	}
+	return nil
+}
+
+// Check проверяет, что платёж на сумму amount укладывается в ограничения
+// с учётом уже совершённых платежей activity.
+// Возвращает ошибку ErrLimitExceeded с указанием нарушенного ограничения.
+func (l *SpendingLimits) Check(amount float64, activity SpendingActivity) error {
+	if l.MaxPayment > 0 && amount > l.MaxPayment {
+		return fmt.Errorf("single payment limit of %.2f: %w", l.MaxPayment, ErrLimitExceeded)
+	}
+	if l.DailyLimit > 0 && activity.DailyTotal+amount > l.DailyLimit {
+		return fmt.Errorf("daily limit of %.2f: %w", l.DailyLimit, ErrLimitExceeded)
+	}
+	if l.MonthlyLimit > 0 && activity.MonthlyTotal+amount > l.MonthlyLimit {
+		return fmt.Errorf("monthly limit of %.2f: %w", l.MonthlyLimit, ErrLimitExceeded)
+	}
+	if l.MaxPaymentsPerHour > 0 && activity.PaymentsLastHour >= l.MaxPaymentsPerHour {
+		return fmt.Errorf("limit of %d payments per hour: %w", l.MaxPaymentsPerHour, ErrLimitExceeded)
+	}
+	return nil
+}
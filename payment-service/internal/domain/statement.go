@@ -0,0 +1,70 @@
+package domain
+
+import "time"
+
+// StatementMonthFormat — формат месяца выписки, например 2025-10.
+const StatementMonthFormat = "2006-01"
+
+// Statement — выписка по счёту за период: баланс на начало, проведённые транзакции и баланс на конец.
+type Statement struct {
+	AccountId      int              `json:"account_id"`      // Идентификатор счёта
+	UserId         int              `json:"user_id"`         // Идентификатор владельца счёта
+	Currency       string           `json:"currency"`        // Валюта счёта
+	PeriodStart    time.Time        `json:"period_start"`    // Момент баланса на начало; транзакции учитываются строго после него
+	PeriodEnd      time.Time        `json:"period_end"`      // Момент баланса на конец периода включительно
+	OpeningBalance float64          `json:"opening_balance"` // Баланс на начало периода
+	ClosingBalance float64          `json:"closing_balance"` // Баланс на конец периода
+	Entries        []StatementEntry `json:"entries"`         // Проведённые транзакции в порядке даты
+}
+
+// StatementEntry — строка выписки: проведённая транзакция и баланс после неё.
+type StatementEntry struct {
+	TransactionId int             `json:"transaction_id"` // Идентификатор транзакции
+	Date          time.Time       `json:"date"`           // Дата проведения
+	Type          TransactionType `json:"type"`           // Происхождение операции
+	Amount        float64         `json:"amount"`         // Сумма со знаком: пополнения положительные, списания отрицательные
+	Balance       float64         `json:"balance"`        // Баланс после транзакции
+}
+
+// StatementPeriod возвращает границы выписки за календарный месяц month в UTC:
+// момент баланса на начало (последняя микросекунда предыдущего месяца)
+// и момент баланса на конец (последняя микросекунда месяца).
+// Точность в микросекунду совпадает с точностью хранения дат транзакций.
+func StatementPeriod(month time.Time) (start, end time.Time) {
+	month = month.UTC()
+	first := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return first.Add(-time.Microsecond), first.AddDate(0, 1, 0).Add(-time.Microsecond)
+}
+
+// NewStatement составляет выписку по балансу на начало периода opening
+// и транзакциям периода в порядке их проведения. Непроведённые транзакции пропускаются.
+func NewStatement(opening AccountBalance, transactions []Transaction, end time.Time) Statement {
+	statement := Statement{
+		AccountId:      opening.AccountId,
+		UserId:         opening.UserId,
+		Currency:       opening.Currency,
+		PeriodStart:    opening.At,
+		PeriodEnd:      end,
+		OpeningBalance: opening.Balance,
+		ClosingBalance: opening.Balance,
+		Entries:        make([]StatementEntry, 0, len(transactions)),
+	}
+	for _, tx := range transactions {
+		if tx.Status != TransactionStatusCompleted {
+			continue
+		}
+		amount := tx.Amount
+		if !tx.IsDeposit {
+			amount = -amount
+		}
+		statement.ClosingBalance = roundCents(statement.ClosingBalance + amount)
+		statement.Entries = append(statement.Entries, StatementEntry{
+			TransactionId: tx.Id,
+			Date:          tx.Date,
+			Type:          tx.Type,
+			Amount:        amount,
+			Balance:       statement.ClosingBalance,
+		})
+	}
+	return statement
+}
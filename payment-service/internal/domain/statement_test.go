@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStatementPeriod(t *testing.T) {
+	start, end := StatementPeriod(time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC))
+	if !start.Equal(time.Date(2024, 12, 31, 23, 59, 59, 999999000, time.UTC)) {
+		t.Errorf("Unexpected start: %v", start)
+	}
+	if !end.Equal(time.Date(2025, 1, 31, 23, 59, 59, 999999000, time.UTC)) {
+		t.Errorf("Unexpected end: %v", end)
+	}
+}
+
+func TestNewStatement(t *testing.T) {
+	start, end := StatementPeriod(time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC))
+	opening := AccountBalance{AccountId: 1, UserId: 5, Currency: "RUB", Balance: 100, At: start}
+	transactions := []Transaction{
+		{Id: 1, IsDeposit: true, Type: TransactionTypeTopUp, Amount: 50.1, Status: TransactionStatusCompleted, Date: start.Add(time.Hour)},
+		{Id: 2, Type: TransactionTypePayment, Amount: 500, Status: TransactionStatusDeclined, Date: start.Add(2 * time.Hour)},
+		{Id: 3, Type: TransactionTypePayment, Amount: 30.2, Status: TransactionStatusCompleted, Date: start.Add(3 * time.Hour)},
+	}
+
+	statement := NewStatement(opening, transactions, end)
+	if statement.OpeningBalance != 100 || statement.ClosingBalance != 119.9 {
+		t.Errorf("Unexpected balances: opening %.2f, closing %.2f", statement.OpeningBalance, statement.ClosingBalance)
+	}
+	if len(statement.Entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(statement.Entries))
+	}
+	if statement.Entries[0].Balance != 150.1 || statement.Entries[1].Amount != -30.2 || statement.Entries[1].Balance != 119.9 {
+		t.Errorf("Unexpected entries: %+v", statement.Entries)
+	}
+}
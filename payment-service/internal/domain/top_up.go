@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// TopUpStatus описывает состояние пополнения счёта через платёжного провайдера.
+type TopUpStatus string
+
+const (
+	TopUpStatusPending   TopUpStatus = "pending"   // Ожидает подтверждения провайдером
+	TopUpStatusSucceeded TopUpStatus = "succeeded" // Провайдер подтвердил оплату, баланс пополнен
+	TopUpStatusFailed    TopUpStatus = "failed"    // Провайдер сообщил об ошибке оплаты, баланс не изменён
+)
+
+// ParseTopUpStatus преобразует строку в TopUpStatus.
+// Возвращает ошибку, если статус неизвестен.
+func ParseTopUpStatus(value string) (TopUpStatus, error) {
+	status := TopUpStatus(value)
+	switch status {
+	case TopUpStatusPending, TopUpStatusSucceeded, TopUpStatusFailed:
+		return status, nil
+	}
+	return "", fmt.Errorf("unknown top-up status: %q", value)
+}
+
+// TopUp — намерение пополнить счёт через внешнего платёжного провайдера.
+// Баланс пополняется только после того, как провайдер подтвердит оплату.
+type TopUp struct {
+	Id              int         `json:"id"`               // Уникальный идентификатор пополнения
+	AccountId       int         `json:"account_id"`       // Пополняемый кошелёк
+	UserId          int         `json:"user_id"`          // Владелец кошелька
+	Amount          float64     `json:"amount"`           // Сумма пополнения
+	Status          TopUpStatus `json:"status"`           // Состояние пополнения
+	Provider        string      `json:"provider"`         // Название платёжного провайдера
+	ProviderRef     string      `json:"provider_ref"`     // Идентификатор платежа у провайдера
+	ConfirmationURL string      `json:"confirmation_url"` // Адрес, по которому пользователь подтверждает оплату
+	TransactionId   *int        `json:"transaction_id"`   // Транзакция пополнения (nil, пока оплата не подтверждена)
+	FailureReason   string      `json:"failure_reason"`   // Причина ошибки оплаты
+	CreatedAt       time.Time   `json:"created_at"`       // Момент создания
+	UpdatedAt       time.Time   `json:"updated_at"`       // Момент последнего изменения состояния
+}
+
+// Succeed отмечает пополнение как подтверждённое и связывает его с транзакцией transactionId.
+// Возвращает ошибку, если пополнение уже завершено.
+func (t *TopUp) Succeed(transactionId int, now time.Time) error {
+	if t.Status != TopUpStatusPending {
+		return fmt.Errorf("top-up is already %s: %w", t.Status, ErrOperationNotAllowed)
+	}
+	t.Status = TopUpStatusSucceeded
+	t.TransactionId = &transactionId
+	t.UpdatedAt = now
+	return nil
+}
+
+// Fail отмечает пополнение как неуспешное с причиной reason.
+// Возвращает ошибку, если пополнение уже завершено.
+func (t *TopUp) Fail(reason string, now time.Time) error {
+	if t.Status != TopUpStatusPending {
+		return fmt.Errorf("top-up is already %s: %w", t.Status, ErrOperationNotAllowed)
+	}
+	t.Status = TopUpStatusFailed
+	t.FailureReason = reason
+	t.UpdatedAt = now
+	return nil
+}
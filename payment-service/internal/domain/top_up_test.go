@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTopUp_Transitions(t *testing.T) {
+	now := time.Now()
+
+	topUp := TopUp{Id: 1, Amount: 50, Status: TopUpStatusPending}
+	if err := topUp.Succeed(7, now); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if topUp.Status != TopUpStatusSucceeded || topUp.TransactionId == nil || *topUp.TransactionId != 7 {
+		t.Errorf("Unexpected top-up: %+v", topUp)
+	}
+	if err := topUp.Fail("late failure", now); !errors.Is(err, ErrOperationNotAllowed) {
+		t.Errorf("Expected ErrOperationNotAllowed, got %v", err)
+	}
+
+	topUp = TopUp{Id: 2, Amount: 50, Status: TopUpStatusPending}
+	if err := topUp.Fail("card declined", now); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if topUp.Status != TopUpStatusFailed || topUp.FailureReason != "card declined" {
+		t.Errorf("Unexpected top-up: %+v", topUp)
+	}
+	if err := topUp.Succeed(8, now); !errors.Is(err, ErrOperationNotAllowed) {
+		t.Errorf("Expected ErrOperationNotAllowed, got %v", err)
+	}
+}
@@ -2,11 +2,77 @@ package domain
 
 import "time"
 
+// TransactionType описывает происхождение транзакции.
+type TransactionType string
+
+const (
+	TransactionTypePayment TransactionType = "payment" // Операция, поступившая от других сервисов через Kafka
+	TransactionTypePayout  TransactionType = "payout"  // Выплата остатка средств при закрытии счёта
+	TransactionTypeManual  TransactionType = "manual"  // Ручное пополнение через HTTP API
+	TransactionTypeTopUp   TransactionType = "top_up"  // Пополнение, подтверждённое платёжным провайдером
+	// TransactionTypeAdjustment — корректировка истории по итогам сверки балансов, одобренная администратором
+	TransactionTypeAdjustment TransactionType = "adjustment"
+	// TransactionTypeReversal — полная или частичная отмена ранее проведённой транзакции
+	TransactionTypeReversal TransactionType = "reversal"
+)
+
+// TransactionStatus описывает результат обработки транзакции.
+type TransactionStatus string
+
+const (
+	TransactionStatusCompleted TransactionStatus = "completed" // Транзакция проведена, баланс изменён
+	TransactionStatusDeclined  TransactionStatus = "declined"  // Транзакция отклонена, баланс не изменён
+	// TransactionStatusPendingReview — транзакция задержана до ручной проверки, баланс не изменён
+	TransactionStatusPendingReview TransactionStatus = "pending_review"
+)
+
 // Transaction описывает операцию пополнения или снятия средств.
 type Transaction struct {
-	Id        int       `json:"id"`         // Уникальный идентификатор транзакции
-	UserId    int       `json:"user_id"`    // Идентификатор пользователя, связанного с операцией
-	IsDeposit bool      `json:"is_deposit"` // Тип операции: true — пополнение, false — снятие
-	Amount    float64   `json:"amount"`     // Сумма операции
-	Date      time.Time `json:"date"`       // Дата выполнения транзакции
+	Id             int               `json:"id"`                        // Уникальный идентификатор транзакции
+	UserId         int               `json:"user_id"`                   // Идентификатор пользователя, связанного с операцией
+	AccountId      int               `json:"account_id"`                // Идентификатор кошелька; 0 — основной кошелёк пользователя
+	IsDeposit      bool              `json:"is_deposit"`                // Направление операции: true — пополнение, false — снятие
+	Type           TransactionType   `json:"type"`                      // Происхождение операции; пустое значение означает payment
+	Amount         float64           `json:"amount"`                    // Сумма операции
+	Status         TransactionStatus `json:"status"`                    // Результат обработки транзакции
+	FailureCode    ReasonCode        `json:"failure_code"`              // Код причины отказа для отклонённой транзакции
+	FailureReason  string            `json:"failure_reason"`            // Причина отказа для отклонённой транзакции
+	ReversedAmount float64           `json:"reversed_amount"`           // Уже отменённая часть суммы транзакции
+	ReversalOf     *int              `json:"reversal_of,omitempty"`     // ID отменяемой транзакции (только для отмен)
+	ReversalReason ReversalReason    `json:"reversal_reason,omitempty"` // Код причины отмены (только для отмен)
+	Date           time.Time         `json:"date"`                      // Дата выполнения транзакции
+}
+
+// Complete отмечает транзакцию как проведённую.
+func (t *Transaction) Complete() {
+	t.Status = TransactionStatusCompleted
+	t.FailureCode = ""
+	t.FailureReason = ""
+}
+
+// Decline отмечает транзакцию как отклонённую и запоминает причину отказа.
+func (t *Transaction) Decline(reason error) {
+	t.Status = TransactionStatusDeclined
+	t.FailureCode = DeclineReason(reason)
+	t.FailureReason = reason.Error()
+}
+
+// HoldForReview отмечает транзакцию как задержанную до ручной проверки.
+func (t *Transaction) HoldForReview() {
+	t.Status = TransactionStatusPendingReview
+	t.FailureCode = ""
+	t.FailureReason = ""
+}
+
+// Outcome возвращает текущий результат обработки транзакции:
+// nil для проведённой, ErrPendingReview для задержанной
+// и ошибку с сохранённой причиной для отклонённой.
+func (t *Transaction) Outcome() error {
+	switch t.Status {
+	case TransactionStatusDeclined:
+		return declinedError{code: t.FailureCode, reason: t.FailureReason}
+	case TransactionStatusPendingReview:
+		return ErrPendingReview
+	}
+	return nil
 }
@@ -0,0 +1,138 @@
+package fakeprovider
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"payment-service/internal/application/provider"
+	"payment-service/internal/domain"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Name — название фиктивного провайдера.
+const Name = "fake"
+
+// referencePrefix — префикс идентификаторов платежей фиктивного провайдера.
+const referencePrefix = "fake-"
+
+// Provider — фиктивный платёжный провайдер для локального запуска.
+// Вместо страницы оплаты предоставляет HTTP-метод, которым можно подтвердить или отклонить платёж;
+// результат отправляется в payment-service подписанным уведомлением, как у настоящего провайдера.
+type Provider struct {
+	secret      []byte
+	callbackURL string
+	baseURL     string
+	client      *http.Client
+}
+
+// New создаёт фиктивного провайдера.
+// secret используется для подписи уведомлений, callbackURL — адрес приёма уведомлений,
+// baseURL — адрес сервиса, по которому доступен метод подтверждения платежа.
+func New(secret, callbackURL, baseURL string) *Provider {
+	return &Provider{
+		secret:      []byte(secret),
+		callbackURL: callbackURL,
+		baseURL:     strings.TrimSuffix(baseURL, "/"),
+		client:      &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name возвращает название провайдера.
+func (p *Provider) Name() string {
+	return Name
+}
+
+// CreatePayment регистрирует платёж для пополнения.
+// Идентификатор платежа содержит ID пополнения, поэтому провайдеру не нужно хранить состояние.
+func (p *Provider) CreatePayment(ctx context.Context, topUp domain.TopUp) (*provider.Payment, error) {
+	reference := referencePrefix + strconv.Itoa(topUp.Id)
+	return &provider.Payment{
+		Reference:       reference,
+		ConfirmationURL: p.baseURL + "/fake-provider/payments/" + reference,
+	}, nil
+}
+
+// ParseCallback проверяет HMAC-SHA256 подпись уведомления и разбирает его тело.
+func (p *Provider) ParseCallback(body []byte, signature string) (*provider.Callback, error) {
+	expected, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(expected, p.mac(body)) {
+		return nil, provider.ErrInvalidSignature
+	}
+	var callback provider.Callback
+	err = json.Unmarshal(body, &callback)
+	if err != nil {
+		return nil, fmt.Errorf("invalid callback body: %w", err)
+	}
+	return &callback, nil
+}
+
+// Sign возвращает подпись тела уведомления в шестнадцатеричном виде.
+func (p *Provider) Sign(body []byte) string {
+	return hex.EncodeToString(p.mac(body))
+}
+
+// Confirm завершает платёж reference и отправляет подписанное уведомление о результате.
+func (p *Provider) Confirm(ctx context.Context, reference string, succeeded bool, failureReason string) error {
+	topUpId, err := strconv.Atoi(strings.TrimPrefix(reference, referencePrefix))
+	if err != nil || !strings.HasPrefix(reference, referencePrefix) {
+		return fmt.Errorf("unknown payment reference %q", reference)
+	}
+	body, err := json.Marshal(provider.Callback{
+		TopUpId:       topUpId,
+		Reference:     reference,
+		Succeeded:     succeeded,
+		FailureReason: failureReason,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.callbackURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(provider.SignatureHeader, p.Sign(body))
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("callback rejected with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// ConfirmPayment — HTTP-метод подтверждения платежа, заменяющий страницу оплаты провайдера.
+// Тело запроса: {"succeeded": true} или {"succeeded": false, "failure_reason": "..."}.
+func (p *Provider) ConfirmPayment(w http.ResponseWriter, r *http.Request) {
+	request := struct {
+		Succeeded     bool   `json:"succeeded"`
+		FailureReason string `json:"failure_reason"`
+	}{}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		http.Error(w, "Invalid input format", http.StatusBadRequest)
+		return
+	}
+	err = p.Confirm(r.Context(), r.PathValue("reference"), request.Succeeded, request.FailureReason)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// mac вычисляет HMAC-SHA256 тела уведомления.
+func (p *Provider) mac(body []byte) []byte {
+	h := hmac.New(sha256.New, p.secret)
+	h.Write(body)
+	return h.Sum(nil)
+}
@@ -0,0 +1,64 @@
+package fakeprovider
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"payment-service/internal/application/provider"
+	"payment-service/internal/domain"
+	"testing"
+)
+
+func TestProvider_ConfirmSendsSignedCallback(t *testing.T) {
+	var received *provider.Callback
+	var parseErr error
+	p := New("secret", "", "http://payments.local/")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received, parseErr = p.ParseCallback(body, r.Header.Get(provider.SignatureHeader))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	p.callbackURL = server.URL
+
+	payment, err := p.CreatePayment(context.Background(), domain.TopUp{Id: 42, Amount: 100})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if payment.ConfirmationURL != "http://payments.local/fake-provider/payments/fake-42" {
+		t.Errorf("unexpected confirmation url: %s", payment.ConfirmationURL)
+	}
+
+	err = p.Confirm(context.Background(), payment.Reference, false, "card declined")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if parseErr != nil {
+		t.Fatalf("callback signature rejected: %v", parseErr)
+	}
+	if received.TopUpId != 42 || received.Succeeded || received.FailureReason != "card declined" {
+		t.Errorf("unexpected callback: %+v", received)
+	}
+
+	if err := p.Confirm(context.Background(), "unknown", true, ""); err == nil {
+		t.Error("expected error for unknown reference")
+	}
+}
+
+func TestProvider_ParseCallback_InvalidSignature(t *testing.T) {
+	p := New("secret", "", "")
+	body := []byte(`{"top_up_id": 1, "reference": "fake-1", "succeeded": true}`)
+
+	if _, err := p.ParseCallback(body, p.Sign(body)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	other := New("other-secret", "", "")
+	if _, err := p.ParseCallback(body, other.Sign(body)); !errors.Is(err, provider.ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature, got %v", err)
+	}
+	if _, err := p.ParseCallback(body, "not-hex"); !errors.Is(err, provider.ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature, got %v", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/segmentio/kafka-go"
+	"kafkacluster"
 )
 
 // Consumer - обёртка над kafka.Reader, обеспечивающая чтение сообщений из топика.
@@ -11,25 +12,37 @@ type Consumer struct {
 	reader *kafka.Reader
 }
 
-// NewConsumer создаёт нового Consumer с заданными брокерами, топиком и groupID.
-func NewConsumer(brokers []string, topic, groupID string) *Consumer {
+// NewConsumer создаёт нового Consumer для кластера cluster с заданными топиком и groupID.
+func NewConsumer(cluster kafkacluster.Cluster, topic, groupID string) *Consumer {
 	return &Consumer{reader: kafka.NewReader(kafka.ReaderConfig{
-		Brokers: brokers,
+		Brokers: cluster.Brokers,
+		Dialer:  cluster.Dialer(),
 		GroupID: groupID,
 		Topic:   topic,
 	})}
 }
 
-// ReadMessage читает одно сообщение из Kafka.
+// FetchMessage читает одно сообщение из Kafka без фиксации смещения.
+// Смещение фиксируется отдельно через CommitMessage после обработки сообщения.
 // Возвращает ошибку, если чтение не удалось или контекст отменён.
-func (c *Consumer) ReadMessage(ctx context.Context) (*kafka.Message, error) {
-	message, err := c.reader.ReadMessage(ctx)
+func (c *Consumer) FetchMessage(ctx context.Context) (*kafka.Message, error) {
+	message, err := c.reader.FetchMessage(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("error reading message: %w", err)
+		return nil, fmt.Errorf("error fetching message: %w", err)
 	}
 	return &message, nil
 }
 
+// CommitMessage фиксирует смещение группы после сообщения message:
+// при перезапуске чтение продолжится со следующего сообщения партиции.
+func (c *Consumer) CommitMessage(ctx context.Context, message *kafka.Message) error {
+	err := c.reader.CommitMessages(ctx, *message)
+	if err != nil {
+		return fmt.Errorf("error committing message: %w", err)
+	}
+	return nil
+}
+
 // Close закрывает Kafka reader и освобождает ресурсы.
 func (c *Consumer) Close() error {
 	err := c.reader.Close()
@@ -0,0 +1,209 @@
+package kafka
+
+import (
+	"context"
+	"contracts/signing"
+	"errors"
+	"fmt"
+	"github.com/segmentio/kafka-go"
+	"kafkacluster"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Заголовки, которыми сообщение дополняется при переносе в топик недоставленных сообщений.
+const (
+	DeadLetterErrorHeader    = "dlq-error"          // Текст последней ошибки обработки
+	DeadLetterAttemptsHeader = "dlq-attempts"       // Число выполненных попыток обработки
+	DeadLetterTopicHeader    = "dlq-original-topic" // Топик, из которого было прочитано сообщение
+	DeadLetterFailedAtHeader = "dlq-failed-at"      // Время последней попытки в формате RFC 3339
+)
+
+// DeadLetter — сообщение из топика недоставленных сообщений с причиной сбоя.
+type DeadLetter struct {
+	Partition     int       `json:"partition"`      // Партиция топика недоставленных сообщений
+	Offset        int64     `json:"offset"`         // Смещение в партиции
+	Key           string    `json:"key"`            // Ключ исходного сообщения
+	Value         string    `json:"value"`          // Тело исходного сообщения
+	OriginalTopic string    `json:"original_topic"` // Топик исходного сообщения
+	Error         string    `json:"error"`          // Последняя ошибка обработки
+	Attempts      int       `json:"attempts"`       // Число выполненных попыток
+	FailedAt      time.Time `json:"failed_at"`      // Время последней попытки
+}
+
+// newDeadLetterMessage возвращает копию сообщения message для топика недоставленных сообщений
+// с причиной сбоя в заголовках. Исходные заголовки сохраняются.
+func newDeadLetterMessage(message *kafka.Message, cause error, attempts int, now time.Time) *kafka.Message {
+	headers := make([]kafka.Header, 0, len(message.Headers)+4)
+	headers = append(headers, message.Headers...)
+	headers = append(headers,
+		kafka.Header{Key: DeadLetterErrorHeader, Value: []byte(cause.Error())},
+		kafka.Header{Key: DeadLetterAttemptsHeader, Value: []byte(strconv.Itoa(attempts))},
+		kafka.Header{Key: DeadLetterTopicHeader, Value: []byte(message.Topic)},
+		kafka.Header{Key: DeadLetterFailedAtHeader, Value: []byte(now.UTC().Format(time.RFC3339))},
+	)
+	return &kafka.Message{Key: message.Key, Value: message.Value, Headers: headers}
+}
+
+// parseDeadLetter разбирает сообщение из топика недоставленных сообщений.
+func parseDeadLetter(message *kafka.Message) DeadLetter {
+	deadLetter := DeadLetter{
+		Partition:     message.Partition,
+		Offset:        message.Offset,
+		Key:           string(message.Key),
+		Value:         string(message.Value),
+		OriginalTopic: headerValue(message, DeadLetterTopicHeader),
+		Error:         headerValue(message, DeadLetterErrorHeader),
+	}
+	deadLetter.Attempts, _ = strconv.Atoi(headerValue(message, DeadLetterAttemptsHeader))
+	deadLetter.FailedAt, _ = time.Parse(time.RFC3339, headerValue(message, DeadLetterFailedAtHeader))
+	return deadLetter
+}
+
+// replayMessage возвращает исходное сообщение без заголовков недоставленного сообщения.
+// Если задан resigner, сообщение с устаревшей подписью подписывается заново
+// (signing.Resigner.Resign); для сообщения без действительной подписи возвращается ошибка.
+func replayMessage(message *kafka.Message, resigner *signing.Resigner) (kafka.Message, error) {
+	headers := make([]kafka.Header, 0, len(message.Headers))
+	for _, header := range message.Headers {
+		if !strings.HasPrefix(header.Key, "dlq-") {
+			headers = append(headers, header)
+		}
+	}
+	replay := kafka.Message{Key: message.Key, Value: message.Value, Headers: headers}
+	if resigner == nil {
+		return replay, nil
+	}
+	signed := kafkacluster.FromKafka(&replay)
+	err := resigner.Resign(signed)
+	if err != nil {
+		return kafka.Message{}, err
+	}
+	return *kafkacluster.ToKafka(signed), nil
+}
+
+// ReadDeadLetters читает до limit сообщений из топика недоставленных сообщений topic,
+// начиная с самых старых в каждой партиции. Чтение не сдвигает смещения групп потребителей,
+// поэтому показывает и уже переотправленные сообщения.
+func ReadDeadLetters(ctx context.Context, cluster kafkacluster.Cluster, topic string, limit int) ([]DeadLetter, error) {
+	conn, err := cluster.Dialer().DialContext(ctx, "tcp", cluster.Brokers[0])
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to kafka: %w", err)
+	}
+	partitions, err := conn.ReadPartitions(topic)
+	conn.Close()
+	if err != nil {
+		return nil, fmt.Errorf("error reading partitions: %w", err)
+	}
+
+	deadLetters := make([]DeadLetter, 0)
+	for _, partition := range partitions {
+		if len(deadLetters) >= limit {
+			break
+		}
+		read, err := readPartition(ctx, cluster, topic, partition.ID, limit-len(deadLetters))
+		if err != nil {
+			return nil, err
+		}
+		deadLetters = append(deadLetters, read...)
+	}
+	return deadLetters, nil
+}
+
+// readPartition читает до limit сообщений партиции с первого доступного смещения до текущего конца.
+func readPartition(ctx context.Context, cluster kafkacluster.Cluster, topic string, partition, limit int) ([]DeadLetter, error) {
+	leader, err := cluster.Dialer().DialLeader(ctx, "tcp", cluster.Brokers[0], topic, partition)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to partition %d: %w", partition, err)
+	}
+	first, last, err := leader.ReadOffsets()
+	leader.Close()
+	if err != nil {
+		return nil, fmt.Errorf("error reading offsets of partition %d: %w", partition, err)
+	}
+
+	deadLetters := make([]DeadLetter, 0)
+	if first >= last {
+		return deadLetters, nil
+	}
+	reader := kafka.NewReader(kafka.ReaderConfig{Brokers: cluster.Brokers, Dialer: cluster.Dialer(), Topic: topic, Partition: partition})
+	defer reader.Close()
+	err = reader.SetOffset(first)
+	if err != nil {
+		return nil, fmt.Errorf("error seeking partition %d: %w", partition, err)
+	}
+	for len(deadLetters) < limit {
+		message, err := reader.ReadMessage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error reading partition %d: %w", partition, err)
+		}
+		deadLetters = append(deadLetters, parseDeadLetter(&message))
+		if message.Offset >= last-1 {
+			break
+		}
+	}
+	return deadLetters, nil
+}
+
+// ReplayDeadLetters переотправляет до limit недоставленных сообщений из топика deadLetterTopic
+// в топик targetTopic и возвращает их число. Переотправленные сообщения фиксируются
+// в группе потребителей groupID и повторно не отправляются. Если задан resigner, сообщения
+// с устаревшей подписью подписываются заново, а сообщения без действительной подписи
+// пропускаются: они фиксируются в группе, не отправляются и записываются в журнал.
+// Переотправка заканчивается, если новых сообщений нет дольше idle.
+func ReplayDeadLetters(ctx context.Context, cluster kafkacluster.Cluster, deadLetterTopic, targetTopic, groupID string,
+	resigner *signing.Resigner, limit int, idle time.Duration) (int, error) {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     cluster.Brokers,
+		Dialer:      cluster.Dialer(),
+		Topic:       deadLetterTopic,
+		GroupID:     groupID,
+		StartOffset: kafka.FirstOffset,
+	})
+	defer reader.Close()
+	writer := cluster.Writer(targetTopic)
+	defer writer.Close()
+
+	replayed := 0
+	for replayed < limit {
+		fetchCtx, cancel := context.WithTimeout(ctx, idle)
+		message, err := reader.FetchMessage(fetchCtx)
+		cancel()
+		if errors.Is(err, context.DeadlineExceeded) {
+			break
+		}
+		if err != nil {
+			return replayed, fmt.Errorf("error reading dead letter: %w", err)
+		}
+		replay, resignErr := replayMessage(&message, resigner)
+		if resignErr != nil {
+			log.Printf("dead letters: %d/%d skipped: %v", message.Partition, message.Offset, resignErr)
+		} else {
+			err = writer.WriteMessages(ctx, replay)
+			if err != nil {
+				return replayed, fmt.Errorf("error replaying dead letter %d/%d: %w", message.Partition, message.Offset, err)
+			}
+		}
+		err = reader.CommitMessages(ctx, message)
+		if err != nil {
+			return replayed, fmt.Errorf("error committing dead letter %d/%d: %w", message.Partition, message.Offset, err)
+		}
+		if resignErr == nil {
+			replayed++
+		}
+	}
+	return replayed, nil
+}
+
+// headerValue возвращает значение заголовка key сообщения
+// или пустую строку, если заголовка нет.
+func headerValue(message *kafka.Message, key string) string {
+	for _, header := range message.Headers {
+		if header.Key == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
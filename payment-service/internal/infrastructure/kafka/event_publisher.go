@@ -0,0 +1,59 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/segmentio/kafka-go"
+	"kafkacluster"
+	"payment-service/internal/domain"
+	"strconv"
+)
+
+// EventTypeHeader — заголовок сообщения Kafka с типом доменного события.
+const EventTypeHeader = "event-type"
+
+// EventPublisher публикует доменные события счетов в топик Kafka.
+// Ключ сообщения — ID счёта, поэтому события одного счёта попадают в одну партицию
+// и читаются потребителями в порядке публикации.
+type EventPublisher struct {
+	writer *kafka.Writer
+}
+
+// NewEventPublisher создаёт новый EventPublisher для кластера cluster с заданным топиком.
+func NewEventPublisher(cluster kafkacluster.Cluster, topic string) *EventPublisher {
+	writer := cluster.Writer(topic)
+	writer.Balancer = &kafka.Hash{}
+	return &EventPublisher{writer: writer}
+}
+
+// Publish публикует события одним пакетом в переданном порядке.
+// Возвращает ошибку, если событие не удалось сериализовать или записать.
+func (p *EventPublisher) Publish(ctx context.Context, events []domain.AccountEvent) error {
+	messages := make([]kafka.Message, 0, len(events))
+	for _, event := range events {
+		value, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("error encoding event %d: %w", event.Id, err)
+		}
+		messages = append(messages, kafka.Message{
+			Key:     []byte(strconv.Itoa(event.AccountId)),
+			Value:   value,
+			Headers: []kafka.Header{{Key: EventTypeHeader, Value: []byte(event.Type)}},
+		})
+	}
+	err := p.writer.WriteMessages(ctx, messages...)
+	if err != nil {
+		return fmt.Errorf("error writing events: %w", err)
+	}
+	return nil
+}
+
+// Close закрывает Kafka writer и освобождает ресурсы.
+func (p *EventPublisher) Close() error {
+	err := p.writer.Close()
+	if err != nil {
+		return fmt.Errorf("closing writer: %w", err)
+	}
+	return nil
+}
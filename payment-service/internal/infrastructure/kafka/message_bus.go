@@ -2,74 +2,203 @@ package kafka
 
 import (
 	"context"
-	"errors"
+	"contracts/transport"
 	"github.com/segmentio/kafka-go"
+	"kafkacluster"
 	"log"
+	"strings"
+	"time"
 )
 
+// messageReader читает сообщения из топика и фиксирует смещения; реализуется Consumer.
+type messageReader interface {
+	FetchMessage(ctx context.Context) (*kafka.Message, error)
+	CommitMessage(ctx context.Context, message *kafka.Message) error
+	Close() error
+}
+
+// messageWriter отправляет сообщения в топик; реализуется Producer.
+type messageWriter interface {
+	SendMessage(ctx context.Context, message *kafka.Message) error
+	Close() error
+}
+
 // MessageBus объединяет Kafka consumer и producer, обеспечивая двустороннюю обработку сообщений.
+// Реализует messaging.MessageBus.
+// Сообщения, обработка которых не удалась после всех попыток, переносятся в топик недоставленных сообщений.
+//
+// Смещение группы фиксируется только после обработки сообщения и отправки ответа,
+// поэтому после сбоя необработанные сообщения будут прочитаны повторно (доставка не менее одного раза).
+//
+// Ответ отправляется в топик из заголовка запроса transport.ReplyToHeader, чтобы его получил
+// экземпляр отправителя, который ожидает ответ. Допускаются только топик ответов
+// и топики с его именем в качестве префикса ("<топик ответов>.<экземпляр>");
+// запрос без заголовка или с другим топиком получает ответ в топик ответов.
 type MessageBus struct {
-	consumer *Consumer
-	producer *Producer
+	consumer    messageReader
+	producer    messageWriter
+	replyTopic  string // Топик ответов по умолчанию
+	deadLetters messageWriter
+	offsets     *offsetTracker
+	retryPolicy RetryPolicy
+	workers     int // Число параллельных обработчиков сообщений
+	queueSize   int // Длина очереди сообщений каждого обработчика
+	// Метки времени сообщений ставит брокер (LogAppendTime); иначе временем записи
+	// сообщения считается время его получения.
+	brokerTimestamps bool
 }
 
-// NewMessageBus создаёт новый экземпляр MessageBus с указанными топиками и groupID.
-func NewMessageBus(brokers []string, consumerTopic, producerTopic, groupID string) *MessageBus {
+// NewMessageBus создаёт новый экземпляр MessageBus для кластера cluster с указанными топиками, groupID, политикой повторов
+// и пулом из workers обработчиков с очередями длины queueSize.
+// brokerTimestamps сообщает, что метки времени сообщений в consumer-топике ставит брокер
+// (kafkacluster.Topics.LogAppendTime): только тогда обработчик получает их как время записи
+// сообщения (transport.Message.Time), иначе — время получения сообщения.
+func NewMessageBus(cluster kafkacluster.Cluster, consumerTopic, producerTopic, deadLetterTopic, groupID string,
+	retryPolicy RetryPolicy, workers, queueSize int, brokerTimestamps bool) *MessageBus {
 	return &MessageBus{
-		consumer: NewConsumer(brokers, consumerTopic, groupID),
-		producer: NewProducer(brokers, producerTopic),
+		consumer:         NewConsumer(cluster, consumerTopic, groupID),
+		producer:         NewProducer(cluster, producerTopic),
+		replyTopic:       producerTopic,
+		deadLetters:      NewProducer(cluster, deadLetterTopic),
+		offsets:          newOffsetTracker(),
+		retryPolicy:      retryPolicy,
+		workers:          workers,
+		queueSize:        queueSize,
+		brokerTimestamps: brokerTimestamps,
 	}
 }
 
-// Start запускает бесконечный цикл чтения сообщений из consumer-топика,
+// Start читает сообщения из consumer-топика, пока не будет отменён контекст,
 // передаёт каждое сообщение обработчику handler,
 // и отправляет результат обратно через producer.
 //
-// Обработка сообщений выполняется асинхронно (в отдельных горутинах).
-func (mb *MessageBus) Start(ctx context.Context, handler func(ctx context.Context, message *kafka.Message) (*kafka.Message, error)) {
+// Сообщения обрабатываются пулом обработчиков: сообщения с одним ключом — по порядку,
+// с разными ключами — параллельно. Когда очереди заполнены, чтение приостанавливается.
+// После отмены контекста Start дожидается обработки уже прочитанных сообщений и возвращается.
+func (mb *MessageBus) Start(ctx context.Context, handler transport.Handler) {
+	pool := newWorkerPool(mb.workers, mb.queueSize)
 	for {
-		select {
-		case <-ctx.Done():
-			err := mb.consumer.Close()
-			if err != nil {
-				log.Printf("Error closing consumer: %s\n", err)
-			}
-			err = mb.producer.Close()
-			if err != nil {
-				log.Printf("Error closing producer: %s\n", err)
-			}
-			return
-		default:
-		}
-
-		m, err := mb.consumer.ReadMessage(ctx)
+		m, err := mb.consumer.FetchMessage(ctx)
 		if err != nil {
-			if errors.Is(err, context.Canceled) {
+			if ctx.Err() != nil {
 				break
 			}
 			log.Printf("Error reading message: %s\n", err)
 			continue
 		}
+		if !mb.brokerTimestamps {
+			// Метку времени поставил отправитель, и ей нельзя доверять.
+			m.Time = time.Now()
+		}
 
-		go func() {
-			response, err := handler(ctx, m)
-			if err != nil {
-				log.Printf("Error processing message: %s\n", err)
-				return
-			}
-			err = mb.producer.SendMessage(ctx, response)
-			if err != nil {
-				log.Printf("Error sending message: %s", err)
-			}
-		}()
+		mb.offsets.track(m)
+		pool.submit(m.Key, func() { mb.handle(ctx, handler, m) })
 	}
 
-	err := mb.producer.Close()
+	pool.stop()
+	mb.close()
+}
+
+// handle обрабатывает сообщение m и фиксирует смещение, если это позволяют
+// более ранние сообщения партиции. Если сообщение не обработано, смещение не фиксируется,
+// и после перезапуска сообщение будет прочитано повторно.
+//
+// Контекст ctx отменяется при остановке шины: начатая обработка, отправка ответа и фиксация
+// смещения завершаются, а паузы между повторами прерываются.
+func (mb *MessageBus) handle(ctx context.Context, handler transport.Handler, m *kafka.Message) {
+	if !mb.process(ctx, handler, m) {
+		return
+	}
+	commit := mb.offsets.complete(m)
+	if commit == nil {
+		return
+	}
+	err := mb.consumer.CommitMessage(context.WithoutCancel(ctx), commit)
 	if err != nil {
-		log.Printf("Error closing producer: %s\n", err)
+		log.Printf("Error committing offset: %s\n", err)
+	}
+}
+
+// process обрабатывает сообщение m с повторами по политике retryPolicy и отправляет ответ.
+// Если обработка так и не удалась, сообщение переносится в топик недоставленных сообщений
+// с причиной сбоя, а отправителю уходит ответ с описанием ошибки, если обработчик его вернул.
+// Сбой отправки ответа или недоставленного сообщения не повторяет обработку: повторяется только отправка (send).
+// Если ctx отменён во время паузы между попытками, повторы прекращаются и возвращается false.
+// Возвращает true, если сообщение обработано или перенесено и ответ отправлен.
+func (mb *MessageBus) process(ctx context.Context, handler transport.Handler, m *kafka.Message) bool {
+	processCtx := context.WithoutCancel(ctx)
+	var response *transport.Message
+	var err error
+	attempt := 1
+	for ; ; attempt++ {
+		response, err = handler(processCtx, kafkacluster.FromKafka(m))
+		if err == nil || !mb.retryPolicy.retryable(err, attempt) {
+			break
+		}
+		log.Printf("Error processing message (attempt %d): %s\n", attempt, err)
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(mb.retryPolicy.backoff(attempt)):
+		}
 	}
-	err = mb.consumer.Close()
+	if err != nil {
+		log.Printf("Error processing message, moving to dead letters: %s\n", err)
+		if !mb.send(ctx, mb.deadLetters, newDeadLetterMessage(m, err, attempt, time.Now())) {
+			return false
+		}
+	}
+	if response == nil {
+		return true
+	}
+	reply := kafkacluster.ToKafka(response)
+	reply.Topic = mb.replyDestination(m)
+	return mb.send(ctx, mb.producer, reply)
+}
+
+// send отправляет сообщение message через writer, повторяя отправку с паузами
+// по политике retryPolicy, пока она не удастся. Сообщение без отправленного ответа
+// нельзя отметить обработанным, а без этого не фиксируются и смещения после него,
+// поэтому отправка не прекращается, пока шина работает: при недоступном брокере
+// обработчик ждёт, а чтение новых сообщений приостанавливается, когда заполнятся очереди.
+// Возвращает false, если ctx отменён раньше, чем сообщение удалось отправить.
+func (mb *MessageBus) send(ctx context.Context, writer messageWriter, message *kafka.Message) bool {
+	for attempt := 1; ; attempt++ {
+		err := writer.SendMessage(context.WithoutCancel(ctx), message)
+		if err == nil {
+			return true
+		}
+		log.Printf("Error sending message (attempt %d): %s\n", attempt, err)
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(mb.retryPolicy.backoff(attempt)):
+		}
+	}
+}
+
+// replyDestination возвращает топик для ответа на запрос m.
+func (mb *MessageBus) replyDestination(m *kafka.Message) string {
+	replyTo := headerValue(m, transport.ReplyToHeader)
+	if replyTo == mb.replyTopic || strings.HasPrefix(replyTo, mb.replyTopic+".") {
+		return replyTo
+	}
+	if replyTo != "" {
+		log.Printf("Ignoring reply-to topic %q outside of %q", replyTo, mb.replyTopic)
+	}
+	return mb.replyTopic
+}
+
+// close закрывает consumer и producers.
+func (mb *MessageBus) close() {
+	err := mb.consumer.Close()
 	if err != nil {
 		log.Printf("Error closing consumer: %s\n", err)
 	}
+	for _, producer := range []messageWriter{mb.producer, mb.deadLetters} {
+		err = producer.Close()
+		if err != nil {
+			log.Printf("Error closing producer: %s\n", err)
+		}
+	}
 }
@@ -0,0 +1,426 @@
+package kafka
+
+import (
+	"context"
+	"contracts/signing"
+	"contracts/transport"
+	"errors"
+	"kafkacluster"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+type fakeWriter struct {
+	messages []kafka.Message
+}
+
+func (w *fakeWriter) SendMessage(ctx context.Context, message *kafka.Message) error {
+	w.messages = append(w.messages, *message)
+	return nil
+}
+
+func (w *fakeWriter) Close() error {
+	return nil
+}
+
+type fakeReader struct {
+	commits []kafka.Message
+}
+
+func (r *fakeReader) FetchMessage(ctx context.Context) (*kafka.Message, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (r *fakeReader) CommitMessage(ctx context.Context, message *kafka.Message) error {
+	r.commits = append(r.commits, *message)
+	return nil
+}
+
+func (r *fakeReader) Close() error {
+	return nil
+}
+
+type failingWriter struct{}
+
+func (w failingWriter) SendMessage(ctx context.Context, message *kafka.Message) error {
+	return errors.New("broker unavailable")
+}
+
+func (w failingWriter) Close() error {
+	return nil
+}
+
+var errPermanent = errors.New("permanent")
+
+func newTestBus() (*MessageBus, *fakeWriter, *fakeWriter) {
+	replies, deadLetters := &fakeWriter{}, &fakeWriter{}
+	return &MessageBus{
+		consumer:    &fakeReader{},
+		producer:    replies,
+		replyTopic:  "response",
+		deadLetters: deadLetters,
+		offsets:     newOffsetTracker(),
+		retryPolicy: RetryPolicy{
+			MaxAttempts:    3,
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     time.Millisecond,
+			Retryable:      func(err error) bool { return !errors.Is(err, errPermanent) },
+		},
+	}, replies, deadLetters
+}
+
+func TestMessageBus_ProcessRetriesTransientErrors(t *testing.T) {
+	mb, replies, deadLetters := newTestBus()
+	calls := 0
+	handler := func(ctx context.Context, message *transport.Message) (*transport.Message, error) {
+		calls++
+		if calls < 3 {
+			return &transport.Message{Value: []byte("error")}, errors.New("database unavailable")
+		}
+		return &transport.Message{Value: []byte("OK")}, nil
+	}
+
+	mb.process(context.Background(), handler, &kafka.Message{Topic: "request", Value: []byte("{}")})
+	if calls != 3 {
+		t.Errorf("expected 3 attempts, got %d", calls)
+	}
+	if len(replies.messages) != 1 || string(replies.messages[0].Value) != "OK" {
+		t.Errorf("expected single OK reply, got %+v", replies.messages)
+	}
+	if len(deadLetters.messages) != 0 {
+		t.Errorf("expected no dead letters, got %d", len(deadLetters.messages))
+	}
+}
+
+func TestMessageBus_ProcessMovesFailuresToDeadLetters(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		wantAttempts int
+	}{
+		{name: "transient error exhausts attempts", err: errors.New("database unavailable"), wantAttempts: 3},
+		{name: "permanent error is not retried", err: errPermanent, wantAttempts: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mb, replies, deadLetters := newTestBus()
+			calls := 0
+			handler := func(ctx context.Context, message *transport.Message) (*transport.Message, error) {
+				calls++
+				return &transport.Message{Value: []byte("Error processing transaction")}, tt.err
+			}
+			message := &kafka.Message{Topic: "request", Key: []byte("10"), Value: []byte("{}"),
+				Headers: []kafka.Header{{Key: "correlation-id", Value: []byte("abc")}}}
+
+			mb.process(context.Background(), handler, message)
+			if calls != tt.wantAttempts {
+				t.Errorf("expected %d attempts, got %d", tt.wantAttempts, calls)
+			}
+			if len(replies.messages) != 1 {
+				t.Errorf("expected error reply to be sent, got %d replies", len(replies.messages))
+			}
+			if len(deadLetters.messages) != 1 {
+				t.Fatalf("expected one dead letter, got %d", len(deadLetters.messages))
+			}
+			deadLetter := parseDeadLetter(&deadLetters.messages[0])
+			if deadLetter.Error != tt.err.Error() || deadLetter.Attempts != tt.wantAttempts ||
+				deadLetter.OriginalTopic != "request" || deadLetter.Key != "10" || deadLetter.FailedAt.IsZero() {
+				t.Errorf("unexpected dead letter: %+v", deadLetter)
+			}
+			replay, _ := replayMessage(&deadLetters.messages[0], nil)
+			if len(replay.Headers) != 1 || replay.Headers[0].Key != "correlation-id" || string(replay.Value) != "{}" {
+				t.Errorf("unexpected replay message: %+v", replay)
+			}
+		})
+	}
+}
+
+func TestReplayMessage_Resign(t *testing.T) {
+	key := signing.Key{Id: "k1", Algorithm: signing.HMACSHA256, Material: []byte("0123456789abcdef0123456789abcdef")}
+	operator := signing.Key{Id: "operator", Algorithm: signing.HMACSHA256, Material: []byte("fedcba9876543210fedcba9876543210")}
+	maxAge := 50 * time.Millisecond
+	verifier := signing.NewVerifier([]signing.Key{key, operator}, maxAge)
+	resigner := signing.NewResigner(signing.NewSigner(operator), verifier)
+	newDeadLetter := func(request *transport.Message) *kafka.Message {
+		return newDeadLetterMessage(kafkacluster.ToKafka(request), errors.New("database unavailable"), 3, time.Now())
+	}
+	request := &transport.Message{Key: []byte("10"), Value: []byte("{}"), Headers: map[string]string{"correlation-id": "abc"}}
+	signing.NewSigner(key).Sign(request)
+	deadLetter := newDeadLetter(request)
+	forged := &transport.Message{Key: []byte("10"), Value: []byte("{}"), Headers: map[string]string{"correlation-id": "def"}}
+	signing.NewSigner(key).Sign(forged)
+	forged.Value = []byte(`{"amount":1000}`)
+	forgedDeadLetter := newDeadLetter(forged)
+	unsignedDeadLetter := newDeadLetter(&transport.Message{Key: []byte("10"), Value: []byte("{}")})
+	time.Sleep(2 * maxAge)
+
+	// Переотправленное сообщение записывается заново, позже maxAge после подписи.
+	replay, _ := replayMessage(deadLetter, nil)
+	err := verifier.Verify(kafkacluster.FromKafka(&replay))
+	if !errors.Is(err, signing.ErrStale) {
+		t.Errorf("expected ErrStale for replay with the original signature, got %v", err)
+	}
+	replay, err = replayMessage(deadLetter, resigner)
+	if err != nil {
+		t.Fatalf("expected stale dead letter to be re-signed, got %v", err)
+	}
+	err = verifier.Verify(kafkacluster.FromKafka(&replay))
+	if err != nil {
+		t.Errorf("expected re-signed replay to be accepted, got %v", err)
+	}
+	if headerValue(&replay, "correlation-id") != "abc" || headerValue(&replay, DeadLetterErrorHeader) != "" {
+		t.Errorf("unexpected replay headers: %+v", replay.Headers)
+	}
+
+	// Поддельные и неподписанные сообщения заново не подписываются.
+	_, err = replayMessage(forgedDeadLetter, resigner)
+	if !errors.Is(err, signing.ErrInvalidSignature) {
+		t.Errorf("expected forged dead letter to be refused, got %v", err)
+	}
+	_, err = replayMessage(unsignedDeadLetter, resigner)
+	if !errors.Is(err, signing.ErrUnsigned) {
+		t.Errorf("expected unsigned dead letter to be refused, got %v", err)
+	}
+}
+func TestMessageBus_ProcessRepliesToSender(t *testing.T) {
+	tests := []struct {
+		name    string
+		replyTo string
+		want    string
+	}{
+		{name: "no reply-to", replyTo: "", want: "response"},
+		{name: "instance reply topic", replyTo: "response.order-2", want: "response.order-2"},
+		{name: "default reply topic", replyTo: "response", want: "response"},
+		{name: "foreign topic", replyTo: "account-events", want: "response"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mb, replies, _ := newTestBus()
+			handler := func(ctx context.Context, message *transport.Message) (*transport.Message, error) {
+				return &transport.Message{Value: []byte("OK")}, nil
+			}
+			message := &kafka.Message{Topic: "request", Value: []byte("{}")}
+			if tt.replyTo != "" {
+				message.Headers = []kafka.Header{{Key: transport.ReplyToHeader, Value: []byte(tt.replyTo)}}
+			}
+
+			mb.process(context.Background(), handler, message)
+			if len(replies.messages) != 1 || replies.messages[0].Topic != tt.want {
+				t.Errorf("expected reply to %s, got %+v", tt.want, replies.messages)
+			}
+		})
+	}
+}
+
+func TestMessageBus_ProcessStopsRetriesOnShutdown(t *testing.T) {
+	mb, replies, deadLetters := newTestBus()
+	mb.retryPolicy.InitialBackoff, mb.retryPolicy.MaxBackoff = time.Hour, time.Hour
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	handler := func(handlerCtx context.Context, message *transport.Message) (*transport.Message, error) {
+		calls++
+		cancel()
+		if handlerCtx.Err() != nil {
+			t.Error("expected handler context to survive shutdown")
+		}
+		return nil, errors.New("database unavailable")
+	}
+
+	done := make(chan bool)
+	go func() { done <- mb.process(ctx, handler, &kafka.Message{Topic: "request", Value: []byte("{}")}) }()
+	select {
+	case processed := <-done:
+		if processed {
+			t.Error("expected message to stay unprocessed")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected retry backoff to stop on shutdown")
+	}
+	if calls != 1 || len(replies.messages) != 0 || len(deadLetters.messages) != 0 {
+		t.Errorf("expected a single attempt without replies or dead letters, got %d attempts", calls)
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, InitialBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond, 300 * time.Millisecond}
+	for i, expected := range want {
+		if got := policy.backoff(i + 1); got != expected {
+			t.Errorf("attempt %d: expected %s, got %s", i+1, expected, got)
+		}
+	}
+	if policy.retryable(errors.New("boom"), 5) {
+		t.Error("expected no retry after the last attempt")
+	}
+}
+
+func TestMessageBus_HandleCommitsOnlyProcessedPrefix(t *testing.T) {
+	ctx := context.Background()
+	mb, _, _ := newTestBus()
+	reader := mb.consumer.(*fakeReader)
+	ok := func(ctx context.Context, message *transport.Message) (*transport.Message, error) {
+		return &transport.Message{Value: []byte("OK")}, nil
+	}
+	messages := make([]*kafka.Message, 3)
+	for i := range messages {
+		messages[i] = &kafka.Message{Topic: "request", Partition: 0, Offset: int64(10 + i)}
+		mb.offsets.track(messages[i])
+	}
+
+	mb.handle(ctx, ok, messages[1])
+	if len(reader.commits) != 0 {
+		t.Fatalf("expected no commit before earlier offset is processed, got %+v", reader.commits)
+	}
+	mb.handle(ctx, ok, messages[0])
+	if len(reader.commits) != 1 || reader.commits[0].Offset != 11 {
+		t.Fatalf("expected commit at offset 11, got %+v", reader.commits)
+	}
+
+	mb.producer = failingWriter{}
+	stopped, stop := context.WithCancel(ctx)
+	stop()
+	mb.handle(stopped, ok, messages[2])
+	if len(reader.commits) != 1 {
+		t.Errorf("expected no commit when reply was not sent before shutdown, got %+v", reader.commits)
+	}
+}
+
+// flakyWriter не может отправить первые failures сообщений.
+type flakyWriter struct {
+	fakeWriter
+	failures int
+}
+
+func (w *flakyWriter) SendMessage(ctx context.Context, message *kafka.Message) error {
+	if w.failures > 0 {
+		w.failures--
+		return errors.New("broker unavailable")
+	}
+	return w.fakeWriter.SendMessage(ctx, message)
+}
+
+func TestMessageBus_HandleRetriesDeadLetterSend(t *testing.T) {
+	ctx := context.Background()
+	mb, replies, _ := newTestBus()
+	reader := mb.consumer.(*fakeReader)
+	deadLetters := &flakyWriter{failures: 3}
+	mb.deadLetters = deadLetters
+	calls := 0
+	handler := func(ctx context.Context, message *transport.Message) (*transport.Message, error) {
+		calls++
+		if string(message.Value) == "bad" {
+			return &transport.Message{Value: []byte("error")}, errPermanent
+		}
+		return &transport.Message{Value: []byte("OK")}, nil
+	}
+	failed := &kafka.Message{Topic: "request", Partition: 0, Offset: 10, Value: []byte("bad")}
+	later := &kafka.Message{Topic: "request", Partition: 0, Offset: 11, Value: []byte("{}")}
+	mb.offsets.track(failed)
+	mb.offsets.track(later)
+
+	mb.handle(ctx, handler, failed)
+	mb.handle(ctx, handler, later)
+	if calls != 2 {
+		t.Errorf("expected each message to be handled once, got %d calls", calls)
+	}
+	if len(deadLetters.messages) != 1 || len(replies.messages) != 2 {
+		t.Errorf("expected one dead letter and two replies, got %d and %d", len(deadLetters.messages), len(replies.messages))
+	}
+	if len(reader.commits) != 2 || reader.commits[1].Offset != 11 {
+		t.Errorf("expected later offset 11 to be committed, got %+v", reader.commits)
+	}
+}
+
+func TestMessageBus_StartStopsWhenDeadLettersAreUnavailable(t *testing.T) {
+	mb, _, _ := newTestBus()
+	mb.workers, mb.queueSize = 1, 1
+	mb.deadLetters = failingWriter{}
+	reader := &queueReader{messages: []*kafka.Message{
+		{Topic: "request", Partition: 0, Offset: 10, Value: []byte("bad")},
+		{Topic: "request", Partition: 0, Offset: 11, Value: []byte("{}")},
+	}}
+	mb.consumer = reader
+	handler := func(ctx context.Context, message *transport.Message) (*transport.Message, error) {
+		if string(message.Value) == "bad" {
+			return nil, errPermanent
+		}
+		return &transport.Message{Value: []byte("OK")}, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		mb.Start(ctx, handler)
+		close(done)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected consumer to stop while dead letters are unavailable")
+	}
+	if len(reader.commits) != 0 {
+		t.Errorf("expected no offsets past the unsent dead letter to be committed, got %+v", reader.commits)
+	}
+}
+
+// queueReader возвращает сообщения messages по очереди, а затем ждёт отмены контекста.
+type queueReader struct {
+	fakeReader
+	mu       sync.Mutex
+	messages []*kafka.Message
+}
+
+func (r *queueReader) FetchMessage(ctx context.Context) (*kafka.Message, error) {
+	r.mu.Lock()
+	if len(r.messages) > 0 {
+		message := r.messages[0]
+		r.messages = r.messages[1:]
+		r.mu.Unlock()
+		return message, nil
+	}
+	r.mu.Unlock()
+	return r.fakeReader.FetchMessage(ctx)
+}
+
+func (r *queueReader) CommitMessage(ctx context.Context, message *kafka.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.fakeReader.CommitMessage(ctx, message)
+}
+
+func TestMessageBus_StartTrustsOnlyBrokerTimestamps(t *testing.T) {
+	for _, brokerTimestamps := range []bool{false, true} {
+		mb, _, _ := newTestBus()
+		mb.workers, mb.queueSize = 1, 1
+		mb.brokerTimestamps = brokerTimestamps
+		written := time.Now().Add(-time.Hour)
+		mb.consumer = &queueReader{messages: []*kafka.Message{{Topic: "request", Value: []byte("{}"), Time: written}}}
+		times := make(chan time.Time, 1)
+		handler := func(ctx context.Context, message *transport.Message) (*transport.Message, error) {
+			times <- message.Time
+			return nil, nil
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go mb.Start(ctx, handler)
+		var got time.Time
+		select {
+		case got = <-times:
+		case <-time.After(time.Second):
+			t.Fatal("expected message to be handled")
+		}
+		cancel()
+		if brokerTimestamps && !got.Equal(written) {
+			t.Errorf("expected broker timestamp %s, got %s", written, got)
+		}
+		if !brokerTimestamps && time.Since(got) > time.Minute {
+			t.Errorf("expected receive time instead of the producer timestamp, got %s", got)
+		}
+	}
+}
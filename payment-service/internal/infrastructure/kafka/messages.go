@@ -0,0 +1,147 @@
+package kafka
+
+import (
+	"context"
+	"contracts/transport"
+	"errors"
+	"fmt"
+	"github.com/segmentio/kafka-go"
+	"kafkacluster"
+	"sync"
+	"time"
+)
+
+// TopicMessage — сообщение топика вместе с его положением и временем записи.
+type TopicMessage struct {
+	Topic     string             // Топик сообщения
+	Partition int                // Партиция
+	Offset    int64              // Смещение в партиции
+	Time      time.Time          // Время записи сообщения в Kafka
+	Message   *transport.Message // Ключ, тело и заголовки сообщения
+}
+
+// MessageRange задаёт, какие сообщения топика читать. Нулевое время не ограничивает выборку.
+type MessageRange struct {
+	Since  time.Time // Сообщения, записанные не раньше этого времени
+	Until  time.Time // Сообщения, записанные не позже этого времени
+	Follow bool      // Продолжать читать новые сообщения после конца партиций
+}
+
+// ReadMessages читает сообщения топика topic из диапазона messageRange и передаёт каждое в fn.
+// Чтение не использует группы потребителей и не сдвигает их смещения.
+//
+// Партиции читаются параллельно, поэтому порядок сообщений сохраняется только внутри партиции;
+// fn вызывается из одной горутины. Без Follow чтение заканчивается на конце партиций
+// на момент вызова, с Follow — продолжается, пока не отменён контекст или не прочитаны
+// сообщения позже Until во всех партициях. Ошибка fn прекращает чтение и возвращается.
+func ReadMessages(ctx context.Context, cluster kafkacluster.Cluster, topic string, messageRange MessageRange,
+	fn func(message TopicMessage) error) error {
+	conn, err := cluster.Dialer().DialContext(ctx, "tcp", cluster.Brokers[0])
+	if err != nil {
+		return fmt.Errorf("error connecting to kafka: %w", err)
+	}
+	partitions, err := conn.ReadPartitions(topic)
+	conn.Close()
+	if err != nil {
+		return fmt.Errorf("error reading partitions: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	messages := make(chan TopicMessage)
+	errs := make(chan error, len(partitions))
+	var wg sync.WaitGroup
+	for _, partition := range partitions {
+		wg.Add(1)
+		go func(partition int) {
+			defer wg.Done()
+			errs <- readMessages(ctx, cluster, topic, partition, messageRange, messages)
+		}(partition.ID)
+	}
+	go func() {
+		wg.Wait()
+		close(messages)
+	}()
+
+	var fnErr error
+	for message := range messages {
+		if fnErr != nil {
+			continue
+		}
+		fnErr = fn(message)
+		if fnErr != nil {
+			cancel()
+		}
+	}
+	if fnErr != nil {
+		return fnErr
+	}
+	close(errs)
+	for err := range errs {
+		if err != nil && !errors.Is(err, context.Canceled) {
+			return err
+		}
+	}
+	return nil
+}
+
+// readMessages читает сообщения партиции partition из диапазона messageRange и отправляет их в messages.
+func readMessages(ctx context.Context, cluster kafkacluster.Cluster, topic string, partition int, messageRange MessageRange,
+	messages chan<- TopicMessage) error {
+	leader, err := cluster.Dialer().DialLeader(ctx, "tcp", cluster.Brokers[0], topic, partition)
+	if err != nil {
+		return fmt.Errorf("error connecting to partition %d: %w", partition, err)
+	}
+	first, last, err := leader.ReadOffsets()
+	start := first
+	if err == nil && !messageRange.Since.IsZero() {
+		start, err = leader.ReadOffset(messageRange.Since)
+		// Сообщений позже Since ещё нет.
+		if start < 0 {
+			start = last
+		}
+	}
+	leader.Close()
+	if err != nil {
+		return fmt.Errorf("error reading offsets of partition %d: %w", partition, err)
+	}
+	if !messageRange.Follow && start >= last {
+		return nil
+	}
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:   cluster.Brokers,
+		Dialer:    cluster.Dialer(),
+		Topic:     topic,
+		Partition: partition,
+	})
+	defer reader.Close()
+	err = reader.SetOffset(start)
+	if err != nil {
+		return fmt.Errorf("error seeking partition %d: %w", partition, err)
+	}
+	for {
+		message, err := reader.ReadMessage(ctx)
+		if err != nil {
+			return fmt.Errorf("error reading partition %d: %w", partition, err)
+		}
+		if !messageRange.Until.IsZero() && message.Time.After(messageRange.Until) {
+			return nil
+		}
+		topicMessage := TopicMessage{
+			Topic:     message.Topic,
+			Partition: message.Partition,
+			Offset:    message.Offset,
+			Time:      message.Time,
+			Message:   kafkacluster.FromKafka(&message),
+		}
+		select {
+		case messages <- topicMessage:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if !messageRange.Follow && message.Offset >= last-1 {
+			return nil
+		}
+	}
+}
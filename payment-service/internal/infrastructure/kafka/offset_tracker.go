@@ -0,0 +1,60 @@
+package kafka
+
+import (
+	"github.com/segmentio/kafka-go"
+	"sync"
+)
+
+// offsetTracker отслеживает обработку прочитанных сообщений по партициям.
+// Сообщения одной партиции могут обрабатываться не по порядку, а смещение группы
+// фиксирует все сообщения до него, поэтому фиксировать можно только смещение,
+// до которого обработаны все прочитанные сообщения партиции.
+type offsetTracker struct {
+	mu         sync.Mutex
+	partitions map[int]*partitionOffsets
+}
+
+// partitionOffsets — необработанные сообщения одной партиции.
+type partitionOffsets struct {
+	pending []int64        // Смещения прочитанных сообщений в порядке чтения
+	done    map[int64]bool // Обработанные смещения, ещё не вошедшие в фиксацию
+}
+
+// newOffsetTracker создаёт пустой offsetTracker.
+func newOffsetTracker() *offsetTracker {
+	return &offsetTracker{partitions: make(map[int]*partitionOffsets)}
+}
+
+// track регистрирует прочитанное сообщение. Вызывается в порядке чтения.
+func (t *offsetTracker) track(message *kafka.Message) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	partition, ok := t.partitions[message.Partition]
+	if !ok {
+		partition = &partitionOffsets{done: make(map[int64]bool)}
+		t.partitions[message.Partition] = partition
+	}
+	partition.pending = append(partition.pending, message.Offset)
+}
+
+// complete отмечает сообщение обработанным и возвращает сообщение, смещение после которого
+// можно зафиксировать, или nil, если в партиции остались более ранние необработанные сообщения.
+func (t *offsetTracker) complete(message *kafka.Message) *kafka.Message {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	partition, ok := t.partitions[message.Partition]
+	if !ok {
+		return nil
+	}
+	partition.done[message.Offset] = true
+	committable := int64(-1)
+	for len(partition.pending) > 0 && partition.done[partition.pending[0]] {
+		committable = partition.pending[0]
+		delete(partition.done, committable)
+		partition.pending = partition.pending[1:]
+	}
+	if committable < 0 {
+		return nil
+	}
+	return &kafka.Message{Topic: message.Topic, Partition: message.Partition, Offset: committable}
+}
@@ -2,33 +2,43 @@ package kafka
 
 import (
 	"context"
+	"contracts/transport"
 	"fmt"
 	"github.com/segmentio/kafka-go"
+	"kafkacluster"
 )
 
 // Producer - обёртка над kafka.Writer, обеспечивающая отправку сообщений в топик.
 type Producer struct {
 	writer *kafka.Writer
+	topic  string // Топик для сообщений, в которых топик не указан
 }
 
-// NewProducer создаёт нового Producer с заданными брокерами и топиком.
-func NewProducer(brokers []string, topic string) *Producer {
-	return &Producer{writer: &kafka.Writer{
-		Addr:  kafka.TCP(brokers...),
-		Topic: topic,
-	}}
+// NewProducer создаёт нового Producer для кластера cluster с заданным топиком по умолчанию.
+func NewProducer(cluster kafkacluster.Cluster, topic string) *Producer {
+	return &Producer{writer: cluster.Writer(""), topic: topic}
 }
 
-// SendMessage отправляет сообщение в Kafka.
+// SendMessage отправляет сообщение в Kafka: в топик message.Topic,
+// а если он не указан — в топик по умолчанию.
 // Возвращает ошибку при сбое записи.
 func (p *Producer) SendMessage(ctx context.Context, message *kafka.Message) error {
-	err := p.writer.WriteMessages(ctx, *message)
+	m := *message
+	if m.Topic == "" {
+		m.Topic = p.topic
+	}
+	err := p.writer.WriteMessages(ctx, m)
 	if err != nil {
 		return fmt.Errorf("error writing message: %w", err)
 	}
 	return nil
 }
 
+// Send отправляет сообщение транспорта message в топик по умолчанию.
+func (p *Producer) Send(ctx context.Context, message *transport.Message) error {
+	return p.SendMessage(ctx, kafkacluster.ToKafka(message))
+}
+
 // Close закрывает Kafka writer и освобождает ресурсы.
 func (p *Producer) Close() error {
 	err := p.writer.Close()
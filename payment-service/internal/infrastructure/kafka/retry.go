@@ -0,0 +1,36 @@
+package kafka
+
+import "time"
+
+// RetryPolicy задаёт повторную обработку сообщений, завершившихся ошибкой.
+type RetryPolicy struct {
+	MaxAttempts    int              // Число попыток обработки, включая первую
+	InitialBackoff time.Duration    // Пауза перед второй попыткой; далее удваивается
+	MaxBackoff     time.Duration    // Максимальная пауза между попытками
+	Retryable      func(error) bool // Определяет, стоит ли повторять обработку; nil — повторять любую ошибку
+}
+
+// DefaultRetryPolicy возвращает политику по умолчанию: три попытки с паузами 200 мс и 400 мс.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 3, InitialBackoff: 200 * time.Millisecond, MaxBackoff: 5 * time.Second}
+}
+
+// retryable сообщает, нужно ли повторить обработку после ошибки err на попытке attempt (с 1).
+func (p RetryPolicy) retryable(err error, attempt int) bool {
+	if attempt >= p.MaxAttempts {
+		return false
+	}
+	return p.Retryable == nil || p.Retryable(err)
+}
+
+// backoff возвращает паузу перед попыткой attempt+1 после неудачной попытки attempt (с 1).
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	pause := p.InitialBackoff
+	for i := 1; i < attempt && pause < p.MaxBackoff; i++ {
+		pause *= 2
+	}
+	if p.MaxBackoff > 0 && pause > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+	return pause
+}
@@ -0,0 +1,76 @@
+package kafka
+
+import (
+	"expvar"
+	"hash/fnv"
+	"sync"
+)
+
+// Метрики пула обработчиков, доступные через expvar (/debug/vars).
+var (
+	queuedJobs    = expvar.NewInt("kafka_worker_queued")     // Сообщения, ожидающие обработчика
+	busyWorkers   = expvar.NewInt("kafka_workers_busy")      // Обработчики, занятые сообщением
+	queueFullHits = expvar.NewInt("kafka_worker_queue_full") // Сколько раз чтение ждало из-за заполненной очереди
+)
+
+// workerPool — пул обработчиков фиксированного размера с очередью у каждого обработчика.
+// Задачи с одинаковым ключом попадают к одному обработчику и выполняются по порядку,
+// задачи с разными ключами выполняются параллельно. Если очередь обработчика заполнена,
+// submit блокируется, что останавливает чтение новых сообщений.
+type workerPool struct {
+	queues []chan func()
+	wg     sync.WaitGroup
+}
+
+// newWorkerPool запускает workers обработчиков с очередями длины queueSize.
+// Значения меньше 1 заменяются на 1 и 0 соответственно.
+func newWorkerPool(workers, queueSize int) *workerPool {
+	workers = max(workers, 1)
+	queueSize = max(queueSize, 0)
+	pool := &workerPool{queues: make([]chan func(), workers)}
+	for i := range pool.queues {
+		pool.queues[i] = make(chan func(), queueSize)
+		pool.wg.Add(1)
+		go pool.run(pool.queues[i])
+	}
+	return pool
+}
+
+// run выполняет задачи очереди queue, пока она не будет закрыта и опустошена.
+func (p *workerPool) run(queue chan func()) {
+	defer p.wg.Done()
+	for job := range queue {
+		queuedJobs.Add(-1)
+		busyWorkers.Add(1)
+		job()
+		busyWorkers.Add(-1)
+	}
+}
+
+// submit ставит задачу job в очередь обработчика, выбранного по ключу key.
+// Блокируется, пока в очереди нет места.
+func (p *workerPool) submit(key []byte, job func()) {
+	queue := p.queues[p.queueOf(key)]
+	queuedJobs.Add(1)
+	select {
+	case queue <- job:
+	default:
+		queueFullHits.Add(1)
+		queue <- job
+	}
+}
+
+// queueOf возвращает номер обработчика для ключа key.
+func (p *workerPool) queueOf(key []byte) int {
+	hash := fnv.New32a()
+	_, _ = hash.Write(key)
+	return int(hash.Sum32() % uint32(len(p.queues)))
+}
+
+// stop перестаёт принимать задачи и ждёт выполнения уже поставленных в очередь.
+func (p *workerPool) stop() {
+	for _, queue := range p.queues {
+		close(queue)
+	}
+	p.wg.Wait()
+}
@@ -0,0 +1,68 @@
+package kafka
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWorkerPool_KeepsOrderPerKey(t *testing.T) {
+	pool := newWorkerPool(4, 2)
+	var mu sync.Mutex
+	processed := make(map[string][]int)
+	for i := 0; i < 50; i++ {
+		for _, key := range []string{"1", "2", "3"} {
+			pool.submit([]byte(key), func() {
+				mu.Lock()
+				defer mu.Unlock()
+				processed[key] = append(processed[key], i)
+			})
+		}
+	}
+	pool.stop()
+
+	for key, order := range processed {
+		if len(order) != 50 {
+			t.Fatalf("key %s: expected 50 jobs after stop, got %d", key, len(order))
+		}
+		for i, value := range order {
+			if value != i {
+				t.Fatalf("key %s: jobs out of order: %v", key, order)
+			}
+		}
+	}
+}
+
+func TestWorkerPool_RunsDifferentKeysInParallel(t *testing.T) {
+	pool := newWorkerPool(8, 0)
+	defer pool.stop()
+	release := make(chan struct{})
+	started := make(chan string, 2)
+	blocking := func(key string) func() {
+		return func() {
+			started <- key
+			<-release
+		}
+	}
+
+	// Второй ключ выбирается так, чтобы попасть к другому обработчику.
+	keys := []string{"a", "b", "c", "d"}
+	first := keys[0]
+	second := ""
+	for _, key := range keys[1:] {
+		if pool.queueOf([]byte(key)) != pool.queueOf([]byte(first)) {
+			second = key
+			break
+		}
+	}
+	pool.submit([]byte(first), blocking(first))
+	pool.submit([]byte(second), blocking(second))
+	for range 2 {
+		select {
+		case <-started:
+		case <-time.After(time.Second):
+			t.Fatal("jobs with different keys did not run in parallel")
+		}
+	}
+	close(release)
+}
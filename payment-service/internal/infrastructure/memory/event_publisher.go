@@ -0,0 +1,44 @@
+package memory
+
+import (
+	"context"
+	"contracts/transport"
+	"encoding/json"
+	"fmt"
+	"payment-service/internal/domain"
+	"strconv"
+)
+
+// EventTypeHeader — заголовок сообщения с типом доменного события.
+const EventTypeHeader = "event-type"
+
+// EventPublisher публикует доменные события счетов в топик брокера в памяти процесса
+// в том же формате, что и публикатор Kafka: ключ — ID счёта, тело — событие в JSON.
+type EventPublisher struct {
+	broker *transport.InMemory
+	topic  string
+}
+
+// NewEventPublisher создаёт новый EventPublisher для топика topic брокера broker.
+func NewEventPublisher(broker *transport.InMemory, topic string) *EventPublisher {
+	return &EventPublisher{broker: broker, topic: topic}
+}
+
+// Publish публикует события в переданном порядке.
+// Возвращает ошибку, если событие не удалось сериализовать; тогда не публикуется ни одно событие.
+func (p *EventPublisher) Publish(ctx context.Context, events []domain.AccountEvent) error {
+	messages := make([]transport.Message, 0, len(events))
+	for _, event := range events {
+		value, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("error encoding event %d: %w", event.Id, err)
+		}
+		messages = append(messages, transport.Message{
+			Key:     []byte(strconv.Itoa(event.AccountId)),
+			Value:   value,
+			Headers: map[string]string{EventTypeHeader: string(event.Type)},
+		})
+	}
+	p.broker.Publish(p.topic, messages...)
+	return nil
+}
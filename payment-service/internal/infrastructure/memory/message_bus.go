@@ -0,0 +1,40 @@
+package memory
+
+import (
+	"context"
+	"contracts/transport"
+	"log"
+)
+
+// MessageBus получает запросы через брокер в памяти процесса и реализует messaging.MessageBus.
+// Используется для запуска сервисов в одном процессе без Kafka.
+//
+// Запросы обрабатываются без повторов: если обработчик вернул ошибку вместе с ответом,
+// ошибка записывается в лог, а отправителю уходит ответ.
+type MessageBus struct {
+	broker *transport.InMemory
+	topic  string // Топик запросов
+}
+
+// NewMessageBus создаёт новый экземпляр MessageBus, получающий запросы топика topic брокера broker.
+func NewMessageBus(broker *transport.InMemory, topic string) *MessageBus {
+	return &MessageBus{broker: broker, topic: topic}
+}
+
+// Start передаёт запросы обработчику handler, пока не будет отменён контекст,
+// затем дожидается обработки уже полученных запросов.
+func (mb *MessageBus) Start(ctx context.Context, handler transport.Handler) {
+	err := mb.broker.Serve(ctx, mb.topic, func(ctx context.Context, message *transport.Message) (*transport.Message, error) {
+		response, err := handler(ctx, message)
+		if err != nil {
+			log.Printf("Error processing message: %s\n", err)
+			if response == nil {
+				return nil, err
+			}
+		}
+		return response, nil
+	})
+	if err != nil {
+		log.Printf("Error serving messages: %s\n", err)
+	}
+}
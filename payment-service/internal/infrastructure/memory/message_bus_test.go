@@ -0,0 +1,67 @@
+package memory
+
+import (
+	"context"
+	"contracts/transport"
+	"errors"
+	"payment-service/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestMessageBus_RepliesThroughBroker(t *testing.T) {
+	broker := transport.NewInMemory()
+	mb := NewMessageBus(broker, "request")
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		mb.Start(ctx, func(ctx context.Context, message *transport.Message) (*transport.Message, error) {
+			if string(message.Value) == "fail" {
+				return &transport.Message{Value: []byte("Error processing transaction")}, errors.New("database unavailable")
+			}
+			if string(message.Value) == "drop" {
+				return nil, errors.New("database unavailable")
+			}
+			return &transport.Message{Value: []byte("OK")}, nil
+		})
+		close(done)
+	}()
+
+	reply, err := broker.Request(ctx, "request", &transport.Message{Value: []byte("pay")})
+	if err != nil || string(reply.Value) != "OK" {
+		t.Errorf("expected OK reply, got %+v, %v", reply, err)
+	}
+	reply, err = broker.Request(ctx, "request", &transport.Message{Value: []byte("fail")})
+	if err != nil || string(reply.Value) != "Error processing transaction" {
+		t.Errorf("expected error reply without error, got %+v, %v", reply, err)
+	}
+	_, err = broker.Request(ctx, "request", &transport.Message{Value: []byte("drop")})
+	if err == nil {
+		t.Error("expected error when handler returned no reply")
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Start did not return after cancel")
+	}
+}
+
+func TestEventPublisher_Publish(t *testing.T) {
+	broker := transport.NewInMemory()
+	publisher := NewEventPublisher(broker, "account-events")
+	events := []domain.AccountEvent{
+		{Id: 1, AccountId: 5, Type: domain.AccountEventCreated},
+		{Id: 2, AccountId: 5, Type: domain.AccountEventCredited},
+	}
+	err := publisher.Publish(context.Background(), events)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	messages := broker.Messages("account-events")
+	if len(messages) != 2 || string(messages[0].Key) != "5" ||
+		messages[1].Header(EventTypeHeader) != string(domain.AccountEventCredited) {
+		t.Errorf("unexpected messages: %+v", messages)
+	}
+}
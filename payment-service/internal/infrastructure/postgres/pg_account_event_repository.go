@@ -0,0 +1,74 @@
+package postgres
+
+import (
+	"context"
+	"payment-service/internal/application/repository"
+	"payment-service/internal/domain"
+	"time"
+)
+
+// AccountEventDb реализует интерфейс repository.AccountEventRepository
+// и работает с таблицей account_events в PostgreSQL.
+type AccountEventDb struct {
+	db PgxPool
+}
+
+// NewAccountEventDb создаёт новый экземпляр AccountEventDb,
+// принимая пул подключений к PostgreSQL.
+func NewAccountEventDb(db PgxPool) (repository.AccountEventRepository, error) {
+	return AccountEventDb{db: db}, nil
+}
+
+// Save сохраняет событие для последующей публикации и заполняет его Id.
+func (edb AccountEventDb) Save(ctx context.Context, event *domain.AccountEvent) error {
+	row := conn(ctx, edb.db).QueryRow(ctx, `
+INSERT INTO account_events (type, account_id, user_id, currency, balance, amount, transaction_id, status, previous_status,
+                            transaction_status, failure_code, occurred_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+RETURNING id
+`, &event.Type, &event.AccountId, &event.UserId, &event.Currency, &event.Balance, &event.Amount,
+		event.TransactionId, &event.Status, &event.PreviousStatus, &event.TransactionStatus, &event.FailureCode, &event.OccurredAt)
+	return row.Scan(&event.Id)
+}
+
+// GetUnpublished возвращает до limit неопубликованных событий в порядке Id.
+// Внутри транзакции строки блокируются, а заблокированные другими транзакциями пропускаются.
+func (edb AccountEventDb) GetUnpublished(ctx context.Context, limit int) ([]domain.AccountEvent, error) {
+	rows, err := conn(ctx, edb.db).Query(ctx, `
+SELECT id, type, account_id, user_id, currency, balance, amount, transaction_id, status, previous_status,
+       transaction_status, failure_code, occurred_at
+FROM account_events
+WHERE published_at IS NULL
+ORDER BY id
+LIMIT $1`+skipLockedClause(ctx), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]domain.AccountEvent, 0)
+	for rows.Next() {
+		var event domain.AccountEvent
+		err = rows.Scan(&event.Id, &event.Type, &event.AccountId, &event.UserId, &event.Currency, &event.Balance,
+			&event.Amount, &event.TransactionId, &event.Status, &event.PreviousStatus, &event.TransactionStatus,
+			&event.FailureCode, &event.OccurredAt)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// MarkPublished отмечает события ids опубликованными в момент at.
+func (edb AccountEventDb) MarkPublished(ctx context.Context, ids []int64, at time.Time) error {
+	_, err := conn(ctx, edb.db).Exec(ctx, `
+UPDATE account_events
+SET published_at = $2
+WHERE id = ANY($1)
+`, ids, at)
+	return err
+}
@@ -0,0 +1,99 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v3"
+	"payment-service/internal/domain"
+	"payment-service/internal/infrastructure/postgres"
+)
+
+// TestAccountEventDb_Save проверяет сохранение события и получение его идентификатора.
+func TestAccountEventDb_Save(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	db, _ := postgres.NewAccountEventDb(mock)
+	transactionId := 7
+	event := &domain.AccountEvent{Type: domain.AccountEventCredited, AccountId: 1, UserId: 10, Currency: "RUB",
+		Balance: 150, Amount: 50, TransactionId: &transactionId, Status: domain.AccountStatusActive, OccurredAt: time.Now()}
+
+	mock.ExpectQuery(`INSERT INTO account_events`).
+		WithArgs(&event.Type, &event.AccountId, &event.UserId, &event.Currency, &event.Balance, &event.Amount,
+			event.TransactionId, &event.Status, &event.PreviousStatus, &event.TransactionStatus, &event.FailureCode, &event.OccurredAt).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(int64(42)))
+
+	err = db.Save(context.Background(), event)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if event.Id != 42 {
+		t.Errorf("expected id 42, got %d", event.Id)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+// TestAccountEventDb_GetUnpublished проверяет выборку неопубликованных событий в порядке Id.
+func TestAccountEventDb_GetUnpublished(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	db, _ := postgres.NewAccountEventDb(mock)
+	now := time.Now()
+	columns := []string{"id", "type", "account_id", "user_id", "currency", "balance", "amount", "transaction_id",
+		"status", "previous_status", "transaction_status", "failure_code", "occurred_at"}
+
+	mock.ExpectQuery(`FROM account_events WHERE published_at IS NULL ORDER BY id LIMIT \$1`).
+		WithArgs(100).
+		WillReturnRows(pgxmock.NewRows(columns).
+			AddRow(int64(1), domain.AccountEventCreated, 1, 10, "RUB", 0.0, 0.0, (*int)(nil),
+				domain.AccountStatusActive, domain.AccountStatus(""), domain.TransactionStatus(""), domain.ReasonCode(""), now).
+			AddRow(int64(2), domain.AccountEventStatusChanged, 1, 10, "RUB", 0.0, 0.0, (*int)(nil),
+				domain.AccountStatusFrozen, domain.AccountStatusActive, domain.TransactionStatus(""), domain.ReasonCode(""), now))
+
+	events, err := db.GetUnpublished(context.Background(), 100)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 2 || events[0].Id != 1 || events[1].PreviousStatus != domain.AccountStatusActive {
+		t.Errorf("unexpected result: %+v", events)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+// TestAccountEventDb_MarkPublished проверяет отметку событий опубликованными.
+func TestAccountEventDb_MarkPublished(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	db, _ := postgres.NewAccountEventDb(mock)
+	ids := []int64{1, 2}
+	at := time.Now()
+
+	mock.ExpectExec(`UPDATE account_events SET published_at = \$2 WHERE id = ANY\(\$1\)`).
+		WithArgs(ids, at).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+
+	err = db.MarkPublished(context.Background(), ids, at)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
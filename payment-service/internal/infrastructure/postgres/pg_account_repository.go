@@ -3,12 +3,14 @@ package postgres
 import (
 	"context"
 	"errors"
-	"fmt"
 	"github.com/jackc/pgx/v5"
 	"payment-service/internal/application/repository"
 	"payment-service/internal/domain"
 )
 
+// accountColumns — список колонок таблицы accounts в порядке сканирования scanAccount.
+const accountColumns = `id, user_id, name, currency, is_default, status, balance, credit_limit, grace_period_days, overdraft_since, creation_date`
+
 // AccountDb реализует интерфейс AccountRepository
 // и работает с таблицей accounts в PostgreSQL через pgxpool.
 type AccountDb struct {
@@ -20,19 +22,13 @@ func NewAccountDb(db PgxPool) (repository.AccountRepository, error) {
 	return AccountDb{db: db}, nil
 }
 
-// GetById возвращает аккаунт по его ID.
-// Возвращает ошибку, если аккаунт не найден.
-func (adb AccountDb) GetById(ctx context.Context, id int) (*domain.Account, error) {
-	row := adb.db.QueryRow(ctx, `
-SELECT id, user_id, balance, creation_date
-FROM accounts
-WHERE id=$1
-`, id)
-
+// scanAccount считывает аккаунт из строки результата запроса.
+func scanAccount(row pgx.Row) (*domain.Account, error) {
 	var acc domain.Account
-	err := row.Scan(&acc.Id, &acc.UserId, &acc.Balance, &acc.CreationDate)
+	err := row.Scan(&acc.Id, &acc.UserId, &acc.Name, &acc.Currency, &acc.IsDefault, &acc.Status, &acc.Balance,
+		&acc.CreditLimit, &acc.GracePeriodDays, &acc.OverdraftSince, &acc.CreationDate)
 	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, fmt.Errorf("account not found")
+		return nil, domain.ErrAccountNotFound
 	}
 	if err != nil {
 		return nil, err
@@ -40,34 +36,115 @@ WHERE id=$1
 	return &acc, nil
 }
 
+// GetById возвращает аккаунт по его ID.
+// Внутри транзакции строка аккаунта блокируется до её завершения.
+// Возвращает ошибку, если аккаунт не найден.
+func (adb AccountDb) GetById(ctx context.Context, id int) (*domain.Account, error) {
+	row := conn(ctx, adb.db).QueryRow(ctx, `
+SELECT `+accountColumns+`
+FROM accounts
+WHERE id=$1`+lockClause(ctx), id)
+	return scanAccount(row)
+}
+
 // Save сохраняет аккаунт в базу данных.
-// Если аккаунт с таким id уже существует — обновляет баланс.
+// Если аккаунт с таким id уже существует — обновляет статус, баланс и параметры кредитного лимита.
+// Признак основного счёта при обновлении не меняется — для этого используется SetDefault.
 func (adb AccountDb) Save(ctx context.Context, account *domain.Account) error {
-	_, err := adb.db.Exec(ctx, `
-INSERT INTO accounts (id, user_id, balance, creation_date)
-VALUES ($1, $2, $3, $4)
+	_, err := conn(ctx, adb.db).Exec(ctx, `
+INSERT INTO accounts (id, user_id, name, currency, is_default, status, balance, credit_limit, grace_period_days, overdraft_since, creation_date)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 ON CONFLICT (id) DO UPDATE
-    SET balance = EXCLUDED.balance
-`, &account.Id, &account.UserId, &account.Balance, &account.CreationDate)
+    SET status = EXCLUDED.status,
+        balance = EXCLUDED.balance,
+        credit_limit = EXCLUDED.credit_limit,
+        grace_period_days = EXCLUDED.grace_period_days,
+        overdraft_since = EXCLUDED.overdraft_since
+`, &account.Id, &account.UserId, &account.Name, &account.Currency, &account.IsDefault, &account.Status, &account.Balance,
+		&account.CreditLimit, &account.GracePeriodDays, account.OverdraftSince, &account.CreationDate)
 	return err
 }
 
-// GetByUserId возвращает аккаунт по user_id.
+// GetDefaultByUserId возвращает основной аккаунт пользователя.
+// Внутри транзакции строка аккаунта блокируется до её завершения.
 // Возвращает ошибку, если аккаунт не найден.
-func (adb AccountDb) GetByUserId(ctx context.Context, userId int) (*domain.Account, error) {
-	row := adb.db.QueryRow(ctx, `
-SELECT id, user_id, balance, creation_date
+func (adb AccountDb) GetDefaultByUserId(ctx context.Context, userId int) (*domain.Account, error) {
+	row := conn(ctx, adb.db).QueryRow(ctx, `
+SELECT `+accountColumns+`
+FROM accounts
+WHERE user_id=$1 AND is_default`+lockClause(ctx), userId)
+	return scanAccount(row)
+}
+
+// GetUserAccounts возвращает все аккаунты пользователя, начиная с основного.
+func (adb AccountDb) GetUserAccounts(ctx context.Context, userId int) ([]domain.Account, error) {
+	rows, err := conn(ctx, adb.db).Query(ctx, `
+SELECT `+accountColumns+`
 FROM accounts
 WHERE user_id=$1
+ORDER BY is_default DESC, creation_date
 `, userId)
-
-	var acc domain.Account
-	err := row.Scan(&acc.Id, &acc.UserId, &acc.Balance, &acc.CreationDate)
-	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, fmt.Errorf("account not found")
+	if err != nil {
+		return nil, err
 	}
+	return scanAccounts(rows)
+}
+
+// GetAll возвращает все аккаунты в порядке их идентификаторов.
+func (adb AccountDb) GetAll(ctx context.Context) ([]domain.Account, error) {
+	rows, err := conn(ctx, adb.db).Query(ctx, `
+SELECT `+accountColumns+`
+FROM accounts
+ORDER BY id
+`)
 	if err != nil {
 		return nil, err
 	}
-	return &acc, nil
+	return scanAccounts(rows)
+}
+
+// scanAccounts считывает все аккаунты из результата запроса и закрывает его.
+func scanAccounts(rows pgx.Rows) ([]domain.Account, error) {
+	defer rows.Close()
+
+	accounts := make([]domain.Account, 0)
+	for rows.Next() {
+		acc, err := scanAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, *acc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+// SetDefault делает аккаунт основным для пользователя.
+// Снятие признака со старого аккаунта и установка на новый выполняются в одной транзакции.
+// Возвращает ошибку, если аккаунт не найден или принадлежит другому пользователю.
+func (adb AccountDb) SetDefault(ctx context.Context, userId int, accountId int) error {
+	return inTransaction(ctx, adb.db, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `
+UPDATE accounts
+SET is_default = FALSE
+WHERE user_id=$1 AND is_default
+`, userId)
+		if err != nil {
+			return err
+		}
+		tag, err := tx.Exec(ctx, `
+UPDATE accounts
+SET is_default = TRUE
+WHERE id=$1 AND user_id=$2
+`, accountId, userId)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return domain.ErrAccountNotFound
+		}
+		return nil
+	})
 }
@@ -8,6 +8,17 @@ import (
 	"time"
 )
 
+// accountRows формирует строки результата запроса к таблице accounts.
+func accountRows(accounts ...domain.Account) *pgxmock.Rows {
+	rows := pgxmock.NewRows([]string{"id", "user_id", "name", "currency", "is_default", "status", "balance",
+		"credit_limit", "grace_period_days", "overdraft_since", "creation_date"})
+	for _, acc := range accounts {
+		rows.AddRow(acc.Id, acc.UserId, acc.Name, acc.Currency, acc.IsDefault, acc.Status, acc.Balance,
+			acc.CreditLimit, acc.GracePeriodDays, acc.OverdraftSince, acc.CreationDate)
+	}
+	return rows
+}
+
 func TestAccountDb_GetById(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	if err != nil {
@@ -18,22 +29,24 @@ func TestAccountDb_GetById(t *testing.T) {
 	account := domain.Account{
 		Id:           1,
 		UserId:       42,
+		Name:         "main",
+		Currency:     "RUB",
+		IsDefault:    true,
+		Status:       domain.AccountStatusActive,
 		Balance:      100.5,
 		CreationDate: time.Now(),
 	}
 
-	rows := pgxmock.NewRows([]string{"id", "user_id", "balance", "creation_date"}).
-		AddRow(account.Id, account.UserId, account.Balance, account.CreationDate)
-	mock.ExpectQuery(`SELECT id, user_id, balance, creation_date FROM accounts WHERE id=`).
+	mock.ExpectQuery(`SELECT id, user_id, name, currency, is_default, status, balance, credit_limit, grace_period_days, overdraft_since, creation_date FROM accounts WHERE id=`).
 		WithArgs(1).
-		WillReturnRows(rows)
+		WillReturnRows(accountRows(account))
 
 	db := AccountDb{db: mock}
 	got, err := db.GetById(context.Background(), 1)
 	if err != nil {
 		t.Fatalf("неожиданная ошибка: %v", err)
 	}
-	if got.Id != account.Id || got.Balance != account.Balance {
+	if got.Id != account.Id || got.Balance != account.Balance || got.Name != account.Name {
 		t.Errorf("ожидалось %+v, получено %+v", account, got)
 	}
 }
@@ -48,12 +61,15 @@ func TestAccountDb_Save(t *testing.T) {
 	account := domain.Account{
 		Id:           2,
 		UserId:       11,
+		Name:         "bonus",
+		Currency:     "RUB",
 		Balance:      500,
 		CreationDate: time.Now(),
 	}
 
 	mock.ExpectExec(`INSERT INTO accounts`).
-		WithArgs(&account.Id, &account.UserId, &account.Balance, &account.CreationDate).
+		WithArgs(&account.Id, &account.UserId, &account.Name, &account.Currency, &account.IsDefault, &account.Status, &account.Balance,
+			&account.CreditLimit, &account.GracePeriodDays, account.OverdraftSince, &account.CreationDate).
 		WillReturnResult(pgxmock.NewResult("INSERT", 1))
 
 	db := AccountDb{db: mock}
@@ -63,7 +79,7 @@ func TestAccountDb_Save(t *testing.T) {
 	}
 }
 
-func TestAccountDb_GetByUserId(t *testing.T) {
+func TestAccountDb_GetDefaultByUserId(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	if err != nil {
 		t.Fatalf("ошибка создания мока: %v", err)
@@ -73,22 +89,99 @@ func TestAccountDb_GetByUserId(t *testing.T) {
 	account := domain.Account{
 		Id:           3,
 		UserId:       77,
+		Name:         "main",
+		Currency:     "RUB",
+		IsDefault:    true,
 		Balance:      250.25,
 		CreationDate: time.Now(),
 	}
 
-	rows := pgxmock.NewRows([]string{"id", "user_id", "balance", "creation_date"}).
-		AddRow(account.Id, account.UserId, account.Balance, account.CreationDate)
-	mock.ExpectQuery(`SELECT id, user_id, balance, creation_date FROM accounts WHERE user_id=`).
+	mock.ExpectQuery(`SELECT id, user_id, name, currency, is_default, status, balance, credit_limit, grace_period_days, overdraft_since, creation_date FROM accounts WHERE user_id=\$1 AND is_default`).
 		WithArgs(77).
-		WillReturnRows(rows)
+		WillReturnRows(accountRows(account))
+
+	db, _ := NewAccountDb(mock)
+	got, err := db.GetDefaultByUserId(context.Background(), 77)
+	if err != nil {
+		t.Fatalf("ошибка при GetDefaultByUserId: %v", err)
+	}
+	if got.UserId != account.UserId || !got.IsDefault {
+		t.Errorf("ожидался основной счёт пользователя %d, получен %+v", account.UserId, got)
+	}
+}
+
+func TestAccountDb_GetUserAccounts(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("ошибка создания мока: %v", err)
+	}
+	defer mock.Close()
+
+	main := domain.Account{Id: 4, UserId: 5, Name: "main", Currency: "RUB", IsDefault: true, CreationDate: time.Now()}
+	bonus := domain.Account{Id: 6, UserId: 5, Name: "bonus", Currency: "RUB", CreationDate: time.Now()}
+
+	mock.ExpectQuery(`SELECT .* FROM accounts WHERE user_id=\$1 ORDER BY is_default DESC, creation_date`).
+		WithArgs(5).
+		WillReturnRows(accountRows(main, bonus))
+
+	db, _ := NewAccountDb(mock)
+	got, err := db.GetUserAccounts(context.Background(), 5)
+	if err != nil {
+		t.Fatalf("ошибка при GetUserAccounts: %v", err)
+	}
+	if len(got) != 2 || got[0].Id != main.Id || got[1].Id != bonus.Id {
+		t.Errorf("неожиданный список счетов: %+v", got)
+	}
+}
+
+func TestAccountDb_SetDefault(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("ошибка создания мока: %v", err)
+	}
+	defer mock.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE accounts SET is_default = FALSE WHERE user_id=\$1 AND is_default`).
+		WithArgs(5).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec(`UPDATE accounts SET is_default = TRUE WHERE id=\$1 AND user_id=\$2`).
+		WithArgs(6, 5).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectCommit()
 
 	db, _ := NewAccountDb(mock)
-	got, err := db.GetByUserId(context.Background(), 77)
+	err = db.SetDefault(context.Background(), 5, 6)
 	if err != nil {
-		t.Fatalf("ошибка при GetByUserId: %v", err)
+		t.Fatalf("ошибка при SetDefault: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("не выполнены ожидания: %v", err)
+	}
+}
+
+func TestAccountDb_SetDefault_NotFound(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("ошибка создания мока: %v", err)
+	}
+	defer mock.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE accounts SET is_default = FALSE`).
+		WithArgs(5).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec(`UPDATE accounts SET is_default = TRUE`).
+		WithArgs(7, 5).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	mock.ExpectRollback()
+
+	db, _ := NewAccountDb(mock)
+	err = db.SetDefault(context.Background(), 5, 7)
+	if err == nil {
+		t.Fatal("ожидалась ошибка для чужого счёта")
 	}
-	if got.UserId != account.UserId {
-		t.Errorf("ожидался user_id %d, получен %d", account.UserId, got.UserId)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("не выполнены ожидания: %v", err)
 	}
 }
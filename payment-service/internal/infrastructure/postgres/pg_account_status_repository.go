@@ -0,0 +1,58 @@
+package postgres
+
+import (
+	"context"
+	"payment-service/internal/application/repository"
+	"payment-service/internal/domain"
+)
+
+// AccountStatusDb реализует интерфейс repository.AccountStatusRepository
+// и работает с таблицей account_status_changes в PostgreSQL.
+type AccountStatusDb struct {
+	db PgxPool
+}
+
+// NewAccountStatusDb создаёт новый экземпляр AccountStatusDb,
+// принимая пул подключений к PostgreSQL.
+func NewAccountStatusDb(db PgxPool) (repository.AccountStatusRepository, error) {
+	return AccountStatusDb{db: db}, nil
+}
+
+// Save добавляет запись об изменении статуса и заполняет её идентификатор.
+func (sdb AccountStatusDb) Save(ctx context.Context, change *domain.AccountStatusChange) error {
+	row := conn(ctx, sdb.db).QueryRow(ctx, `
+INSERT INTO account_status_changes (account_id, from_status, to_status, changed_by, reason, changed_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id
+`, &change.AccountId, &change.FromStatus, &change.ToStatus, &change.ChangedBy, &change.Reason, &change.ChangedAt)
+	return row.Scan(&change.Id)
+}
+
+// GetByAccountId возвращает историю изменений статуса счёта, начиная с самых ранних.
+func (sdb AccountStatusDb) GetByAccountId(ctx context.Context, accountId int) ([]domain.AccountStatusChange, error) {
+	rows, err := conn(ctx, sdb.db).Query(ctx, `
+SELECT id, account_id, from_status, to_status, changed_by, reason, changed_at
+FROM account_status_changes
+WHERE account_id = $1
+ORDER BY changed_at, id
+`, accountId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := make([]domain.AccountStatusChange, 0)
+	for rows.Next() {
+		var change domain.AccountStatusChange
+		err := rows.Scan(&change.Id, &change.AccountId, &change.FromStatus, &change.ToStatus,
+			&change.ChangedBy, &change.Reason, &change.ChangedAt)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
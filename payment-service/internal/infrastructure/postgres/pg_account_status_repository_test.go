@@ -0,0 +1,74 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v3"
+	"payment-service/internal/domain"
+	"payment-service/internal/infrastructure/postgres"
+)
+
+// TestAccountStatusDb_Save проверяет сохранение записи журнала статусов.
+func TestAccountStatusDb_Save(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	db, _ := postgres.NewAccountStatusDb(mock)
+	change := &domain.AccountStatusChange{
+		AccountId:  5,
+		FromStatus: domain.AccountStatusActive,
+		ToStatus:   domain.AccountStatusFrozen,
+		ChangedBy:  "admin",
+		Reason:     "fraud suspicion",
+		ChangedAt:  time.Now(),
+	}
+
+	mock.ExpectQuery(`INSERT INTO account_status_changes`).
+		WithArgs(&change.AccountId, &change.FromStatus, &change.ToStatus, &change.ChangedBy, &change.Reason, &change.ChangedAt).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(11))
+
+	err = db.Save(context.Background(), change)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if change.Id != 11 {
+		t.Errorf("expected id 11, got %d", change.Id)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+// TestAccountStatusDb_GetByAccountId проверяет чтение истории статусов счёта.
+func TestAccountStatusDb_GetByAccountId(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	db, _ := postgres.NewAccountStatusDb(mock)
+	rows := pgxmock.NewRows([]string{"id", "account_id", "from_status", "to_status", "changed_by", "reason", "changed_at"}).
+		AddRow(1, 5, domain.AccountStatusActive, domain.AccountStatusFrozen, "admin", "check", time.Now()).
+		AddRow(2, 5, domain.AccountStatusFrozen, domain.AccountStatusActive, "admin", "ok", time.Now())
+
+	mock.ExpectQuery(`SELECT id, account_id, from_status, to_status, changed_by, reason, changed_at FROM account_status_changes WHERE account_id = \$1`).
+		WithArgs(5).
+		WillReturnRows(rows)
+
+	changes, err := db.GetByAccountId(context.Background(), 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(changes) != 2 || changes[1].ToStatus != domain.AccountStatusActive {
+		t.Errorf("unexpected result: %+v", changes)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"payment-service/internal/application/repository"
+	"payment-service/internal/domain"
+	"time"
+)
+
+// BalanceSnapshotDb реализует интерфейс repository.BalanceSnapshotRepository
+// и работает с таблицей balance_snapshots в PostgreSQL.
+type BalanceSnapshotDb struct {
+	db PgxPool
+}
+
+// NewBalanceSnapshotDb создаёт новый экземпляр BalanceSnapshotDb,
+// принимая пул подключений к PostgreSQL.
+func NewBalanceSnapshotDb(db PgxPool) (repository.BalanceSnapshotRepository, error) {
+	return BalanceSnapshotDb{db: db}, nil
+}
+
+// GetLatest возвращает последний снимок баланса счёта, сделанный не позже момента at.
+// Возвращает nil, nil если такого снимка нет.
+func (sdb BalanceSnapshotDb) GetLatest(ctx context.Context, accountId int, at time.Time) (*domain.BalanceSnapshot, error) {
+	row := conn(ctx, sdb.db).QueryRow(ctx, `
+SELECT account_id, balance, taken_at, created_at
+FROM balance_snapshots
+WHERE account_id = $1 AND taken_at <= $2
+ORDER BY taken_at DESC
+LIMIT 1
+`, accountId, at)
+
+	var snapshot domain.BalanceSnapshot
+	err := row.Scan(&snapshot.AccountId, &snapshot.Balance, &snapshot.TakenAt, &snapshot.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// Save сохраняет снимок баланса.
+// Если снимок счёта на тот же момент уже существует — перезаписывает его.
+func (sdb BalanceSnapshotDb) Save(ctx context.Context, snapshot *domain.BalanceSnapshot) error {
+	_, err := conn(ctx, sdb.db).Exec(ctx, `
+INSERT INTO balance_snapshots (account_id, taken_at, balance, created_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (account_id, taken_at) DO UPDATE
+    SET balance = EXCLUDED.balance,
+        created_at = EXCLUDED.created_at
+`, &snapshot.AccountId, &snapshot.TakenAt, &snapshot.Balance, &snapshot.CreatedAt)
+	return err
+}
+
+// DeleteFrom удаляет снимки баланса счёта, сделанные в момент from или позже.
+func (sdb BalanceSnapshotDb) DeleteFrom(ctx context.Context, accountId int, from time.Time) error {
+	_, err := conn(ctx, sdb.db).Exec(ctx, `
+DELETE FROM balance_snapshots
+WHERE account_id = $1 AND taken_at >= $2
+`, accountId, from)
+	return err
+}
@@ -0,0 +1,94 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v3"
+	"payment-service/internal/domain"
+	"payment-service/internal/infrastructure/postgres"
+)
+
+// TestBalanceSnapshotDb_GetLatest проверяет выбор последнего снимка не позже заданного момента.
+func TestBalanceSnapshotDb_GetLatest(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	db, _ := postgres.NewBalanceSnapshotDb(mock)
+	at := time.Date(2025, 9, 30, 23, 59, 59, 0, time.UTC)
+	takenAt := at.Add(-time.Hour)
+
+	mock.ExpectQuery(`SELECT account_id, balance, taken_at, created_at FROM balance_snapshots WHERE account_id = \$1 AND taken_at <= \$2 ORDER BY taken_at DESC LIMIT 1`).
+		WithArgs(3, at).
+		WillReturnRows(pgxmock.NewRows([]string{"account_id", "balance", "taken_at", "created_at"}).AddRow(3, 250.0, takenAt, takenAt))
+	mock.ExpectQuery(`FROM balance_snapshots`).
+		WithArgs(4, at).
+		WillReturnRows(pgxmock.NewRows([]string{"account_id", "balance", "taken_at", "created_at"}))
+
+	snapshot, err := db.GetLatest(context.Background(), 3, at)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if snapshot == nil || snapshot.Balance != 250 || !snapshot.TakenAt.Equal(takenAt) {
+		t.Errorf("unexpected result: %+v", snapshot)
+	}
+	snapshot, err = db.GetLatest(context.Background(), 4, at)
+	if err != nil || snapshot != nil {
+		t.Errorf("expected nil, nil; got %+v, %v", snapshot, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+// TestBalanceSnapshotDb_Save проверяет сохранение снимка баланса.
+func TestBalanceSnapshotDb_Save(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	db, _ := postgres.NewBalanceSnapshotDb(mock)
+	snapshot := &domain.BalanceSnapshot{AccountId: 3, Balance: 250, TakenAt: time.Now(), CreatedAt: time.Now()}
+
+	mock.ExpectExec(`INSERT INTO balance_snapshots`).
+		WithArgs(&snapshot.AccountId, &snapshot.TakenAt, &snapshot.Balance, &snapshot.CreatedAt).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	err = db.Save(context.Background(), snapshot)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+// TestBalanceSnapshotDb_DeleteFrom проверяет удаление снимков, сделанных не раньше заданного момента.
+func TestBalanceSnapshotDb_DeleteFrom(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	db, _ := postgres.NewBalanceSnapshotDb(mock)
+	from := time.Date(2025, 9, 30, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectExec(`DELETE FROM balance_snapshots WHERE account_id = \$1 AND taken_at >= \$2`).
+		WithArgs(3, from).
+		WillReturnResult(pgxmock.NewResult("DELETE", 2))
+
+	err = db.DeleteFrom(context.Background(), 3, from)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
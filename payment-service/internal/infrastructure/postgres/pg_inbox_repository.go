@@ -0,0 +1,49 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"payment-service/internal/application/repository"
+	"payment-service/internal/domain"
+)
+
+// InboxDb реализует интерфейс repository.InboxRepository
+// и работает с таблицей inbox_messages в PostgreSQL.
+type InboxDb struct {
+	db PgxPool
+}
+
+// NewInboxDb создаёт новый экземпляр InboxDb,
+// принимая пул подключений к PostgreSQL.
+func NewInboxDb(db PgxPool) (repository.InboxRepository, error) {
+	return InboxDb{db: db}, nil
+}
+
+// GetById возвращает запись об обработке сообщения по его идентификатору.
+// Внутри транзакции строка блокируется до её завершения.
+// Возвращает nil, nil если сообщение ещё не обработано.
+func (idb InboxDb) GetById(ctx context.Context, messageId string) (*domain.InboxMessage, error) {
+	row := conn(ctx, idb.db).QueryRow(ctx, `
+SELECT message_id, response, reason_code, processed_at
+FROM inbox_messages
+WHERE message_id = $1`+lockClause(ctx), messageId)
+	var message domain.InboxMessage
+	err := row.Scan(&message.MessageId, &message.Response, &message.ReasonCode, &message.ProcessedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+// Save сохраняет запись об обработке сообщения.
+func (idb InboxDb) Save(ctx context.Context, message *domain.InboxMessage) error {
+	_, err := conn(ctx, idb.db).Exec(ctx, `
+INSERT INTO inbox_messages (message_id, response, reason_code, processed_at)
+VALUES ($1, $2, $3, $4)
+`, &message.MessageId, &message.Response, &message.ReasonCode, &message.ProcessedAt)
+	return err
+}
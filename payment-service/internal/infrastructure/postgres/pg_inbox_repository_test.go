@@ -0,0 +1,71 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v3"
+	"payment-service/internal/domain"
+	"payment-service/internal/infrastructure/postgres"
+)
+
+// TestInboxDb_GetById проверяет чтение записи об обработанном сообщении.
+func TestInboxDb_GetById(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	db, _ := postgres.NewInboxDb(mock)
+	now := time.Now()
+	columns := []string{"message_id", "response", "reason_code", "processed_at"}
+
+	mock.ExpectQuery(`SELECT message_id, response, reason_code, processed_at FROM inbox_messages WHERE message_id = \$1`).
+		WithArgs("request-1").
+		WillReturnRows(pgxmock.NewRows(columns).AddRow("request-1", "OK", domain.ReasonCode(""), now))
+	mock.ExpectQuery(`FROM inbox_messages`).
+		WithArgs("request-2").
+		WillReturnRows(pgxmock.NewRows(columns))
+
+	message, err := db.GetById(context.Background(), "request-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if message == nil || message.Response != "OK" || !message.ProcessedAt.Equal(now) {
+		t.Errorf("unexpected result: %+v", message)
+	}
+	message, err = db.GetById(context.Background(), "request-2")
+	if err != nil || message != nil {
+		t.Errorf("expected nil, nil; got %+v, %v", message, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+// TestInboxDb_Save проверяет сохранение записи об обработанном сообщении.
+func TestInboxDb_Save(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	db, _ := postgres.NewInboxDb(mock)
+	message := &domain.InboxMessage{MessageId: "request-1", Response: "Error processing transaction: insufficient funds",
+		ReasonCode: domain.ReasonInsufficientFunds, ProcessedAt: time.Now()}
+
+	mock.ExpectExec(`INSERT INTO inbox_messages`).
+		WithArgs(&message.MessageId, &message.Response, &message.ReasonCode, &message.ProcessedAt).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	err = db.Save(context.Background(), message)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
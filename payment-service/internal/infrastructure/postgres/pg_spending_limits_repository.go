@@ -0,0 +1,66 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"payment-service/internal/application/repository"
+	"payment-service/internal/domain"
+)
+
+// SpendingLimitsDb реализует интерфейс repository.SpendingLimitsRepository
+// и работает с таблицей spending_limits в PostgreSQL.
+type SpendingLimitsDb struct {
+	db PgxPool
+}
+
+// NewSpendingLimitsDb создаёт новый экземпляр SpendingLimitsDb,
+// принимая пул подключений к PostgreSQL.
+func NewSpendingLimitsDb(db PgxPool) (repository.SpendingLimitsRepository, error) {
+	return SpendingLimitsDb{db: db}, nil
+}
+
+// GetByAccountId возвращает ограничения счёта.
+// Возвращает nil, nil если ограничения не заданы.
+func (ldb SpendingLimitsDb) GetByAccountId(ctx context.Context, accountId int) (*domain.SpendingLimits, error) {
+	row := conn(ctx, ldb.db).QueryRow(ctx, `
+SELECT account_id, max_payment, daily_limit, monthly_limit, max_payments_per_hour, updated_at
+FROM spending_limits
+WHERE account_id = $1
+`, accountId)
+
+	var limits domain.SpendingLimits
+	err := row.Scan(&limits.AccountId, &limits.MaxPayment, &limits.DailyLimit, &limits.MonthlyLimit,
+		&limits.MaxPaymentsPerHour, &limits.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &limits, nil
+}
+
+// Save сохраняет ограничения счёта, заменяя ранее заданные.
+func (ldb SpendingLimitsDb) Save(ctx context.Context, limits *domain.SpendingLimits) error {
+	_, err := conn(ctx, ldb.db).Exec(ctx, `
+INSERT INTO spending_limits (account_id, max_payment, daily_limit, monthly_limit, max_payments_per_hour, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (account_id) DO UPDATE
+    SET max_payment = EXCLUDED.max_payment,
+        daily_limit = EXCLUDED.daily_limit,
+        monthly_limit = EXCLUDED.monthly_limit,
+        max_payments_per_hour = EXCLUDED.max_payments_per_hour,
+        updated_at = EXCLUDED.updated_at
+`, &limits.AccountId, &limits.MaxPayment, &limits.DailyLimit, &limits.MonthlyLimit, &limits.MaxPaymentsPerHour, &limits.UpdatedAt)
+	return err
+}
+
+// Delete удаляет ограничения счёта.
+func (ldb SpendingLimitsDb) Delete(ctx context.Context, accountId int) error {
+	_, err := conn(ctx, ldb.db).Exec(ctx, `
+DELETE FROM spending_limits
+WHERE account_id = $1
+`, accountId)
+	return err
+}
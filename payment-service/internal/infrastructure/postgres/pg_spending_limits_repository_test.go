@@ -0,0 +1,63 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v3"
+	"payment-service/internal/domain"
+	"payment-service/internal/infrastructure/postgres"
+)
+
+// TestSpendingLimitsDb_GetByAccountId проверяет чтение ограничений счёта.
+func TestSpendingLimitsDb_GetByAccountId(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	db, _ := postgres.NewSpendingLimitsDb(mock)
+	rows := pgxmock.NewRows([]string{"account_id", "max_payment", "daily_limit", "monthly_limit", "max_payments_per_hour", "updated_at"}).
+		AddRow(5, 500.0, 1000.0, 5000.0, 3, time.Now())
+
+	mock.ExpectQuery(`SELECT account_id, max_payment, daily_limit, monthly_limit, max_payments_per_hour, updated_at FROM spending_limits WHERE account_id = \$1`).
+		WithArgs(5).
+		WillReturnRows(rows)
+
+	limits, err := db.GetByAccountId(context.Background(), 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if limits == nil || limits.MaxPayment != 500 || limits.MaxPaymentsPerHour != 3 {
+		t.Errorf("unexpected result: %+v", limits)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+// TestSpendingLimitsDb_Save проверяет сохранение ограничений счёта.
+func TestSpendingLimitsDb_Save(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	db, _ := postgres.NewSpendingLimitsDb(mock)
+	limits := &domain.SpendingLimits{AccountId: 5, DailyLimit: 1000, UpdatedAt: time.Now()}
+
+	mock.ExpectExec(`INSERT INTO spending_limits`).
+		WithArgs(&limits.AccountId, &limits.MaxPayment, &limits.DailyLimit, &limits.MonthlyLimit, &limits.MaxPaymentsPerHour, &limits.UpdatedAt).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	err = db.Save(context.Background(), limits)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
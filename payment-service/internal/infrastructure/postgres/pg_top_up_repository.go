@@ -0,0 +1,114 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"payment-service/internal/application/repository"
+	"payment-service/internal/domain"
+)
+
+// topUpColumns — список колонок таблицы top_ups в порядке сканирования scanTopUp.
+const topUpColumns = `id, account_id, user_id, amount, status, provider, provider_ref, confirmation_url, transaction_id, failure_reason, created_at, updated_at`
+
+// TopUpDb реализует интерфейс repository.TopUpRepository
+// и работает с таблицей top_ups в PostgreSQL.
+type TopUpDb struct {
+	db PgxPool
+}
+
+// NewTopUpDb создаёт новый экземпляр TopUpDb,
+// принимая пул подключений к PostgreSQL.
+func NewTopUpDb(db PgxPool) (repository.TopUpRepository, error) {
+	return TopUpDb{db: db}, nil
+}
+
+// scanTopUp считывает пополнение из строки результата запроса.
+func scanTopUp(row pgx.Row) (*domain.TopUp, error) {
+	var topUp domain.TopUp
+	err := row.Scan(&topUp.Id, &topUp.AccountId, &topUp.UserId, &topUp.Amount, &topUp.Status, &topUp.Provider,
+		&topUp.ProviderRef, &topUp.ConfirmationURL, &topUp.TransactionId, &topUp.FailureReason, &topUp.CreatedAt, &topUp.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &topUp, nil
+}
+
+// scanTopUps считывает все пополнения из результата запроса и закрывает его.
+func scanTopUps(rows pgx.Rows) ([]domain.TopUp, error) {
+	defer rows.Close()
+
+	topUps := make([]domain.TopUp, 0)
+	for rows.Next() {
+		topUp, err := scanTopUp(rows)
+		if err != nil {
+			return nil, err
+		}
+		topUps = append(topUps, *topUp)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return topUps, nil
+}
+
+// GetById возвращает пополнение по его ID.
+// Внутри транзакции строка блокируется до её завершения.
+// Возвращает nil, nil если пополнение не найдено.
+func (tdb TopUpDb) GetById(ctx context.Context, id int) (*domain.TopUp, error) {
+	row := conn(ctx, tdb.db).QueryRow(ctx, `
+SELECT `+topUpColumns+`
+FROM top_ups
+WHERE id = $1`+lockClause(ctx), id)
+	topUp, err := scanTopUp(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return topUp, err
+}
+
+// GetByAccountId возвращает пополнения кошелька, начиная с последних.
+func (tdb TopUpDb) GetByAccountId(ctx context.Context, accountId int) ([]domain.TopUp, error) {
+	rows, err := conn(ctx, tdb.db).Query(ctx, `
+SELECT `+topUpColumns+`
+FROM top_ups
+WHERE account_id = $1
+ORDER BY created_at DESC, id
+`, accountId)
+	if err != nil {
+		return nil, err
+	}
+	return scanTopUps(rows)
+}
+
+// GetByStatus возвращает пополнения в указанном статусе, начиная с последних.
+func (tdb TopUpDb) GetByStatus(ctx context.Context, status domain.TopUpStatus) ([]domain.TopUp, error) {
+	rows, err := conn(ctx, tdb.db).Query(ctx, `
+SELECT `+topUpColumns+`
+FROM top_ups
+WHERE status = $1
+ORDER BY created_at DESC, id
+`, status)
+	if err != nil {
+		return nil, err
+	}
+	return scanTopUps(rows)
+}
+
+// Save сохраняет пополнение.
+// Если пополнение с таким ID уже существует — обновляет его состояние и данные платежа.
+func (tdb TopUpDb) Save(ctx context.Context, topUp *domain.TopUp) error {
+	_, err := conn(ctx, tdb.db).Exec(ctx, `
+INSERT INTO top_ups (`+topUpColumns+`)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+ON CONFLICT (id) DO UPDATE
+    SET status = EXCLUDED.status,
+        provider_ref = EXCLUDED.provider_ref,
+        confirmation_url = EXCLUDED.confirmation_url,
+        transaction_id = EXCLUDED.transaction_id,
+        failure_reason = EXCLUDED.failure_reason,
+        updated_at = EXCLUDED.updated_at
+`, &topUp.Id, &topUp.AccountId, &topUp.UserId, &topUp.Amount, &topUp.Status, &topUp.Provider,
+		&topUp.ProviderRef, &topUp.ConfirmationURL, topUp.TransactionId, &topUp.FailureReason, &topUp.CreatedAt, &topUp.UpdatedAt)
+	return err
+}
@@ -0,0 +1,93 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v3"
+	"payment-service/internal/domain"
+	"payment-service/internal/infrastructure/postgres"
+)
+
+var topUpColumnNames = []string{"id", "account_id", "user_id", "amount", "status", "provider", "provider_ref",
+	"confirmation_url", "transaction_id", "failure_reason", "created_at", "updated_at"}
+
+// TestTopUpDb_GetByStatus проверяет чтение пополнений в заданном статусе.
+func TestTopUpDb_GetByStatus(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	db, _ := postgres.NewTopUpDb(mock)
+	now := time.Now()
+	rows := pgxmock.NewRows(topUpColumnNames).
+		AddRow(3, 1, 10, 50.0, domain.TopUpStatusFailed, "fake", "fake-3", "http://localhost/fake-provider/payments/fake-3",
+			(*int)(nil), "card declined", now, now)
+
+	mock.ExpectQuery(`SELECT id, account_id, user_id, amount, status, provider, provider_ref, confirmation_url, transaction_id, failure_reason, created_at, updated_at\s+FROM top_ups\s+WHERE status = \$1`).
+		WithArgs(domain.TopUpStatusFailed).
+		WillReturnRows(rows)
+
+	topUps, err := db.GetByStatus(context.Background(), domain.TopUpStatusFailed)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(topUps) != 1 || topUps[0].Id != 3 || topUps[0].TransactionId != nil || topUps[0].FailureReason != "card declined" {
+		t.Errorf("unexpected result: %+v", topUps)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+// TestTopUpDb_GetById_NotFound проверяет, что отсутствующее пополнение возвращается как nil без ошибки.
+func TestTopUpDb_GetById_NotFound(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	db, _ := postgres.NewTopUpDb(mock)
+	mock.ExpectQuery(`FROM top_ups\s+WHERE id = \$1`).
+		WithArgs(3).
+		WillReturnRows(pgxmock.NewRows(topUpColumnNames))
+
+	topUp, err := db.GetById(context.Background(), 3)
+	if err != nil || topUp != nil {
+		t.Errorf("expected nil, nil; got %+v, %v", topUp, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+// TestTopUpDb_Save проверяет сохранение подтверждённого пополнения.
+func TestTopUpDb_Save(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	db, _ := postgres.NewTopUpDb(mock)
+	txId := 9
+	topUp := &domain.TopUp{Id: 3, AccountId: 1, UserId: 10, Amount: 50, Status: domain.TopUpStatusSucceeded, Provider: "fake",
+		ProviderRef: "fake-3", TransactionId: &txId, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+
+	mock.ExpectExec(`INSERT INTO top_ups`).
+		WithArgs(&topUp.Id, &topUp.AccountId, &topUp.UserId, &topUp.Amount, &topUp.Status, &topUp.Provider,
+			&topUp.ProviderRef, &topUp.ConfirmationURL, topUp.TransactionId, &topUp.FailureReason, &topUp.CreatedAt, &topUp.UpdatedAt).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	err = db.Save(context.Background(), topUp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
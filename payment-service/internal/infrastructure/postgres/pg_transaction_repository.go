@@ -6,6 +6,7 @@ import (
 	"github.com/jackc/pgx/v5"
 	"payment-service/internal/application/repository"
 	"payment-service/internal/domain"
+	"time"
 )
 
 // TransactionDb реализует интерфейс repository.TransactionRepository
@@ -20,33 +21,188 @@ func NewTransactionDb(db PgxPool) (repository.TransactionRepository, error) {
 	return TransactionDb{db: db}, nil
 }
 
+// transactionColumns — список колонок таблицы transactions в порядке сканирования scanTransaction.
+const transactionColumns = `id, user_id, account_id, is_deposit, type, amount, status, failure_code, failure_reason, reversed_amount, reversal_of, reversal_reason, date`
+
+// scanTransaction считывает транзакцию из строки результата запроса.
+func scanTransaction(row pgx.Row) (*domain.Transaction, error) {
+	txn := domain.Transaction{}
+	err := row.Scan(&txn.Id, &txn.UserId, &txn.AccountId, &txn.IsDeposit, &txn.Type, &txn.Amount, &txn.Status, &txn.FailureCode, &txn.FailureReason,
+		&txn.ReversedAmount, &txn.ReversalOf, &txn.ReversalReason, &txn.Date)
+	if err != nil {
+		return nil, err
+	}
+	return &txn, nil
+}
+
 // GetById возвращает транзакцию по её ID.
+// Внутри транзакции строка блокируется до её завершения.
 // Возвращает nil, nil если транзакция не найдена.
 func (tdb TransactionDb) GetById(ctx context.Context, id int) (*domain.Transaction, error) {
-	row := tdb.db.QueryRow(ctx, `
-SELECT id, user_id, is_deposit, amount, date
+	row := conn(ctx, tdb.db).QueryRow(ctx, `
+SELECT `+transactionColumns+`
 FROM transactions
-WHERE id = $1
-`, id)
+WHERE id = $1`+lockClause(ctx), id)
 
-	txn := domain.Transaction{}
-	err := row.Scan(&txn.Id, &txn.UserId, &txn.IsDeposit, &txn.Amount, &txn.Date)
+	txn, err := scanTransaction(row)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, nil
 	}
+	return txn, err
+}
+
+// GetByAccountId возвращает все транзакции счёта в порядке даты проведения.
+func (tdb TransactionDb) GetByAccountId(ctx context.Context, accountId int) ([]domain.Transaction, error) {
+	rows, err := conn(ctx, tdb.db).Query(ctx, `
+SELECT `+transactionColumns+`
+FROM transactions
+WHERE account_id = $1
+ORDER BY date, id
+`, accountId)
 	if err != nil {
 		return nil, err
 	}
-	return &txn, nil
+	return scanTransactions(rows)
+}
+
+// GetCompletedBetween возвращает проведённые транзакции счёта с датой в промежутке (after, until]
+// в порядке даты проведения.
+func (tdb TransactionDb) GetCompletedBetween(ctx context.Context, accountId int, after, until time.Time) ([]domain.Transaction, error) {
+	rows, err := conn(ctx, tdb.db).Query(ctx, `
+SELECT `+transactionColumns+`
+FROM transactions
+WHERE account_id = $1 AND status = $2 AND date > $3 AND date <= $4
+ORDER BY date, id
+`, accountId, domain.TransactionStatusCompleted, after, until)
+	if err != nil {
+		return nil, err
+	}
+	return scanTransactions(rows)
+}
+
+// scanTransactions считывает все транзакции из результата запроса и закрывает его.
+func scanTransactions(rows pgx.Rows) ([]domain.Transaction, error) {
+	defer rows.Close()
+
+	transactions := make([]domain.Transaction, 0)
+	for rows.Next() {
+		txn, err := scanTransaction(rows)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, *txn)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// GetSpendingActivity возвращает сумму и число проведённых платежей со счёта
+// с начала дня, с начала месяца и за последний час относительно now.
+// Выплаты и корректировки в ограничениях не учитываются.
+func (tdb TransactionDb) GetSpendingActivity(ctx context.Context, accountId int, now time.Time) (*domain.SpendingActivity, error) {
+	dayStart, monthStart, hourStart := domain.SpendingWindows(now)
+	row := conn(ctx, tdb.db).QueryRow(ctx, `
+SELECT COALESCE(SUM(amount) FILTER (WHERE date >= $2), 0),
+       COALESCE(SUM(amount) FILTER (WHERE date >= $3), 0),
+       COUNT(*) FILTER (WHERE date >= $4)
+FROM transactions
+WHERE account_id = $1 AND NOT is_deposit AND type = $5 AND status = $6 AND date >= LEAST($3, $4)
+`, accountId, dayStart, monthStart, hourStart, domain.TransactionTypePayment, domain.TransactionStatusCompleted)
+
+	var activity domain.SpendingActivity
+	err := row.Scan(&activity.DailyTotal, &activity.MonthlyTotal, &activity.PaymentsLastHour)
+	if err != nil {
+		return nil, err
+	}
+	return &activity, nil
+}
+
+// GetBalanceChange возвращает изменение баланса счёта по проведённым транзакциям
+// с датой в промежутке (after, until].
+func (tdb TransactionDb) GetBalanceChange(ctx context.Context, accountId int, after, until time.Time) (float64, error) {
+	row := conn(ctx, tdb.db).QueryRow(ctx, `
+SELECT COALESCE(SUM(CASE WHEN is_deposit THEN amount ELSE -amount END), 0)
+FROM transactions
+WHERE account_id = $1 AND status = $2 AND date > $3 AND date <= $4
+`, accountId, domain.TransactionStatusCompleted, after, until)
+
+	var change float64
+	err := row.Scan(&change)
+	return change, err
+}
+
+// GetOldestPendingReviewDate возвращает дату самой ранней транзакции, ожидающей ручной проверки.
+// Возвращает nil, если таких транзакций нет.
+func (tdb TransactionDb) GetOldestPendingReviewDate(ctx context.Context) (*time.Time, error) {
+	row := conn(ctx, tdb.db).QueryRow(ctx, `
+SELECT MIN(date)
+FROM transactions
+WHERE status = $1
+`, domain.TransactionStatusPendingReview)
+
+	var date *time.Time
+	err := row.Scan(&date)
+	return date, err
+}
+
+// CountDeclined возвращает число отклонённых транзакций пользователя с момента since.
+func (tdb TransactionDb) CountDeclined(ctx context.Context, userId int, since time.Time) (int, error) {
+	row := conn(ctx, tdb.db).QueryRow(ctx, `
+SELECT COUNT(*)
+FROM transactions
+WHERE user_id = $1 AND status = $2 AND date >= $3
+`, userId, domain.TransactionStatusDeclined, since)
+
+	var count int
+	err := row.Scan(&count)
+	return count, err
+}
+
+// UpdateStatus обновляет статус, причину отказа и кошелёк ранее сохранённой транзакции.
+func (tdb TransactionDb) UpdateStatus(ctx context.Context, txn *domain.Transaction) error {
+	_, err := conn(ctx, tdb.db).Exec(ctx, `
+UPDATE transactions
+SET account_id = $2, status = $3, failure_code = $4, failure_reason = $5
+WHERE id = $1
+`, &txn.Id, &txn.AccountId, &txn.Status, &txn.FailureCode, &txn.FailureReason)
+	return err
+}
+
+// AddReversedAmount увеличивает отменённую часть суммы транзакции.
+// Проверка и обновление выполняются одним условным UPDATE, поэтому параллельные отмены
+// не могут в сумме превысить сумму транзакции.
+// Возвращает domain.ErrReversalExceeded, если отменённая часть превысила бы сумму транзакции.
+func (tdb TransactionDb) AddReversedAmount(ctx context.Context, id int, amount float64) error {
+	tag, err := conn(ctx, tdb.db).Exec(ctx, `
+UPDATE transactions
+SET reversed_amount = reversed_amount + $2
+WHERE id = $1 AND reversed_amount + $2 <= amount
+`, id, amount)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrReversalExceeded
+	}
+	return nil
 }
 
 // Save сохраняет новую транзакцию в базу данных.
-// Если запись с таким ID уже существует — операция игнорируется.
+// Возвращает repository.ErrDuplicateTransaction, если запись с таким ID уже существует.
 func (tdb TransactionDb) Save(ctx context.Context, txn *domain.Transaction) error {
-	_, err := tdb.db.Exec(ctx, `
-INSERT INTO transactions (id, user_id, is_deposit, amount, date)
-VALUES ($1, $2, $3, $4, $5)
+	tag, err := conn(ctx, tdb.db).Exec(ctx, `
+INSERT INTO transactions (`+transactionColumns+`)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 ON CONFLICT (id) DO NOTHING
-`, &txn.Id, &txn.UserId, &txn.IsDeposit, &txn.Amount, &txn.Date)
-	return err
+`, &txn.Id, &txn.UserId, &txn.AccountId, &txn.IsDeposit, &txn.Type, &txn.Amount, &txn.Status, &txn.FailureCode, &txn.FailureReason,
+		&txn.ReversedAmount, txn.ReversalOf, &txn.ReversalReason, &txn.Date)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrDuplicateTransaction
+	}
+	return nil
 }
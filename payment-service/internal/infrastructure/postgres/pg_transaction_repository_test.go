@@ -2,10 +2,12 @@ package postgres_test
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/pashagolub/pgxmock/v3"
+	"payment-service/internal/application/repository"
 	"payment-service/internal/domain"
 	"payment-service/internal/infrastructure/postgres"
 )
@@ -21,10 +23,10 @@ func TestTransactionDb_GetById(t *testing.T) {
 	db, _ := postgres.NewTransactionDb(mock)
 	ctx := context.Background()
 
-	rows := pgxmock.NewRows([]string{"id", "user_id", "is_deposit", "amount", "date"}).
-		AddRow(1, 10, true, 100.0, time.Now())
+	rows := pgxmock.NewRows([]string{"id", "user_id", "account_id", "is_deposit", "type", "amount", "status", "failure_code", "failure_reason", "reversed_amount", "reversal_of", "reversal_reason", "date"}).
+		AddRow(1, 10, 3, true, domain.TransactionTypePayment, 100.0, domain.TransactionStatusCompleted, domain.ReasonCode(""), "", 0.0, (*int)(nil), domain.ReversalReason(""), time.Now())
 
-	mock.ExpectQuery(`SELECT id, user_id, is_deposit, amount, date FROM transactions WHERE id = \$1`).
+	mock.ExpectQuery(`SELECT id, user_id, account_id, is_deposit, type, amount, status, failure_code, failure_reason, reversed_amount, reversal_of, reversal_reason, date FROM transactions WHERE id = \$1`).
 		WithArgs(1).
 		WillReturnRows(rows)
 
@@ -33,7 +35,7 @@ func TestTransactionDb_GetById(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	if txn == nil || txn.Id != 1 || txn.UserId != 10 {
+	if txn == nil || txn.Id != 1 || txn.UserId != 10 || txn.AccountId != 3 {
 		t.Errorf("unexpected result: %+v", txn)
 	}
 
@@ -56,13 +58,17 @@ func TestTransactionDb_Save(t *testing.T) {
 	txn := &domain.Transaction{
 		Id:        2,
 		UserId:    42,
+		AccountId: 7,
 		IsDeposit: true,
+		Type:      domain.TransactionTypePayment,
 		Amount:    250.5,
+		Status:    domain.TransactionStatusCompleted,
 		Date:      time.Now(),
 	}
 
 	mock.ExpectExec(`INSERT INTO transactions`).
-		WithArgs(&txn.Id, &txn.UserId, &txn.IsDeposit, &txn.Amount, &txn.Date).
+		WithArgs(&txn.Id, &txn.UserId, &txn.AccountId, &txn.IsDeposit, &txn.Type, &txn.Amount, &txn.Status, &txn.FailureCode, &txn.FailureReason,
+			&txn.ReversedAmount, txn.ReversalOf, &txn.ReversalReason, &txn.Date).
 		WillReturnResult(pgxmock.NewResult("INSERT", 1))
 
 	err = db.Save(ctx, txn)
@@ -74,3 +80,178 @@ func TestTransactionDb_Save(t *testing.T) {
 		t.Errorf("unmet expectations: %v", err)
 	}
 }
+
+// TestTransactionDb_Save_Duplicate проверяет, что повторное сохранение транзакции возвращает ошибку дубликата.
+func TestTransactionDb_Save_Duplicate(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	db, _ := postgres.NewTransactionDb(mock)
+	ctx := context.Background()
+
+	txn := &domain.Transaction{Id: 2, UserId: 42, Amount: 250.5, Date: time.Now()}
+
+	mock.ExpectExec(`INSERT INTO transactions`).
+		WithArgs(&txn.Id, &txn.UserId, &txn.AccountId, &txn.IsDeposit, &txn.Type, &txn.Amount, &txn.Status, &txn.FailureCode, &txn.FailureReason,
+			&txn.ReversedAmount, txn.ReversalOf, &txn.ReversalReason, &txn.Date).
+		WillReturnResult(pgxmock.NewResult("INSERT", 0))
+
+	err = db.Save(ctx, txn)
+	if !errors.Is(err, repository.ErrDuplicateTransaction) {
+		t.Fatalf("expected duplicate error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+// TestTransactionDb_GetByAccountId проверяет получение истории транзакций счёта.
+func TestTransactionDb_GetByAccountId(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	db, _ := postgres.NewTransactionDb(mock)
+	ctx := context.Background()
+
+	rows := pgxmock.NewRows([]string{"id", "user_id", "account_id", "is_deposit", "type", "amount", "status", "failure_code", "failure_reason", "reversed_amount", "reversal_of", "reversal_reason", "date"}).
+		AddRow(1, 10, 3, true, domain.TransactionTypePayment, 100.0, domain.TransactionStatusCompleted, domain.ReasonCode(""), "", 0.0, (*int)(nil), domain.ReversalReason(""), time.Now()).
+		AddRow(2, 10, 3, false, domain.TransactionTypePayment, 30.0, domain.TransactionStatusCompleted, domain.ReasonCode(""), "", 0.0, (*int)(nil), domain.ReversalReason(""), time.Now())
+
+	mock.ExpectQuery(`SELECT .* FROM transactions WHERE account_id = \$1 ORDER BY date, id`).
+		WithArgs(3).
+		WillReturnRows(rows)
+
+	transactions, err := db.GetByAccountId(ctx, 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(transactions) != 2 || transactions[1].Amount != 30 {
+		t.Errorf("unexpected result: %+v", transactions)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+// TestTransactionDb_GetSpendingActivity проверяет расчёт платежей в окнах ограничений.
+func TestTransactionDb_GetSpendingActivity(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	db, _ := postgres.NewTransactionDb(mock)
+	now := time.Date(2025, 10, 24, 12, 30, 0, 0, time.UTC)
+	dayStart, monthStart, hourStart := domain.SpendingWindows(now)
+
+	mock.ExpectQuery(`SELECT .* FROM transactions WHERE account_id = \$1 AND NOT is_deposit`).
+		WithArgs(3, dayStart, monthStart, hourStart, domain.TransactionTypePayment, domain.TransactionStatusCompleted).
+		WillReturnRows(pgxmock.NewRows([]string{"daily", "monthly", "hourly"}).AddRow(150.0, 900.0, 2))
+
+	activity, err := db.GetSpendingActivity(context.Background(), 3, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if activity.DailyTotal != 150 || activity.MonthlyTotal != 900 || activity.PaymentsLastHour != 2 {
+		t.Errorf("unexpected result: %+v", activity)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+// TestTransactionDb_AddReversedAmount проверяет, что отмена сверх суммы транзакции отклоняется.
+func TestTransactionDb_AddReversedAmount(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	db, _ := postgres.NewTransactionDb(mock)
+
+	mock.ExpectExec(`UPDATE transactions SET reversed_amount = reversed_amount \+ \$2 WHERE id = \$1 AND reversed_amount \+ \$2 <= amount`).
+		WithArgs(1, 40.0).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec(`UPDATE transactions SET reversed_amount`).
+		WithArgs(1, 70.0).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+	if err := db.AddReversedAmount(context.Background(), 1, 40); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := db.AddReversedAmount(context.Background(), 1, 70); !errors.Is(err, domain.ErrReversalExceeded) {
+		t.Errorf("expected ErrReversalExceeded, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+// TestTransactionDb_GetBalanceChange проверяет расчёт изменения баланса за период.
+func TestTransactionDb_GetBalanceChange(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	db, _ := postgres.NewTransactionDb(mock)
+	after := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2025, 9, 30, 23, 59, 59, 0, time.UTC)
+
+	mock.ExpectQuery(`SELECT COALESCE\(SUM\(CASE WHEN is_deposit THEN amount ELSE -amount END\), 0\) FROM transactions WHERE account_id = \$1 AND status = \$2 AND date > \$3 AND date <= \$4`).
+		WithArgs(3, domain.TransactionStatusCompleted, after, until).
+		WillReturnRows(pgxmock.NewRows([]string{"change"}).AddRow(-120.5))
+
+	change, err := db.GetBalanceChange(context.Background(), 3, after, until)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if change != -120.5 {
+		t.Errorf("expected -120.5, got %v", change)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+// TestTransactionDb_GetCompletedBetween проверяет выборку проведённых транзакций за период.
+func TestTransactionDb_GetCompletedBetween(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	db, _ := postgres.NewTransactionDb(mock)
+	after, until := domain.StatementPeriod(time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC))
+
+	rows := pgxmock.NewRows([]string{"id", "user_id", "account_id", "is_deposit", "type", "amount", "status", "failure_code", "failure_reason", "reversed_amount", "reversal_of", "reversal_reason", "date"}).
+		AddRow(1, 10, 3, true, domain.TransactionTypeTopUp, 100.0, domain.TransactionStatusCompleted, domain.ReasonCode(""), "", 0.0, (*int)(nil), domain.ReversalReason(""), after.Add(time.Hour))
+
+	mock.ExpectQuery(`SELECT .* FROM transactions WHERE account_id = \$1 AND status = \$2 AND date > \$3 AND date <= \$4 ORDER BY date, id`).
+		WithArgs(3, domain.TransactionStatusCompleted, after, until).
+		WillReturnRows(rows)
+
+	transactions, err := db.GetCompletedBetween(context.Background(), 3, after, until)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(transactions) != 1 || transactions[0].Type != domain.TransactionTypeTopUp {
+		t.Errorf("unexpected result: %+v", transactions)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
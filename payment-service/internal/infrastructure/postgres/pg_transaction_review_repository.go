@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"payment-service/internal/application/repository"
+	"payment-service/internal/domain"
+)
+
+// reviewColumns — список колонок таблицы transaction_reviews в порядке сканирования scanReview.
+const reviewColumns = `transaction_id, flags, status, created_at, decided_by, comment, decided_at`
+
+// TransactionReviewDb реализует интерфейс repository.TransactionReviewRepository
+// и работает с таблицей transaction_reviews в PostgreSQL.
+type TransactionReviewDb struct {
+	db PgxPool
+}
+
+// NewTransactionReviewDb создаёт новый экземпляр TransactionReviewDb,
+// принимая пул подключений к PostgreSQL.
+func NewTransactionReviewDb(db PgxPool) (repository.TransactionReviewRepository, error) {
+	return TransactionReviewDb{db: db}, nil
+}
+
+// scanReview считывает запись проверки из строки результата запроса.
+func scanReview(row pgx.Row) (*domain.TransactionReview, error) {
+	var review domain.TransactionReview
+	var flags []string
+	err := row.Scan(&review.TransactionId, &flags, &review.Status, &review.CreatedAt,
+		&review.DecidedBy, &review.Comment, &review.DecidedAt)
+	if err != nil {
+		return nil, err
+	}
+	review.Flags = make([]domain.RiskFlag, 0, len(flags))
+	for _, flag := range flags {
+		review.Flags = append(review.Flags, domain.RiskFlag(flag))
+	}
+	return &review, nil
+}
+
+// GetByTransactionId возвращает запись проверки транзакции.
+// Возвращает nil, nil если транзакция не попадала в очередь.
+func (rdb TransactionReviewDb) GetByTransactionId(ctx context.Context, transactionId int) (*domain.TransactionReview, error) {
+	row := conn(ctx, rdb.db).QueryRow(ctx, `
+SELECT `+reviewColumns+`
+FROM transaction_reviews
+WHERE transaction_id = $1
+`, transactionId)
+	review, err := scanReview(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return review, err
+}
+
+// GetPending возвращает операции, ожидающие решения, в порядке постановки в очередь.
+func (rdb TransactionReviewDb) GetPending(ctx context.Context) ([]domain.TransactionReview, error) {
+	rows, err := conn(ctx, rdb.db).Query(ctx, `
+SELECT `+reviewColumns+`
+FROM transaction_reviews
+WHERE status = $1
+ORDER BY created_at, transaction_id
+`, domain.ReviewStatusPending)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reviews := make([]domain.TransactionReview, 0)
+	for rows.Next() {
+		review, err := scanReview(rows)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, *review)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
+
+// Save сохраняет запись проверки.
+// Если запись для транзакции уже существует — обновляет решение администратора.
+func (rdb TransactionReviewDb) Save(ctx context.Context, review *domain.TransactionReview) error {
+	flags := make([]string, 0, len(review.Flags))
+	for _, flag := range review.Flags {
+		flags = append(flags, string(flag))
+	}
+	_, err := conn(ctx, rdb.db).Exec(ctx, `
+INSERT INTO transaction_reviews (`+reviewColumns+`)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (transaction_id) DO UPDATE
+    SET status = EXCLUDED.status,
+        decided_by = EXCLUDED.decided_by,
+        comment = EXCLUDED.comment,
+        decided_at = EXCLUDED.decided_at
+`, &review.TransactionId, flags, &review.Status, &review.CreatedAt, &review.DecidedBy, &review.Comment, review.DecidedAt)
+	return err
+}
@@ -0,0 +1,65 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v3"
+	"payment-service/internal/domain"
+	"payment-service/internal/infrastructure/postgres"
+)
+
+// TestTransactionReviewDb_GetPending проверяет чтение очереди ручной проверки.
+func TestTransactionReviewDb_GetPending(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	db, _ := postgres.NewTransactionReviewDb(mock)
+	rows := pgxmock.NewRows([]string{"transaction_id", "flags", "status", "created_at", "decided_by", "comment", "decided_at"}).
+		AddRow(7, []string{"large_amount", "failed_attempts"}, domain.ReviewStatusPending, time.Now(), "", "", nil)
+
+	mock.ExpectQuery(`SELECT transaction_id, flags, status, created_at, decided_by, comment, decided_at FROM transaction_reviews WHERE status = \$1`).
+		WithArgs(domain.ReviewStatusPending).
+		WillReturnRows(rows)
+
+	reviews, err := db.GetPending(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(reviews) != 1 || reviews[0].TransactionId != 7 || len(reviews[0].Flags) != 2 || reviews[0].Flags[1] != domain.RiskFlagFailedAttempts {
+		t.Errorf("unexpected result: %+v", reviews)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+// TestTransactionReviewDb_Save проверяет сохранение решения по задержанной операции.
+func TestTransactionReviewDb_Save(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock: %v", err)
+	}
+	defer mock.Close()
+
+	db, _ := postgres.NewTransactionReviewDb(mock)
+	decidedAt := time.Now()
+	review := &domain.TransactionReview{TransactionId: 7, Flags: []domain.RiskFlag{domain.RiskFlagLargeAmount},
+		Status: domain.ReviewStatusApproved, CreatedAt: time.Now(), DecidedBy: "admin", DecidedAt: &decidedAt}
+
+	mock.ExpectExec(`INSERT INTO transaction_reviews`).
+		WithArgs(&review.TransactionId, []string{"large_amount"}, &review.Status, &review.CreatedAt, &review.DecidedBy, &review.Comment, review.DecidedAt).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	err = db.Save(context.Background(), review)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+package postgres
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"payment-service/internal/application/repository"
+)
+
+// txKey — ключ контекста, под которым хранится открытая транзакция pgx.
+type txKey struct{}
+
+// querier — общий набор методов пула подключений и транзакции pgx.
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// Transactor реализует интерфейс repository.Transactor поверх пула подключений PostgreSQL.
+type Transactor struct {
+	db PgxPool
+}
+
+// NewTransactor создаёт новый экземпляр Transactor,
+// принимая пул подключений к PostgreSQL.
+func NewTransactor(db PgxPool) (repository.Transactor, error) {
+	return Transactor{db: db}, nil
+}
+
+// WithinTransaction выполняет fn в транзакции PostgreSQL.
+// Если контекст уже содержит транзакцию, fn выполняется в ней.
+func (t Transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return inTransaction(ctx, t.db, func(tx pgx.Tx) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// inTransaction выполняет fn в транзакции из контекста или открывает новую.
+// Новая транзакция фиксируется, только если fn завершилась без ошибки.
+func inTransaction(ctx context.Context, db PgxPool, fn func(tx pgx.Tx) error) error {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return fn(tx)
+	}
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = fn(tx)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// conn возвращает транзакцию из контекста, если она открыта, иначе — пул подключений.
+func conn(ctx context.Context, db PgxPool) querier {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+	return db
+}
+
+// lockClause возвращает FOR UPDATE для чтения внутри транзакции,
+// чтобы параллельные операции над той же строкой выполнялись последовательно.
+func lockClause(ctx context.Context) string {
+	if _, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return "\nFOR UPDATE"
+	}
+	return ""
+}
+
+// skipLockedClause возвращает FOR UPDATE SKIP LOCKED для чтения внутри транзакции,
+// чтобы параллельные обработчики забирали разные строки, не дожидаясь друг друга.
+func skipLockedClause(ctx context.Context) string {
+	if _, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return "\nFOR UPDATE SKIP LOCKED"
+	}
+	return ""
+}
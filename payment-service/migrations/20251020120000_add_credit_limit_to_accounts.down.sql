@@ -0,0 +1,4 @@
+ALTER TABLE accounts
+    DROP COLUMN IF EXISTS overdraft_since,
+    DROP COLUMN IF EXISTS grace_period_days,
+    DROP COLUMN IF EXISTS credit_limit;
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE accounts
+    ADD COLUMN IF NOT EXISTS credit_limit NUMERIC(12,2) NOT NULL DEFAULT 0 CHECK (credit_limit >= 0),
+    ADD COLUMN IF NOT EXISTS grace_period_days INTEGER NOT NULL DEFAULT 30 CHECK (grace_period_days >= 0),
+    ADD COLUMN IF NOT EXISTS overdraft_since TIMESTAMPTZ;
\ No newline at end of file
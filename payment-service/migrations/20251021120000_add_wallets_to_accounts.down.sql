@@ -0,0 +1,11 @@
+ALTER TABLE transactions DROP COLUMN IF EXISTS account_id;
+
+DROP INDEX IF EXISTS accounts_user_id_default_idx;
+DROP INDEX IF EXISTS accounts_user_id_name_idx;
+
+ALTER TABLE accounts
+    DROP COLUMN IF EXISTS is_default,
+    DROP COLUMN IF EXISTS currency,
+    DROP COLUMN IF EXISTS name;
+
+ALTER TABLE accounts ADD CONSTRAINT accounts_user_id_key UNIQUE (user_id);
\ No newline at end of file
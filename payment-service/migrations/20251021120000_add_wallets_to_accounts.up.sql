@@ -0,0 +1,18 @@
+ALTER TABLE accounts DROP CONSTRAINT IF EXISTS accounts_user_id_key;
+
+ALTER TABLE accounts
+    ADD COLUMN IF NOT EXISTS name VARCHAR(64) NOT NULL DEFAULT 'main',
+    ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'RUB',
+    ADD COLUMN IF NOT EXISTS is_default BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE accounts SET is_default = TRUE;
+
+CREATE UNIQUE INDEX IF NOT EXISTS accounts_user_id_name_idx ON accounts (user_id, name);
+CREATE UNIQUE INDEX IF NOT EXISTS accounts_user_id_default_idx ON accounts (user_id) WHERE is_default;
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS account_id INTEGER;
+
+UPDATE transactions t
+SET account_id = a.id
+FROM accounts a
+WHERE a.user_id = t.user_id AND t.account_id IS NULL;
\ No newline at end of file
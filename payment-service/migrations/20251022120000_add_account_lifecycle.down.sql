@@ -0,0 +1,5 @@
+ALTER TABLE transactions DROP COLUMN IF EXISTS type;
+
+DROP TABLE IF EXISTS account_status_changes;
+
+ALTER TABLE accounts DROP COLUMN IF EXISTS status;
\ No newline at end of file
@@ -0,0 +1,18 @@
+ALTER TABLE accounts
+    ADD COLUMN IF NOT EXISTS status VARCHAR(16) NOT NULL DEFAULT 'active'
+        CHECK (status IN ('active', 'frozen', 'suspended', 'closed'));
+
+CREATE TABLE IF NOT EXISTS account_status_changes (
+    id BIGSERIAL PRIMARY KEY,
+    account_id INTEGER NOT NULL REFERENCES accounts (id),
+    from_status VARCHAR(16) NOT NULL,
+    to_status VARCHAR(16) NOT NULL,
+    changed_by VARCHAR(128) NOT NULL,
+    reason TEXT NOT NULL,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS account_status_changes_account_id_idx ON account_status_changes (account_id);
+
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS type VARCHAR(32) NOT NULL DEFAULT 'payment';
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE transactions
+    DROP COLUMN IF EXISTS failure_reason,
+    DROP COLUMN IF EXISTS status;
\ No newline at end of file
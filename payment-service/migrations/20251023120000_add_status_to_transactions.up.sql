@@ -0,0 +1,4 @@
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS status VARCHAR(16) NOT NULL DEFAULT 'completed'
+        CHECK (status IN ('completed', 'declined')),
+    ADD COLUMN IF NOT EXISTS failure_reason TEXT NOT NULL DEFAULT '';
\ No newline at end of file
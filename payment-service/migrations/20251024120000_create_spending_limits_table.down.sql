@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS transactions_account_id_date_idx;
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS failure_code;
+
+DROP TABLE IF EXISTS spending_limits;
\ No newline at end of file
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS spending_limits (
+    account_id INTEGER PRIMARY KEY REFERENCES accounts (id),
+    max_payment NUMERIC(12,2) NOT NULL DEFAULT 0,
+    daily_limit NUMERIC(12,2) NOT NULL DEFAULT 0,
+    monthly_limit NUMERIC(12,2) NOT NULL DEFAULT 0,
+    max_payments_per_hour INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS failure_code VARCHAR(32) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS transactions_account_id_date_idx ON transactions (account_id, date);
\ No newline at end of file
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS transactions_user_id_date_idx;
+
+DROP TABLE IF EXISTS transaction_reviews;
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_status_check;
+
+ALTER TABLE transactions
+    ADD CONSTRAINT transactions_status_check CHECK (status IN ('completed', 'declined'));
\ No newline at end of file
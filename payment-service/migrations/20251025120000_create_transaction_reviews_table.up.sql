@@ -0,0 +1,19 @@
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_status_check;
+
+ALTER TABLE transactions
+    ADD CONSTRAINT transactions_status_check CHECK (status IN ('completed', 'declined', 'pending_review'));
+
+CREATE TABLE IF NOT EXISTS transaction_reviews (
+    transaction_id INTEGER PRIMARY KEY REFERENCES transactions (id),
+    flags TEXT[] NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'approved', 'rejected')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    decided_by VARCHAR(128) NOT NULL DEFAULT '',
+    comment TEXT NOT NULL DEFAULT '',
+    decided_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS transaction_reviews_pending_idx ON transaction_reviews (created_at) WHERE status = 'pending';
+
+CREATE INDEX IF NOT EXISTS transactions_user_id_date_idx ON transactions (user_id, date);
\ No newline at end of file
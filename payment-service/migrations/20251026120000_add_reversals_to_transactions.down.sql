@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS transactions_reversal_of_idx;
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_reversed_amount_check;
+
+ALTER TABLE transactions
+    DROP COLUMN IF EXISTS reversal_reason,
+    DROP COLUMN IF EXISTS reversal_of,
+    DROP COLUMN IF EXISTS reversed_amount;
\ No newline at end of file
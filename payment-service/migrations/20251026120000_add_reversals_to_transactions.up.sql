@@ -0,0 +1,9 @@
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS reversed_amount NUMERIC(12,2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS reversal_of INTEGER REFERENCES transactions (id),
+    ADD COLUMN IF NOT EXISTS reversal_reason VARCHAR(32) NOT NULL DEFAULT '';
+
+ALTER TABLE transactions
+    ADD CONSTRAINT transactions_reversed_amount_check CHECK (reversed_amount >= 0 AND reversed_amount <= amount);
+
+CREATE INDEX IF NOT EXISTS transactions_reversal_of_idx ON transactions (reversal_of) WHERE reversal_of IS NOT NULL;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS top_ups;
\ No newline at end of file
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS top_ups (
+    id INTEGER PRIMARY KEY,
+    account_id INTEGER NOT NULL REFERENCES accounts (id),
+    user_id INTEGER NOT NULL,
+    amount NUMERIC(12,2) NOT NULL CHECK (amount > 0),
+    status VARCHAR(16) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'succeeded', 'failed')),
+    provider VARCHAR(32) NOT NULL,
+    provider_ref VARCHAR(128) NOT NULL DEFAULT '',
+    confirmation_url TEXT NOT NULL DEFAULT '',
+    transaction_id INTEGER REFERENCES transactions (id),
+    failure_reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS top_ups_account_id_idx ON top_ups (account_id, created_at);
+
+CREATE INDEX IF NOT EXISTS top_ups_status_idx ON top_ups (status, created_at);
\ No newline at end of file
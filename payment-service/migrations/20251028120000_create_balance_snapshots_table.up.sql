@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS balance_snapshots (
+    account_id INTEGER NOT NULL REFERENCES accounts (id),
+    taken_at TIMESTAMPTZ NOT NULL,
+    balance NUMERIC(12,2) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (account_id, taken_at)
+);
\ No newline at end of file
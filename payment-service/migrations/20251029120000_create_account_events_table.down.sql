@@ -0,0 +1 @@
+DROP TABLE IF EXISTS account_events;
\ No newline at end of file
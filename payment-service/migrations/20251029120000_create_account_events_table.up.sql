@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS account_events (
+    id BIGSERIAL PRIMARY KEY,
+    type VARCHAR(32) NOT NULL,
+    account_id INTEGER NOT NULL REFERENCES accounts (id),
+    user_id INTEGER NOT NULL,
+    currency CHAR(3) NOT NULL,
+    balance NUMERIC(12,2) NOT NULL,
+    amount NUMERIC(12,2) NOT NULL DEFAULT 0,
+    transaction_id INTEGER REFERENCES transactions (id),
+    status VARCHAR(16) NOT NULL,
+    previous_status VARCHAR(16) NOT NULL DEFAULT '',
+    occurred_at TIMESTAMPTZ NOT NULL,
+    published_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS account_events_unpublished_idx ON account_events (id) WHERE published_at IS NULL;
\ No newline at end of file
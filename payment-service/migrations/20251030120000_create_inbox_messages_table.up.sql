@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS inbox_messages (
+    message_id VARCHAR(128) PRIMARY KEY,
+    response TEXT NOT NULL,
+    reason_code VARCHAR(32) NOT NULL DEFAULT '',
+    processed_at TIMESTAMPTZ NOT NULL
+);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS transactions_pending_review_date_idx;
\ No newline at end of file
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS transactions_pending_review_date_idx ON transactions (date) WHERE status = 'pending_review';
\ No newline at end of file
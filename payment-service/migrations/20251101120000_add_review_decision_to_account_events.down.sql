@@ -0,0 +1,3 @@
+ALTER TABLE account_events
+    DROP COLUMN IF EXISTS failure_code,
+    DROP COLUMN IF EXISTS transaction_status;
\ No newline at end of file
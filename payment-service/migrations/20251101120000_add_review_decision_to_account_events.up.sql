@@ -0,0 +1,3 @@
+ALTER TABLE account_events
+    ADD COLUMN IF NOT EXISTS transaction_status VARCHAR(16) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS failure_code VARCHAR(32) NOT NULL DEFAULT '';
\ No newline at end of file